@@ -0,0 +1,129 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AddEnvVar adds a field of the given Go type, e.g. 'int' or 'time.Duration',
+// or string if it's empty, reading the given env var to the 'Config' struct
+// declared in the given Go file, e.g. 'appcfg/config.go', and appends the
+// env var to the given env file, e.g. '.env', unless it's defined there
+// already, so that adding a setting doesn't require regenerating the
+// package. The field is optional, so that environments that don't set
+// the env var yet keep working, and immutable configs get a getter for it,
+// which records that the env var was read when their usage is tracked.
+// The env var may be given with or without the prefix of the package.
+// Options other than WithFileSystem, which the files are read from and
+// written to, WithFieldNamer, WithStructName and WithFormatter are ignored.
+func AddEnvVar(configFilePath, envFilePath, key, fieldType string, opts ...Option) error {
+	g := NewGenerator("config", opts...).(*generator)
+	if !token.IsIdentifier(key) {
+		return errors.Errorf("invalid env var name %q", key)
+	}
+	if fieldType == "" {
+		fieldType = "string"
+	}
+	if _, err := parser.ParseExpr(fieldType); err != nil {
+		return errors.Errorf("invalid type %q", fieldType)
+	}
+	s, err := g.parseConfigSource(configFilePath)
+	if err != nil {
+		return err
+	}
+	tagKey := s.tagKey(key)
+	envKey := fieldEnvKey(tagKey, "", s.prefix)
+	fieldName := g.fieldNamer(tagKey)
+	if err := checkNewField(s.configStruct, fieldName, tagKey, nil); err != nil {
+		return errors.Wrapf(err, "adding %s to %s", envKey, configFilePath)
+	}
+	var buf bytes.Buffer
+	closing := s.offset(s.configStruct.Fields.Closing)
+	buf.Write(s.src[:closing])
+	fmt.Fprintf(&buf, "\t%s %s `envconfig:%q`\n", fieldName, fieldType, tagKey)
+	buf.Write(s.src[closing:])
+	if findStruct(s.file, configValuesStructName) != nil {
+		fmt.Fprintf(&buf, "\n// %s returns the value of the %s env var.\n", fieldName, envKey)
+		fmt.Fprintf(&buf, "func (c *%s) %s() %s {\n", g.structName, fieldName, fieldType)
+		if s.tracksUsage() {
+			fmt.Fprintf(&buf, "\tmarkUsed(%q)\n", envKey)
+		}
+		fmt.Fprintf(&buf, "\treturn c.values.%s\n}\n", fieldName)
+	}
+	envFileContent, err := envFileWithVar(g.fs, envFilePath, envKey)
+	if err != nil {
+		return err
+	}
+	return g.writeRefactoredFiles(configFilePath, buf.Bytes(), map[string][]byte{envFilePath: envFileContent}, nil)
+}
+
+// checkNewField checks that a field with the given name, reading the
+// env var with the given unprefixed name, can be added to the given
+// struct: that neither the field nor the env var are there already,
+// leaving out the given field, if any, which is the one being renamed.
+func checkNewField(configStruct *ast.StructType, fieldName, tagKey string, renamed *ast.Ident) error {
+	for _, field := range configStruct.Fields.List {
+		tag := fieldTag(field)
+		for _, name := range field.Names {
+			if name == renamed {
+				continue
+			}
+			if name.Name == fieldName {
+				return errors.Errorf("field %s already exists", fieldName)
+			}
+			if tag.Get("ignored") != "true" && fieldEnvKey(name.Name, tag, "") == strings.ToUpper(tagKey) {
+				return errors.Errorf("env var already read into field %s", name.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// envFileWithVar returns the content of the given env file with the given
+// env var appended, with an empty value, unless it's defined there already.
+// Env files that don't exist are taken as empty.
+func envFileWithVar(fsys FileSystem, envFilePath, key string) ([]byte, error) {
+	content, err := fsys.ReadFile(envFilePath)
+	if err != nil && !fsys.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "reading file %s", envFilePath)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if name, ok := envFileVarName(line); ok && name == key {
+			return content, nil
+		}
+	}
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	return append(content, key+"=\n"...), nil
+}
+
+// updateSourceChecksum replaces the checksum of the given env file, if
+// it's recorded in the header of the given source of the given generated
+// file, with the given one, so that the package isn't reported as stale
+// because of the env vars added to both of them.
+func updateSourceChecksum(src, configFilePath, envFilePath, newChecksum string) string {
+	rootDir := filepath.Dir(filepath.Dir(configFilePath))
+	for _, s := range parseSources(src) {
+		sourcePath := filepath.FromSlash(s.path)
+		if !filepath.IsAbs(sourcePath) {
+			sourcePath = filepath.Join(rootDir, sourcePath)
+		}
+		if filepath.Clean(sourcePath) == filepath.Clean(envFilePath) {
+			line := sourceCommentPrefix + s.path + checksumPrefix
+			src = strings.Replace(src, line+s.checksum+"\n", line+newChecksum+"\n", 1)
+		}
+	}
+	return src
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddEnvVar(t *testing.T) {
+	configFile := "// Code generated by goprojconfig; DO NOT EDIT.\n" +
+		"// Source: .env sha256:" + checksum([]byte("PORT=8080\n")) + "\n\n" +
+		"package appcfg\n\n" +
+		"// Config holds all configuration needed by this app.\n" +
+		"type Config struct {\n" +
+		"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+		"}\n"
+	immutableConfigFile := "package appcfg\n\n" +
+		"const envPrefix = \"APP\"\n\n" +
+		"type Config struct {\n\tvalues configValues\n}\n\n" +
+		"type configValues struct {\n" +
+		"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+		"}\n"
+	trackedConfigFile := "package appcfg\n\n" +
+		"type Config struct {\n\tvalues configValues\n}\n\n" +
+		"type configValues struct {\n" +
+		"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+		"}\n\n" +
+		"func (c *Config) Port() int {\n\tmarkUsed(\"PORT\")\n\treturn c.values.Port\n}\n"
+	testCases := []struct {
+		name          string
+		files         map[string][]byte
+		key           string
+		fieldType     string
+		expectedFiles map[string]string
+		expectedError error
+	}{
+		{
+			name:      "happy path",
+			files:     map[string][]byte{"appcfg/config.go": []byte(configFile), ".env": []byte("PORT=8080")},
+			key:       "REQUEST_TIMEOUT",
+			fieldType: "time.Duration",
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "// Code generated by goprojconfig; DO NOT EDIT.\n" +
+					"// Source: .env sha256:" + checksum([]byte("PORT=8080\nREQUEST_TIMEOUT=\n")) + "\n\n" +
+					"package appcfg\n\n" +
+					"import \"time\"\n\n" +
+					"// Config holds all configuration needed by this app.\n" +
+					"type Config struct {\n" +
+					"\tPort           int           `envconfig:\"PORT\" required:\"true\"`\n" +
+					"\tRequestTimeout time.Duration `envconfig:\"REQUEST_TIMEOUT\"`\n" +
+					"}\n",
+				".env": "PORT=8080\nREQUEST_TIMEOUT=\n",
+			},
+		},
+		{
+			name:  "happy path, env var already in env file",
+			files: map[string][]byte{"appcfg/config.go": []byte(configFile), ".env": []byte("PORT=8080\nexport HOST=localhost\n")},
+			key:   "HOST",
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "// Code generated by goprojconfig; DO NOT EDIT.\n" +
+					"// Source: .env sha256:" + checksum([]byte("PORT=8080\nexport HOST=localhost\n")) + "\n\n" +
+					"package appcfg\n\n" +
+					"// Config holds all configuration needed by this app.\n" +
+					"type Config struct {\n" +
+					"\tPort int    `envconfig:\"PORT\" required:\"true\"`\n" +
+					"\tHost string `envconfig:\"HOST\"`\n" +
+					"}\n",
+				".env": "PORT=8080\nexport HOST=localhost\n",
+			},
+		},
+		{
+			name:      "happy path, immutable config with prefix",
+			files:     map[string][]byte{"appcfg/config.go": []byte(immutableConfigFile)},
+			key:       "APP_RETRIES",
+			fieldType: "int",
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "package appcfg\n\n" +
+					"const envPrefix = \"APP\"\n\n" +
+					"type Config struct {\n\tvalues configValues\n}\n\n" +
+					"type configValues struct {\n" +
+					"\tPort    int `envconfig:\"PORT\" required:\"true\"`\n" +
+					"\tRetries int `envconfig:\"RETRIES\"`\n" +
+					"}\n\n" +
+					"// Retries returns the value of the APP_RETRIES env var.\n" +
+					"func (c *Config) Retries() int {\n\treturn c.values.Retries\n}\n",
+				".env": "APP_RETRIES=\n",
+			},
+		},
+		{
+			name:      "happy path, immutable config tracking usage",
+			files:     map[string][]byte{"appcfg/config.go": []byte(trackedConfigFile)},
+			key:       "RETRIES",
+			fieldType: "int",
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "package appcfg\n\n" +
+					"type Config struct {\n\tvalues configValues\n}\n\n" +
+					"type configValues struct {\n" +
+					"\tPort    int `envconfig:\"PORT\" required:\"true\"`\n" +
+					"\tRetries int `envconfig:\"RETRIES\"`\n" +
+					"}\n\n" +
+					"func (c *Config) Port() int {\n\tmarkUsed(\"PORT\")\n\treturn c.values.Port\n}\n\n" +
+					"// Retries returns the value of the RETRIES env var.\n" +
+					"func (c *Config) Retries() int {\n\tmarkUsed(\"RETRIES\")\n\treturn c.values.Retries\n}\n",
+				".env": "RETRIES=\n",
+			},
+		},
+		{
+			name:          "invalid env var name",
+			key:           "REQUEST-TIMEOUT",
+			expectedError: errors.New(`invalid env var name "REQUEST-TIMEOUT"`),
+		},
+		{
+			name:          "invalid type",
+			key:           "REQUEST_TIMEOUT",
+			fieldType:     "time.",
+			expectedError: errors.New(`invalid type "time."`),
+		},
+		{
+			name:          "error reading config file",
+			key:           "HOST",
+			expectedError: errors.New("reading file appcfg/config.go: open appcfg/config.go: file does not exist"),
+		},
+		{
+			name:          "error parsing config file",
+			files:         map[string][]byte{"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {")},
+			key:           "HOST",
+			expectedError: errors.New("parsing file appcfg/config.go: appcfg/config.go:3:21: expected '}', found 'EOF'"),
+		},
+		{
+			name:          "struct not found",
+			files:         map[string][]byte{"appcfg/config.go": []byte("package appcfg\n")},
+			key:           "HOST",
+			expectedError: errors.New("struct Config not found in appcfg/config.go"),
+		},
+		{
+			name:          "field already exists",
+			files:         map[string][]byte{"appcfg/config.go": []byte(configFile)},
+			key:           "port",
+			expectedError: errors.New("adding PORT to appcfg/config.go: field Port already exists"),
+		},
+		{
+			name:          "env var already read",
+			files:         map[string][]byte{"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {\n\tHttpPort int `envconfig:\"PORT\"`\n}\n")},
+			key:           "PORT",
+			expectedError: errors.New("adding PORT to appcfg/config.go: env var already read into field HttpPort"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(tc.files)
+			err := AddEnvVar("appcfg/config.go", ".env", tc.key, tc.fieldType, WithFileSystem(fsys), WithFieldNamer(PascalCaseFieldNamer))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				files := fsys.Files()
+				for name, content := range tc.expectedFiles {
+					require.Equal(t, content, string(files[name]), name)
+				}
+			}
+		})
+	}
+}
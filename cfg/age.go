@@ -0,0 +1,100 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/pkg/errors"
+)
+
+// AgeEnvFileExtension is the extension of the env files
+// encrypted with EncryptEnvFile, e.g. '.env.age'.
+const AgeEnvFileExtension = ".age"
+
+// EncryptEnvFile encrypts the given env file with age for the given
+// recipients, i.e. age public keys like 'age1...', writing it to the
+// given path, e.g. '.env.age', which can be committed, so that teams
+// share secrets at rest without running a secrets manager. Options
+// other than WithFileSystem, which the files are read from and written
+// to, are ignored.
+func EncryptEnvFile(envFilePath, encryptedFilePath string, recipients []string, opts ...Option) error {
+	g := NewGenerator("config", opts...).(*generator)
+	if len(recipients) == 0 {
+		return errors.New("no recipients given")
+	}
+	var ageRecipients []age.Recipient
+	for _, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(r))
+		if err != nil {
+			return errors.Wrapf(err, "parsing recipient %s", r)
+		}
+		ageRecipients = append(ageRecipients, recipient)
+	}
+	content, err := g.readFile(envFilePath)
+	if err != nil {
+		return err
+	}
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, ageRecipients...)
+	if err != nil {
+		return errors.Wrapf(err, "encrypting %s", envFilePath)
+	}
+	if _, err := w.Write(content); err != nil {
+		return errors.Wrapf(err, "encrypting %s", envFilePath)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "encrypting %s", envFilePath)
+	}
+	if err := g.fs.WriteFile(encryptedFilePath, encrypted.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "writing file %s", encryptedFilePath)
+	}
+	return nil
+}
+
+// DecryptEnvFile decrypts the given env file, encrypted with
+// EncryptEnvFile, with the identities of the given identity file, e.g.
+// one generated by 'age-keygen', writing it to the given path, e.g.
+// '.env', readable by its owner only. Options other than WithFileSystem,
+// which the files are read from and written to, are ignored.
+func DecryptEnvFile(encryptedFilePath, envFilePath, identityFilePath string, opts ...Option) error {
+	g := NewGenerator("config", opts...).(*generator)
+	identityFile, err := g.readFile(identityFilePath)
+	if err != nil {
+		return err
+	}
+	identities, err := age.ParseIdentities(bytes.NewReader(identityFile))
+	if err != nil {
+		return errors.Wrapf(err, "parsing identities of %s", identityFilePath)
+	}
+	encrypted, err := g.readFile(encryptedFilePath)
+	if err != nil {
+		return err
+	}
+	r, err := age.Decrypt(bytes.NewReader(encrypted), identities...)
+	if err != nil {
+		return errors.Wrapf(err, "decrypting %s", encryptedFilePath)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return errors.Wrapf(err, "decrypting %s", encryptedFilePath)
+	}
+	if err := g.fs.WriteFile(envFilePath, content, 0600); err != nil {
+		return errors.Wrapf(err, "writing file %s", envFilePath)
+	}
+	return nil
+}
+
+// readFile returns the content of the given file.
+func (g *generator) readFile(filePath string) ([]byte, error) {
+	content, err := g.fs.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading file %s", filePath)
+	}
+	return content, nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	ageFileName                 = "age.go"
+	ageUnitTestFileName         = "age_test.go"
+	ageFileTemplateName         = "ageFile"
+	ageUnitTestFileTemplateName = "ageUnitTestFile"
+	ageFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"os"
+
+	"filippo.io/age"
+	"github.com/pkg/errors"
+)
+
+// {{ .ReaderName }}FromAgeEnvFile reads configuration from the given env file encrypted
+// with age, e.g. with 'goprojconfig encrypt', decrypting it with the identities
+// of the given identity file, e.g. one generated by 'age-keygen'. The decrypted
+// env file is never written to disk. As with the other functions, env vars
+// that are already set take precedence over the env file ones.
+func {{ .ReaderName }}FromAgeEnvFile({{ if .Context }}ctx context.Context, {{ end }}encryptedFilePath, identityFilePath string) (*{{ .StructName }}, error) {
+	identityFile, err := os.Open(identityFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", identityFilePath)
+	}
+	defer identityFile.Close()
+	identities, err := age.ParseIdentities(identityFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing identities of %s", identityFilePath)
+	}
+	encryptedFile, err := os.Open(encryptedFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", encryptedFilePath)
+	}
+	defer encryptedFile.Close()
+	envFile, err := age.Decrypt(encryptedFile, identities...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting %s", encryptedFilePath)
+	}
+	config, err := {{ .ReaderName }}FromReader({{ if .Context }}ctx, {{ end }}envFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", encryptedFilePath)
+	}
+	return config, nil
+}
+`
+
+	ageUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"bytes"
+{{- if .Context }}
+	"context"
+{{- end }}
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+{{- if not .DockerDialect }}
+	"github.com/joho/godotenv"
+{{- end }}
+	"github.com/stretchr/testify/require"
+)
+
+func Test{{ .ReaderName }}FromAgeEnvFile(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, identity.Recipient())
+	require.NoError(t, err)
+	_, err = w.Write([]byte("DB_HOST=localhost\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	dir := t.TempDir()
+	files := map[string]string{
+		".env.age":        encrypted.String(),
+		"key.txt":         identity.String() + "\n",
+		"other-key.txt":   otherIdentity.String() + "\n",
+		"invalid-key.txt": "invalid\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	testCases := []struct {
+		name                   string
+		encryptedFile          string
+		identityFile           string
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name:          "happy path",
+			encryptedFile: ".env.age",
+			identityFile:  "key.txt",
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "localhost"},
+		},
+		{
+			name:          "missing identity file",
+			encryptedFile: ".env.age",
+			identityFile:  "missing-key.txt",
+			expectedError: errors.New("opening " + filepath.Join(dir, "missing-key.txt")),
+		},
+		{
+			name:          "invalid identity file",
+			encryptedFile: ".env.age",
+			identityFile:  "invalid-key.txt",
+			expectedError: errors.New("parsing identities of " + filepath.Join(dir, "invalid-key.txt")),
+		},
+		{
+			name:          "missing encrypted file",
+			encryptedFile: ".env.local.age",
+			identityFile:  "key.txt",
+			expectedError: errors.New("opening " + filepath.Join(dir, ".env.local.age")),
+		},
+		{
+			name:          "wrong identity",
+			encryptedFile: ".env.age",
+			identityFile:  "other-key.txt",
+			expectedError: errors.New("decrypting " + filepath.Join(dir, ".env.age")),
+		},
+		{
+			name:          "error processing env vars",
+			encryptedFile: ".env.age",
+			identityFile:  "key.txt",
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("reading " + filepath.Join(dir, ".env.age") + ": processing env vars: random error"),
+		},
+	}
+	parse, lookup, set, process := godotenvParse, lookupEnv, setenv, envconfigProcess
+	t.Cleanup(func() {
+		godotenvParse, lookupEnv, setenv, envconfigProcess = parse, lookup, set, process
+	})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := make(map[string]string)
+			godotenvParse = {{ if .DockerDialect }}parseDockerEnv{{ else }}godotenv.Parse{{ end }}
+			lookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			setenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}FromAgeEnvFile({{ if .Context }}context.Background(), {{ end }}filepath.Join(dir, tc.encryptedFile), filepath.Join(dir, tc.identityFile))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+`
+)
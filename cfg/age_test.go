@@ -0,0 +1,131 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecryptEnvFile(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	envFile := []byte("DB_HOST=localhost\nDB_PASSWORD=s3cr3t\n")
+	fsys := NewMemFileSystem(map[string][]byte{
+		".env":          envFile,
+		"key.txt":       []byte("# created: 2024-01-01T00:00:00Z\n" + identity.String() + "\n"),
+		"other-key.txt": []byte(otherIdentity.String() + "\n"),
+	})
+	recipients := []string{identity.Recipient().String(), otherIdentity.Recipient().String()}
+	require.NoError(t, EncryptEnvFile(".env", ".env.age", recipients, WithFileSystem(fsys)))
+	encrypted := fsys.Files()[".env.age"]
+	require.NotContains(t, string(encrypted), "s3cr3t")
+	for _, identityFile := range []string{"key.txt", "other-key.txt"} {
+		require.NoError(t, DecryptEnvFile(".env.age", "decrypted/.env", identityFile, WithFileSystem(fsys)))
+		require.Equal(t, envFile, fsys.Files()["decrypted/.env"])
+	}
+}
+
+func TestEncryptEnvFileErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		envFilePath   string
+		recipients    []string
+		expectedError error
+	}{
+		{
+			name:          "no recipients",
+			envFilePath:   ".env",
+			expectedError: errors.New("no recipients given"),
+		},
+		{
+			name:          "invalid recipient",
+			envFilePath:   ".env",
+			recipients:    []string{"invalid"},
+			expectedError: errors.New(`parsing recipient invalid: malformed recipient "invalid": separator '1' at invalid position: pos=-1, len=7`),
+		},
+		{
+			name:          "missing env file",
+			envFilePath:   ".env.local",
+			recipients:    []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"},
+			expectedError: errors.New("reading file .env.local: open .env.local: file does not exist"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte("DB_HOST=localhost\n")})
+			err := EncryptEnvFile(tc.envFilePath, ".env.age", tc.recipients, WithFileSystem(fsys))
+			if err == nil {
+				t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+			}
+			require.Equal(t, tc.expectedError.Error(), err.Error())
+		})
+	}
+}
+
+func TestDecryptEnvFileErrors(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	fsys := NewMemFileSystem(map[string][]byte{
+		".env":            []byte("DB_HOST=localhost\n"),
+		"key.txt":         []byte(identity.String() + "\n"),
+		"other-key.txt":   []byte(otherIdentity.String() + "\n"),
+		"invalid-key.txt": []byte("invalid\n"),
+	})
+	require.NoError(t, EncryptEnvFile(".env", ".env.age", []string{identity.Recipient().String()}, WithFileSystem(fsys)))
+	testCases := []struct {
+		name              string
+		encryptedFilePath string
+		identityFilePath  string
+		expectedError     error
+	}{
+		{
+			name:              "missing identity file",
+			encryptedFilePath: ".env.age",
+			identityFilePath:  "missing-key.txt",
+			expectedError:     errors.New("reading file missing-key.txt: open missing-key.txt: file does not exist"),
+		},
+		{
+			name:              "invalid identity file",
+			encryptedFilePath: ".env.age",
+			identityFilePath:  "invalid-key.txt",
+			expectedError:     errors.New("parsing identities of invalid-key.txt: error at line 1: malformed secret key: separator '1' at invalid position: pos=-1, len=7"),
+		},
+		{
+			name:              "missing encrypted file",
+			encryptedFilePath: ".env.local.age",
+			identityFilePath:  "key.txt",
+			expectedError:     errors.New("reading file .env.local.age: open .env.local.age: file does not exist"),
+		},
+		{
+			name:              "not encrypted",
+			encryptedFilePath: ".env",
+			identityFilePath:  "key.txt",
+			expectedError:     errors.New(`decrypting .env: failed to read header: parsing age header: unexpected intro: "DB_HOST=localhost\n"`),
+		},
+		{
+			name:              "wrong identity",
+			encryptedFilePath: ".env.age",
+			identityFilePath:  "other-key.txt",
+			expectedError:     errors.New("decrypting .env.age: no identity matched any of the recipients"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := DecryptEnvFile(tc.encryptedFilePath, ".env.decrypted", tc.identityFilePath, WithFileSystem(fsys))
+			if err == nil {
+				t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+			}
+			require.Equal(t, tc.expectedError.Error(), err.Error())
+		})
+	}
+}
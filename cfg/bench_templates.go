@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	benchFileName         = "config_bench_test.go"
+	benchFileTemplateName = "benchFile"
+	benchFileTemplate     = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// benchEnvFile is the env file read by the benchmarks.
+const benchEnvFile = {{ .BenchEnvFile }}
+
+// The functions reading configuration as set before the tests,
+// which may leave them mocked, run.
+var (
+	benchGodotenvLoad     = godotenvLoad
+	benchEnvconfigProcess = envconfigProcess
+	benchLookupEnv        = lookupEnv
+	benchSetenv           = setenv
+{{- if .Profiles }}
+	benchOsGetenv         = osGetenv
+{{- end }}
+{{- if .Reload }}
+	benchGodotenvOverload = godotenvOverload
+{{- end }}
+)
+
+// setUpBenchmark makes the benchmark read configuration with the actual
+// functions reading it, from benchEnvFile written into a temporary working
+// dir, whose path is returned, without the env vars of the environment
+// running it. Env vars set by the first read are kept by the next ones, as
+// in processes reading configuration again.
+{{- if .Secrets }}
+// Secret loaders are left out, so that the latency of secrets managers
+// isn't measured.
+{{- end }}
+func setUpBenchmark(b *testing.B) string {
+	godotenvLoad, envconfigProcess, lookupEnv, setenv = benchGodotenvLoad, benchEnvconfigProcess, benchLookupEnv, benchSetenv
+{{- if .Profiles }}
+	osGetenv = benchOsGetenv
+{{- end }}
+{{- if .Reload }}
+	godotenvOverload = benchGodotenvOverload
+{{- end }}
+{{- if .Secrets }}
+	loaders := secretLoaders
+	secretLoaders = nil
+	b.Cleanup(func() {
+		secretLoaders = loaders
+	})
+{{- end }}
+	keys := []string{
+{{- if .Profiles }}
+		appEnvVar,
+{{- end }}
+	}
+	configType := reflect.TypeOf({{ if .Immutable }}configValues{{ else }}{{ .StructName }}{{ end }}{})
+	for i := 0; i < configType.NumField(); i++ {
+		if key := configType.Field(i).Tag.Get("envconfig"); key != "" {
+			keys = append(keys, {{ if .EnvPrefix }}envPrefix+"_"+{{ end }}key)
+		}
+	}
+	for _, key := range keys {
+		b.Setenv(key, "")
+		if err := os.Unsetenv(key); err != nil {
+			b.Fatal(err)
+		}
+	}
+	dir := b.TempDir()
+	envFilePath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFilePath, []byte(benchEnvFile), 0644); err != nil {
+		b.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			b.Fatal(err)
+		}
+	})
+	return envFilePath
+}
+
+func Benchmark{{ .ReaderName }}(b *testing.B) {
+	setUpBenchmark(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := {{ .ReaderName }}({{ if .Context }}context.Background(){{ end }}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProcessEnvVars(b *testing.B) {
+	setUpBenchmark(b)
+	if _, err := {{ .ReaderName }}({{ if .Context }}context.Background(){{ end }}); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := processEnvVars({{ if .Context }}context.Background(), {{ end }}new({{ .StructName }})); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+{{- if .Reload }}
+
+func BenchmarkReload(b *testing.B) {
+	envFilePath := setUpBenchmark(b)
+	previous := Current()
+	b.Cleanup(func() {
+		current.Store(previous)
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := reload({{ if .Context }}context.Background(), {{ end }}envFilePath, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+{{- end }}
+`
+)
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testEnvFile is the env file the generated packages built by
+// TestGeneratedPackageBuilds are generated from.
+const testEnvFile = "# required\nDB_HOST=localhost\n# optional\nDB_PASSWORD=secret\nPORT=8080\nTIMEOUT=5s\n"
+
+func TestGeneratedPackageBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building generated packages in short mode")
+	}
+	testCases := []struct {
+		name        string
+		packageName string
+		options     []Option
+	}{
+		{
+			name:        "test config package of a package named config",
+			packageName: "config",
+			options:     []Option{WithTestConfigPackage()},
+		},
+		{
+			name:        "test config package of a package named config, real tests",
+			packageName: "config",
+			options:     []Option{WithTestConfigPackage(), WithTestStyle(RealTestStyle)},
+		},
+		{
+			name:        "schema checksum",
+			packageName: "config",
+			options:     []Option{WithSchemaChecksum()},
+		},
+		{
+			name:        "schema checksum, real tests",
+			packageName: "config",
+			options:     []Option{WithSchemaChecksum(), WithTestStyle(RealTestStyle)},
+		},
+		{
+			name:        "age env file, real tests",
+			packageName: "config",
+			options:     []Option{WithAgeEnvFile(), WithTestStyle(RealTestStyle)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := buildTestModule(t)
+			getwd := func() (string, error) { return dir, nil }
+			g := NewGenerator(tc.packageName, append([]Option{WithOutputDir(dir), withGetwd(getwd)}, tc.options...)...)
+			_, err := g.GenerateConfigPackageFromEnvFile(filepath.Join(dir, ".env"))
+			require.NoError(t, err)
+			for _, args := range [][]string{{"vet", "./..."}, {"test", "-count=1", "./..."}} {
+				cmd := exec.Command("go", args...)
+				cmd.Dir = dir
+				cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+				output, err := cmd.CombinedOutput()
+				require.NoError(t, err, "go %v:\n%s", args, output)
+			}
+		})
+	}
+}
+
+// buildTestModule returns a temporary dir holding a Go module, requiring
+// the same modules as this one, and testEnvFile as its '.env' file.
+func buildTestModule(t *testing.T) string {
+	dir := t.TempDir()
+	gomod, err := os.ReadFile(filepath.Join("..", "go.mod"))
+	require.NoError(t, err)
+	gomod = regexp.MustCompile(`(?m)^module .*$`).ReplaceAll(gomod, []byte("module example.com/generated"))
+	gosum, err := os.ReadFile(filepath.Join("..", "go.sum"))
+	require.NoError(t, err)
+	files := map[string][]byte{"go.mod": gomod, "go.sum": gosum, ".env": []byte(testEnvFile)}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0644))
+	}
+	return dir
+}
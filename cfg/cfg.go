@@ -5,11 +5,16 @@
 package cfg
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -20,24 +25,9 @@ const (
 	envFileName                  = ".env"
 )
 
-// For ease of unit testing.
-var (
-	// fsProvider is a variable of interface type fileSystem. It abstracts
-	// file system operations and allows the use of different file system
-	// implementations (like mocks for testing).
-	fsProvider fileSystem = osFileSystem{}
-
-	// templateProcessorProvider is a variable of interface type templateProcessor.
-	// It abstracts template parsing and execution and allows different implementations.
-	templateProcessorProvider templateProcessor = textTemplateProcessor{}
-
-	// lr is a function that returns a lineReader interface. It abstracts the creation
-	// of a lineReader, which provides functions for scanning lines, retrieving the text
-	// of the current line, and obtaining any encountered errors during the scanning process.
-	lr = func(r io.Reader) lineReader {
-		return bufio.NewScanner(r)
-	}
-)
+// StdinEnvFile is the env file path standing for the standard input,
+// e.g. to generate a package from the output of another command.
+const StdinEnvFile = "-"
 
 // Generator is an interface for generating configuration files.
 type Generator interface {
@@ -47,71 +37,414 @@ type Generator interface {
 	// GenerateConfigPackage generates 'config' package with '<packagename>/config.go'
 	// and '<packagename>/config_test.go' using a provided .env file.
 	GenerateConfigPackageFromEnvFile(envFilePath string) ([]string, error)
+	// GenerateConfigPackageFromEnvFiles works like GenerateConfigPackageFromEnvFile,
+	// but merges the provided env files first. Variables defined in later
+	// files override the ones defined in earlier files, like '.env.local'
+	// usually overrides '.env'.
+	GenerateConfigPackageFromEnvFiles(envFilePaths ...string) ([]string, error)
+	// GenerateConfigPackageFromK8sManifest works like GenerateConfigPackageFromEnvFile,
+	// but reads the variables from the data keys of the Kubernetes ConfigMaps
+	// and Secrets defined in the provided manifest.
+	GenerateConfigPackageFromK8sManifest(manifestPath string) ([]string, error)
 }
 
 // generator struct implements the Generator interface.
 type generator struct {
-	packageName string
+	packageName        string
+	fieldNamer         func(envKey string) string
+	defaultsFromValues bool
+	profiles           bool
+	hotReload          bool
+	sighupReload       bool
+	singleton          bool
+	secretsBackends    []SecretsBackend
+	remoteSources      []RemoteSource
+	dockerCompose      bool
+	gha                bool
+	helm               bool
+	systemd            bool
+	terraform          bool
+	readme             bool
+	jsonSchema         bool
+	cue                bool
+	gitignore          bool
+	noGoGenerate       bool
+	noTests            bool
+	inferCollections   bool
+	envrc              bool
+	outputDir          string
+	changelog          bool
+	plugins            []Plugin
+	structTemplate     string
+	schemaVersion      int
+	dialect            Dialect
+	docsFormat         DocsFormat
+	immutable          bool
+	usageTracking      bool
+	benchmarks         bool
+	benchEnvFile       string
+	clone              bool
+	maskSecrets        bool
+	validatorTags      bool
+	checkPaths         bool
+	debugHandler       bool
+	metrics            bool
+	tracing            bool
+	withContext        bool
+	embed              bool
+	overrides          bool
+	flagsLibrary       FlagsLibrary
+	withFeatures       bool
+	testConfigPackage  bool
+	configProvider     bool
+	verify             bool
+	validation         bool
+	pathChecks         bool
+	sensitiveVars      []string
+	secretFiles        bool
+	ageEnvFile         bool
+	checksum           bool
+	strict             bool
+	knownEnvVars       []string
+	schema             []schemaEnvVar
+	secretFileVars     []string
+	flags              []configFlag
+	features           []feature
+	providerMethods    []providerMethod
+	goGenerateFlags    []string
+	logger             *slog.Logger
+	testStyle          TestStyle
+	sortOrder          SortOrder
+	includes           []string
+	prefix             string
+	excludes           []string
+	version            string
+	license            string
+	structName         string
+	readerName         string
+	modImportPath      string
+	header             string
+
+	// fs abstracts file system operations, allowing the use of different
+	// file system implementations, like mocks for testing or in-memory ones.
+	fs FileSystem
+	// templateProcessor abstracts template parsing and execution.
+	templateProcessor TemplateProcessor
+	// formatter formats the generated Go files.
+	formatter Formatter
+	// newLineReader returns a lineReader reading the lines of env files.
+	newLineReader func(r io.Reader) lineReader
+	// maxLineLength is the maximum length, in bytes, of those lines.
+	maxLineLength int
+	// stdin is where the StdinEnvFile env file is read from.
+	stdin io.Reader
+	// stdinContent is the content read from stdin, if any.
+	stdinContent []byte
+	// getwd returns the working dir, which is used to find
+	// the module enclosing the generated package.
+	getwd func() (string, error)
+	// now returns the current time, which dates the changelog entries.
+	now func() time.Time
 }
 
 // NewGenerator creates a new instance of Generator.
-func NewGenerator(packageName string) Generator {
-	return &generator{
-		packageName: packageName,
+func NewGenerator(packageName string, opts ...Option) Generator {
+	g := &generator{
+		packageName:    packageName,
+		fieldNamer:     CamelCaseFieldNamer,
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		testStyle:      MockTestStyle,
+		dialect:        GodotenvDialect,
+		docsFormat:     MarkdownDocsFormat,
+		sortOrder:      SourceOrder,
+		structName:     defaultStructName,
+		structTemplate: structTemplate,
+		readerName:     defaultReaderName,
+
+		fs:                osFileSystem{},
+		templateProcessor: textTemplateProcessor{},
+		formatter:         coreFormatter{},
+		maxLineLength:     DefaultMaxLineLength,
+		stdin:             os.Stdin,
+		getwd:             os.Getwd,
+		now:               time.Now,
+	}
+	g.newLineReader = func(r io.Reader) lineReader {
+		return newBufferedLineReader(r, g.maxLineLength)
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	return g
 }
 
 func (g *generator) GenerateConfigPackage() ([]string, error) {
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+	g.modImportPath = g.importPath()
 	generatedFiles, err := g.generateConfigReaderFiles()
 	if err != nil {
 		return nil, err
 	}
+	if g.verify {
+		if err := g.verifyPackages(generatedFiles); err != nil {
+			return nil, err
+		}
+	}
 	return generatedFiles, nil
 }
 
 func (g *generator) GenerateConfigPackageFromEnvFile(envFilePath string) ([]string, error) {
-	generatedFiles, err := g.generateConfigReaderFilesFromEnvFile(envFilePath)
+	return g.GenerateConfigPackageFromEnvFiles(envFilePath)
+}
+
+func (g *generator) GenerateConfigPackageFromEnvFiles(envFilePaths ...string) ([]string, error) {
+	return g.generateConfigPackageFromEnvFiles(envFilePaths, nil)
+}
+
+func (g *generator) GenerateConfigPackageFromK8sManifest(manifestPath string) ([]string, error) {
+	return g.generateConfigPackageFromEnvFiles([]string{manifestPath}, parseK8sManifest)
+}
+
+// generateConfigPackageFromEnvFiles generates the config package from the
+// given env files, parsed with the given parser, or with the one matching
+// each env file if nil.
+func (g *generator) generateConfigPackageFromEnvFiles(envFilePaths []string, parse envFileParser) ([]string, error) {
+	if len(envFilePaths) == 0 {
+		return nil, errors.New("no env files provided")
+	}
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+	g.modImportPath = g.importPath()
+	generatedFiles, err := g.generateConfigReaderFilesFromEnvFiles(envFilePaths, parse)
 	if err != nil {
 		return nil, err
 	}
+	if g.verify {
+		if err := g.verifyPackages(generatedFiles); err != nil {
+			return nil, err
+		}
+	}
 	return generatedFiles, nil
 }
 
-// generateConfigReaderFilesFromEnvFile generates config reader files from .env file.
-func (g *generator) generateConfigReaderFilesFromEnvFile(envFilePath string) ([]string, error) {
+// validate checks that the package name and the generator options are valid.
+func (g *generator) validate() error {
+	if err := ValidatePackageName(g.packageName); err != nil {
+		return err
+	}
+	if err := g.validateNames(); err != nil {
+		return err
+	}
+	for _, backend := range g.secretsBackends {
+		if _, ok := secretsBackendFiles[backend]; !ok {
+			return errors.Errorf("unsupported secrets backend %s", backend)
+		}
+	}
+	for _, source := range g.remoteSources {
+		if _, ok := remoteSourceFiles[source]; !ok {
+			return errors.Errorf("unsupported remote source %s", source)
+		}
+	}
+	if _, ok := flagsLibraryFiles[g.flagsLibrary]; g.flagsLibrary != "" && !ok {
+		return errors.Errorf("unsupported flags library %s", g.flagsLibrary)
+	}
+	if g.testStyle != MockTestStyle && g.testStyle != RealTestStyle {
+		return errors.Errorf("unsupported test style %s", g.testStyle)
+	}
+	if g.prefix != "" && !isValidPrefix(g.prefix) {
+		return errors.Errorf("invalid prefix %q", g.prefix)
+	}
+	if g.dialect != GodotenvDialect && g.dialect != DockerDialect {
+		return errors.Errorf("unsupported dialect %s", g.dialect)
+	}
+	if g.maxLineLength <= 0 {
+		return errors.Errorf("invalid max line length %d: it must be positive", g.maxLineLength)
+	}
+	if g.schemaVersion < 0 {
+		return errors.Errorf("invalid schema version %d: it must be positive", g.schemaVersion)
+	}
+	if g.sortOrder != SourceOrder && g.sortOrder != FieldsOrder {
+		return errors.Errorf("unsupported sort order %s", g.sortOrder)
+	}
+	if g.testConfigPackage && g.immutable {
+		return errors.New("the test config package can't set the fields of an immutable config")
+	}
+	if g.metrics && !g.hotReload && !g.sighupReload {
+		return errors.New("metrics require hot reload or SIGHUP reload")
+	}
+	if g.benchmarks && g.noTests {
+		return errors.New("benchmarks can't be generated without tests")
+	}
+	if g.usageTracking && !g.immutable {
+		return errors.New("usage tracking requires an immutable config")
+	}
+	if g.strict && g.prefix == "" {
+		return errors.New("strict mode requires a prefix")
+	}
+	return nil
+}
+
+// hasSecretsBackend reports whether the given secrets backend is enabled.
+func (g *generator) hasSecretsBackend(backend SecretsBackend) bool {
+	for _, b := range g.secretsBackends {
+		if b == backend {
+			return true
+		}
+	}
+	return false
+}
+
+// generateConfigReaderFilesFromEnvFiles generates config reader files from env files.
+func (g *generator) generateConfigReaderFilesFromEnvFiles(envFilePaths []string, parse envFileParser) ([]string, error) {
 	var generatedFiles []string
-	if err := fsProvider.Mkdir(g.packageName); err != nil && !os.IsExist(err) {
-		return nil, errors.Wrapf(err, "creating dir %s", g.packageName)
+	if err := g.fs.Mkdir(g.packageDir()); err != nil && !os.IsExist(err) {
+		return nil, errors.Wrapf(err, "creating dir %s", g.packageDir())
+	}
+	vars, err := g.readEnvFiles(envFilePaths, parse)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.afterParse(vars); err != nil {
+		return nil, err
+	}
+	sources, err := g.readSources(withoutStdinEnvFile(envFilePaths))
+	if err != nil {
+		return nil, err
+	}
+	g.header = g.generatedHeader(sources)
+	g.validation = g.hasValidation(vars)
+	g.pathChecks = g.hasPathChecks(vars)
+	g.sensitiveVars = sensitiveVarKeys(vars)
+	g.secretFileVars = secretFileVarKeys(vars)
+	if g.checksum {
+		g.schema = g.schemaEnvVars(vars)
+	}
+	if g.strict {
+		g.knownEnvVars = g.knownEnvVarKeys(vars)
+	}
+	if g.benchmarks {
+		g.benchEnvFile, _, _ = realTestValues(vars, g.fieldName, g.structName, g.immutable, g.dialect)
+	}
+	g.flags = g.configFlags(vars)
+	g.features = g.featureFlags(vars)
+	g.providerMethods = g.providerGetters(vars)
+	var previousFields []structField
+	if g.changelog {
+		if previousFields, err = g.previousStructFields(); err != nil {
+			return nil, err
+		}
 	}
-	mainFilePath, err := g.generateConfigReaderMainFile(envFilePath)
+	g.logger.Debug("generating struct", "fields", len(vars))
+	imports := append(structImports(vars), g.validationImports(vars)...)
+	sort.Strings(imports)
+	configStruct, err := g.generateStruct(vars)
+	if err != nil {
+		return nil, err
+	}
+	mainFilePath, err := g.generateConfigReaderMainFile(configStruct, imports, g.goGenerateCommand(envFilePaths))
 	if err != nil {
 		return nil, err
 	}
 	generatedFiles = append(generatedFiles, mainFilePath)
-	unitTestFilePath, err := g.generateConfigReaderUnitTestFile()
+	if g.changelog {
+		changelogFilePath, err := g.generateChangelog(previousFields, vars)
+		if err != nil {
+			return nil, err
+		}
+		if changelogFilePath != "" {
+			generatedFiles = append(generatedFiles, changelogFilePath)
+		}
+	}
+	if !g.noTests {
+		unitTestFilePath, err := g.generateConfigReaderUnitTestFile(vars, g.fieldName)
+		if err != nil {
+			return nil, err
+		}
+		generatedFiles = append(generatedFiles, unitTestFilePath)
+	}
+	optionalFilePaths, err := g.generateOptionalFiles()
+	if err != nil {
+		return nil, err
+	}
+	generatedFiles = append(generatedFiles, optionalFilePaths...)
+	extraFilePaths, err := g.generateExtraFiles()
+	if err != nil {
+		return nil, err
+	}
+	generatedFiles = append(generatedFiles, extraFilePaths...)
+	artifactPaths, err := g.generateArtifacts(vars)
 	if err != nil {
 		return nil, err
 	}
-	generatedFiles = append(generatedFiles, unitTestFilePath)
+	generatedFiles = append(generatedFiles, artifactPaths...)
+	if g.testConfigPackage {
+		testConfigFilePaths, err := g.generateTestConfigPackage(vars)
+		if err != nil {
+			return nil, err
+		}
+		generatedFiles = append(generatedFiles, testConfigFilePaths...)
+	}
 	return generatedFiles, nil
 }
 
 // generateConfigReaderFiles generates config reader files.
 func (g *generator) generateConfigReaderFiles() ([]string, error) {
 	var generatedFiles []string
-	if err := fsProvider.Mkdir(g.packageName); err != nil && !os.IsExist(err) {
-		return nil, errors.Wrapf(err, "creating dir %s", g.packageName)
+	if err := g.fs.Mkdir(g.packageDir()); err != nil && !os.IsExist(err) {
+		return nil, errors.Wrapf(err, "creating dir %s", g.packageDir())
+	}
+	g.header = g.generatedHeader(nil)
+	if g.checksum {
+		g.schema = g.schemaEnvVars(sampleEnvVars)
+	}
+	if g.strict {
+		g.knownEnvVars = g.knownEnvVarKeys(sampleEnvVars)
+	}
+	if g.benchmarks {
+		g.benchEnvFile, _, _ = realTestValues(sampleEnvVars, CamelCaseFieldNamer, g.structName, g.immutable, g.dialect)
+	}
+	g.flags = g.configFlags(sampleEnvVars)
+	g.features = g.featureFlags(sampleEnvVars)
+	g.providerMethods = g.providerGetters(sampleEnvVars)
+	configStruct := fmt.Sprintf(defaultConfigStructTemplate, g.structName)
+	if g.immutable {
+		var err error
+		if configStruct, err = g.generateStruct(sampleEnvVars); err != nil {
+			return nil, err
+		}
 	}
-	mainFilePath, err := g.generateConfigReaderMainFile("")
+	mainFilePath, err := g.generateConfigReaderMainFile(configStruct, nil, "")
 	if err != nil {
 		return nil, err
 	}
 	generatedFiles = append(generatedFiles, mainFilePath)
-	unitTestFilePath, err := g.generateConfigReaderUnitTestFile()
+	if !g.noTests {
+		unitTestFilePath, err := g.generateConfigReaderUnitTestFile(sampleEnvVars, CamelCaseFieldNamer)
+		if err != nil {
+			return nil, err
+		}
+		generatedFiles = append(generatedFiles, unitTestFilePath)
+	}
+	optionalFilePaths, err := g.generateOptionalFiles()
+	if err != nil {
+		return nil, err
+	}
+	generatedFiles = append(generatedFiles, optionalFilePaths...)
+	artifactPaths, err := g.generateArtifacts(sampleEnvVars)
 	if err != nil {
 		return nil, err
 	}
-	generatedFiles = append(generatedFiles, unitTestFilePath)
+	generatedFiles = append(generatedFiles, artifactPaths...)
+	if g.testConfigPackage {
+		testConfigFilePaths, err := g.generateTestConfigPackage(sampleEnvVars)
+		if err != nil {
+			return nil, err
+		}
+		generatedFiles = append(generatedFiles, testConfigFilePaths...)
+	}
 	if err := g.generateEnvFile(); err != nil {
 		return nil, err
 	}
@@ -121,12 +454,13 @@ func (g *generator) generateConfigReaderFiles() ([]string, error) {
 
 // generateEnvFile generates a sample .env file.
 func (g *generator) generateEnvFile() error {
-	envFile, err := fsProvider.Create(envFileName)
+	envFile, err := g.fs.Create(envFileName)
 	if err != nil {
 		return errors.Wrapf(err, "creating file %s", envFileName)
 	}
 	defer envFile.Close()
-	if err := writeFileFromTemplate(envFileTemplateName,
+	g.logger.Debug("executing template", "template", envFileTemplateName, "file", envFileName)
+	if err := g.writeFileFromTemplate(envFileTemplateName,
 		envFileTemplate,
 		nil,
 		envFile); err != nil {
@@ -135,94 +469,634 @@ func (g *generator) generateEnvFile() error {
 	return nil
 }
 
-// generateConfigReaderMainFile generates config reader main file.
-func (g *generator) generateConfigReaderMainFile(envFilePath string) (string, error) {
-	configReaderFilePath := fmt.Sprintf("%s/%s", g.packageName, configReadFileName)
-	configReaderFile, err := fsProvider.Create(configReaderFilePath)
+// generateConfigReaderMainFile generates config reader main file,
+// declaring the given 'Config' struct, which uses the given imports,
+// and, unless empty, a 'go:generate' directive running the given command.
+func (g *generator) generateConfigReaderMainFile(configStruct string, imports []string, goGenerateCommand string) (string, error) {
+	configReaderFilePath := filepath.Join(g.packageDir(), configReadFileName)
+	configReaderFile, err := g.fs.Create(configReaderFilePath)
 	if err != nil {
 		return "", errors.Wrapf(err, "creating file %s", configReaderFilePath)
 	}
 	defer configReaderFile.Close()
-	templateValues := map[string]string{configReaderPkgPlaceHolder: g.packageName, configStructTemplateName: defaultConfigStructTemplate}
-	if envFilePath != "" {
-		structFromEnvFile, err := generateConfigStructFromEnvFile(envFilePath)
-		if err != nil {
-			return "", err
-		}
-		templateValues[configStructTemplateName] = structFromEnvFile
-	}
-	if err := writeFileFromTemplate(configReaderMainFileTemplateName,
+	templateValues := g.templateValues()
+	templateValues[configStructTemplateName] = configStruct
+	templateValues[importsPlaceHolder] = imports
+	templateValues[goGeneratePlaceHolder] = goGenerateCommand
+	g.logger.Debug("executing template", "template", configReaderMainFileTemplateName, "file", configReaderFilePath)
+	if err := g.writeFileFromTemplate(configReaderMainFileTemplateName,
 		configReaderMainFileTemplatePlaceHolder,
 		templateValues,
 		configReaderFile); err != nil {
 		return "", err
 	}
-	if err := formatGoFile(configReaderFilePath); err != nil {
+	g.logger.Debug("formatting file", "file", configReaderFilePath)
+	if err := g.formatGoFile(configReaderFilePath); err != nil {
 		return "", err
 	}
 	return configReaderFilePath, nil
 }
 
-// generateConfigStructFromEnvFile generates the 'Config' struct from
-// variables defined in the provided .env file.
-func generateConfigStructFromEnvFile(envFilePath string) (string, error) {
-	envFile, err := fsProvider.Open(envFilePath)
+// readEnvFiles parses, merges and validates the variables defined in
+// the provided env files with the given parser, or with the one matching
+// each env file if nil, inferring their collection types, if enabled,
+// and sorting them as configured.
+func (g *generator) readEnvFiles(envFilePaths []string, parse envFileParser) ([]envVar, error) {
+	var vars []envVar
+	values := make(map[string]string)
+	for _, envFilePath := range envFilePaths {
+		fileVars, err := g.readEnvFile(envFilePath, values, parse)
+		if err != nil {
+			return nil, err
+		}
+		vars = mergeEnvVars(vars, fileVars)
+	}
+	if len(envFilePaths) > 1 {
+		if err := validateEnvVars(vars, g.fieldName); err != nil {
+			return nil, errors.Wrapf(err, "merging env files %s", strings.Join(envFilePaths, ", "))
+		}
+	}
+	if g.inferCollections {
+		inferCollectionTypes(vars)
+	}
+	g.sortEnvVars(vars)
+	return vars, nil
+}
+
+// readEnvFile parses, filters and validates the variables defined in
+// the provided env file with the given parser, or with the one matching
+// the env file if nil. The env file is read from the standard input when
+// it's StdinEnvFile.
+func (g *generator) readEnvFile(envFilePath string, values map[string]string, parse envFileParser) ([]envVar, error) {
+	var envFile io.Reader
+	if envFilePath == StdinEnvFile {
+		content, err := g.stdinEnvFile()
+		if err != nil {
+			return nil, err
+		}
+		envFile = bytes.NewReader(content)
+	} else {
+		file, err := g.fs.Open(envFilePath)
+		if err != nil {
+			if g.fs.IsNotExist(err) {
+				err = &sentinelError{sentinel: ErrEnvFileNotFound, err: err}
+			}
+			return nil, errors.Wrapf(err, "opening env file %s", envFilePath)
+		}
+		defer file.Close()
+		envFile = file
+	}
+	g.logger.Debug("parsing env file", "file", envFilePath)
+	if parse == nil {
+		parse = g.parserFor(envFilePath)
+	}
+	vars, err := parse(&normalizedLineReader{lineReader: g.newLineReader(envFile)}, values)
 	if err != nil {
-		return "", errors.Wrapf(err, "opening env file %s", envFilePath)
+		return nil, errors.Wrapf(err, "generating struct from env file %s", envFilePath)
 	}
-	defer envFile.Close()
-	structFromEnvFile, err := generateStructFromEnvFile(lr(envFile))
+	g.logger.Debug("parsed env file", "file", envFilePath, "vars", len(vars))
+	if vars, err = g.filterEnvVars(vars); err != nil {
+		return nil, errors.Wrapf(err, "generating struct from env file %s", envFilePath)
+	}
+	if err := validateEnvVars(vars, g.fieldName); err != nil {
+		return nil, errors.Wrapf(err, "generating struct from env file %s", envFilePath)
+	}
+	return vars, nil
+}
+
+// envFileParser parses the lines of an env file into its variables,
+// given the values of the ones defined before it.
+type envFileParser func(lineReader lineReader, values map[string]string) ([]envVar, error)
+
+// parserFor returns the parser of the given env file: the one of
+// .envrc files or of the dialect.
+func (g *generator) parserFor(envFilePath string) envFileParser {
+	switch {
+	case g.envrc || filepath.Base(envFilePath) == envrcFileName:
+		return parseEnvrc
+	case g.dialect == DockerDialect:
+		return parseDockerEnvFile
+	}
+	return parseEnvFile
+}
+
+// stdinEnvFile returns the content of the env file read from the standard
+// input. It's read only once, so that it can be given several times.
+func (g *generator) stdinEnvFile() ([]byte, error) {
+	if g.stdinContent == nil {
+		content, err := io.ReadAll(g.stdin)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading env file from standard input")
+		}
+		g.stdinContent = content
+	}
+	return g.stdinContent, nil
+}
+
+// withoutStdinEnvFile returns the given env file paths
+// without StdinEnvFile, which can't be read again.
+func withoutStdinEnvFile(envFilePaths []string) []string {
+	var paths []string
+	for _, envFilePath := range envFilePaths {
+		if envFilePath != StdinEnvFile {
+			paths = append(paths, envFilePath)
+		}
+	}
+	return paths
+}
+
+// structTagsTODO is the comment written by the struct template at the top
+// of the generated struct, pointing to the struct tags that may be added to
+// its fields.
+const structTagsTODO = "TODO: see https://github.com/kelseyhightower/envconfig for all available options\nfor struct tags."
+
+// generateStruct generates the 'Config' struct with the properties
+// correspondent to the given variables, followed by the types of the
+// ones read with non-default time layouts, of enums and of sensitive ones,
+// and by the 'Validate' method checking their constraints, if any.
+func (g *generator) generateStruct(vars []envVar) (string, error) {
+	types := g.layoutTypes(vars) + g.enumTypes(vars) + g.secretTypes(vars) + g.validateMethod(vars)
+	if g.immutable {
+		model, err := g.structModel(configValuesStructName, vars)
+		if err != nil {
+			return "", err
+		}
+		immutableStruct, err := g.generateImmutableStruct(model, g.hasSecretFields(vars))
+		if err != nil {
+			return "", err
+		}
+		return immutableStruct + types, nil
+	}
+	model, err := g.structModel(g.structName, vars)
+	if err != nil {
+		return "", err
+	}
+	declaration, err := g.structDeclaration(model)
 	if err != nil {
-		return "", errors.Wrapf(err, "generating struct from env file %s", envFilePath)
+		return "", err
+	}
+	return "// " + g.structName + " holds all configuration needed by this app.\n" + declaration + types, nil
+}
+
+// structModel returns the model of the struct with the given name, with
+// the properties correspondent to the given variables, once mutated by
+// the plugins.
+func (g *generator) structModel(name string, vars []envVar) (*StructModel, error) {
+	model := &StructModel{Name: name}
+	for _, v := range vars {
+		fieldType, tags := g.structField(v)
+		field := StructField{Name: g.fieldName(v.key), Type: fieldType, Tags: tags, EnvVar: v.key}
+		if fieldType == "" {
+			field.Type = defaultFieldType
+			field.Comment = "TODO: set the correct data type."
+		}
+		model.Fields = append(model.Fields, field)
+	}
+	if err := g.mutateStruct(model); err != nil {
+		return nil, err
 	}
-	return structFromEnvFile, nil
+	return model, nil
 }
 
-// generateStructFromEnvFile parses the provided .env file and
-// generate the 'Config' struct with the correspondent properties.
-func generateStructFromEnvFile(lineReader lineReader) (string, error) {
+// structDeclaration declares the struct of the given model by executing
+// the struct template with it.
+func (g *generator) structDeclaration(model *StructModel) (string, error) {
+	tmpl, err := template.New(structTemplateName).Funcs(template.FuncMap{"comment": comment}).Parse(g.structTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing struct template")
+	}
 	var sb strings.Builder
-	sb.WriteString("// Config holds all configuration needed by this app.\n")
-	sb.WriteString("type Config struct {\n")
-	sb.WriteString("// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n // for struct tags.\n")
-	for lineReader.Scan() {
-		line := lineReader.Text()
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue // skip invalid lines.
-		}
-		key := parts[0]
-		goFieldName := toCamelCase(key)
-		sb.WriteString(fmt.Sprintf("\t%s %s `envconfig:\"%s\" required:\"true\"` // TODO: set the correct data type.\n", goFieldName, "interface{}", key))
-	}
-	sb.WriteString("}\n")
-	if err := lineReader.Err(); err != nil {
-		return "", errors.Wrap(err, "scanning")
+	if err := tmpl.Execute(&sb, model); err != nil {
+		return "", errors.Wrap(err, "executing struct template")
 	}
 	return sb.String(), nil
 }
 
-// generateConfigReaderUnitTestFile generates unit test file.
-func (g *generator) generateConfigReaderUnitTestFile() (string, error) {
-	configReaderUnitTestFilePath := fmt.Sprintf("%s/%s", g.packageName, configReaderUnitTestFileName)
-	configReaderUnitTestFile, err := fsProvider.Create(configReaderUnitTestFilePath)
+// comment returns the given text as a line comment of a struct field,
+// continued on the following lines if it has many.
+func comment(text string) string {
+	return "// " + strings.ReplaceAll(text, "\n", "\n\t// ")
+}
+
+// structField returns the Go type and the tags of the field generated
+// for the given variable. The type is empty when it can't be inferred.
+func (g *generator) structField(v envVar) (string, string) {
+	fieldType := fieldType(v)
+	tags := fmt.Sprintf("envconfig:%q", g.unprefixedKey(v.key))
+	switch {
+	case g.hasDefault(v):
+		tags += fmt.Sprintf(" default:%q", v.value)
+	case isRequired(v):
+		tags += ` required:"true"`
+	case fieldType == "":
+		fieldType = optionalFieldType
+	}
+	if _, ok := timeLayout(v); ok && fieldType == "time.Time" {
+		fieldType = layoutTypeName(g.fieldName(v.key))
+	}
+	if _, ok := enumValues(v); ok && fieldType == "string" {
+		fieldType = g.fieldName(v.key)
+	}
+	if g.secretField(v, fieldType) {
+		fieldType = strings.Replace(fieldType, "string", secretTypeName, 1)
+	}
+	if ref, ok := annotationValue(v.comment, "vault"); ok && g.hasSecretsBackend(HashiCorpVault) {
+		tags += fmt.Sprintf(" vault:%q", ref)
+	}
+	if g.validatorTags {
+		tags += g.validateTag(v, fieldType)
+	}
+	return fieldType, tags
+}
+
+// generateConfigReaderUnitTestFile generates unit test file. With the real
+// test style, it tests reading the given variables into the fields named
+// with the given field namer.
+func (g *generator) generateConfigReaderUnitTestFile(vars []envVar, fieldNamer func(envKey string) string) (string, error) {
+	configReaderUnitTestFilePath := filepath.Join(g.packageDir(), configReaderUnitTestFileName)
+	configReaderUnitTestFile, err := g.fs.Create(configReaderUnitTestFilePath)
 	if err != nil {
 		return "", errors.Wrapf(err, "creating file %s", configReaderUnitTestFilePath)
 	}
 	defer configReaderUnitTestFile.Close()
-	if err := writeFileFromTemplate(configReaderUnitTestFileTemplateName,
-		configReaderUnitTestFileTemplate,
-		map[string]string{configReaderPkgPlaceHolder: g.packageName},
+	templateText := configReaderUnitTestFileTemplate
+	templateValues := g.templateValues()
+	if g.testStyle == RealTestStyle {
+		templateText = realConfigReaderUnitTestFileTemplate
+		envFile, envVars, config := realTestValues(vars, fieldNamer, g.structName, g.immutable, g.dialect)
+		templateValues[testEnvFilePlaceHolder] = envFile
+		templateValues[testEnvVarsPlaceHolder] = envVars
+		templateValues[testConfigPlaceHolder] = config
+		templateValues[testImportsPlaceHolder], templateValues[testHelpersPlaceHolder] = realTestImports(config)
+	}
+	g.logger.Debug("executing template", "template", configReaderUnitTestFileTemplateName, "file", configReaderUnitTestFilePath)
+	if err := g.writeFileFromTemplate(configReaderUnitTestFileTemplateName,
+		templateText,
+		templateValues,
 		configReaderUnitTestFile); err != nil {
 		return "", err
 	}
+	if g.testStyle == RealTestStyle {
+		g.logger.Debug("formatting file", "file", configReaderUnitTestFilePath)
+		if err := g.formatGoFile(configReaderUnitTestFilePath); err != nil {
+			return "", err
+		}
+	}
 	return configReaderUnitTestFilePath, nil
 }
 
+// optionalFile describes a file that is generated into the config
+// package only when the correspondent option is enabled.
+type optionalFile struct {
+	fileName     string
+	templateName string
+	templateText string
+}
+
+// optionalFiles returns the optional files enabled for this generator.
+func (g *generator) optionalFiles() []optionalFile {
+	var files []optionalFile
+	if g.hotReload || g.sighupReload {
+		files = append(files,
+			optionalFile{reloadFileName, reloadFileTemplateName, reloadFileTemplate},
+			optionalFile{reloadUnitTestFileName, reloadUnitTestFileTemplateName, reloadUnitTestFileTemplate},
+			optionalFile{reloadLogFileName, reloadLogFileTemplateName, reloadLogFileTemplate},
+			optionalFile{reloadLogUnitTestFileName, reloadLogUnitTestFileTemplateName, reloadLogUnitTestFileTemplate},
+		)
+	}
+	if g.hotReload {
+		files = append(files,
+			optionalFile{hotReloadFileName, hotReloadFileTemplateName, hotReloadFileTemplate},
+			optionalFile{hotReloadUnitTestFileName, hotReloadUnitTestFileTemplateName, hotReloadUnitTestFileTemplate},
+		)
+	}
+	if g.sighupReload {
+		files = append(files,
+			optionalFile{sighupReloadFileName, sighupReloadFileTemplateName, sighupReloadFileTemplate},
+			optionalFile{sighupReloadUnitTestFileName, sighupReloadUnitTestFileTemplateName, sighupReloadUnitTestFileTemplate},
+		)
+	}
+	if g.singleton {
+		files = append(files,
+			optionalFile{singletonFileName, singletonFileTemplateName, singletonFileTemplate},
+			optionalFile{singletonUnitTestFileName, singletonUnitTestFileTemplateName, singletonUnitTestFileTemplate},
+		)
+	}
+	if g.clone {
+		files = append(files,
+			optionalFile{cloneFileName, cloneFileTemplateName, cloneFileTemplate},
+			optionalFile{cloneUnitTestFileName, cloneUnitTestFileTemplateName, cloneUnitTestFileTemplate},
+		)
+	}
+	if g.tracing {
+		files = append(files,
+			optionalFile{otelFileName, otelFileTemplateName, otelFileTemplate},
+			optionalFile{otelUnitTestFileName, otelUnitTestFileTemplateName, otelUnitTestFileTemplate},
+		)
+	}
+	if g.metrics {
+		files = append(files,
+			optionalFile{metricsFileName, metricsFileTemplateName, metricsFileTemplate},
+			optionalFile{metricsUnitTestFileName, metricsUnitTestFileTemplateName, metricsUnitTestFileTemplate},
+		)
+	}
+	if g.debugHandler {
+		files = append(files,
+			optionalFile{debugFileName, debugFileTemplateName, debugFileTemplate},
+			optionalFile{debugUnitTestFileName, debugUnitTestFileTemplateName, debugUnitTestFileTemplate},
+		)
+	}
+	if g.embed {
+		files = append(files,
+			optionalFile{embedFileName, embedFileTemplateName, embedFileTemplate},
+			optionalFile{embedUnitTestFileName, embedUnitTestFileTemplateName, embedUnitTestFileTemplate},
+		)
+	}
+	if g.benchmarks {
+		files = append(files, optionalFile{benchFileName, benchFileTemplateName, benchFileTemplate})
+	}
+	if g.usageTracking {
+		files = append(files,
+			optionalFile{usageFileName, usageFileTemplateName, usageFileTemplate},
+			optionalFile{usageUnitTestFileName, usageUnitTestFileTemplateName, usageUnitTestFileTemplate},
+		)
+	}
+	if g.strict {
+		files = append(files,
+			optionalFile{strictFileName, strictFileTemplateName, strictFileTemplate},
+			optionalFile{strictUnitTestFileName, strictUnitTestFileTemplateName, strictUnitTestFileTemplate},
+		)
+	}
+	if g.checksum {
+		files = append(files,
+			optionalFile{checksumFileName, checksumFileTemplateName, checksumFileTemplate},
+			optionalFile{checksumUnitTestFileName, checksumUnitTestFileTemplateName, checksumUnitTestFileTemplate},
+		)
+	}
+	if g.ageEnvFile {
+		files = append(files,
+			optionalFile{ageFileName, ageFileTemplateName, ageFileTemplate},
+			optionalFile{ageUnitTestFileName, ageUnitTestFileTemplateName, ageUnitTestFileTemplate},
+		)
+	}
+	if g.secretFiles {
+		files = append(files,
+			optionalFile{secretFilesFileName, secretFilesFileTemplateName, secretFilesFileTemplate},
+			optionalFile{secretFilesUnitTestFileName, secretFilesUnitTestFileTemplateName, secretFilesUnitTestFileTemplate},
+		)
+	}
+	if g.overrides {
+		files = append(files,
+			optionalFile{overridesFileName, overridesFileTemplateName, overridesFileTemplate},
+			optionalFile{overridesUnitTestFileName, overridesUnitTestFileTemplateName, overridesUnitTestFileTemplate},
+		)
+	}
+	if g.schemaVersion != 0 {
+		files = append(files,
+			optionalFile{migrateFileName, migrateFileTemplateName, migrateFileTemplate},
+			optionalFile{migrateUnitTestFileName, migrateUnitTestFileTemplateName, migrateUnitTestFileTemplate},
+		)
+	}
+	files = append(files, flagsLibraryFiles[g.flagsLibrary]...)
+	if g.configProvider {
+		files = append(files,
+			optionalFile{providerFileName, providerFileTemplateName, providerFileTemplate},
+			optionalFile{providerMockFileName, providerMockFileTemplateName, providerMockFileTemplate},
+			optionalFile{providerUnitTestFileName, providerUnitTestFileTemplateName, providerUnitTestFileTemplate},
+		)
+	}
+	if g.withFeatures {
+		files = append(files,
+			optionalFile{featuresFileName, featuresFileTemplateName, featuresFileTemplate},
+			optionalFile{featuresUnitTestFileName, featuresUnitTestFileTemplateName, featuresUnitTestFileTemplate},
+		)
+	}
+	if g.pathChecks {
+		files = append(files,
+			optionalFile{pathsFileName, pathsFileTemplateName, pathsFileTemplate},
+			optionalFile{pathsUnitTestFileName, pathsUnitTestFileTemplateName, pathsUnitTestFileTemplate},
+		)
+	}
+	if len(g.secretsBackends) > 0 {
+		files = append(files,
+			optionalFile{secretsFileName, secretsFileTemplateName, secretsFileTemplate},
+			optionalFile{secretsUnitTestFileName, secretsUnitTestFileTemplateName, secretsUnitTestFileTemplate},
+		)
+	}
+	for _, backend := range g.secretsBackends {
+		files = append(files, secretsBackendFiles[backend]...)
+	}
+	if len(g.remoteSources) > 0 {
+		files = append(files,
+			optionalFile{remoteFileName, remoteFileTemplateName, remoteFileTemplate},
+			optionalFile{remoteUnitTestFileName, remoteUnitTestFileTemplateName, remoteUnitTestFileTemplate},
+		)
+	}
+	for _, source := range g.remoteSources {
+		files = append(files, remoteSourceFiles[source]...)
+	}
+	if g.dialect == DockerDialect {
+		files = append(files,
+			optionalFile{dockerEnvFileName, dockerEnvFileTemplateName, dockerEnvFileTemplate},
+			optionalFile{dockerEnvUnitTestFileName, dockerEnvUnitTestFileTemplateName, dockerEnvUnitTestFileTemplate},
+		)
+	}
+	if g.cue {
+		files = append(files,
+			optionalFile{cueFileName, cueFileTemplateName, cueFileTemplate},
+			optionalFile{cueUnitTestFileName, cueUnitTestFileTemplateName, cueUnitTestFileTemplate},
+		)
+	}
+	return files
+}
+
+// generateOptionalFiles generates the optional files enabled for this
+// generator, leaving out their unit test files if tests are disabled.
+func (g *generator) generateOptionalFiles() ([]string, error) {
+	if g.withFeatures && len(g.features) == 0 {
+		return nil, errors.Errorf("feature flags require bool %s* variables", featurePrefix)
+	}
+	var generatedFiles []string
+	for _, f := range g.optionalFiles() {
+		if g.noTests && strings.HasSuffix(f.fileName, "_test.go") {
+			continue
+		}
+		filePath, err := g.generateFileFromTemplate(f.fileName, f.templateName, f.templateText)
+		if err != nil {
+			return nil, err
+		}
+		generatedFiles = append(generatedFiles, filePath)
+	}
+	return generatedFiles, nil
+}
+
+// artifact describes a file, other than Go source, that is generated from
+// the env vars when the correspondent option is enabled, to wire them into
+// other tools, e.g. Docker Compose.
+type artifact struct {
+	fileName string
+	content  func(vars []envVar) (string, error)
+}
+
+// artifacts returns the artifacts enabled for this generator.
+func (g *generator) artifacts() []artifact {
+	var artifacts []artifact
+	if g.dockerCompose {
+		artifacts = append(artifacts, artifact{g.outputPath(dockerComposeFileName), g.dockerComposeEnv})
+	}
+	if g.gha {
+		artifacts = append(artifacts, artifact{g.outputPath(ghaEnvFileName), g.ghaEnv})
+	}
+	if g.helm {
+		artifacts = append(artifacts,
+			artifact{g.outputPath(helmValuesFileName), g.helmValues},
+			artifact{g.outputPath(helmEnvFileName), g.helmEnv},
+		)
+	}
+	if g.systemd {
+		artifacts = append(artifacts, artifact{g.outputPath(systemdEnvFileName), g.systemdEnvFile})
+	}
+	if g.terraform {
+		artifacts = append(artifacts,
+			artifact{g.outputPath(terraformVariablesFileName), g.terraformVariables},
+			artifact{g.outputPath(terraformTfvarsFileName), g.terraformTfvars},
+		)
+	}
+	if g.jsonSchema {
+		artifacts = append(artifacts, artifact{filepath.Join(g.packageDir(), jsonSchemaFileName), g.jsonSchemaFile})
+	}
+	if g.cue {
+		artifacts = append(artifacts, artifact{filepath.Join(g.packageDir(), cueDefinitionFileName), g.cueDefinitionFile})
+	}
+	if g.readme {
+		artifacts = append(artifacts, artifact{filepath.Join(g.packageDir(), packageReadmeFileName), g.packageReadmeFile})
+	}
+	if g.embed {
+		artifacts = append(artifacts, artifact{filepath.Join(g.packageDir(), embeddedEnvFileName), g.embeddedEnvFile})
+	}
+	if g.gitignore {
+		artifacts = append(artifacts, artifact{gitignoreFileName, g.gitignoreFile})
+	}
+	return artifacts
+}
+
+// generateArtifacts generates the artifacts enabled for this
+// generator from the given env vars.
+func (g *generator) generateArtifacts(vars []envVar) ([]string, error) {
+	var generatedFiles []string
+	for _, a := range g.artifacts() {
+		g.logger.Debug("generating file", "file", a.fileName)
+		content, err := a.content(vars)
+		if err != nil {
+			return nil, errors.Wrapf(err, "generating file %s", a.fileName)
+		}
+		if err := writeArtifact(g.fs, a.fileName, content); err != nil {
+			return nil, err
+		}
+		generatedFiles = append(generatedFiles, a.fileName)
+	}
+	return generatedFiles, nil
+}
+
+// writeArtifact creates the given file of the given file system with
+// the given content, creating its parent dir if needed.
+func writeArtifact(fsys FileSystem, fileName, content string) error {
+	if dir := filepath.Dir(fileName); dir != "." {
+		if err := fsys.Mkdir(dir); err != nil && !os.IsExist(err) {
+			return errors.Wrapf(err, "creating dir %s", dir)
+		}
+	}
+	file, err := fsys.Create(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "creating file %s", fileName)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(content); err != nil {
+		return errors.Wrapf(err, "writing file %s", fileName)
+	}
+	return nil
+}
+
+// generateFileFromTemplate generates '<packagename>/<fileName>' from the
+// given template. Go files are formatted after being generated.
+func (g *generator) generateFileFromTemplate(fileName, templateName, templateText string) (string, error) {
+	filePath := filepath.Join(g.packageDir(), fileName)
+	file, err := g.fs.Create(filePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating file %s", filePath)
+	}
+	defer file.Close()
+	g.logger.Debug("executing template", "template", templateName, "file", filePath)
+	if err := g.writeFileFromTemplate(templateName, templateText, g.templateValues(), file); err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(fileName, ".go") {
+		g.logger.Debug("formatting file", "file", filePath)
+		if err := g.formatGoFile(filePath); err != nil {
+			return "", err
+		}
+	}
+	return filePath, nil
+}
+
+// templateValues returns the values shared by all templates.
+func (g *generator) templateValues() map[string]interface{} {
+	return map[string]interface{}{
+		configReaderPkgPlaceHolder:    g.packageName,
+		defaultsFromValuesPlaceHolder: g.defaultsFromValues,
+		profilesPlaceHolder:           g.profiles,
+		secretsPlaceHolder:            len(g.secretsBackends) > 0,
+		hotReloadPlaceHolder:          g.hotReload,
+		importPathPlaceHolder:         g.modImportPath,
+		headerPlaceHolder:             g.header,
+		envPrefixPlaceHolder:          g.envPrefix(),
+		immutablePlaceHolder:          g.immutable,
+		validationPlaceHolder:         g.validation,
+		validatorTagsPlaceHolder:      g.validation && g.validatorTags,
+		pathChecksPlaceHolder:         g.pathChecks,
+		reloadPlaceHolder:             g.hotReload || g.sighupReload,
+		dockerDialectPlaceHolder:      g.dialect == DockerDialect,
+		schemaVersionPlaceHolder:      g.schemaVersion,
+		debugHandlerPlaceHolder:       g.debugHandler,
+		singletonPlaceHolder:          g.singleton,
+		metricsPlaceHolder:            g.metrics,
+		tracingPlaceHolder:            g.tracing,
+		contextPlaceHolder:            g.withContext || g.tracing,
+		sensitiveVarsPlaceHolder:      g.sensitiveVars,
+		secretFilesPlaceHolder:        g.secretFiles,
+		secretFileVarsPlaceHolder:     g.secretFileVars,
+		schemaEnvVarsPlaceHolder:      g.schema,
+		schemaChecksumPlaceHolder:     g.schemaChecksum(),
+		strictPlaceHolder:             g.strict,
+		usageTrackingPlaceHolder:      g.usageTracking,
+		benchEnvFilePlaceHolder:       g.benchEnvFile,
+		maxLineLengthPlaceHolder:      g.maxLineLength,
+		knownEnvVarsPlaceHolder:       g.knownEnvVars,
+		flagsPlaceHolder:              g.flags,
+		featuresPlaceHolder:           g.features,
+		providerMethodsPlaceHolder:    g.providerMethods,
+		providerImportsPlaceHolder:    providerImports(g.providerMethods),
+		structNamePlaceHolder:         g.structName,
+		readerNamePlaceHolder:         g.readerName,
+	}
+}
+
+// hasDefault reports whether the field generated for the given
+// variable defaults to its env file value.
+func (g *generator) hasDefault(v envVar) bool {
+	return g.defaultsFromValues && isValidDefaultValue(v.value)
+}
+
+// isValidDefaultValue reports whether the given value can be used
+// as a 'default' struct tag.
+func isValidDefaultValue(value string) bool {
+	return value != "" && !strings.ContainsAny(value, "`\n")
+}
+
 // writeFileFromTemplate parses and then executes the given template with
 // the given template values.
-func writeFileFromTemplate(templateName, templateText string, templateValues map[string]string, file File) error {
-	tmplExecutor, err := templateProcessorProvider.Parse(templateName, templateText)
+func (g *generator) writeFileFromTemplate(templateName, templateText string, templateValues map[string]interface{}, file File) error {
+	tmplExecutor, err := g.templateProcessor.Parse(templateName, templateText)
 	if err != nil {
 		return errors.Wrapf(err, "parsing template %s", templateName)
 	}
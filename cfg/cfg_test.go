@@ -5,9 +5,13 @@
 package cfg
 
 import (
+	"bytes"
 	"errors"
 	"io"
+	"log/slog"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -15,6 +19,7 @@ import (
 func TestGenerateConfigPackage(t *testing.T) {
 	testCases := []struct {
 		name           string
+		opts           []Option
 		mockClosure    func(mfs *mockFileSystem, mtp *mockTemplateProcessor)
 		expectedOutput []string
 		expectedError  error
@@ -31,6 +36,303 @@ func TestGenerateConfigPackage(t *testing.T) {
 				".env",
 			},
 		},
+		{
+			name: "happy path with hot reload",
+			opts: []Option{WithHotReload()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"config/reload.go",
+				"config/reload_test.go",
+				"config/reloadlog.go",
+				"config/reloadlog_test.go",
+				"config/watch.go",
+				"config/watch_test.go",
+				".env",
+			},
+		},
+		{
+			name: "happy path without tests",
+			opts: []Option{WithHotReload(), WithoutTests()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+				mfs.createUnitTestFileErr = errors.New("create error")
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/reload.go",
+				"config/reloadlog.go",
+				"config/watch.go",
+				".env",
+			},
+		},
+		{
+			name: "happy path with docker compose",
+			opts: []Option{WithDockerCompose()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"docker-compose.env.yaml",
+				".env",
+			},
+		},
+		{
+			name: "error when writing docker compose file",
+			opts: []Option{WithDockerCompose()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = &mockFile{writeStringErr: errors.New("write error")}
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedError: errors.New("writing file docker-compose.env.yaml: write error"),
+		},
+		{
+			name: "happy path with helm",
+			opts: []Option{WithHelm()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"helm/values.yaml",
+				"helm/_env.tpl",
+				".env",
+			},
+		},
+		{
+			name: "happy path with systemd",
+			opts: []Option{WithSystemd()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"systemd.env",
+				".env",
+			},
+		},
+		{
+			name: "happy path with terraform",
+			opts: []Option{WithTerraform()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"terraform/variables.tf",
+				"terraform/terraform.tfvars.example",
+				".env",
+			},
+		},
+		{
+			name: "happy path with github actions",
+			opts: []Option{WithGHA()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"gha.env.yaml",
+				".env",
+			},
+		},
+		{
+			name: "happy path with json schema",
+			opts: []Option{WithJSONSchema()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"config/config.schema.json",
+				".env",
+			},
+		},
+		{
+			name: "happy path with cue",
+			opts: []Option{WithCUE()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"config/cue.go",
+				"config/cue_test.go",
+				"config/config.cue",
+				".env",
+			},
+		},
+		{
+			name: "happy path with readme",
+			opts: []Option{WithReadme()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				"config/README.md",
+				".env",
+			},
+		},
+		{
+			name: "happy path with gitignore",
+			opts: []Option{WithGitignore()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				mtp.te = new(mockTemplateExecutor)
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+				".gitignore",
+				".env",
+			},
+		},
+		{
+			name: "error when writing hot reload file, template parse error",
+			opts: []Option{WithHotReload()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+				mfs.createdFile = new(mockFile)
+				te := new(mockTemplateExecutor)
+				mtp.te = te
+				mtp.optionalFileParseErr = errors.New("parse error")
+			},
+			expectedError: errors.New("parsing template reloadFile: parse error"),
+		},
+		{
+			name: "unsupported secrets backend",
+			opts: []Option{WithSecretsBackends("unknown")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("unsupported secrets backend unknown"),
+		},
+		{
+			name: "unsupported remote source",
+			opts: []Option{WithRemoteSources("unknown")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("unsupported remote source unknown"),
+		},
+		{
+			name: "unsupported test style",
+			opts: []Option{WithTestStyle("unknown")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("unsupported test style unknown"),
+		},
+		{
+			name: "unsupported sort order",
+			opts: []Option{WithSortOrder("unknown")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("unsupported sort order unknown"),
+		},
+		{
+			name: "invalid schema version",
+			opts: []Option{WithSchemaVersion(-1)},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("invalid schema version -1: it must be positive"),
+		},
+		{
+			name: "test config package with immutable config",
+			opts: []Option{WithTestConfigPackage(), WithImmutable()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("the test config package can't set the fields of an immutable config"),
+		},
+		{
+			name: "invalid struct name",
+			opts: []Option{WithStructName("appConfig")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New(`invalid struct name "appConfig": it must be an exported Go identifier`),
+		},
+		{
+			name: "invalid reader name",
+			opts: []Option{WithReaderName("Load-Config")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New(`invalid reader name "Load-Config": it must be an exported Go identifier`),
+		},
+		{
+			name: "same struct and reader names",
+			opts: []Option{WithStructName("Settings"), WithReaderName("Settings")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("the struct and the reader can't both be named Settings"),
+		},
+		{
+			name: "unsupported flags library",
+			opts: []Option{WithFlags("unknown")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("unsupported flags library unknown"),
+		},
+		{
+			name: "invalid prefix",
+			opts: []Option{WithPrefix("APP-")},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New(`invalid prefix "APP-"`),
+		},
+		{
+			name: "metrics without reload",
+			opts: []Option{WithMetrics()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("metrics require hot reload or SIGHUP reload"),
+		},
+		{
+			name: "invalid max line length",
+			opts: []Option{WithMaxLineLength(0)},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("invalid max line length 0: it must be positive"),
+		},
+		{
+			name: "benchmarks without tests",
+			opts: []Option{WithBenchmarks(), WithoutTests()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("benchmarks can't be generated without tests"),
+		},
+		{
+			name: "usage tracking with mutable config",
+			opts: []Option{WithUsageTracking()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("usage tracking requires an immutable config"),
+		},
+		{
+			name: "strict mode without prefix",
+			opts: []Option{WithStrict()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
+			},
+			expectedError: errors.New("strict mode requires a prefix"),
+		},
 		{
 			name: "error when creating config files dir",
 			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor) {
@@ -104,9 +406,8 @@ func TestGenerateConfigPackage(t *testing.T) {
 			mfs := new(mockFileSystem)
 			mtp := new(mockTemplateProcessor)
 			tc.mockClosure(mfs, mtp)
-			fsProvider = mfs
-			templateProcessorProvider = mtp
-			g := NewGenerator("config")
+			opts := append([]Option{WithFileSystem(mfs), WithTemplateProcessor(mtp)}, tc.opts...)
+			g := NewGenerator("config", opts...)
 			output, err := g.GenerateConfigPackage()
 			if err != nil {
 				if tc.expectedError == nil {
@@ -126,6 +427,7 @@ func TestGenerateConfigPackage(t *testing.T) {
 func TestGenerateConfigPackageFromEnvFile(t *testing.T) {
 	testCases := []struct {
 		name           string
+		opts           []Option
 		mockClosure    func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter)
 		expectedOutput []string
 		expectedError  error
@@ -144,6 +446,36 @@ func TestGenerateConfigPackageFromEnvFile(t *testing.T) {
 				"config/config_test.go",
 			},
 		},
+		{
+			name: "happy path with real test style",
+			opts: []Option{WithTestStyle(RealTestStyle)},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter) {
+				mf := new(mockFile)
+				mfs.createdFile = mf
+				mfs.openedFile = mf
+				mtp.te = new(mockTemplateExecutor)
+				mlr.lines = []string{"invalid", "var=value"}
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+			},
+		},
+		{
+			name: "happy path without tests",
+			opts: []Option{WithoutTests()},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter) {
+				mf := new(mockFile)
+				mfs.createdFile = mf
+				mfs.openedFile = mf
+				mtp.te = new(mockTemplateExecutor)
+				mlr.lines = []string{"invalid", "var=value"}
+				mfs.createUnitTestFileErr = errors.New("create error")
+			},
+			expectedOutput: []string{
+				"config/config.go",
+			},
+		},
 		{
 			name: "error when creating config files dir",
 			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter) {
@@ -171,6 +503,44 @@ func TestGenerateConfigPackageFromEnvFile(t *testing.T) {
 			},
 			expectedError: errors.New("generating struct from env file .env-local: scanning: error"),
 		},
+		{
+			name: "duplicate variables",
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter) {
+				mf := new(mockFile)
+				mfs.createdFile = mf
+				mfs.openedFile = mf
+				mtp.te = new(mockTemplateExecutor)
+				mlr.lines = []string{"var=value", "var=other value"}
+			},
+			expectedError: errors.New("generating struct from env file .env-local: line 2: duplicate variable var, first defined at line 1"),
+		},
+		{
+			name: "conflicting field names with custom field namer",
+			opts: []Option{
+				WithFieldNamer(func(envKey string) string {
+					return "Field"
+				}),
+			},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter) {
+				mf := new(mockFile)
+				mfs.createdFile = mf
+				mfs.openedFile = mf
+				mtp.te = new(mockTemplateExecutor)
+				mlr.lines = []string{"var=value", "other_var=value"}
+			},
+			expectedError: errors.New("generating struct from env file .env-local: line 2: variable other_var conflicts with var (line 1), both map to field Field"),
+		},
+		{
+			name: "error when reading env file to record its checksum",
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter) {
+				mf := new(mockFile)
+				mfs.createdFile = mf
+				mfs.openedFile = mf
+				mfs.readFileErr = errors.New("read error")
+				mlr.lines = []string{"var=value"}
+			},
+			expectedError: errors.New("reading file .env-local: read error"),
+		},
 		{
 			name: "error when creating config reader unit test file",
 			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader, mfr *mockFormatter) {
@@ -215,13 +585,15 @@ func TestGenerateConfigPackageFromEnvFile(t *testing.T) {
 			mlr := new(mockLineReader)
 			mfr := new(mockFormatter)
 			tc.mockClosure(mfs, mtp, mlr, mfr)
-			fsProvider = mfs
-			templateProcessorProvider = mtp
-			formatterProvider = mfr
-			lr = func(_ io.Reader) lineReader {
-				return mlr
-			}
-			g := NewGenerator("config")
+			opts := append([]Option{
+				WithFileSystem(mfs),
+				WithTemplateProcessor(mtp),
+				WithFormatter(mfr),
+				withLineReader(func(_ io.Reader) lineReader {
+					return mlr
+				}),
+			}, tc.opts...)
+			g := NewGenerator("config", opts...)
 			output, err := g.GenerateConfigPackageFromEnvFile(".env-local")
 			if err != nil {
 				if tc.expectedError == nil {
@@ -237,3 +609,577 @@ func TestGenerateConfigPackageFromEnvFile(t *testing.T) {
 		})
 	}
 }
+
+func Test_generateStruct(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           []Option
+		lines          []string
+		expectedOutput string
+	}{
+		{
+			name:  "happy path",
+			lines: []string{"PORT=8080", "HOST=localhost", "EMPTY=", "REQUIRED_EMPTY= # required", "OPTIONAL=true # optional"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+				"\tHost string `envconfig:\"HOST\" required:\"true\"`\n" +
+				"\tEmpty *string `envconfig:\"EMPTY\"`\n" +
+				"\tRequiredEmpty interface{} `envconfig:\"REQUIRED_EMPTY\" required:\"true\"` // TODO: set the correct data type.\n" +
+				"\tOptional bool `envconfig:\"OPTIONAL\"`\n" +
+				"}\n",
+		},
+		{
+			name:  "struct name",
+			opts:  []Option{WithStructName("AppConfig")},
+			lines: []string{"PORT=8080"},
+			expectedOutput: "// AppConfig holds all configuration needed by this app.\n" +
+				"type AppConfig struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+				"}\n",
+		},
+		{
+			name:  "defaults from values",
+			opts:  []Option{WithDefaultsFromValues()},
+			lines: []string{"PORT=8080", `GREETING="say \"hi\""`, "EMPTY="},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" default:\"8080\"`\n" +
+				"\tGreeting string `envconfig:\"GREETING\" default:\"say \\\"hi\\\"\"`\n" +
+				"\tEmpty *string `envconfig:\"EMPTY\"`\n" +
+				"}\n",
+		},
+		{
+			name:  "vault annotations",
+			opts:  []Option{WithSecretsBackends(HashiCorpVault)},
+			lines: []string{"# vault: secret/data/db#password", "DB_PASSWORD=", "API_KEY=abc # vault: kv/api#key"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tDbPassword *string `envconfig:\"DB_PASSWORD\" vault:\"secret/data/db#password\"`\n" +
+				"\tApiKey string `envconfig:\"API_KEY\" required:\"true\" vault:\"kv/api#key\"`\n" +
+				"}\n",
+		},
+		{
+			name:  "vault annotations without vault backend",
+			lines: []string{"API_KEY=abc # vault: kv/api#key"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tApiKey string `envconfig:\"API_KEY\" required:\"true\"`\n" +
+				"}\n",
+		},
+		{
+			name:  "prefix",
+			opts:  []Option{WithPrefix("APP_")},
+			lines: []string{"APP_DB_HOST=localhost", "APP_PORT=8080"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tDbHost string `envconfig:\"DB_HOST\" required:\"true\"`\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+				"}\n",
+		},
+		{
+			name:  "type annotations",
+			opts:  []Option{WithDefaultsFromValues()},
+			lines: []string{"# type: int", "# required", "PORT=", "HOSTS=a,b # type: []string", "# type: bool", "DEBUG="},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+				"\tHosts []string `envconfig:\"HOSTS\" default:\"a,b\"`\n" +
+				"\tDebug bool `envconfig:\"DEBUG\"`\n" +
+				"}\n",
+		},
+		{
+			name:  "time layouts",
+			lines: []string{"STARTED_AT=2024-03-01T10:00:00Z", "# layout: 2006-01-02", "START_DATE=2024-03-01", "OPEN_TIME=10:00 # layout: 15:04"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tStartedAt time.Time `envconfig:\"STARTED_AT\" required:\"true\"`\n" +
+				"\tStartDate StartDateTime `envconfig:\"START_DATE\" required:\"true\"`\n" +
+				"\tOpenTime OpenTimeValue `envconfig:\"OPEN_TIME\" required:\"true\"`\n" +
+				"}\n" +
+				"\n// StartDateTime is the time read from START_DATE with the \"2006-01-02\" layout.\n" +
+				"type StartDateTime struct {\n\ttime.Time\n}\n" +
+				"\n// Decode parses the given value with the \"2006-01-02\" layout.\n" +
+				"func (t *StartDateTime) Decode(value string) error {\n" +
+				"\tparsed, err := time.Parse(\"2006-01-02\", value)\n" +
+				"\tif err != nil {\n\t\treturn err\n\t}\n" +
+				"\tt.Time = parsed\n\treturn nil\n}\n" +
+				"\n// OpenTimeValue is the time read from OPEN_TIME with the \"15:04\" layout.\n" +
+				"type OpenTimeValue struct {\n\ttime.Time\n}\n" +
+				"\n// Decode parses the given value with the \"15:04\" layout.\n" +
+				"func (t *OpenTimeValue) Decode(value string) error {\n" +
+				"\tparsed, err := time.Parse(\"15:04\", value)\n" +
+				"\tif err != nil {\n\t\treturn err\n\t}\n" +
+				"\tt.Time = parsed\n\treturn nil\n}\n",
+		},
+		{
+			name:  "immutable",
+			opts:  []Option{WithImmutable()},
+			lines: []string{"PORT=8080", "EMPTY=", "REQUIRED_EMPTY= # required"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"// Its values can't be changed once read: they're exposed through getters.\n" +
+				"type Config struct {\n" +
+				"\tvalues configValues\n" +
+				"}\n\n" +
+				"// configValues holds the values of Config, read from env vars.\n" +
+				"type configValues struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+				"\tEmpty *string `envconfig:\"EMPTY\"`\n" +
+				"\tRequiredEmpty interface{} `envconfig:\"REQUIRED_EMPTY\" required:\"true\"` // TODO: set the correct data type.\n" +
+				"}\n" +
+				"\n// Port returns the value of the PORT env var.\n" +
+				"func (c *Config) Port() int {\n\treturn c.values.Port\n}\n" +
+				"\n// Empty returns the value of the EMPTY env var.\n" +
+				"func (c *Config) Empty() *string {\n\treturn c.values.Empty\n}\n" +
+				"\n// RequiredEmpty returns the value of the REQUIRED_EMPTY env var.\n" +
+				"func (c *Config) RequiredEmpty() interface{} {\n\treturn c.values.RequiredEmpty\n}\n",
+		},
+		{
+			name:  "immutable with usage tracking",
+			opts:  []Option{WithImmutable(), WithUsageTracking(), WithPrefix("APP_")},
+			lines: []string{"APP_PORT=8080"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"// Its values can't be changed once read: they're exposed through getters.\n" +
+				"type Config struct {\n" +
+				"\tvalues configValues\n" +
+				"}\n\n" +
+				"// configValues holds the values of Config, read from env vars.\n" +
+				"type configValues struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+				"}\n" +
+				"\n// Port returns the value of the APP_PORT env var.\n" +
+				"func (c *Config) Port() int {\n\tmarkUsed(\"APP_PORT\")\n\treturn c.values.Port\n}\n",
+		},
+		{
+			name:  "enums",
+			lines: []string{"# enum: debug, info, warn-level", "LOG_LEVEL=info", "MODE= # enum: fast,safe"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tLogLevel LogLevel `envconfig:\"LOG_LEVEL\" required:\"true\"`\n" +
+				"\tMode Mode `envconfig:\"MODE\"`\n" +
+				"}\n" +
+				"\n// LogLevel is the value of LOG_LEVEL, one of: debug, info, warn-level.\n" +
+				"type LogLevel string\n" +
+				"\n// Values of LogLevel.\nconst (\n" +
+				"\tLogLevelDebug LogLevel = \"debug\"\n" +
+				"\tLogLevelInfo LogLevel = \"info\"\n" +
+				"\tLogLevelWarnLevel LogLevel = \"warn-level\"\n" +
+				")\n" +
+				"\n// Decode sets the given value, which must be one of the values of LogLevel.\n" +
+				"func (e *LogLevel) Decode(value string) error {\n" +
+				"\tswitch LogLevel(value) {\n" +
+				"\tcase LogLevelDebug, LogLevelInfo, LogLevelWarnLevel:\n" +
+				"\t\t*e = LogLevel(value)\n\t\treturn nil\n\t}\n" +
+				"\treturn fmt.Errorf(\"invalid value %q, must be one of: debug, info, warn-level\", value)\n}\n" +
+				"\n// Mode is the value of MODE, one of: fast, safe.\n" +
+				"type Mode string\n" +
+				"\n// Values of Mode.\nconst (\n" +
+				"\tModeFast Mode = \"fast\"\n" +
+				"\tModeSafe Mode = \"safe\"\n" +
+				")\n" +
+				"\n// Decode sets the given value, which must be one of the values of Mode.\n" +
+				"func (e *Mode) Decode(value string) error {\n" +
+				"\tswitch Mode(value) {\n" +
+				"\tcase ModeFast, ModeSafe:\n" +
+				"\t\t*e = Mode(value)\n\t\treturn nil\n\t}\n" +
+				"\treturn fmt.Errorf(\"invalid value %q, must be one of: fast, safe\", value)\n}\n",
+		},
+		{
+			name:  "constraints",
+			lines: []string{"PORT=8080 # min: 1 max: 65535", "# type: int", "# max: 10", "RETRIES=", "NAME=app # pattern: ^[a-z]+$"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+				"\tRetries int `envconfig:\"RETRIES\"`\n" +
+				"\tName string `envconfig:\"NAME\" required:\"true\"`\n" +
+				"}\n" +
+				"\n// namePattern is the pattern of NAME values.\n" +
+				"var namePattern = regexp.MustCompile(`^[a-z]+$`)\n" +
+				"\n// Validate checks the values of the configuration against the\n" +
+				"// constraints annotated in the env file, returning all the violations.\n" +
+				"func (c *Config) Validate() error {\n" +
+				"\tvar errs []error\n" +
+				"\tif c.Port < 1 {\n" +
+				"\t\terrs = append(errs, fmt.Errorf(\"PORT: %v is less than 1\", c.Port))\n\t}\n" +
+				"\tif c.Port > 65535 {\n" +
+				"\t\terrs = append(errs, fmt.Errorf(\"PORT: %v is greater than 65535\", c.Port))\n\t}\n" +
+				"\tif c.Retries != 0 && c.Retries > 10 {\n" +
+				"\t\terrs = append(errs, fmt.Errorf(\"RETRIES: %v is greater than 10\", c.Retries))\n\t}\n" +
+				"\tif !namePattern.MatchString(c.Name) {\n" +
+				"\t\terrs = append(errs, stderrors.New(\"NAME: value doesn't match ^[a-z]+$\"))\n\t}\n" +
+				"\treturn stderrors.Join(errs...)\n}\n",
+		},
+		{
+			name:  "constraints with validator tags",
+			opts:  []Option{WithValidatorTags(), WithImmutable()},
+			lines: []string{"PORT=8080 # min: 1 max: 65535"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"// Its values can't be changed once read: they're exposed through getters.\n" +
+				"type Config struct {\n" +
+				"\tvalues configValues\n" +
+				"}\n\n" +
+				"// configValues holds the values of Config, read from env vars.\n" +
+				"type configValues struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tPort int `envconfig:\"PORT\" required:\"true\" validate:\"min=1,max=65535\"`\n" +
+				"}\n" +
+				"\n// Port returns the value of the PORT env var.\n" +
+				"func (c *Config) Port() int {\n\treturn c.values.Port\n}\n" +
+				"\n// validate validates values against the constraints in their 'validate'\n" +
+				"// tags, with custom validations, e.g. 'pattern', matching regular expressions.\n" +
+				"var validate = newValidator()\n" +
+				"\n// newValidator returns a validator with the custom validations registered.\n" +
+				"func newValidator() *validator.Validate {\n" +
+				"\tv := validator.New()\n" +
+				"\tif err := v.RegisterValidation(\"pattern\", func(fl validator.FieldLevel) bool {\n" +
+				"\t\treturn regexp.MustCompile(fl.Param()).MatchString(fl.Field().String())\n" +
+				"\t}); err != nil {\n\t\tpanic(err)\n\t}\n" +
+				"\treturn v\n}\n" +
+				"\n// Validate checks the values of the configuration against the\n" +
+				"// constraints annotated in the env file.\n" +
+				"func (c *Config) Validate() error {\n" +
+				"\treturn validate.Struct(c.values)\n}\n",
+		},
+		{
+			name:  "path checks",
+			opts:  []Option{WithPathChecks()},
+			lines: []string{"CERT_FILE=/etc/cert.pem", "# optional", "DATA_DIR=", "TIMEOUT_PATH=30"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tCertFile string `envconfig:\"CERT_FILE\" required:\"true\"`\n" +
+				"\tDataDir *string `envconfig:\"DATA_DIR\"`\n" +
+				"\tTimeoutPath int `envconfig:\"TIMEOUT_PATH\" required:\"true\"`\n" +
+				"}\n" +
+				"\n// Validate checks the values of the configuration against the\n" +
+				"// constraints annotated in the env file and checks that its paths exist,\n" +
+				"// returning all the violations.\n" +
+				"func (c *Config) Validate() error {\n" +
+				"\tvar errs []error\n" +
+				"\tif c.CertFile != \"\" {\n" +
+				"\t\tif err := checkFile(c.CertFile); err != nil {\n" +
+				"\t\t\terrs = append(errs, fmt.Errorf(\"CERT_FILE: %w\", err))\n\t\t}\n\t}\n" +
+				"\tif c.DataDir != nil && *c.DataDir != \"\" {\n" +
+				"\t\tif err := checkDir(*c.DataDir); err != nil {\n" +
+				"\t\t\terrs = append(errs, fmt.Errorf(\"DATA_DIR: %w\", err))\n\t\t}\n\t}\n" +
+				"\treturn stderrors.Join(errs...)\n}\n",
+		},
+		{
+			name:  "path checks with validator tags",
+			opts:  []Option{WithPathChecks(), WithValidatorTags()},
+			lines: []string{"LOG_PATH=/var/log"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tLogPath string `envconfig:\"LOG_PATH\" required:\"true\" validate:\"existing_path\"`\n" +
+				"}\n" +
+				"\n// validate validates values against the constraints in their 'validate'\n" +
+				"// tags, with custom validations, e.g. 'pattern', matching regular expressions.\n" +
+				"var validate = newValidator()\n" +
+				"\n// newValidator returns a validator with the custom validations registered.\n" +
+				"func newValidator() *validator.Validate {\n" +
+				"\tv := validator.New()\n" +
+				"\tif err := v.RegisterValidation(\"pattern\", func(fl validator.FieldLevel) bool {\n" +
+				"\t\treturn regexp.MustCompile(fl.Param()).MatchString(fl.Field().String())\n" +
+				"\t}); err != nil {\n\t\tpanic(err)\n\t}\n" +
+				"\tfor tag, check := range map[string]func(path string) error{\n" +
+				"\t\t\"readable_file\": func(path string) error { return checkFile(path) },\n" +
+				"\t\t\"existing_dir\": func(path string) error { return checkDir(path) },\n" +
+				"\t\t\"existing_path\": func(path string) error { return checkPath(path) },\n" +
+				"\t} {\n" +
+				"\t\tif err := v.RegisterValidation(tag, pathValidation(check)); err != nil {\n" +
+				"\t\t\tpanic(err)\n\t\t}\n\t}\n" +
+				"\treturn v\n}\n" +
+				"\n// pathValidation returns a validation of paths with the given check,\n" +
+				"// leaving empty paths unchecked.\n" +
+				"func pathValidation(check func(path string) error) validator.Func {\n" +
+				"\treturn func(fl validator.FieldLevel) bool {\n" +
+				"\t\tpath := fl.Field().String()\n" +
+				"\t\treturn path == \"\" || check(path) == nil\n\t}\n}\n" +
+				"\n// Validate checks the values of the configuration against the\n" +
+				"// constraints annotated in the env file and checks that its paths exist.\n" +
+				"func (c *Config) Validate() error {\n" +
+				"\treturn validate.Struct(c)\n}\n",
+		},
+		{
+			name:  "masked secrets",
+			opts:  []Option{WithMaskedSecrets()},
+			lines: []string{"DB_PASSWORD=secret", "API_TOKEN=", "TOKEN_TTL=60", "SIGNING_KEY=abc", "KEY_NAME=main"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tDbPassword Secret `envconfig:\"DB_PASSWORD\" required:\"true\"`\n" +
+				"\tApiToken *Secret `envconfig:\"API_TOKEN\"`\n" +
+				"\tTokenTtl int `envconfig:\"TOKEN_TTL\" required:\"true\"`\n" +
+				"\tSigningKey Secret `envconfig:\"SIGNING_KEY\" required:\"true\"`\n" +
+				"\tKeyName string `envconfig:\"KEY_NAME\" required:\"true\"`\n" +
+				"}\n" + secretTypeDeclaration,
+		},
+		{
+			name:  "masked secrets immutable",
+			opts:  []Option{WithMaskedSecrets(), WithImmutable()},
+			lines: []string{"DB_PASSWORD=secret"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"// Its values can't be changed once read: they're exposed through getters.\n" +
+				"type Config struct {\n" +
+				"\tvalues configValues\n" +
+				"}\n\n" +
+				"// configValues holds the values of Config, read from env vars.\n" +
+				"type configValues struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tDbPassword Secret `envconfig:\"DB_PASSWORD\" required:\"true\"`\n" +
+				"}\n" +
+				"\n// DbPassword returns the value of the DB_PASSWORD env var.\n" +
+				"func (c *Config) DbPassword() Secret {\n\treturn c.values.DbPassword\n}\n" +
+				"\n// String returns the values of the configuration, with secrets masked.\n" +
+				"func (c Config) String() string {\n\treturn fmt.Sprintf(\"%+v\", c.values)\n}\n" +
+				secretTypeDeclaration,
+		},
+		{
+			name:  "masked secrets without sensitive variables",
+			opts:  []Option{WithMaskedSecrets()},
+			lines: []string{"DB_HOST=localhost"},
+			expectedOutput: "// Config holds all configuration needed by this app.\n" +
+				"type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\tDbHost string `envconfig:\"DB_HOST\" required:\"true\"`\n" +
+				"}\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			vars, err := parseEnvFile(&mockLineReader{lines: tc.lines}, make(map[string]string))
+			require.NoError(t, err)
+			output, err := g.generateStruct(vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+func Test_structDeclaration(t *testing.T) {
+	model := &StructModel{
+		Name: "Config",
+		Fields: []StructField{
+			{Name: "Port", Type: "int", Tags: `envconfig:"PORT"`, Doc: "Port is the port\nto listen on."},
+			{Name: "Extra", Type: "interface{}", Comment: "TODO: set the correct data type."},
+		},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			expectedOutput: "type Config struct {\n" +
+				"\t// TODO: see https://github.com/kelseyhightower/envconfig for all available options\n\t// for struct tags.\n" +
+				"\t// Port is the port\n\t// to listen on.\n" +
+				"\tPort int `envconfig:\"PORT\"`\n" +
+				"\tExtra interface{} // TODO: set the correct data type.\n" +
+				"}\n",
+		},
+		{
+			name: "custom struct template",
+			opts: []Option{WithStructTemplate("type {{ .Name }} struct {\n{{ range .Fields }}\t{{ .Name }} {{ .Type }}\n{{ end }}}\n")},
+			expectedOutput: "type Config struct {\n" +
+				"\tPort int\n" +
+				"\tExtra interface{}\n" +
+				"}\n",
+		},
+		{
+			name:          "error parsing struct template",
+			opts:          []Option{WithStructTemplate("type {{ .Name }")},
+			expectedError: errors.New(`parsing struct template: template: struct:1: unexpected "}" in operand`),
+		},
+		{
+			name:          "error executing struct template",
+			opts:          []Option{WithStructTemplate("type {{ .Package }} struct {}")},
+			expectedError: errors.New(`executing struct template: template: struct:1:8: executing "struct" at <.Package>: can't evaluate field Package in type *cfg.StructModel`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			output, err := g.structDeclaration(model)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestGenerateConfigPackageFromEnvFiles(t *testing.T) {
+	testCases := []struct {
+		name           string
+		envFilePaths   []string
+		mockClosure    func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader)
+		expectedOutput []string
+		expectedError  error
+	}{
+		{
+			name:         "happy path",
+			envFilePaths: []string{".env", ".env.local"},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader) {
+				mf := new(mockFile)
+				mfs.createdFile = mf
+				mfs.openedFile = mf
+				mtp.te = new(mockTemplateExecutor)
+				mlr.lines = []string{"var=value"}
+			},
+			expectedOutput: []string{
+				"config/config.go",
+				"config/config_test.go",
+			},
+		},
+		{
+			name:          "no env files",
+			mockClosure:   func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader) {},
+			expectedError: errors.New("no env files provided"),
+		},
+		{
+			name:         "error when opening env file",
+			envFilePaths: []string{".env", ".env.local"},
+			mockClosure: func(mfs *mockFileSystem, mtp *mockTemplateProcessor, mlr *mockLineReader) {
+				mfs.createdFile = new(mockFile)
+				mfs.openErr = errors.New("open error")
+			},
+			expectedError: errors.New("opening env file .env: open error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			mtp := new(mockTemplateProcessor)
+			mlr := new(mockLineReader)
+			tc.mockClosure(mfs, mtp, mlr)
+			g := NewGenerator("config",
+				WithFileSystem(mfs),
+				WithTemplateProcessor(mtp),
+				WithFormatter(new(mockFormatter)),
+				withLineReader(func(_ io.Reader) lineReader {
+					return mlr
+				}),
+			)
+			output, err := g.GenerateConfigPackageFromEnvFiles(tc.envFilePaths...)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	mf := new(mockFile)
+	mocks := []Option{
+		WithFileSystem(&mockFileSystem{createdFile: mf, openedFile: mf}),
+		WithTemplateProcessor(&mockTemplateProcessor{te: new(mockTemplateExecutor)}),
+		WithFormatter(new(mockFormatter)),
+		withLineReader(func(_ io.Reader) lineReader {
+			return &mockLineReader{lines: []string{"PORT=8080"}}
+		}),
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	_, err := NewGenerator("config", append(mocks, WithLogger(logger), WithSystemd())...).GenerateConfigPackageFromEnvFile(".env")
+	require.NoError(t, err)
+	expectedOutput := `level=DEBUG msg="parsing env file" file=.env
+level=DEBUG msg="parsed env file" file=.env vars=1
+level=DEBUG msg="generating struct" fields=1
+level=DEBUG msg="executing template" template=configReaderMainFile file=config/config.go
+level=DEBUG msg="formatting file" file=config/config.go
+level=DEBUG msg="executing template" template=configReaderUnitTestFile file=config/config_test.go
+level=DEBUG msg="generating file" file=systemd.env
+`
+	require.Equal(t, expectedOutput, buf.String())
+}
+
+func TestGenerateConfigPackageFromStdin(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stdin         io.Reader
+		envFilePaths  []string
+		expectedVars  []string
+		expectedError error
+	}{
+		{
+			name:         "stdin only",
+			stdin:        strings.NewReader("PORT=8080\n"),
+			envFilePaths: []string{StdinEnvFile},
+			expectedVars: []string{"PORT"},
+		},
+		{
+			name:         "stdin overriding env file",
+			stdin:        strings.NewReader("PORT=8081\nHOST=localhost\n"),
+			envFilePaths: []string{".env", StdinEnvFile},
+			expectedVars: []string{"PORT", "HOST"},
+		},
+		{
+			name:          "error reading stdin",
+			stdin:         iotest.ErrReader(errors.New("read error")),
+			envFilePaths:  []string{StdinEnvFile},
+			expectedError: errors.New("reading env file from standard input: read error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte("PORT=8080\n")})
+			g := NewGenerator("config", WithFileSystem(fsys), WithStdin(tc.stdin))
+			_, err := g.GenerateConfigPackageFromEnvFiles(tc.envFilePaths...)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				config := string(fsys.Files()["config/config.go"])
+				for _, v := range tc.expectedVars {
+					require.Contains(t, config, `envconfig:"`+v+`"`)
+				}
+				require.NotContains(t, config, "//go:generate")
+				require.NotContains(t, config, "// Source: -")
+			}
+		})
+	}
+}
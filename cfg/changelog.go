@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// changelogFileName is the name of the changelog generated
+// in the package dir with WithChangelog.
+const changelogFileName = "CONFIG_CHANGELOG.md"
+
+// changelogDateLayout is the layout of the dates of the changelog entries.
+const changelogDateLayout = "2006-01-02"
+
+// previousStructFields returns the fields of the 'Config' struct of the
+// package being regenerated, keyed by their prefixed env vars, or nil if
+// it wasn't generated yet.
+func (g *generator) previousStructFields() ([]structField, error) {
+	configFilePath := filepath.Join(g.packageDir(), configReadFileName)
+	if _, err := g.fs.ReadFile(configFilePath); g.fs.IsNotExist(err) {
+		return nil, nil
+	}
+	configStruct, prefix, err := g.readConfigStruct(configFilePath, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading previous config")
+	}
+	return prefixedStructFields(structFields(configStruct), prefix), nil
+}
+
+// prefixedStructFields returns the given fields with
+// the given env var prefix, if any, added to their keys.
+func prefixedStructFields(fields []structField, prefix string) []structField {
+	if prefix == "" {
+		return fields
+	}
+	for i := range fields {
+		fields[i].key = strings.ToUpper(prefix) + "_" + fields[i].key
+	}
+	return fields
+}
+
+// changelogEntry returns the entry of the changelog describing the env vars
+// added, removed or retyped from the given previous fields to the given new
+// ones, or an empty string if none was.
+func changelogEntry(previous, current []structField) string {
+	previousIndex := make(map[string]structField, len(previous))
+	for _, f := range previous {
+		previousIndex[f.key] = f
+	}
+	currentIndex := make(map[string]structField, len(current))
+	var added, removed, retyped []string
+	for _, f := range current {
+		currentIndex[f.key] = f
+		switch previousField, ok := previousIndex[f.key]; {
+		case !ok:
+			added = append(added, fmt.Sprintf("- `%s` (%s)\n", f.key, f.typ))
+		case previousField.typ != f.typ:
+			retyped = append(retyped, fmt.Sprintf("- `%s`: %s -> %s\n", f.key, previousField.typ, f.typ))
+		}
+	}
+	for _, f := range previous {
+		if _, ok := currentIndex[f.key]; !ok {
+			removed = append(removed, fmt.Sprintf("- `%s` (%s)\n", f.key, f.typ))
+		}
+	}
+	var sb strings.Builder
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{{"Added", added}, {"Removed", removed}, {"Retyped", retyped}} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		sb.WriteString("\n" + section.title + ":\n\n")
+		sb.WriteString(strings.Join(section.lines, ""))
+	}
+	return sb.String()
+}
+
+// generateChangelog appends an entry, dated today, describing the env vars
+// added, removed or retyped from the given previous fields to the fields
+// generated for the given variables to the changelog of the package,
+// creating it if needed. It returns the path of the changelog, or an empty
+// string if no env var changed, in which case it's left untouched.
+func (g *generator) generateChangelog(previous []structField, vars []envVar) (string, error) {
+	configStruct, err := g.parseGeneratedStruct(vars)
+	if err != nil {
+		return "", err
+	}
+	entry := changelogEntry(previous, prefixedStructFields(structFields(configStruct), g.envPrefix()))
+	if entry == "" {
+		return "", nil
+	}
+	changelogFilePath := filepath.Join(g.packageDir(), changelogFileName)
+	content, err := g.fs.ReadFile(changelogFilePath)
+	if err != nil && !g.fs.IsNotExist(err) {
+		return "", errors.Wrapf(err, "reading file %s", changelogFilePath)
+	}
+	changelog := string(content)
+	if changelog == "" {
+		changelog = "# Config changelog\n\nChanges to the env vars read by the " + g.packageName + " package, appended by goprojconfig\nwhenever it's regenerated.\n"
+	}
+	if !strings.HasSuffix(changelog, "\n") {
+		changelog += "\n"
+	}
+	changelog += "\n## " + g.now().Format(changelogDateLayout) + "\n" + entry
+	if err := g.fs.WriteFile(changelogFilePath, []byte(changelog), 0644); err != nil {
+		return "", errors.Wrapf(err, "writing file %s", changelogFilePath)
+	}
+	return changelogFilePath, nil
+}
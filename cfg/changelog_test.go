@@ -0,0 +1,85 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_changelogEntry(t *testing.T) {
+	testCases := []struct {
+		name           string
+		previous       []structField
+		current        []structField
+		expectedOutput string
+	}{
+		{
+			name:           "first generation",
+			current:        []structField{{key: "PORT", typ: "int"}, {key: "HOST", typ: "string"}},
+			expectedOutput: "\nAdded:\n\n- `PORT` (int)\n- `HOST` (string)\n",
+		},
+		{
+			name:     "added, removed and retyped",
+			previous: []structField{{key: "PORT", typ: "string"}, {key: "LEGACY_URL", typ: "*url.URL"}, {key: "HOST", typ: "string"}},
+			current:  []structField{{key: "PORT", typ: "int"}, {key: "HOST", typ: "string"}, {key: "TIMEOUT", typ: "time.Duration"}},
+			expectedOutput: "\nAdded:\n\n- `TIMEOUT` (time.Duration)\n" +
+				"\nRemoved:\n\n- `LEGACY_URL` (*url.URL)\n" +
+				"\nRetyped:\n\n- `PORT`: string -> int\n",
+		},
+		{
+			name:     "no changes",
+			previous: []structField{{key: "PORT", typ: "int"}},
+			current:  []structField{{key: "PORT", typ: "int"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, changelogEntry(tc.previous, tc.current))
+		})
+	}
+}
+
+func TestGenerateWithChangelog(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{".env": []byte("APP_PORT=8080\nAPP_HOST=localhost\n")})
+	generate := func(date string, envFile string) []string {
+		now := func() time.Time {
+			d, err := time.Parse(changelogDateLayout, date)
+			require.NoError(t, err)
+			return d
+		}
+		require.NoError(t, fsys.WriteFile(".env", []byte(envFile), 0644))
+		g := NewGenerator("appcfg", WithFileSystem(fsys), WithPrefix("APP_"), WithChangelog(), withNow(now))
+		generatedFiles, err := g.GenerateConfigPackageFromEnvFile(".env")
+		require.NoError(t, err)
+		return generatedFiles
+	}
+	require.Contains(t, generate("2024-05-01", "APP_PORT=8080\nAPP_HOST=localhost\n"), "appcfg/CONFIG_CHANGELOG.md")
+	require.NotContains(t, generate("2024-05-02", "APP_PORT=8080\nAPP_HOST=localhost\n"), "appcfg/CONFIG_CHANGELOG.md")
+	require.Contains(t, generate("2024-05-03", "APP_PORT=http\nAPP_DEBUG=true\n"), "appcfg/CONFIG_CHANGELOG.md")
+	require.Equal(t, "# Config changelog\n\n"+
+		"Changes to the env vars read by the appcfg package, appended by goprojconfig\n"+
+		"whenever it's regenerated.\n"+
+		"\n## 2024-05-01\n"+
+		"\nAdded:\n\n- `APP_PORT` (int)\n- `APP_HOST` (string)\n"+
+		"\n## 2024-05-03\n"+
+		"\nAdded:\n\n- `APP_DEBUG` (bool)\n"+
+		"\nRemoved:\n\n- `APP_HOST` (string)\n"+
+		"\nRetyped:\n\n- `APP_PORT`: int -> string\n",
+		string(fsys.Files()["appcfg/CONFIG_CHANGELOG.md"]))
+}
+
+func TestGenerateWithChangelogInvalidPreviousConfig(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{
+		".env":             []byte("PORT=8080\n"),
+		"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {\n"),
+	})
+	g := NewGenerator("appcfg", WithFileSystem(fsys), WithChangelog())
+	_, err := g.GenerateConfigPackageFromEnvFile(".env")
+	require.Equal(t, errors.New("reading previous config: parsing file appcfg/config.go: appcfg/config.go:3:22: expected '}', found 'EOF'").Error(), err.Error())
+}
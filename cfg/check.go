@@ -0,0 +1,226 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CheckEnv validates the given env vars against the 'Config' struct
+// declared in the given Go file, e.g. 'appcfg/config.go', the same way
+// the generated package does when reading them. It returns a description
+// of every problem found: required variables that are not set and values
+// that can't be parsed into the types of the correspondent fields. Options
+// other than WithFileSystem, which the Go file is read from, and
+// WithStructName are ignored.
+func CheckEnv(configFilePath string, env map[string]string, opts ...Option) ([]string, error) {
+	g := NewGenerator("config", opts...).(*generator)
+	configStruct, prefix, err := g.readConfigStruct(configFilePath, 0)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, field := range configStruct.Fields.List {
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			if problem := checkField(name.Name, field, env, prefix); problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems, nil
+}
+
+// readConfigStruct parses the given Go file with the given parser mode,
+// returning the declaration of its 'Config' struct, or of the struct set
+// with WithStructName, or of the struct holding its values if it's
+// immutable, along with the prefix of the env vars read into it, if any.
+func (g *generator) readConfigStruct(configFilePath string, mode parser.Mode) (*ast.StructType, string, error) {
+	src, err := g.fs.ReadFile(configFilePath)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "reading file %s", configFilePath)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), configFilePath, src, mode)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "parsing file %s", configFilePath)
+	}
+	configStruct := findConfigStruct(file, g.structName)
+	if configStruct == nil {
+		return nil, "", errors.Errorf("struct %s not found in %s", g.structName, configFilePath)
+	}
+	return configStruct, findEnvPrefix(file), nil
+}
+
+// findConfigStruct returns the struct declared in the given file whose
+// fields are read from env vars: 'configValues' for immutable packages,
+// or else the one with the given name. It returns nil if there's none.
+func findConfigStruct(file *ast.File, structName string) *ast.StructType {
+	if values := findStruct(file, configValuesStructName); values != nil {
+		return values
+	}
+	return findStruct(file, structName)
+}
+
+// findEnvPrefix returns the value of the 'envPrefix' constant declared
+// in the given file by packages generated with a prefix, or an empty
+// string if there's none.
+func findEnvPrefix(file *ast.File) string {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, name := range valueSpec.Names {
+				if name.Name != "envPrefix" || i >= len(valueSpec.Values) {
+					continue
+				}
+				if lit, ok := valueSpec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					prefix, _ := strconv.Unquote(lit.Value)
+					return prefix
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// findStruct returns the struct type with the given name
+// declared in the given file, or nil if there's none.
+func findStruct(file *ast.File, name string) *ast.StructType {
+	var found *ast.StructType
+	ast.Inspect(file, func(n ast.Node) bool {
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok || typeSpec.Name.Name != name {
+			return found == nil
+		}
+		if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+			found = structType
+		}
+		return false
+	})
+	return found
+}
+
+// checkField validates the env var of the given struct field, read with
+// the given prefix, returning a description of the problem found, if any.
+func checkField(fieldName string, field *ast.Field, env map[string]string, prefix string) string {
+	tag := fieldTag(field)
+	if tag.Get("ignored") == "true" {
+		return ""
+	}
+	key := fieldEnvKey(fieldName, tag, prefix)
+	value, ok := env[key]
+	if !ok {
+		defaultValue, hasDefault := tag.Lookup("default")
+		if !hasDefault {
+			if tag.Get("required") == "true" {
+				return fmt.Sprintf("%s: required but not set", key)
+			}
+			return ""
+		}
+		value = defaultValue
+	}
+	fieldType := types.ExprString(field.Type)
+	if err := checkValue(strings.TrimPrefix(fieldType, "*"), value); err != nil {
+		return fmt.Sprintf("%s: invalid value %q for %s: %v", key, value, fieldType, err)
+	}
+	return ""
+}
+
+// fieldTag returns the tag of the given struct field.
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	unquoted, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(unquoted)
+}
+
+// fieldEnvKey returns the name of the env var read into the struct field
+// with the given name and tag, as envconfig does with the given prefix.
+func fieldEnvKey(fieldName string, tag reflect.StructTag, prefix string) string {
+	key := fieldName
+	if alt := tag.Get("envconfig"); alt != "" {
+		key = alt
+	}
+	if prefix != "" {
+		key = prefix + "_" + key
+	}
+	return strings.ToUpper(key)
+}
+
+// checkValue checks that the given value can be parsed into the given
+// type. Types that are not known to envconfig, like custom decoders,
+// are not checked.
+func checkValue(fieldType, value string) error {
+	var err error
+	switch fieldType {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int", "int64":
+		_, err = strconv.ParseInt(value, 0, 64)
+	case "int8", "int16", "int32":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(fieldType, "int"))
+		_, err = strconv.ParseInt(value, 0, bits)
+	case "uint", "uint64":
+		_, err = strconv.ParseUint(value, 0, 64)
+	case "uint8", "uint16", "uint32":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(fieldType, "uint"))
+		_, err = strconv.ParseUint(value, 0, bits)
+	case "float32":
+		_, err = strconv.ParseFloat(value, 32)
+	case "float64":
+		_, err = strconv.ParseFloat(value, 64)
+	case "time.Duration":
+		_, err = time.ParseDuration(value)
+	case "time.Time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "url.URL", "*url.URL":
+		_, err = url.Parse(value)
+	case "net.IP":
+		if net.ParseIP(value) == nil {
+			err = errors.New("invalid IP address")
+		}
+	case "map[string]string":
+		for _, pair := range strings.Split(value, ",") {
+			if !strings.Contains(pair, ":") {
+				return errors.Errorf("invalid map item %q", pair)
+			}
+		}
+		return nil
+	default:
+		if elemType, ok := strings.CutPrefix(fieldType, "[]"); ok && strings.TrimSpace(value) != "" {
+			for _, elem := range strings.Split(value, ",") {
+				if err := checkValue(elemType, elem); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if numErr, ok := err.(*strconv.NumError); ok {
+		return numErr.Err
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const checkedConfigFile = `package appcfg
+
+import "time"
+
+type Config struct {
+	DbHost   string        ` + "`envconfig:\"DB_HOST\" required:\"true\"`" + `
+	Port     int           ` + "`envconfig:\"PORT\" required:\"true\" default:\"8080\"`" + `
+	Debug    *bool         ` + "`envconfig:\"DEBUG\"`" + `
+	Timeout  time.Duration ` + "`envconfig:\"TIMEOUT\" default:\"5s\"`" + `
+	Ports    []int         ` + "`envconfig:\"PORTS\"`" + `
+	Internal string        ` + "`ignored:\"true\" required:\"true\"`" + `
+	LogLevel string        ` + "`required:\"true\"`" + `
+	private  int
+}
+`
+
+func TestCheckEnv(t *testing.T) {
+	testCases := []struct {
+		name           string
+		env            map[string]string
+		mockClosure    func(mfs *mockFileSystem)
+		expectedOutput []string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			env:  map[string]string{"DB_HOST": "localhost", "LOGLEVEL": "debug"},
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte(checkedConfigFile)
+			},
+		},
+		{
+			name: "invalid values",
+			env: map[string]string{
+				"DB_HOST":  "localhost",
+				"PORT":     "abc",
+				"DEBUG":    "maybe",
+				"TIMEOUT":  "5",
+				"PORTS":    "80,x",
+				"LOGLEVEL": "debug",
+			},
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte(checkedConfigFile)
+			},
+			expectedOutput: []string{
+				`PORT: invalid value "abc" for int: invalid syntax`,
+				`DEBUG: invalid value "maybe" for *bool: invalid syntax`,
+				`TIMEOUT: invalid value "5" for time.Duration: time: missing unit in duration "5"`,
+				`PORTS: invalid value "80,x" for []int: invalid syntax`,
+			},
+		},
+		{
+			name: "required variables not set",
+			env:  map[string]string{},
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte(checkedConfigFile)
+			},
+			expectedOutput: []string{
+				"DB_HOST: required but not set",
+				"LOGLEVEL: required but not set",
+			},
+		},
+		{
+			name: "prefix",
+			env:  map[string]string{"APP_DB_HOST": "localhost", "APP_PORT": "abc"},
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte(checkedConfigFile + "\nconst envPrefix = \"APP\"\n")
+			},
+			expectedOutput: []string{
+				`APP_PORT: invalid value "abc" for int: invalid syntax`,
+				"APP_LOGLEVEL: required but not set",
+			},
+		},
+		{
+			name: "immutable",
+			env:  map[string]string{"PORT": "abc"},
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte("package appcfg\n\ntype Config struct {\n\tvalues configValues\n}\n\n" +
+					"type configValues struct {\n\tPort int `envconfig:\"PORT\" required:\"true\"`\n}\n")
+			},
+			expectedOutput: []string{
+				`PORT: invalid value "abc" for int: invalid syntax`,
+			},
+		},
+		{
+			name: "error reading file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.readFileErr = errors.New("read error")
+			},
+			expectedError: errors.New("reading file appcfg/config.go: read error"),
+		},
+		{
+			name: "error parsing file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte("package appcfg\n\ntype Config struct {")
+			},
+			expectedError: errors.New("parsing file appcfg/config.go: appcfg/config.go:3:21: expected '}', found 'EOF'"),
+		},
+		{
+			name: "struct not found",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte("package appcfg\n\ntype Config string\n")
+			},
+			expectedError: errors.New("struct Config not found in appcfg/config.go"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			tc.mockClosure(mfs)
+			output, err := CheckEnv("appcfg/config.go", tc.env, WithFileSystem(mfs))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func Test_checkValue(t *testing.T) {
+	testCases := []struct {
+		fieldType     string
+		value         string
+		expectedError error
+	}{
+		{fieldType: "bool", value: "TRUE"},
+		{fieldType: "int", value: "0x1F"},
+		{fieldType: "int8", value: "300", expectedError: errors.New("value out of range")},
+		{fieldType: "uint", value: "-1", expectedError: errors.New("invalid syntax")},
+		{fieldType: "uint16", value: "65535"},
+		{fieldType: "float32", value: "0.5"},
+		{fieldType: "float64", value: "abc", expectedError: errors.New("invalid syntax")},
+		{fieldType: "time.Duration", value: "1m30s"},
+		{fieldType: "time.Time", value: "2024-03-01T10:00:00Z"},
+		{fieldType: "[]string", value: "a,b"},
+		{fieldType: "map[string]string", value: "k1:v1,k2:v2"},
+		{fieldType: "map[string]string", value: "k1:v1,k2", expectedError: errors.New(`invalid map item "k2"`)},
+		{fieldType: "[]int", value: ""},
+		{fieldType: "url.URL", value: "anything"},
+		{fieldType: "*url.URL", value: "http://[::1", expectedError: errors.New(`parse "http://[::1": missing ']' in host`)},
+		{fieldType: "net.IP", value: "10.0.0.1"},
+		{fieldType: "net.IP", value: "10.0.0", expectedError: errors.New("invalid IP address")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.fieldType+"="+tc.value, func(t *testing.T) {
+			err := checkValue(tc.fieldType, tc.value)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// schemaEnvVar is an env var of the config schema the generated
+// package pins with its checksum.
+type schemaEnvVar struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// schemaEnvVars returns the config schema defined by the given
+// variables: their names along with the Go types of their
+// fields and whether they're required, sorted by name.
+func (g *generator) schemaEnvVars(vars []envVar) []schemaEnvVar {
+	schema := make([]schemaEnvVar, 0, len(vars))
+	for _, v := range vars {
+		fieldType, _ := g.structField(v)
+		if fieldType == "" {
+			fieldType = defaultFieldType
+		}
+		schema = append(schema, schemaEnvVar{
+			Name:     g.prefixedKey(v.key),
+			Type:     fieldType,
+			Required: !g.hasDefault(v) && isRequired(v),
+		})
+	}
+	sort.Slice(schema, func(i, j int) bool {
+		return schema[i].Name < schema[j].Name
+	})
+	return schema
+}
+
+// schemaChecksum returns the checksum of the config schema
+// pinned by the generated package, if any.
+func (g *generator) schemaChecksum() string {
+	if !g.checksum {
+		return ""
+	}
+	return schemaChecksum(g.schema)
+}
+
+// schemaChecksum returns the hex encoded SHA-256 of the given
+// config schema, one 'NAME type' line per env var, the same
+// way the generated package computes it.
+func schemaChecksum(schema []schemaEnvVar) string {
+	h := sha256.New()
+	for _, v := range schema {
+		fmt.Fprintf(h, "%s %s\n", v.Name, v.Type)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
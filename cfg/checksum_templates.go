@@ -0,0 +1,251 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	checksumFileName                 = "checksum.go"
+	checksumUnitTestFileName         = "checksum_test.go"
+	checksumFileTemplateName         = "checksumFile"
+	checksumUnitTestFileTemplateName = "checksumUnitTestFile"
+	checksumFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+{{- if .EnvPrefix }}
+	"os"
+	"sort"
+{{- end }}
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// schemaEnvVar is an env var of the config schema.
+type schemaEnvVar struct {
+	name     string
+	typ      string
+	required bool
+}
+
+// schemaEnvVars are the env vars of the config schema the package
+// was generated for, sorted by name, along with their Go types and
+// whether they're required.
+var schemaEnvVars = []schemaEnvVar{
+{{- range .SchemaEnvVars }}
+	{ {{- printf "%q" .Name }}, {{ printf "%q" .Type }}, {{ .Required -}} },
+{{- end }}
+}
+
+// schemaChecksum is the SHA-256 of the config schema, pinned
+// when the package was generated.
+var schemaChecksum = "{{ .SchemaChecksum }}"
+
+// For ease of unit testing.
+var (
+	verifySchema  = verifyEnvSchema
+{{- if .EnvPrefix }}
+	schemaEnviron = os.Environ
+{{- end }}
+)
+
+{{- if .EnvPrefix }}
+
+// strictSchema makes verifyEnvSchema reject env vars with
+// the {{ .EnvPrefix }}_ prefix that are not in the config schema.
+var strictSchema bool
+
+// SetStrictSchema sets whether reading configuration fails when env vars
+// with the {{ .EnvPrefix }}_ prefix that are not in the config schema are set,
+// e.g. misspelled ones.
+func SetStrictSchema(strict bool) {
+	strictSchema = strict
+}
+{{- end }}
+
+// SchemaChecksum returns the SHA-256, hex encoded, of the config schema
+// the package was generated for: its env vars and their Go types, one
+// 'NAME type' line each, sorted by name. The schema is verified against
+// it when reading configuration.
+func SchemaChecksum() string {
+	return schemaChecksum
+}
+
+// verifyEnvSchema verifies that the config schema matches the pinned
+// checksum, and that the set env vars match it: every required env var
+// of the schema must be set, even if empty, so that environments that
+// miss any, e.g. because of outdated deployment manifests, are caught
+// before the configuration is used. Optional env vars, and the ones with
+// defaults, may be left unset.
+{{- if .EnvPrefix }}
+// With strictSchema, unexpected env vars with the prefix aren't allowed.
+{{- end }}
+func verifyEnvSchema() error {
+	if checksum(schemaEnvVars) != schemaChecksum {
+		return errors.Errorf("config schema doesn't match its checksum %s, the package must be regenerated", schemaChecksum)
+	}
+	var missing []string
+	for _, v := range schemaEnvVars {
+		if _, ok := lookupEnv(v.name); v.required && !ok {
+			missing = append(missing, v.name)
+		}
+	}
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+{{- if .EnvPrefix }}
+	if strictSchema {
+		if unexpected := unexpectedEnvVars(); len(unexpected) > 0 {
+			problems = append(problems, "unexpected "+strings.Join(unexpected, ", "))
+		}
+	}
+{{- end }}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.Errorf("env vars don't match config schema %s: %s", schemaChecksum, strings.Join(problems, "; "))
+}
+
+{{- if .EnvPrefix }}
+
+// unexpectedEnvVars returns the names of the set env vars with
+// the {{ .EnvPrefix }}_ prefix that are not in the config schema, sorted.
+func unexpectedEnvVars() []string {
+	known := make(map[string]bool, len(schemaEnvVars))
+	for _, v := range schemaEnvVars {
+		known[v.name] = true
+	}
+	var unexpected []string
+	for _, envVar := range schemaEnviron() {
+		name, _, _ := strings.Cut(envVar, "=")
+		if strings.HasPrefix(name, envPrefix+"_") && !known[name] {
+			unexpected = append(unexpected, name)
+		}
+	}
+	sort.Strings(unexpected)
+	return unexpected
+}
+{{- end }}
+
+// checksum returns the hex encoded SHA-256 of the given
+// env vars, one 'NAME type' line each.
+func checksum(vars []schemaEnvVar) string {
+	h := sha256.New()
+	for _, v := range vars {
+		fmt.Fprintf(h, "%s %s\n", v.name, v.typ)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+`
+
+	checksumUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaChecksum(t *testing.T) {
+	require.Equal(t, checksum(schemaEnvVars), SchemaChecksum())
+}
+
+func TestVerifyEnvSchema(t *testing.T) {
+	schema := []schemaEnvVar{ {"{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_HOST", "string", true}, {"{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_PORT", "int", false} }
+	testCases := []struct {
+		name          string
+		env           map[string]string
+{{- if .EnvPrefix }}
+		strict        bool
+{{- end }}
+		checksum      string
+		expectedError error
+	}{
+		{
+			name:     "happy path",
+			env:      map[string]string{"{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_HOST": "", "{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_PORT": "5432"{{ if .EnvPrefix }}, "{{ .EnvPrefix }}_DB_HOSTT": "localhost"{{ end }}},
+			checksum: checksum(schema),
+		},
+		{
+			name:     "optional env var not set",
+			env:      map[string]string{"{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_HOST": "localhost"},
+			checksum: checksum(schema),
+		},
+		{
+			name:          "required env var not set",
+			env:           map[string]string{"{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_PORT": "5432"},
+			checksum:      checksum(schema),
+			expectedError: errors.New("env vars don't match config schema " + checksum(schema) + ": missing {{ with .EnvPrefix }}{{ . }}_{{ end }}DB_HOST"),
+		},
+{{- if .EnvPrefix }}
+		{
+			name:     "strict",
+			env:      map[string]string{"{{ .EnvPrefix }}_DB_HOST": "localhost", "{{ .EnvPrefix }}_DB_PORT": "5432"},
+			strict:   true,
+			checksum: checksum(schema),
+		},
+		{
+			name:          "unexpected env var",
+			env:           map[string]string{"{{ .EnvPrefix }}_DB_PORT": "5432", "{{ .EnvPrefix }}_DB_HOSTT": "localhost"},
+			strict:        true,
+			checksum:      checksum(schema),
+			expectedError: errors.New("env vars don't match config schema " + checksum(schema) + ": missing {{ .EnvPrefix }}_DB_HOST; unexpected {{ .EnvPrefix }}_DB_HOSTT"),
+		},
+{{- end }}
+		{
+			name:          "outdated checksum",
+			env:           map[string]string{"{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_HOST": "localhost", "{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_PORT": "5432"},
+			checksum:      "outdated",
+			expectedError: errors.New("config schema doesn't match its checksum outdated, the package must be regenerated"),
+		},
+	}
+	vars, pinned, lookup := schemaEnvVars, schemaChecksum, lookupEnv
+{{- if .EnvPrefix }}
+	environ := schemaEnviron
+{{- end }}
+	t.Cleanup(func() {
+		schemaEnvVars, schemaChecksum, lookupEnv = vars, pinned, lookup
+{{- if .EnvPrefix }}
+		schemaEnviron = environ
+		strictSchema = false
+{{- end }}
+	})
+	schemaEnvVars = schema
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schemaChecksum = tc.checksum
+			lookupEnv = func(key string) (string, bool) {
+				value, ok := tc.env[key]
+				return value, ok
+			}
+{{- if .EnvPrefix }}
+			schemaEnviron = func() []string {
+				var env []string
+				for key, value := range tc.env {
+					env = append(env, key+"="+value)
+				}
+				return env
+			}
+			SetStrictSchema(tc.strict)
+{{- end }}
+			err := verifyEnvSchema()
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+			}
+		})
+	}
+}
+`
+)
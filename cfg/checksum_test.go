@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_schemaChecksum(t *testing.T) {
+	testCases := []struct {
+		name           string
+		schema         []schemaEnvVar
+		expectedOutput string
+	}{
+		{
+			name:           "empty schema",
+			expectedOutput: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{
+			name:           "env vars",
+			schema:         []schemaEnvVar{{Name: "DB_HOST", Type: "string"}, {Name: "PORT", Type: "int"}},
+			expectedOutput: "6a42cbc1c36002da417d67e242b465ee58fcc45e379e1efa881234e10a4e6642",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, schemaChecksum(tc.schema))
+		})
+	}
+}
+
+func Test_schemaEnvVars(t *testing.T) {
+	vars := []envVar{
+		{key: "PORT", value: "8080"},
+		{key: "DB_HOST", value: "localhost"},
+		{key: "DB_PASSWORD", value: ""},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput []schemaEnvVar
+	}{
+		{
+			name: "env vars",
+			expectedOutput: []schemaEnvVar{
+				{Name: "DB_HOST", Type: "string", Required: true},
+				{Name: "DB_PASSWORD", Type: "*string"},
+				{Name: "PORT", Type: "int", Required: true},
+			},
+		},
+		{
+			name: "prefix",
+			opts: []Option{WithPrefix("APP_")},
+			expectedOutput: []schemaEnvVar{
+				{Name: "APP_DB_HOST", Type: "string", Required: true},
+				{Name: "APP_DB_PASSWORD", Type: "*string"},
+				{Name: "APP_PORT", Type: "int", Required: true},
+			},
+		},
+		{
+			name: "defaults",
+			opts: []Option{WithDefaultsFromValues()},
+			expectedOutput: []schemaEnvVar{
+				{Name: "DB_HOST", Type: "string"},
+				{Name: "DB_PASSWORD", Type: "*string"},
+				{Name: "PORT", Type: "int"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("appcfg", tc.opts...).(*generator)
+			require.Equal(t, tc.expectedOutput, g.schemaEnvVars(vars))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	cloneFileName                 = "clone.go"
+	cloneUnitTestFileName         = "clone_test.go"
+	cloneFileTemplateName         = "cloneFile"
+	cloneUnitTestFileTemplateName = "cloneUnitTestFile"
+	cloneFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import "reflect"
+
+// Clone returns a deep copy of the configuration: what its pointers,
+// slices and maps refer to is copied too, so that changing the copy
+// doesn't change the original. It returns nil if the configuration is nil.
+func (c *{{ .StructName }}) Clone() *{{ .StructName }} {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	copyFields(reflect.ValueOf(&clone{{ if .Immutable }}.values{{ end }}).Elem())
+	return &clone
+}
+
+// Equal reports whether the configuration is deeply equal to the given one,
+// comparing what their pointers, slices and maps refer to. Two nil
+// configurations are equal.
+func (c *{{ .StructName }}) Equal(other *{{ .StructName }}) bool {
+	return reflect.DeepEqual(c, other)
+}
+
+// copyFields replaces the exported fields of the given
+// addressable struct with deep copies of them.
+func copyFields(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			v.Field(i).Set(deepCopy(v.Field(i)))
+		}
+	}
+}
+
+// deepCopy returns a deep copy of the given value.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		copyFields(c)
+		return c
+	}
+	return v
+}
+`
+
+	cloneUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	config := new({{ .StructName }})
+	clone := config.Clone()
+	require.Equal(t, config, clone)
+	require.NotSame(t, config, clone)
+	require.Nil(t, (*{{ .StructName }})(nil).Clone())
+}
+
+func TestEqual(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         *{{ .StructName }}
+		other          *{{ .StructName }}
+		expectedOutput bool
+	}{
+		{
+			name:           "equal",
+			config:         new({{ .StructName }}),
+			other:          new({{ .StructName }}),
+			expectedOutput: true,
+		},
+		{
+			name:           "both nil",
+			expectedOutput: true,
+		},
+		{
+			name:   "nil and not nil",
+			config: new({{ .StructName }}),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, tc.config.Equal(tc.other))
+		})
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	type nested struct {
+		Values []int
+	}
+	type values struct {
+		Ptr    *int
+		Slice  []string
+		Map    map[string]string
+		Nested nested
+		Any    interface{}
+		Nil    []string
+	}
+	n := 1
+	original := values{
+		Ptr:    &n,
+		Slice:  []string{"a", "b"},
+		Map:    map[string]string{"k": "v"},
+		Nested: nested{Values: []int{1, 2}},
+		Any:    []string{"c"},
+	}
+	copied := deepCopy(reflect.ValueOf(original)).Interface().(values)
+	require.Equal(t, original, copied)
+	*copied.Ptr = 2
+	copied.Slice[0] = "x"
+	copied.Map["k"] = "x"
+	copied.Nested.Values[0] = 0
+	copied.Any.([]string)[0] = "x"
+	require.Equal(t, values{
+		Ptr:    &n,
+		Slice:  []string{"a", "b"},
+		Map:    map[string]string{"k": "v"},
+		Nested: nested{Values: []int{1, 2}},
+		Any:    []string{"c"},
+	}, original)
+	require.Equal(t, 1, n)
+}
+`
+)
@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	cobraFlagsFileName                 = "flags.go"
+	cobraFlagsUnitTestFileName         = "flags_test.go"
+	cobraFlagsFileTemplateName         = "cobraFlagsFile"
+	cobraFlagsUnitTestFileTemplateName = "cobraFlagsUnitTestFile"
+	cobraFlagsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import "github.com/spf13/cobra"
+
+// configFlags are the flags registered by BindFlags,
+// one per {{ .StructName }} field.
+var configFlags = []struct {
+	name, envVar, typ, usage string
+}{
+{{- range .Flags }}
+	{"{{ .Name }}", "{{ .EnvVar }}", "{{ .Type }}", {{ printf "%q" .Usage }}},
+{{- end }}
+}
+
+// envVarFlag is a command-line flag that sets its env var when given,
+// so that it overrides it when configuration is read.
+type envVarFlag struct {
+	envVar string
+	typ    string
+	value  string
+}
+
+// Set sets the env var of the flag to the given value.
+func (f *envVarFlag) Set(value string) error {
+	if err := setenv(f.envVar, value); err != nil {
+		return err
+	}
+	f.value = value
+	return nil
+}
+
+// String returns the value the flag was given, if any. The value of
+// its env var is not returned, so that secrets are not shown in usages.
+func (f *envVarFlag) String() string {
+	return f.value
+}
+
+// Type returns the type of the flag values, as shown in usages.
+func (f *envVarFlag) Type() string {
+	return f.typ
+}
+
+// BindFlags registers a flag per {{ .StructName }} field on the given command, named
+// after its env var in kebab-case, e.g. '--db-host' for DB_HOST. Flags
+// set their env vars when the command line is parsed, so that they take
+// precedence over env vars and the '.env' file when configuration is read
+// afterwards, e.g. in the RunE function of the command.
+func BindFlags(cmd *cobra.Command) {
+	for _, f := range configFlags {
+		flag := cmd.Flags().VarPF(&envVarFlag{envVar: f.envVar, typ: f.typ}, f.name, "", f.usage)
+		if f.typ == "bool" {
+			flag.NoOptDefVal = "true"
+		}
+	}
+}
+`
+
+	cobraFlagsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindFlags(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          func(name string) []string
+		mockedSetenv  func(key, value string) error
+		expectedValue string
+		expectedError error
+	}{
+		{
+			name: "happy path",
+			args: func(name string) []string {
+				return []string{"--" + name + "=some value"}
+			},
+			expectedValue: "some value",
+		},
+		{
+			name: "flag not given",
+			args: func(name string) []string {
+				return nil
+			},
+		},
+		{
+			name: "error setting env var",
+			args: func(name string) []string {
+				return []string{"--" + name + "=some value"}
+			},
+			mockedSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("random error"),
+		},
+	}
+	for _, tc := range testCases {
+		for _, f := range configFlags {
+			t.Run(tc.name+" "+f.name, func(t *testing.T) {
+				env := make(map[string]string)
+				setenv = func(key, value string) error {
+					env[key] = value
+					return nil
+				}
+				if tc.mockedSetenv != nil {
+					setenv = tc.mockedSetenv
+				}
+				cmd := &cobra.Command{
+					Use:           "app",
+					SilenceUsage:  true,
+					SilenceErrors: true,
+					RunE: func(cmd *cobra.Command, args []string) error {
+						return nil
+					},
+				}
+				BindFlags(cmd)
+				cmd.SetArgs(tc.args(f.name))
+				err := cmd.Execute()
+				if err != nil {
+					if tc.expectedError == nil {
+						t.Fatalf("expected no error, got %v", err)
+					}
+					require.ErrorContains(t, err, tc.expectedError.Error())
+				} else {
+					if tc.expectedError != nil {
+						t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+					}
+					value, ok := env[f.envVar]
+					require.Equal(t, tc.expectedValue != "", ok)
+					require.Equal(t, tc.expectedValue, value)
+					require.Equal(t, tc.expectedValue, cmd.Flags().Lookup(f.name).Value.String())
+				}
+			})
+		}
+	}
+}
+
+func TestBindFlagsBoolWithoutValue(t *testing.T) {
+	for _, f := range configFlags {
+		if f.typ != "bool" {
+			continue
+		}
+		t.Run(f.name, func(t *testing.T) {
+			env := make(map[string]string)
+			setenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			cmd := &cobra.Command{
+				Use: "app",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return nil
+				},
+			}
+			BindFlags(cmd)
+			cmd.SetArgs([]string{"--" + f.name})
+			require.NoError(t, cmd.Execute())
+			require.Equal(t, "true", env[f.envVar])
+		})
+	}
+}
+`
+)
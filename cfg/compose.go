@@ -0,0 +1,48 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const dockerComposeFileName = "docker-compose.env.yaml"
+
+// sampleEnvVars are the variables of the sample env file generated
+// when no env file is provided.
+var sampleEnvVars = []envVar{{key: "SAMPLE_ENV_VAR", value: "some value", line: 1}}
+
+// dockerComposeEnv returns a Docker Compose 'environment' section listing
+// the given variables. Their values are taken from the host environment
+// through interpolation: required variables must be set, while optional
+// ones default to an empty string, or to the env file value when defaults
+// are taken from values.
+func (g *generator) dockerComposeEnv(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated by goprojconfig. Merge it into a service definition\n")
+	sb.WriteString("# of your docker-compose.yaml file.\n")
+	sb.WriteString("environment:\n")
+	for _, v := range vars {
+		writeComment(&sb, "  ", v.comment)
+		var placeholder string
+		switch {
+		case g.defaultsFromValues && isValidComposeDefaultValue(v.value):
+			placeholder = fmt.Sprintf("${%s:-%s}", v.key, strings.ReplaceAll(v.value, "$", "$$"))
+		case isRequired(v):
+			placeholder = fmt.Sprintf("${%s:?%s is required}", v.key, v.key)
+		default:
+			placeholder = fmt.Sprintf("${%s:-}", v.key)
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %q\n", v.key, placeholder))
+	}
+	return sb.String(), nil
+}
+
+// isValidComposeDefaultValue reports whether the given value can be
+// used as a default value in Docker Compose interpolation.
+func isValidComposeDefaultValue(value string) bool {
+	return isValidDefaultValue(value) && !strings.Contains(value, "}")
+}
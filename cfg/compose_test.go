@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dockerComposeEnv(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           []Option
+		vars           []envVar
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			vars: []envVar{
+				{key: "DB_HOST", value: "localhost", comment: "database host"},
+				{key: "DB_PASSWORD"},
+				{key: "API_KEY", comment: "required"},
+			},
+			expectedOutput: "# Generated by goprojconfig. Merge it into a service definition\n" +
+				"# of your docker-compose.yaml file.\n" +
+				"environment:\n" +
+				"  # database host\n" +
+				"  DB_HOST: \"${DB_HOST:?DB_HOST is required}\"\n" +
+				"  DB_PASSWORD: \"${DB_PASSWORD:-}\"\n" +
+				"  # required\n" +
+				"  API_KEY: \"${API_KEY:?API_KEY is required}\"\n",
+		},
+		{
+			name: "defaults from values",
+			opts: []Option{WithDefaultsFromValues()},
+			vars: []envVar{
+				{key: "PORT", value: "8080"},
+				{key: "PRICE", value: "$5"},
+				{key: "TEMPLATE", value: "{{ .Name }}"},
+				{key: "GREETING", value: `say "hi"`},
+			},
+			expectedOutput: "# Generated by goprojconfig. Merge it into a service definition\n" +
+				"# of your docker-compose.yaml file.\n" +
+				"environment:\n" +
+				"  PORT: \"${PORT:-8080}\"\n" +
+				"  PRICE: \"${PRICE:-$$5}\"\n" +
+				"  TEMPLATE: \"${TEMPLATE:?TEMPLATE is required}\"\n" +
+				"  GREETING: \"${GREETING:-say \\\"hi\\\"}\"\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			output, err := g.dockerComposeEnv(tc.vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const cueDefinitionFileName = "config.cue"
+
+// cueTypes maps the inferred Go field types to CUE types.
+var cueTypes = map[string]string{
+	"int":               "int",
+	"float64":           "number",
+	"bool":              "bool",
+	"string":            "string",
+	"[]string":          "[...string]",
+	"map[string]string": "{[string]: string}",
+	"time.Time":         "string",
+	"*url.URL":          "string",
+	"net.IP":            "string",
+}
+
+// cueDefinitionFile returns a CUE file declaring a '#Config' definition
+// with a field for every given variable, named after it. Fields with
+// defaults are marked as such, while fields that may be unset are optional.
+func (g *generator) cueDefinitionFile(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("// Generated by goprojconfig. Further constraints can be added to the\n")
+	sb.WriteString("// fields below, e.g. 'PORT: int & >0 & <65536'.\n\n")
+	sb.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
+	sb.WriteString(fmt.Sprintf("// #Config holds the env vars read by the %s package.\n", g.packageName))
+	sb.WriteString("#Config: {\n")
+	for _, v := range vars {
+		writeCUEComment(&sb, description(v.comment))
+		fieldType := fieldType(v)
+		cueType, ok := cueTypes[fieldType]
+		values, isEnum := enumValues(v)
+		isEnum = isEnum && fieldType == "string" && len(values) > 0
+		if isEnum {
+			cueType = cueEnum(values, "")
+		}
+		if ok {
+			cueType += cueConstraints(variableConstraints(v))
+		}
+		switch {
+		case g.hasDefault(v) && isEnum:
+			sb.WriteString(fmt.Sprintf("\t%s: %s\n", v.key, cueEnum(values, v.value)))
+		case g.hasDefault(v):
+			defaultValue, err := jsonValue(fieldType, v.value)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(fmt.Sprintf("\t%s: %s | *%s\n", v.key, cueType, defaultValue))
+		case isRequired(v) && ok:
+			sb.WriteString(fmt.Sprintf("\t%s: %s\n", v.key, cueType))
+		case isRequired(v):
+			sb.WriteString(fmt.Sprintf("\t%s: _\n", v.key))
+		case ok:
+			sb.WriteString(fmt.Sprintf("\t%s?: %s\n", v.key, cueType))
+		default:
+			sb.WriteString(fmt.Sprintf("\t%s?: string\n", v.key))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// cueEnum returns the CUE disjunction of the given enum values, marking
+// the given default value as such, e.g. '"debug" | *"info"'.
+func cueEnum(values []string, defaultValue string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+		if value == defaultValue {
+			quoted[i] = "*" + quoted[i]
+		}
+	}
+	return strings.Join(quoted, " | ")
+}
+
+// cueConstraints returns the CUE constraints correspondent to the given
+// ones, to be unified with a type, e.g. ' & >=1 & <=65535'.
+func cueConstraints(c constraints) string {
+	var sb strings.Builder
+	if c.min != "" {
+		sb.WriteString(" & >=" + formatBound(c.min))
+	}
+	if c.max != "" {
+		sb.WriteString(" & <=" + formatBound(c.max))
+	}
+	if c.pattern != "" {
+		sb.WriteString(fmt.Sprintf(" & =~%q", c.pattern))
+	}
+	return sb.String()
+}
+
+// writeCUEComment writes the given comment as indented '//' comment lines.
+func writeCUEComment(sb *strings.Builder, comment string) {
+	for _, line := range strings.Split(comment, "\n") {
+		if line != "" {
+			sb.WriteString(fmt.Sprintf("\t// %s\n", line))
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	cueFileName                 = "cue.go"
+	cueUnitTestFileName         = "cue_test.go"
+	cueFileTemplateName         = "cueFile"
+	cueUnitTestFileTemplateName = "cueUnitTestFile"
+	cueFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	_ "embed"
+	"net/url"
+	"reflect"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/pkg/errors"
+)
+
+// configCUE holds the '#Config' definition, which can be extended
+// with further constraints.
+//
+//go:embed config.cue
+var configCUE string
+
+// ValidateWithCUE validates the given configuration against
+// the '#Config' definition declared in 'config.cue'.
+func ValidateWithCUE(config *{{ .StructName }}) error {
+	return validateWithCUE(configCUE, envVarValues({{ if .Immutable }}&config.values{{ else }}config{{ end }}))
+}
+
+// validateWithCUE validates the given values, keyed by env var name,
+// against the '#Config' definition declared in the given CUE source.
+func validateWithCUE(source string, values map[string]interface{}) error {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(source, cue.Filename("config.cue"))
+	if err := schema.Err(); err != nil {
+		return errors.Wrap(err, "compiling config.cue")
+	}
+	definition := schema.LookupPath(cue.ParsePath("#Config"))
+	if err := definition.Err(); err != nil {
+		return errors.Wrap(err, "looking up #Config")
+	}
+	if err := definition.Unify(ctx.Encode(values)).Validate(cue.Concrete(true)); err != nil {
+		return errors.Wrap(err, "validating configuration")
+	}
+	return nil
+}
+
+// envVarValues returns the values of the fields of the given struct
+// pointer, keyed by env var name. Unset optional fields are left out,
+// while URLs are given as strings.
+func envVarValues(config interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	v := reflect.ValueOf(config).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, ok := field.Tag.Lookup("envconfig")
+		if !ok || !field.IsExported() {
+			continue
+		}
+{{- if .EnvPrefix }}
+		name = envPrefix + "_" + name
+{{- end }}
+		value := v.Field(i)
+		if value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				continue
+			}
+			if u, ok := value.Interface().(*url.URL); ok {
+				values[name] = u.String()
+				continue
+			}
+			value = value.Elem()
+		}
+		values[name] = value.Interface()
+	}
+	return values
+}
+`
+
+	cueUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWithCUE(t *testing.T) {
+	const source = ` + "`" + `package {{ .ConfigReaderPkgName }}
+
+#Config: {
+	DB_HOST: string
+	DB_PORT: int & >0 & <65536
+	DEBUG?:  bool
+}
+` + "`" + `
+	testCases := []struct {
+		name          string
+		source        string
+		values        map[string]interface{}
+		expectedError error
+	}{
+		{
+			name:   "happy path",
+			source: source,
+			values: map[string]interface{}{"DB_HOST": "localhost", "DB_PORT": 5432},
+		},
+		{
+			name:          "invalid value",
+			source:        source,
+			values:        map[string]interface{}{"DB_HOST": "localhost", "DB_PORT": 0},
+			expectedError: errors.New("validating configuration: "),
+		},
+		{
+			name:          "missing value",
+			source:        source,
+			values:        map[string]interface{}{"DB_PORT": 5432},
+			expectedError: errors.New("validating configuration: "),
+		},
+		{
+			name:          "unknown value",
+			source:        source,
+			values:        map[string]interface{}{"DB_HOST": "localhost", "DB_PORT": 5432, "UNKNOWN": "value"},
+			expectedError: errors.New("validating configuration: "),
+		},
+		{
+			name:          "invalid source",
+			source:        "#Config: {",
+			expectedError: errors.New("compiling config.cue: "),
+		},
+		{
+			name:          "missing definition",
+			source:        "package {{ .ConfigReaderPkgName }}",
+			expectedError: errors.New("looking up #Config: "),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateWithCUE(tc.source, tc.values)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestEnvVarValues(t *testing.T) {
+	debug := true
+	config := &struct {
+		DbHost   string      ` + "`" + `envconfig:"DB_HOST"` + "`" + `
+		Debug    *bool       ` + "`" + `envconfig:"DEBUG"` + "`" + `
+		Empty    *string     ` + "`" + `envconfig:"EMPTY"` + "`" + `
+		Any      interface{} ` + "`" + `envconfig:"ANY"` + "`" + `
+		Untagged string
+	}{
+		DbHost:   "localhost",
+		Debug:    &debug,
+		Untagged: "untagged",
+	}
+	require.Equal(t, map[string]interface{}{"{{ with .EnvPrefix }}{{ . }}_{{ end }}DB_HOST": "localhost", "{{ with .EnvPrefix }}{{ . }}_{{ end }}DEBUG": true}, envVarValues(config))
+}
+`
+)
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cueDefinitionFile(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+		{key: "DB_PORT", value: "5432"},
+		{key: "RATIO", value: "0.5", comment: "optional"},
+		{key: "API_KEY", comment: "required"},
+		{key: "EMPTY"},
+		{key: "HOSTS", value: "a,b", inferredType: "[]string"},
+		{key: "LABELS", value: "k:v", comment: "type: map[string]string"},
+		{key: "LOG_LEVEL", value: "info", comment: "enum: debug,info"},
+		{key: "WORKERS", value: "4", comment: "min: 1 max: 0x10"},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			expectedOutput: "// Generated by goprojconfig. Further constraints can be added to the\n" +
+				"// fields below, e.g. 'PORT: int & >0 & <65536'.\n\n" +
+				"package config\n\n" +
+				"// #Config holds the env vars read by the config package.\n" +
+				"#Config: {\n" +
+				"\t// database host\n" +
+				"\tDB_HOST: string\n" +
+				"\tDB_PORT: int\n" +
+				"\tRATIO?: number\n" +
+				"\tAPI_KEY: _\n" +
+				"\tEMPTY?: string\n" +
+				"\tHOSTS: [...string]\n" +
+				"\tLABELS: {[string]: string}\n" +
+				"\tLOG_LEVEL: \"debug\" | \"info\"\n" +
+				"\tWORKERS: int & >=1 & <=16\n" +
+				"}\n",
+		},
+		{
+			name: "defaults from values",
+			opts: []Option{WithDefaultsFromValues()},
+			expectedOutput: "// Generated by goprojconfig. Further constraints can be added to the\n" +
+				"// fields below, e.g. 'PORT: int & >0 & <65536'.\n\n" +
+				"package config\n\n" +
+				"// #Config holds the env vars read by the config package.\n" +
+				"#Config: {\n" +
+				"\t// database host\n" +
+				"\tDB_HOST: string | *\"localhost\"\n" +
+				"\tDB_PORT: int | *5432\n" +
+				"\tRATIO: number | *0.5\n" +
+				"\tAPI_KEY: _\n" +
+				"\tEMPTY?: string\n" +
+				"\tHOSTS: [...string] | *[\"a\",\"b\"]\n" +
+				"\tLABELS: {[string]: string} | *{\"k\":\"v\"}\n" +
+				"\tLOG_LEVEL: \"debug\" | *\"info\"\n" +
+				"\tWORKERS: int & >=1 & <=16 | *4\n" +
+				"}\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			output, err := g.cueDefinitionFile(vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	debugFileName                 = "debug.go"
+	debugUnitTestFileName         = "debug_test.go"
+	debugFileTemplateName         = "debugFile"
+	debugUnitTestFileTemplateName = "debugUnitTestFile"
+	debugFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+{{- if .Reload }}
+
+	"github.com/pkg/errors"
+{{- end }}
+)
+
+// redactedValue replaces the values of sensitive env vars.
+const redactedValue = "****"
+
+// sensitiveVars are the env vars whose values are redacted.
+var sensitiveVars = map[string]bool{
+{{- range .SensitiveVars }}
+	{{ printf "%q" . }}: true,
+{{- end }}
+}
+
+// For ease of unit testing.
+var debugConfig = func(ctx context.Context) (*{{ .StructName }}, error) {
+{{- if .Reload }}
+	config := Current()
+	if config == nil {
+		return nil, errors.New("configuration not loaded yet")
+	}
+	return config, nil
+{{- else if .Singleton }}
+	return Get()
+{{- else }}
+	return {{ .ReaderName }}({{ if .Context }}ctx{{ end }})
+{{- end }}
+}
+
+// Handler returns an HTTP handler serving the current configuration as
+// JSON, keyed by env var names, with the values of sensitive env vars
+// redacted, so that operators can inspect it, e.g.:
+//
+//	http.Handle("/debug/config", {{ .ConfigReaderPkgName }}.Handler())
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		config, err := debugConfig(r.Context())
+		if err != nil {
+			// the error isn't served, as it may hold sensitive values.
+			http.Error(w, "reading configuration failed", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(debugValues(config))
+	})
+}
+
+// debugValues returns the values of the given configuration keyed by
+// env var names, with the values of sensitive env vars redacted.
+func debugValues(config *{{ .StructName }}) map[string]interface{} {
+	v := reflect.ValueOf({{ if .Immutable }}config.values{{ else }}*config{{ end }})
+	values := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		key, ok := v.Type().Field(i).Tag.Lookup("envconfig")
+		if !ok {
+			continue
+		}
+{{- if .EnvPrefix }}
+		key = "{{ .EnvPrefix }}_" + key
+{{- end }}
+		if sensitiveVars[key] {
+			values[key] = redactedValue
+			continue
+		}
+		values[key] = debugValue(v.Field(i))
+	}
+	return values
+}
+
+// debugValue returns the given value as it's served: values that
+// can't marshal themselves but are printable, e.g. durations,
+// are served as printed.
+func debugValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	switch value := v.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return value
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return value
+	}
+}
+`
+
+	debugUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	testCases := []struct {
+		name               string
+		method             string
+		mockedDebugConfig  func(ctx context.Context) (*{{ .StructName }}, error)
+		expectedStatusCode int
+	}{
+		{
+			name:   "happy path",
+			method: http.MethodGet,
+			mockedDebugConfig: func(ctx context.Context) (*{{ .StructName }}, error) {
+				return new({{ .StructName }}), nil
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:   "error reading configuration",
+			method: http.MethodGet,
+			mockedDebugConfig: func(ctx context.Context) (*{{ .StructName }}, error) {
+				return nil, errors.New("random error")
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:               "method not allowed",
+			method:             http.MethodPost,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			debugConfig = tc.mockedDebugConfig
+			rec := httptest.NewRecorder()
+			Handler().ServeHTTP(rec, httptest.NewRequest(tc.method, "/debug/config", nil))
+			require.Equal(t, tc.expectedStatusCode, rec.Code)
+			if tc.expectedStatusCode != http.StatusOK {
+				return
+			}
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			var values map[string]interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &values))
+			for key := range sensitiveVars {
+				require.Equal(t, redactedValue, values[key])
+			}
+		})
+	}
+}
+
+func TestDebugValue(t *testing.T) {
+	var nilTime *time.Time
+	testCases := []struct {
+		name           string
+		value          interface{}
+		expectedOutput interface{}
+	}{
+		{name: "string", value: "value", expectedOutput: "value"},
+		{name: "duration", value: time.Second, expectedOutput: "1s"},
+		{name: "nil pointer", value: nilTime, expectedOutput: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, debugValue(reflect.ValueOf(tc.value)))
+		})
+	}
+}
+`
+)
@@ -0,0 +1,76 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Dialect identifies the rules env files are interpreted with,
+// both when generating the config package and by its readers.
+type Dialect string
+
+const (
+	// GodotenvDialect interprets env files the way godotenv does:
+	// quotes are stripped, variable references are expanded and
+	// '#' starts inline comments. It's the default.
+	GodotenvDialect Dialect = "godotenv"
+	// DockerDialect interprets env files the way 'docker run --env-file'
+	// does: values are taken verbatim, up to the end of the line, and
+	// variables without a value, e.g. 'HOST', are taken from the host.
+	DockerDialect Dialect = "docker"
+)
+
+// parseDockerEnvFile parses the variable definitions of a Docker env file
+// read by the given lineReader, the same way parseEnvFile does for the
+// ones godotenv reads. Values are taken verbatim, with quotes, '$' and
+// '#' included, and only lines starting with '#' are comments. Variables
+// without a value, which Docker takes from the host, are given empty
+// values, so that generation doesn't depend on the host env vars.
+func parseDockerEnvFile(lineReader lineReader, values map[string]string) ([]envVar, error) {
+	var vars []envVar
+	var comments []string
+	lineNumber := 0
+	for lineReader.Scan() {
+		lineNumber++
+		line := strings.TrimLeftFunc(lineReader.Text(), unicode.IsSpace)
+		if line == "" {
+			comments = nil
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			comments = append(comments, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.TrimLeftFunc(key, unicode.IsSpace)
+		if key == "" {
+			return nil, &EnvParseError{Line: lineNumber, msg: fmt.Sprintf("line %d: no variable name", lineNumber)}
+		}
+		if strings.ContainsFunc(key, unicode.IsSpace) {
+			return nil, &EnvParseError{Line: lineNumber, msg: fmt.Sprintf("line %d: variable %q contains whitespaces", lineNumber, key)}
+		}
+		values[key] = value
+		vars = append(vars, envVar{key: key, value: value, line: lineNumber, comment: strings.Join(comments, "\n")})
+		comments = nil
+	}
+	if err := lineReader.Err(); err != nil {
+		return nil, errors.Wrap(err, "scanning")
+	}
+	return vars, nil
+}
+
+// envFileValue returns the given value as written in an env file of
+// this dialect. Docker env files take values verbatim.
+func (d Dialect) envFileValue(value string) string {
+	if d == DockerDialect {
+		return value
+	}
+	return envFileValue(value)
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	dockerEnvFileName                 = "dockerenv.go"
+	dockerEnvUnitTestFileName         = "dockerenv_test.go"
+	dockerEnvFileTemplateName         = "dockerEnvFile"
+	dockerEnvUnitTestFileTemplateName = "dockerEnvUnitTestFile"
+	dockerEnvFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// utf8BOM is the byte order mark Docker strips from env files.
+const utf8BOM = "\ufeff"
+
+// maxLineLength is the maximum length, in bytes, of the lines of env
+// files, well above the one of long values like base64 encoded certificates.
+const maxLineLength = {{ .MaxLineLength }}
+
+// parseDockerEnv parses the env file read from the given reader the way
+// 'docker run --env-file' does: values are taken verbatim, up to the end of
+// the line, with quotes, '$' and '#' included, only lines starting with '#'
+// are comments, and variables without a value, e.g. 'HOST', are taken from
+// the env vars, being left out if they're not set.
+func parseDockerEnv(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineLength)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+		if !utf8.ValidString(line) {
+			return nil, errors.Errorf("line %d: invalid utf8 bytes", lineNumber)
+		}
+		line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, hasValue := strings.Cut(line, "=")
+		if key == "" {
+			return nil, errors.Errorf("line %d: no variable name", lineNumber)
+		}
+		if strings.ContainsFunc(key, unicode.IsSpace) {
+			return nil, errors.Errorf("line %d: variable %q contains whitespaces", lineNumber, key)
+		}
+		if !hasValue {
+			envValue, ok := os.LookupEnv(key)
+			if !ok {
+				continue
+			}
+			value = envValue
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			return nil, errors.Errorf("line %d is longer than the max line length of %d bytes", lineNumber+1, maxLineLength)
+		}
+		return nil, errors.Wrap(err, "scanning")
+	}
+	return values, nil
+}
+
+// loadDockerEnvFiles sets the env vars defined in the given Docker env
+// files, defaulting to '.env', the same way godotenv.Load does for the
+// ones it reads: env vars that are already set are left untouched.
+func loadDockerEnvFiles(filenames ...string) error {
+	return setDockerEnvFiles(filenames, false)
+}
+{{- if .Reload }}
+
+// overloadDockerEnvFiles sets the env vars defined in the given Docker
+// env files, defaulting to '.env', the same way godotenv.Overload does
+// for the ones it reads: env vars that are already set are overridden.
+func overloadDockerEnvFiles(filenames ...string) error {
+	return setDockerEnvFiles(filenames, true)
+}
+{{- end }}
+
+// setDockerEnvFiles sets the env vars defined in the given Docker env
+// files, overriding the ones that are already set if override is true.
+func setDockerEnvFiles(filenames []string, override bool) error {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+	for _, filename := range filenames {
+		envFile, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		values, err := parseDockerEnv(envFile)
+		envFile.Close()
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s", filename)
+		}
+		for key, value := range values {
+			if _, ok := os.LookupEnv(key); ok && !override {
+				continue
+			}
+			if err := os.Setenv(key, value); err != nil {
+				return errors.Wrapf(err, "setting %s", key)
+			}
+		}
+	}
+	return nil
+}
+`
+
+	dockerEnvUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDockerEnv(t *testing.T) {
+	t.Setenv("DOCKER_ENV_TEST_HOST", "from-host")
+	testCases := []struct {
+		name           string
+		envFile        string
+		expectedValues map[string]string
+		expectedError  error
+	}{
+		{
+			name:           "values taken verbatim",
+			envFile:        "QUOTED=\"a b\"\nSINGLE='c'\nREF=${HOME}\nHASH=a # b\n",
+			expectedValues: map[string]string{"QUOTED": "\"a b\"", "SINGLE": "'c'", "REF": "${HOME}", "HASH": "a # b"},
+		},
+		{
+			name:           "comments, blank lines and leading whitespaces",
+			envFile:        "\ufeff# comment\n\n   PORT=8080\n\t# indented comment\n",
+			expectedValues: map[string]string{"PORT": "8080"},
+		},
+		{
+			name:           "variables without value",
+			envFile:        "DOCKER_ENV_TEST_HOST\nDOCKER_ENV_TEST_UNSET\nEMPTY=\n",
+			expectedValues: map[string]string{"DOCKER_ENV_TEST_HOST": "from-host", "EMPTY": ""},
+		},
+		{
+			name:          "no variable name",
+			envFile:       "=value\n",
+			expectedError: errors.New("line 1: no variable name"),
+		},
+		{
+			name:          "variable with whitespaces",
+			envFile:       "PORT=8080\nMY VAR=value\n",
+			expectedError: errors.New("line 2: variable \"MY VAR\" contains whitespaces"),
+		},
+		{
+			name:          "invalid utf8",
+			envFile:       "NAME=\xff\n",
+			expectedError: errors.New("line 1: invalid utf8 bytes"),
+		},
+		{
+			name:           "long line",
+			envFile:        "TLS_CERT=" + strings.Repeat("A", maxLineLength/2) + "\n",
+			expectedValues: map[string]string{"TLS_CERT": strings.Repeat("A", maxLineLength/2)},
+		},
+		{
+			name:          "line too long",
+			envFile:       "PORT=8080\nTLS_CERT=" + strings.Repeat("A", maxLineLength) + "\n",
+			expectedError: fmt.Errorf("line 2 is longer than the max line length of %d bytes", maxLineLength),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := parseDockerEnv(strings.NewReader(tc.envFile))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedValues, values)
+			}
+		})
+	}
+}
+
+func TestLoadDockerEnvFiles(t *testing.T) {
+	envFilePath := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFilePath, []byte("DOCKER_ENV_TEST_SET=\"from file\"\nDOCKER_ENV_TEST_NEW=\"from file\"\n"), 0644))
+	t.Setenv("DOCKER_ENV_TEST_SET", "from env")
+	t.Cleanup(func() {
+		os.Unsetenv("DOCKER_ENV_TEST_NEW")
+	})
+	require.NoError(t, loadDockerEnvFiles(envFilePath))
+	require.Equal(t, "from env", os.Getenv("DOCKER_ENV_TEST_SET"))
+	require.Equal(t, "\"from file\"", os.Getenv("DOCKER_ENV_TEST_NEW"))
+{{- if .Reload }}
+	require.NoError(t, overloadDockerEnvFiles(envFilePath))
+	require.Equal(t, "\"from file\"", os.Getenv("DOCKER_ENV_TEST_SET"))
+{{- end }}
+	err := loadDockerEnvFiles(filepath.Join(t.TempDir(), ".env"))
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+}
+`
+)
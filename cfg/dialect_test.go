@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseDockerEnvFile(t *testing.T) {
+	testCases := []struct {
+		name           string
+		lines          []string
+		scanErr        error
+		expectedOutput []envVar
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			lines: []string{
+				"# the database host.",
+				"DB_HOST=localhost",
+				"",
+				"  DB_PASSWORD=\"p@ss word\" # not a comment",
+				"DB_URL=postgres://${DB_HOST}/app",
+				"HOSTNAME",
+				"EMPTY=",
+			},
+			expectedOutput: []envVar{
+				{key: "DB_HOST", value: "localhost", line: 2, comment: "the database host."},
+				{key: "DB_PASSWORD", value: "\"p@ss word\" # not a comment", line: 4},
+				{key: "DB_URL", value: "postgres://${DB_HOST}/app", line: 5},
+				{key: "HOSTNAME", value: "", line: 6},
+				{key: "EMPTY", value: "", line: 7},
+			},
+		},
+		{
+			name:          "no variable name",
+			lines:         []string{"DB_HOST=localhost", "=value"},
+			expectedError: errors.New("line 2: no variable name"),
+		},
+		{
+			name:          "variable with whitespaces",
+			lines:         []string{"DB HOST=localhost"},
+			expectedError: errors.New(`line 1: variable "DB HOST" contains whitespaces`),
+		},
+		{
+			name:          "scan error",
+			lines:         []string{"DB_HOST=localhost"},
+			scanErr:       errors.New("scan error"),
+			expectedError: errors.New("scanning: scan error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mlr := &mockLineReader{lines: tc.lines, err: tc.scanErr}
+			output, err := parseDockerEnvFile(mlr, make(map[string]string))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestGenerateWithDockerDialect(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{".env": []byte("GREETING=\"hello world\"\nPORT=8080\n")})
+	g := NewGenerator("appcfg", WithFileSystem(fsys), WithDialect(DockerDialect), WithDefaultsFromValues(), WithEmbeddedEnvFile())
+	_, err := g.GenerateConfigPackageFromEnvFile(".env")
+	require.NoError(t, err)
+	files := fsys.Files()
+	configFile := string(files["appcfg/config.go"])
+	require.Contains(t, configFile, "Greeting string `envconfig:\"GREETING\" default:\"\\\"hello world\\\"\"`")
+	require.Contains(t, configFile, "godotenvLoad     = loadDockerEnvFiles")
+	require.NotContains(t, configFile, "github.com/joho/godotenv")
+	require.NotContains(t, string(files["appcfg/config_test.go"]), "github.com/joho/godotenv")
+	require.Contains(t, string(files["appcfg/defaults.env"]), "GREETING=\"hello world\"\n")
+	require.Contains(t, files, "appcfg/dockerenv.go")
+	require.Contains(t, files, "appcfg/dockerenv_test.go")
+}
+
+func TestGenerateWithUnsupportedDialect(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{".env": []byte("PORT=8080\n")})
+	g := NewGenerator("appcfg", WithFileSystem(fsys), WithDialect("compose"))
+	_, err := g.GenerateConfigPackageFromEnvFile(".env")
+	require.EqualError(t, err, "unsupported dialect compose")
+}
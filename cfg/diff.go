@@ -0,0 +1,106 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"github.com/pkg/errors"
+)
+
+// structField is a field of a parsed struct, keyed by its env var.
+type structField struct {
+	key         string
+	typ         string
+	declaration string
+}
+
+// DiffConfig compares the 'Config' struct declared in the given Go file,
+// e.g. 'appcfg/config.go', with the one that would be generated from the
+// given env files with the given options. It returns a line for every
+// field that would be removed ('- '), changed ('~ ') or added ('+ ')
+// by regenerating the package, in this order.
+func DiffConfig(configFilePath string, envFilePaths []string, opts ...Option) ([]string, error) {
+	if len(envFilePaths) == 0 {
+		return nil, errors.New("no env files provided")
+	}
+	g := NewGenerator("config", opts...).(*generator)
+	configStruct, _, err := g.readConfigStruct(configFilePath, 0)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := g.readEnvFiles(envFilePaths, nil)
+	if err != nil {
+		return nil, err
+	}
+	generatedStruct, err := g.parseGeneratedStruct(vars)
+	if err != nil {
+		return nil, err
+	}
+	existing := structFields(configStruct)
+	generated := structFields(generatedStruct)
+	generatedIndex := make(map[string]structField, len(generated))
+	for _, f := range generated {
+		generatedIndex[f.key] = f
+	}
+	existingIndex := make(map[string]structField, len(existing))
+	var lines []string
+	for _, f := range existing {
+		existingIndex[f.key] = f
+		switch generatedField, ok := generatedIndex[f.key]; {
+		case !ok:
+			lines = append(lines, "- "+f.declaration)
+		case generatedField.declaration != f.declaration:
+			lines = append(lines, fmt.Sprintf("~ %s -> %s", f.declaration, generatedField.declaration))
+		}
+	}
+	for _, f := range generated {
+		if _, ok := existingIndex[f.key]; !ok {
+			lines = append(lines, "+ "+f.declaration)
+		}
+	}
+	return lines, nil
+}
+
+// parseGeneratedStruct parses the 'Config' struct generated for the given
+// variables, returning the declaration of the struct holding their fields.
+func (g *generator) parseGeneratedStruct(vars []envVar) (*ast.StructType, error) {
+	configStruct, err := g.generateStruct(vars)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package config\n"+configStruct, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing generated struct")
+	}
+	return findConfigStruct(file, g.structName), nil
+}
+
+// structFields returns the exported fields of the given struct
+// that are read from env vars.
+func structFields(structType *ast.StructType) []structField {
+	var fields []structField
+	for _, field := range structType.Fields.List {
+		tag := fieldTag(field)
+		if tag.Get("ignored") == "true" {
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			fields = append(fields, structField{
+				key:         fieldEnvKey(name.Name, tag, ""),
+				typ:         types.ExprString(field.Type),
+				declaration: fmt.Sprintf("%s %s `%s`", name.Name, types.ExprString(field.Type), tag),
+			})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfig(t *testing.T) {
+	const configFile = `package appcfg
+
+type Config struct {
+	DbHost   string ` + "`envconfig:\"DB_HOST\" required:\"true\"`" + `
+	Port     string ` + "`envconfig:\"PORT\" required:\"true\"`" + `
+	Removed  string ` + "`envconfig:\"REMOVED\" required:\"true\"`" + `
+	Internal string ` + "`ignored:\"true\"`" + `
+}
+`
+	testCases := []struct {
+		name           string
+		envFilePaths   []string
+		opts           []Option
+		mockClosure    func(mfs *mockFileSystem, mlr *mockLineReader)
+		expectedOutput []string
+		expectedError  error
+	}{
+		{
+			name:         "happy path",
+			envFilePaths: []string{".env"},
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.file = []byte(configFile)
+				mfs.openedFile = new(mockFile)
+				mlr.lines = []string{"DB_HOST=localhost", "PORT=8080", "DEBUG=true"}
+			},
+			expectedOutput: []string{
+				"~ Port string `envconfig:\"PORT\" required:\"true\"` -> Port int `envconfig:\"PORT\" required:\"true\"`",
+				"- Removed string `envconfig:\"REMOVED\" required:\"true\"`",
+				"+ Debug bool `envconfig:\"DEBUG\" required:\"true\"`",
+			},
+		},
+		{
+			name:         "no changes",
+			envFilePaths: []string{".env"},
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.file = []byte(configFile)
+				mfs.openedFile = new(mockFile)
+				mlr.lines = []string{"DB_HOST=localhost", "PORT=http", "REMOVED=yes"}
+			},
+		},
+		{
+			name:          "no env files",
+			mockClosure:   func(mfs *mockFileSystem, mlr *mockLineReader) {},
+			expectedError: errors.New("no env files provided"),
+		},
+		{
+			name:         "error reading config file",
+			envFilePaths: []string{".env"},
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.readFileErr = errors.New("read error")
+			},
+			expectedError: errors.New("reading file appcfg/config.go: read error"),
+		},
+		{
+			name:         "error when opening env file",
+			envFilePaths: []string{".env"},
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.file = []byte(configFile)
+				mfs.openErr = errors.New("open error")
+			},
+			expectedError: errors.New("opening env file .env: open error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			mlr := new(mockLineReader)
+			tc.mockClosure(mfs, mlr)
+			opts := append([]Option{WithFileSystem(mfs), withLineReader(func(_ io.Reader) lineReader {
+				return mlr
+			})}, tc.opts...)
+			output, err := DiffConfig("appcfg/config.go", tc.envFilePaths, opts...)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// preferredEnvFileNames are the names of the env files config packages
+// are preferably generated from when a dir has several ones, sample env
+// files first, since they're meant to be committed.
+var preferredEnvFileNames = []string{ExampleEnvFileName, ".env.sample", ".env.dist", envFileName}
+
+// skippedDirNames are the names of the dirs that are not walked
+// looking for env files and modules.
+var skippedDirNames = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+	"testdata":     true,
+}
+
+// GenerationPlan describes a config package to be generated
+// in a dir where an env file was discovered.
+type GenerationPlan struct {
+	// Dir is the dir the package is generated from, i.e. the one
+	// holding the env file, relative to the walked root.
+	Dir string
+	// EnvFile is the name of the env file, in Dir.
+	EnvFile string
+	// PackageDir is the dir of the generated package, relative
+	// to the walked root.
+	PackageDir string
+	// ImportPath is the import path of the generated package.
+	ImportPath string
+}
+
+// DiscoverGenerationPlans walks the given file system, e.g. 'os.DirFS(".")'
+// for a repository, looking for env files and for the go.mod files of its
+// modules, returning a plan for generating a package with the given name
+// next to each env file that belongs to a module, sorted by dir. When a
+// dir has several env files, sample ones like '.env.example' are preferred,
+// then '.env'. Hidden dirs, as well as 'vendor', 'node_modules' and
+// 'testdata' dirs, are skipped.
+func DiscoverGenerationPlans(fsys fs.FS, packageName string) ([]GenerationPlan, error) {
+	if err := ValidatePackageName(packageName); err != nil {
+		return nil, err
+	}
+	modules := make(map[string]string)
+	envFiles := make(map[string][]string)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(name, ".") || skippedDirNames[name]) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		dir := path.Dir(p)
+		switch {
+		case name == "go.mod":
+			gomod, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return errors.Wrapf(err, "reading file %s", p)
+			}
+			if modulePath := modulePath(gomod); modulePath != "" {
+				modules[dir] = modulePath
+			}
+		case isEnvFileName(name):
+			envFiles[dir] = append(envFiles[dir], name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walking dirs")
+	}
+	var plans []GenerationPlan
+	for dir, names := range envFiles {
+		modulePath, rel, ok := enclosingModule(modules, dir)
+		if !ok {
+			continue
+		}
+		plans = append(plans, GenerationPlan{
+			Dir:        dir,
+			EnvFile:    preferredEnvFile(names),
+			PackageDir: path.Join(dir, packageName),
+			ImportPath: path.Join(modulePath, rel, packageName),
+		})
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].Dir < plans[j].Dir
+	})
+	return plans, nil
+}
+
+// isEnvFileName reports whether the given file name is the one of
+// an env file, e.g. '.env', '.env.example' or '.env-local', but not
+// '.envrc', which is a direnv file.
+func isEnvFileName(name string) bool {
+	return name == envFileName ||
+		strings.HasPrefix(name, envFileName+".") ||
+		strings.HasPrefix(name, envFileName+"-")
+}
+
+// enclosingModule returns the path of the module, among the given ones
+// keyed by their dirs, whose dir is the given dir or its closest parent,
+// along with the path of the given dir relative to the module dir.
+func enclosingModule(modules map[string]string, dir string) (string, string, bool) {
+	for moduleDir := dir; ; moduleDir = path.Dir(moduleDir) {
+		if modulePath, ok := modules[moduleDir]; ok {
+			rel := strings.TrimPrefix(strings.TrimPrefix(dir, moduleDir), "/")
+			if moduleDir == "." {
+				rel = dir
+			}
+			return modulePath, rel, true
+		}
+		if moduleDir == "." {
+			return "", "", false
+		}
+	}
+}
+
+// preferredEnvFile returns the env file, among the given names,
+// a package is preferably generated from.
+func preferredEnvFile(names []string) string {
+	for _, preferred := range preferredEnvFileNames {
+		for _, name := range names {
+			if name == preferred {
+				return name
+			}
+		}
+	}
+	sort.Strings(names)
+	return names[0]
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverGenerationPlans(t *testing.T) {
+	testCases := []struct {
+		name           string
+		fsys           fstest.MapFS
+		packageName    string
+		expectedOutput []GenerationPlan
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			fsys: fstest.MapFS{
+				"go.mod":                        {Data: []byte("module example.com/repo\n")},
+				".env":                          {Data: []byte("PORT=8080\n")},
+				"services/api/.env":             {Data: []byte("PORT=8080\n")},
+				"services/api/.env.example":     {Data: []byte("PORT=8080\n")},
+				"services/api/.envrc":           {Data: []byte("dotenv\n")},
+				"tools/go.mod":                  {Data: []byte("module example.com/tools\n")},
+				"tools/cli/.env-local":          {Data: []byte("DEBUG=true\n")},
+				"vendor/example.com/lib/.env":   {Data: []byte("PORT=8080\n")},
+				".github/workflows/.env":        {Data: []byte("PORT=8080\n")},
+				"services/api/testdata/.env":    {Data: []byte("PORT=8080\n")},
+				"services/worker/main.go":       {Data: []byte("package main\n")},
+				"services/worker/.envrc":        {Data: []byte("dotenv\n")},
+				"web/node_modules/pkg/.env":     {Data: []byte("PORT=8080\n")},
+				"services/api/config/config.go": {Data: []byte("package config\n")},
+			},
+			packageName: "config",
+			expectedOutput: []GenerationPlan{
+				{Dir: ".", EnvFile: ".env", PackageDir: "config", ImportPath: "example.com/repo/config"},
+				{Dir: "services/api", EnvFile: ".env.example", PackageDir: "services/api/config", ImportPath: "example.com/repo/services/api/config"},
+				{Dir: "tools/cli", EnvFile: ".env-local", PackageDir: "tools/cli/config", ImportPath: "example.com/tools/cli/config"},
+			},
+		},
+		{
+			name: "env files outside modules",
+			fsys: fstest.MapFS{
+				".env":          {Data: []byte("PORT=8080\n")},
+				"app/go.mod":    {Data: []byte("module example.com/app\n")},
+				"other/.env":    {Data: []byte("PORT=8080\n")},
+				"app/.env.test": {Data: []byte("PORT=8080\n")},
+			},
+			packageName: "appcfg",
+			expectedOutput: []GenerationPlan{
+				{Dir: "app", EnvFile: ".env.test", PackageDir: "app/appcfg", ImportPath: "example.com/app/appcfg"},
+			},
+		},
+		{
+			name:          "invalid package name",
+			fsys:          fstest.MapFS{},
+			packageName:   "my-config",
+			expectedError: errors.New(`invalid package name "my-config": it must be a Go identifier other than a keyword or '_'`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := DiscoverGenerationPlans(tc.fsys, tc.packageName)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
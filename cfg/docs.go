@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DocsFormat is the format of the documentation returned by Docs.
+type DocsFormat string
+
+const (
+	// MarkdownDocsFormat documents the variables in a Markdown table.
+	// It's the default.
+	MarkdownDocsFormat DocsFormat = "markdown"
+	// JSONDocsFormat documents the variables in a JSON catalog, to be
+	// consumed by tools, e.g. developer portals aggregating the
+	// configuration of services.
+	JSONDocsFormat DocsFormat = "json"
+)
+
+// docsEntry documents a variable in the JSON catalog.
+type docsEntry struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Required    bool            `json:"required"`
+	Default     json.RawMessage `json:"default,omitempty"`
+	Description string          `json:"description"`
+	Sensitive   bool            `json:"sensitive"`
+}
+
+// markdownCellEscaper escapes the characters that would break
+// a Markdown table cell.
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// Docs returns a Markdown table documenting the variables defined in the
+// given env files, as read by the package generated with the given options:
+// their types, whether they're required, their defaults and their
+// descriptions, taken from their comments. With WithDocsFormat(JSONDocsFormat),
+// it returns a JSON catalog of them instead, which also tells whether they
+// hold sensitive values.
+func Docs(envFilePaths []string, opts ...Option) (string, error) {
+	if len(envFilePaths) == 0 {
+		return "", errors.New("no env files provided")
+	}
+	g := NewGenerator("config", opts...).(*generator)
+	if g.docsFormat != MarkdownDocsFormat && g.docsFormat != JSONDocsFormat {
+		return "", errors.Errorf("unsupported docs format %s", g.docsFormat)
+	}
+	vars, err := g.readEnvFiles(envFilePaths, nil)
+	if err != nil {
+		return "", err
+	}
+	if g.docsFormat == JSONDocsFormat {
+		return g.jsonDocs(vars)
+	}
+	return g.markdownDocs(vars), nil
+}
+
+// markdownDocs returns a Markdown table documenting the given variables.
+func (g *generator) markdownDocs(vars []envVar) string {
+	var sb strings.Builder
+	sb.WriteString("| Variable | Type | Required | Default | Description |\n")
+	sb.WriteString("|----------|------|----------|---------|-------------|\n")
+	for _, v := range vars {
+		fieldType := fieldType(v)
+		if fieldType == "" {
+			fieldType = "string"
+		}
+		required, defaultValue := "no", ""
+		switch {
+		case g.hasDefault(v):
+			defaultValue = fmt.Sprintf("`%s`", v.value)
+		case isRequired(v):
+			required = "yes"
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | `%s` | %s | %s | %s |\n",
+			v.key, fieldType, required, markdownCellEscaper.Replace(defaultValue), markdownCellEscaper.Replace(description(v.comment))))
+	}
+	return sb.String()
+}
+
+// jsonDocs returns a JSON catalog documenting the given variables.
+func (g *generator) jsonDocs(vars []envVar) (string, error) {
+	entries := make([]docsEntry, 0, len(vars))
+	for _, v := range vars {
+		fieldType := fieldType(v)
+		if fieldType == "" {
+			fieldType = "string"
+		}
+		entry := docsEntry{
+			Name:        v.key,
+			Type:        fieldType,
+			Description: description(v.comment),
+			Sensitive:   isSensitive(v),
+		}
+		switch {
+		case g.hasDefault(v):
+			defaultValue, err := jsonValue(fieldType, v.value)
+			if err != nil {
+				return "", errors.Wrapf(err, "documenting %s", v.key)
+			}
+			entry.Default = defaultValue
+		case isRequired(v):
+			entry.Required = true
+		}
+		entries = append(entries, entry)
+	}
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content) + "\n", nil
+}
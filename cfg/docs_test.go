@@ -0,0 +1,205 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_markdownDocs(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+		{key: "PORT", value: "8080", comment: "http port"},
+		{key: "FILTER", value: "a|b", comment: "first line\nsecond line"},
+		{key: "EMPTY"},
+		{key: "MAX_CONNECTIONS", comment: "type: int\nrequired"},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			expectedOutput: "| Variable | Type | Required | Default | Description |\n" +
+				"|----------|------|----------|---------|-------------|\n" +
+				"| `DB_HOST` | `string` | yes |  | database host |\n" +
+				"| `PORT` | `int` | yes |  | http port |\n" +
+				"| `FILTER` | `string` | yes |  | first line second line |\n" +
+				"| `EMPTY` | `string` | no |  |  |\n" +
+				"| `MAX_CONNECTIONS` | `int` | yes |  |  |\n",
+		},
+		{
+			name: "defaults from values",
+			opts: []Option{WithDefaultsFromValues()},
+			expectedOutput: "| Variable | Type | Required | Default | Description |\n" +
+				"|----------|------|----------|---------|-------------|\n" +
+				"| `DB_HOST` | `string` | no | `localhost` | database host |\n" +
+				"| `PORT` | `int` | no | `8080` | http port |\n" +
+				"| `FILTER` | `string` | no | `a\\|b` | first line second line |\n" +
+				"| `EMPTY` | `string` | no |  |  |\n" +
+				"| `MAX_CONNECTIONS` | `int` | yes |  |  |\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			require.Equal(t, tc.expectedOutput, g.markdownDocs(vars))
+		})
+	}
+}
+
+func Test_jsonDocs(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_PASSWORD", value: "secret", comment: "database password\nrequired"},
+		{key: "PORT", value: "8080", comment: "http port"},
+		{key: "HOSTS", value: "a,b", inferredType: "[]string"},
+		{key: "EMPTY"},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			expectedOutput: `[
+  {
+    "name": "DB_PASSWORD",
+    "type": "string",
+    "required": true,
+    "description": "database password",
+    "sensitive": true
+  },
+  {
+    "name": "PORT",
+    "type": "int",
+    "required": true,
+    "description": "http port",
+    "sensitive": false
+  },
+  {
+    "name": "HOSTS",
+    "type": "[]string",
+    "required": true,
+    "description": "",
+    "sensitive": false
+  },
+  {
+    "name": "EMPTY",
+    "type": "string",
+    "required": false,
+    "description": "",
+    "sensitive": false
+  }
+]
+`,
+		},
+		{
+			name: "defaults from values",
+			opts: []Option{WithDefaultsFromValues()},
+			expectedOutput: `[
+  {
+    "name": "DB_PASSWORD",
+    "type": "string",
+    "required": false,
+    "default": "secret",
+    "description": "database password",
+    "sensitive": true
+  },
+  {
+    "name": "PORT",
+    "type": "int",
+    "required": false,
+    "default": 8080,
+    "description": "http port",
+    "sensitive": false
+  },
+  {
+    "name": "HOSTS",
+    "type": "[]string",
+    "required": false,
+    "default": [
+      "a",
+      "b"
+    ],
+    "description": "",
+    "sensitive": false
+  },
+  {
+    "name": "EMPTY",
+    "type": "string",
+    "required": false,
+    "description": "",
+    "sensitive": false
+  }
+]
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			output, err := g.jsonDocs(vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+func TestDocs(t *testing.T) {
+	testCases := []struct {
+		name           string
+		envFilePaths   []string
+		opts           []Option
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:         "markdown",
+			envFilePaths: []string{".env"},
+			expectedOutput: "| Variable | Type | Required | Default | Description |\n" +
+				"|----------|------|----------|---------|-------------|\n" +
+				"| `PORT` | `int` | yes |  | http port |\n",
+		},
+		{
+			name:         "json",
+			envFilePaths: []string{".env"},
+			opts:         []Option{WithDocsFormat(JSONDocsFormat)},
+			expectedOutput: "[\n  {\n    \"name\": \"PORT\",\n    \"type\": \"int\",\n    \"required\": true,\n" +
+				"    \"description\": \"http port\",\n    \"sensitive\": false\n  }\n]\n",
+		},
+		{
+			name:          "no env files",
+			expectedError: errors.New("no env files provided"),
+		},
+		{
+			name:          "unsupported format",
+			envFilePaths:  []string{".env"},
+			opts:          []Option{WithDocsFormat("yaml")},
+			expectedError: errors.New("unsupported docs format yaml"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte("PORT=8080 # http port\n")})
+			output, err := Docs(tc.envFilePaths, append(tc.opts, WithFileSystem(fsys))...)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
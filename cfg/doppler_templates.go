@@ -0,0 +1,310 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	dopplerFileName                 = "doppler.go"
+	dopplerUnitTestFileName         = "doppler_test.go"
+	dopplerFileTemplateName         = "dopplerFile"
+	dopplerUnitTestFileTemplateName = "dopplerUnitTestFile"
+	dopplerFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Env vars used to connect to Doppler. DOPPLER_TOKEN is used to
+// authenticate, while DOPPLER_PROJECT and DOPPLER_CONFIG select the
+// config to fetch secrets from. They can be left unset with service
+// tokens, which are scoped to a config.
+const (
+	dopplerTokenEnvVar   = "DOPPLER_TOKEN"
+	dopplerProjectEnvVar = "DOPPLER_PROJECT"
+	dopplerConfigEnvVar  = "DOPPLER_CONFIG"
+)
+
+// dopplerClient abstracts the Doppler API client.
+type dopplerClient interface {
+	// secrets returns the secrets of the given config of the given
+	// project, keyed by name.
+	secrets(ctx context.Context, project, config string) (map[string]string, error)
+}
+
+// For ease of unit testing.
+var (
+	dopplerAPIURL    = "https://api.doppler.com"
+	newDopplerClient = func(token string) dopplerClient {
+		return &dopplerAPIClient{token: token, httpClient: http.DefaultClient}
+	}
+)
+
+func init() {
+	secretLoaders = append(secretLoaders, loadDopplerSecrets)
+}
+
+// loadDopplerSecrets sets env vars to the values of the secrets of the
+// Doppler config, when DOPPLER_TOKEN is set. Env vars that are already
+// set take precedence, so that secrets can be overridden locally.
+func loadDopplerSecrets(ctx context.Context) error {
+	token, _ := osLookupEnv(dopplerTokenEnvVar)
+	if token == "" {
+		return nil
+	}
+	project, _ := osLookupEnv(dopplerProjectEnvVar)
+	config, _ := osLookupEnv(dopplerConfigEnvVar)
+	secrets, err := newDopplerClient(token).secrets(ctx, project, config)
+	if err != nil {
+		return errors.Wrap(err, "fetching secrets from Doppler")
+	}
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := osLookupEnv(key); ok {
+			continue
+		}
+		if err := osSetenv(key, secrets[key]); err != nil {
+			return errors.Wrapf(err, "setting %s", key)
+		}
+	}
+	return nil
+}
+
+// dopplerAPIClient fetches secrets through Doppler's HTTP API.
+type dopplerAPIClient struct {
+	token      string
+	httpClient *http.Client
+}
+
+func (c *dopplerAPIClient) secrets(ctx context.Context, project, config string) (map[string]string, error) {
+	query := url.Values{"format": {"json"}}
+	if project != "" {
+		query.Set("project", project)
+	}
+	if config != "" {
+		query.Set("config", config)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dopplerAPIURL+"/v3/configs/config/secrets/download?"+query.Encode(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+	var secrets map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&secrets); err != nil {
+		return nil, errors.Wrap(err, "decoding response")
+	}
+	return secrets, nil
+}
+`
+
+	dopplerUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockDopplerClient struct {
+	project string
+	config  string
+	values  map[string]string
+	err     error
+}
+
+func (m *mockDopplerClient) secrets(ctx context.Context, project, config string) (map[string]string, error) {
+	m.project, m.config = project, config
+	return m.values, m.err
+}
+
+func TestLoadDopplerSecrets(t *testing.T) {
+	testCases := []struct {
+		name            string
+		env             map[string]string
+		client          *mockDopplerClient
+		mockedOsSetenv  func(key, value string) error
+		expectedProject string
+		expectedConfig  string
+		expectedEnviron map[string]string
+		expectedError   error
+	}{
+		{
+			name: "happy path",
+			env:  map[string]string{"DOPPLER_TOKEN": "token", "DOPPLER_PROJECT": "app", "DOPPLER_CONFIG": "prd", "DB_HOST": "localhost"},
+			client: &mockDopplerClient{
+				values: map[string]string{"DB_HOST": "db.internal", "DB_PASSWORD": "s3cr3t"},
+			},
+			expectedProject: "app",
+			expectedConfig:  "prd",
+			expectedEnviron: map[string]string{"DB_PASSWORD": "s3cr3t"},
+		},
+		{
+			name: "service token",
+			env:  map[string]string{"DOPPLER_TOKEN": "token"},
+			client: &mockDopplerClient{
+				values: map[string]string{"DB_PASSWORD": "s3cr3t"},
+			},
+			expectedEnviron: map[string]string{"DB_PASSWORD": "s3cr3t"},
+		},
+		{
+			name:            "doppler not configured",
+			client:          &mockDopplerClient{values: map[string]string{"DB_PASSWORD": "s3cr3t"}},
+			expectedEnviron: map[string]string{},
+		},
+		{
+			name:          "error fetching secrets",
+			env:           map[string]string{"DOPPLER_TOKEN": "token"},
+			client:        &mockDopplerClient{err: errors.New("random error")},
+			expectedError: errors.New("fetching secrets from Doppler: random error"),
+		},
+		{
+			name: "error setting env var",
+			env:  map[string]string{"DOPPLER_TOKEN": "token"},
+			client: &mockDopplerClient{
+				values: map[string]string{"DB_PASSWORD": "s3cr3t"},
+			},
+			mockedOsSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("setting DB_PASSWORD: random error"),
+		},
+	}
+	lookupEnv, setenv, newClient := osLookupEnv, osSetenv, newDopplerClient
+	t.Cleanup(func() {
+		osLookupEnv, osSetenv, newDopplerClient = lookupEnv, setenv, newClient
+	})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			osLookupEnv = func(key string) (string, bool) {
+				value, ok := tc.env[key]
+				return value, ok
+			}
+			environ := make(map[string]string)
+			osSetenv = func(key, value string) error {
+				if tc.mockedOsSetenv != nil {
+					return tc.mockedOsSetenv(key, value)
+				}
+				environ[key] = value
+				return nil
+			}
+			newDopplerClient = func(token string) dopplerClient {
+				require.Equal(t, "token", token)
+				return tc.client
+			}
+			err := loadDopplerSecrets(context.Background())
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedEnviron, environ)
+				require.Equal(t, tc.expectedProject, tc.client.project)
+				require.Equal(t, tc.expectedConfig, tc.client.config)
+			}
+		})
+	}
+}
+
+func TestDopplerAPIClient(t *testing.T) {
+	dopplerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/configs/config/secrets/download" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("format") != "json" || r.URL.Query().Get("config") != "prd" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("project") {
+		case "app":
+			w.Write([]byte("{\"DB_PASSWORD\": \"s3cr3t\"}"))
+		case "invalid":
+			w.Write([]byte("invalid"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer dopplerServer.Close()
+	apiURL := dopplerAPIURL
+	t.Cleanup(func() {
+		dopplerAPIURL = apiURL
+	})
+	dopplerAPIURL = dopplerServer.URL
+	testCases := []struct {
+		name           string
+		token          string
+		project        string
+		expectedOutput map[string]string
+		expectedError  error
+	}{
+		{
+			name:           "happy path",
+			token:          "token",
+			project:        "app",
+			expectedOutput: map[string]string{"DB_PASSWORD": "s3cr3t"},
+		},
+		{
+			name:          "invalid token",
+			token:         "invalid",
+			project:       "app",
+			expectedError: errors.New("unexpected status 401 Unauthorized"),
+		},
+		{
+			name:          "unknown project",
+			token:         "token",
+			project:       "unknown",
+			expectedError: errors.New("unexpected status 404 Not Found"),
+		},
+		{
+			name:          "invalid response",
+			token:         "token",
+			project:       "invalid",
+			expectedError: errors.New("decoding response: invalid character 'i' looking for beginning of value"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newDopplerClient(tc.token)
+			output, err := client.secrets(context.Background(), tc.project, "prd")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+`
+)
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Drift describes an env var that isn't both read by the 'Config' struct
+// and defined in every env file.
+type Drift struct {
+	// Key is the name of the env var.
+	Key string
+	// InConfig reports whether a field of the struct reads the env var.
+	InConfig bool
+	// InEnvFiles reports, for each env file, in the given order,
+	// whether the env var is defined there.
+	InEnvFiles []bool
+}
+
+// FindDrift compares the env vars read by the 'Config' struct declared in
+// the given Go file, e.g. 'appcfg/config.go', with the ones defined in each
+// of the given env files, e.g. '.env' and '.env.example', returning the env
+// vars that are missing from any of them, sorted by name, so that mismatches
+// are caught before they show up at runtime. Env vars without the prefix of
+// packages generated with one are left out, since they're not read. Options
+// other than WithFileSystem, which the files are read from, WithStructName,
+// WithInclude and WithExclude are ignored.
+func FindDrift(configFilePath string, envFilePaths []string, opts ...Option) ([]Drift, error) {
+	if len(envFilePaths) == 0 {
+		return nil, errors.New("no env files provided")
+	}
+	g := NewGenerator("config", opts...).(*generator)
+	configStruct, prefix, err := g.readConfigStruct(configFilePath, 0)
+	if err != nil {
+		return nil, err
+	}
+	drifts := make(map[string]*Drift)
+	driftOf := func(key string) *Drift {
+		if _, ok := drifts[key]; !ok {
+			drifts[key] = &Drift{Key: key, InEnvFiles: make([]bool, len(envFilePaths))}
+		}
+		return drifts[key]
+	}
+	keyPrefix := ""
+	if prefix != "" {
+		keyPrefix = strings.ToUpper(prefix) + "_"
+	}
+	for _, f := range structFields(configStruct) {
+		driftOf(keyPrefix + f.key).InConfig = true
+	}
+	for i, envFilePath := range envFilePaths {
+		vars, err := g.readEnvFile(envFilePath, make(map[string]string), nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vars {
+			if !strings.HasPrefix(v.key, keyPrefix) {
+				continue
+			}
+			driftOf(v.key).InEnvFiles[i] = true
+		}
+	}
+	var found []Drift
+	for _, d := range drifts {
+		if d.InConfig && !containsFalse(d.InEnvFiles) {
+			continue
+		}
+		found = append(found, *d)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Key < found[j].Key
+	})
+	return found, nil
+}
+
+// containsFalse reports whether any of the given values is false.
+func containsFalse(values []bool) bool {
+	for _, v := range values {
+		if !v {
+			return true
+		}
+	}
+	return false
+}
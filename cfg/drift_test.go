@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDrift(t *testing.T) {
+	configFile := "package appcfg\n\n" +
+		"type Config struct {\n" +
+		"\tPort    int    `envconfig:\"PORT\" required:\"true\"`\n" +
+		"\tHost    string `envconfig:\"HOST\"`\n" +
+		"\tTimeout string `envconfig:\"TIMEOUT\"`\n" +
+		"\tDebug   bool   `ignored:\"true\"`\n" +
+		"}\n"
+	testCases := []struct {
+		name           string
+		files          map[string][]byte
+		envFiles       []string
+		expectedOutput []Drift
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(configFile),
+				".env":             []byte("PORT=8080\nHOST=localhost\nTIMEOUT=5s\nLEGACY_MODE=true\n"),
+				".env.example":     []byte("# the port.\nPORT=\nTIMEOUT=\n"),
+			},
+			envFiles: []string{".env", ".env.example"},
+			expectedOutput: []Drift{
+				{Key: "HOST", InConfig: true, InEnvFiles: []bool{true, false}},
+				{Key: "LEGACY_MODE", InConfig: false, InEnvFiles: []bool{true, false}},
+			},
+		},
+		{
+			name: "happy path, no drift",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(configFile),
+				".env":             []byte("PORT=8080\nHOST=localhost\nTIMEOUT=5s\n"),
+			},
+			envFiles: []string{".env"},
+		},
+		{
+			name: "happy path, prefixed package",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte("package appcfg\n\nconst envPrefix = \"APP\"\n\n" +
+					"type Config struct {\n\tPort int `envconfig:\"PORT\"`\n\tHost string `envconfig:\"HOST\"`\n}\n"),
+				".env": []byte("APP_PORT=8080\nAPP_NAME=app\nPATH=/usr/bin\n"),
+			},
+			envFiles: []string{".env"},
+			expectedOutput: []Drift{
+				{Key: "APP_HOST", InConfig: true, InEnvFiles: []bool{false}},
+				{Key: "APP_NAME", InConfig: false, InEnvFiles: []bool{true}},
+			},
+		},
+		{
+			name:          "no env files",
+			expectedError: errors.New("no env files provided"),
+		},
+		{
+			name:          "error reading config file",
+			envFiles:      []string{".env"},
+			expectedError: errors.New("reading file appcfg/config.go: open appcfg/config.go: file does not exist"),
+		},
+		{
+			name:          "error reading env file",
+			files:         map[string][]byte{"appcfg/config.go": []byte(configFile)},
+			envFiles:      []string{".env"},
+			expectedError: errors.New("opening env file .env: open .env: file does not exist"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := FindDrift("appcfg/config.go", tc.envFiles, WithFileSystem(NewMemFileSystem(tc.files)))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
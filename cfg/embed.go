@@ -0,0 +1,29 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const embeddedEnvFileName = "defaults.env"
+
+// embeddedEnvFile returns the env file embedded in the generated package,
+// holding the default values of the given variables. Variables without
+// values are left out, so that required ones must still be set, as well
+// as sensitive ones, so that secrets don't get baked into binaries.
+func (g *generator) embeddedEnvFile(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated by goprojconfig. Embedded in the package as the defaults\n")
+	sb.WriteString("# of ReadEmbedded, sensitive variables are left out.\n")
+	for _, v := range vars {
+		if v.value == "" || isSensitive(v) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s=%s\n", v.key, g.dialect.envFileValue(v.value)))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	embedFileName                 = "embed.go"
+	embedUnitTestFileName         = "embed_test.go"
+	embedFileTemplateName         = "embedFile"
+	embedUnitTestFileTemplateName = "embedUnitTestFile"
+	embedFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	_ "embed"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// embeddedEnvFile is the env file embedded in the binary,
+// holding the default values of the env vars.
+//
+//go:embed ` + embeddedEnvFileName + `
+var embeddedEnvFile string
+
+// {{ .ReaderName }}Embedded reads configuration from environment variables, falling
+// back to the values of the env file embedded in the binary for the ones
+// that are not set, so that it needs no env file at runtime.
+func {{ .ReaderName }}Embedded({{ if .Context }}ctx context.Context{{ end }}) ({{ if .Tracing }}_ *{{ .StructName }}, err error{{ else }}*{{ .StructName }}, error{{ end }}) {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "{{ .ReaderName }}Embedded")
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
+	config, err := {{ .ReaderName }}FromReader({{ if .Context }}ctx, {{ end }}strings.NewReader(embeddedEnvFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading embedded env file")
+	}
+	return config, nil
+}
+`
+
+	embedUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"errors"
+	"testing"
+{{ if not .DockerDialect }}
+	"github.com/joho/godotenv"
+{{- end }}
+	"github.com/stretchr/testify/require"
+)
+
+func Test{{ .ReaderName }}Embedded(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		embeddedEnvFile        string
+		env                    map[string]string
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name:            "happy path",
+			embeddedEnvFile: "DB_HOST=localhost\nDB_PORT=5432\n",
+			env:             map[string]string{"DB_PORT": "6543"},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "localhost", "DB_PORT": "6543"},
+		},
+		{
+			name:            "error processing env vars",
+			embeddedEnvFile: "DB_HOST=localhost\n",
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("reading embedded env file: processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := make(map[string]string)
+			for key, value := range tc.env {
+				env[key] = value
+			}
+			embeddedEnvFile = tc.embeddedEnvFile
+			godotenvParse = {{ if .DockerDialect }}parseDockerEnv{{ else }}godotenv.Parse{{ end }}
+			lookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			setenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}Embedded({{ if .Context }}context.Background(){{ end }})
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+`
+)
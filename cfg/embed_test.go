@@ -0,0 +1,30 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_embeddedEnvFile(t *testing.T) {
+	g := NewGenerator("config", WithEmbeddedEnvFile()).(*generator)
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host"},
+		{key: "DB_PASSWORD", value: "secret"},
+		{key: "API_KEY", value: "dev", comment: "not sensitive"},
+		{key: "GREETING", value: `say "hi" to $USER`},
+		{key: "EMPTY"},
+	}
+	expectedOutput := "# Generated by goprojconfig. Embedded in the package as the defaults\n" +
+		"# of ReadEmbedded, sensitive variables are left out.\n" +
+		"DB_HOST=localhost\n" +
+		"API_KEY=dev\n" +
+		"GREETING=\"say \\\"hi\\\" to \\$USER\"\n"
+	output, err := g.embeddedEnvFile(vars)
+	require.NoError(t, err)
+	require.Equal(t, expectedOutput, output)
+}
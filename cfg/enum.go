@@ -0,0 +1,74 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// enumValues returns the values allowed for the given variable with
+// an '# enum' annotation, e.g. '# enum: debug,info,warn,error', if any.
+func enumValues(v envVar) ([]string, bool) {
+	annotation, ok := annotationValue(v.comment, "enum")
+	if !ok {
+		return nil, false
+	}
+	var values []string
+	for _, value := range strings.Split(annotation, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values, true
+}
+
+// enumConstantSuffix returns the suffix of the name of the constant
+// declared for the given enum value, named with the given field name
+// function as if the value were an env var, e.g. 'Debug' for 'debug'.
+// It's empty when the value has no letters or digits.
+func enumConstantSuffix(value string, fieldName func(envKey string) string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, value)
+	return fieldName(key)
+}
+
+// enumTypes declares the types of the fields generated for the given
+// variables with an '# enum' annotation. Each one is a string named
+// after its field, with a constant for each of its values, and is an
+// envconfig decoder rejecting any other value.
+func (g *generator) enumTypes(vars []envVar) string {
+	var sb strings.Builder
+	for _, v := range vars {
+		values, ok := enumValues(v)
+		if !ok || fieldType(v) != "string" {
+			continue
+		}
+		typeName := g.fieldName(v.key)
+		constants := make([]string, len(values))
+		for i, value := range values {
+			constants[i] = typeName + enumConstantSuffix(value, g.fieldName)
+		}
+		fmt.Fprintf(&sb, "\n// %s is the value of %s, one of: %s.\n", typeName, v.key, strings.Join(values, ", "))
+		fmt.Fprintf(&sb, "type %s string\n", typeName)
+		fmt.Fprintf(&sb, "\n// Values of %s.\nconst (\n", typeName)
+		for i, value := range values {
+			fmt.Fprintf(&sb, "\t%s %s = %q\n", constants[i], typeName, value)
+		}
+		sb.WriteString(")\n")
+		fmt.Fprintf(&sb, "\n// Decode sets the given value, which must be one of the values of %s.\n", typeName)
+		fmt.Fprintf(&sb, "func (e *%s) Decode(value string) error {\n", typeName)
+		fmt.Fprintf(&sb, "\tswitch %s(value) {\n", typeName)
+		fmt.Fprintf(&sb, "\tcase %s:\n", strings.Join(constants, ", "))
+		fmt.Fprintf(&sb, "\t\t*e = %s(value)\n\t\treturn nil\n\t}\n", typeName)
+		fmt.Fprintf(&sb, "\treturn fmt.Errorf(\"invalid value %%q, must be one of: %s\", value)\n}\n", strings.Join(values, ", "))
+	}
+	return sb.String()
+}
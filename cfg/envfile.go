@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExampleEnvFileName is the name of the env file generated
+// by GenerateEnvFileFromConfig.
+const ExampleEnvFileName = ".env.example"
+
+// GenerateEnvFileFromConfig generates an env file at the given path, e.g.
+// '.env.example', from the 'Config' struct declared in the given Go file,
+// e.g. 'appcfg/config.go'. It's the reverse of the config package generation,
+// for projects that have a Config struct but no sample env file: every env
+// var read by the struct is listed, preceded by the field comments and by
+// '# type: <type>' and '# required' or '# optional' annotations, and with
+// its default value, if any, as its value, so that the config package can
+// be generated back from it. Existing env files are not overwritten.
+// Options other than WithFileSystem, which the files are read from and
+// written to, and WithStructName are ignored.
+func GenerateEnvFileFromConfig(configFilePath, envFilePath string, opts ...Option) error {
+	g := NewGenerator("config", opts...).(*generator)
+	existingFile, err := g.fs.Open(envFilePath)
+	if err == nil {
+		existingFile.Close()
+		return &sentinelError{sentinel: ErrFileExists, err: errors.Errorf("%s already exists", envFilePath)}
+	}
+	if !g.fs.IsNotExist(err) {
+		return errors.Wrapf(err, "opening file %s", envFilePath)
+	}
+	configStruct, prefix, err := g.readConfigStruct(configFilePath, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	return writeArtifact(g.fs, envFilePath, envFileFromStruct(configStruct, prefix))
+}
+
+// envFileFromStruct returns the content of an env file listing
+// the env vars read by the given struct with the given prefix.
+func envFileFromStruct(configStruct *ast.StructType, prefix string) string {
+	var sb strings.Builder
+	for _, field := range configStruct.Fields.List {
+		tag := fieldTag(field)
+		if tag.Get("ignored") == "true" {
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			for _, comment := range []*ast.CommentGroup{field.Doc, field.Comment} {
+				writeComment(&sb, "", fieldComment(comment))
+			}
+			if fieldType := types.ExprString(field.Type); annotatedTypes[fieldType] {
+				fmt.Fprintf(&sb, "# type: %s\n", fieldType)
+			}
+			if tag.Get("required") == "true" {
+				sb.WriteString("# required\n")
+			} else {
+				sb.WriteString("# optional\n")
+			}
+			fmt.Fprintf(&sb, "%s=%s\n", fieldEnvKey(name.Name, tag, prefix), envFileValue(tag.Get("default")))
+		}
+	}
+	return sb.String()
+}
+
+// fieldComment returns the text of the given field comment, leaving out
+// the TODO comment that the generated struct has above its first field.
+func fieldComment(comment *ast.CommentGroup) string {
+	return strings.TrimSpace(strings.Replace(comment.Text(), structTagsTODO, "", 1))
+}
+
+// envFileValue returns the given value as written in an env file,
+// double quoted when it has characters that would be taken otherwise,
+// escaped so that both parseEnvFile and godotenv read it back unchanged.
+func envFileValue(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n#\"'$\\") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", `\r`, "\n", `\n`, "$", `\$`).Replace(value) + `"`
+}
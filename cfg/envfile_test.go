@@ -0,0 +1,142 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateEnvFileFromConfig(t *testing.T) {
+	const configFile = `package appcfg
+
+import "time"
+
+// Config holds all configuration needed by this app.
+type Config struct {
+	// TODO: see https://github.com/kelseyhightower/envconfig for all available options
+	// for struct tags.
+	DbHost   string        ` + "`envconfig:\"DB_HOST\" required:\"true\"`" + `
+	// http port
+	Port     int           ` + "`envconfig:\"PORT\" default:\"8080\"`" + ` // must be free
+	Greeting string        ` + "`envconfig:\"GREETING\" default:\"say \\\"hi\\\"\"`" + `
+	Timeout  *time.Duration
+	Internal string        ` + "`ignored:\"true\"`" + `
+	private  string
+}
+`
+	testCases := []struct {
+		name           string
+		mockClosure    func(mfs *mockFileSystem)
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte(configFile)
+				mfs.createdFile = new(mockFile)
+				mfs.openErr = errors.New("not found")
+				mfs.isNotExistOutput = true
+			},
+			expectedOutput: `# type: string
+# required
+DB_HOST=
+
+# http port
+# must be free
+# type: int
+# optional
+PORT=8080
+
+# type: string
+# optional
+GREETING="say \"hi\""
+
+# optional
+TIMEOUT=
+`,
+		},
+		{
+			name: "error reading config file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.readFileErr = errors.New("read error")
+				mfs.openErr = errors.New("not found")
+				mfs.isNotExistOutput = true
+			},
+			expectedError: errors.New("reading file appcfg/config.go: read error"),
+		},
+		{
+			name: "error writing env file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte(configFile)
+				mfs.createdFile = &mockFile{writeStringErr: errors.New("write error")}
+				mfs.openErr = errors.New("not found")
+				mfs.isNotExistOutput = true
+			},
+			expectedError: errors.New("writing file .env.example: write error"),
+		},
+		{
+			name: "env file already exists",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.openedFile = new(mockFile)
+			},
+			expectedError: errors.New(".env.example already exists"),
+		},
+		{
+			name: "error opening env file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.openErr = errors.New("open error")
+			},
+			expectedError: errors.New("opening file .env.example: open error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			tc.mockClosure(mfs)
+			err := GenerateEnvFileFromConfig("appcfg/config.go", ExampleEnvFileName, WithFileSystem(mfs))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, mfs.createdFile.written)
+			}
+		})
+	}
+}
+
+func TestGenerateEnvFileFromConfigRoundTrip(t *testing.T) {
+	const configFile = `package appcfg
+
+// Config holds all configuration needed by this app.
+type Config struct {
+	DbHost   string   ` + "`envconfig:\"DB_HOST\" required:\"true\"`" + `
+	Port     int      ` + "`envconfig:\"PORT\" default:\"8080\"`" + `
+	Debug    bool     ` + "`envconfig:\"DEBUG\"`" + `
+	Hosts    []string ` + "`envconfig:\"HOSTS\" required:\"true\"`" + `
+	Greeting string   ` + "`envconfig:\"GREETING\" default:\"say \\\"hi\\\" to $USER\"`" + `
+	WinPath  string   ` + "`envconfig:\"WIN_PATH\" default:\"C:\\\\dir\\\\x\"`" + `
+	Nickname *string  ` + "`envconfig:\"NICKNAME\"`" + `
+}
+`
+	fsys := NewMemFileSystem(map[string][]byte{"appcfg/config.go": []byte(configFile)})
+	require.NoError(t, GenerateEnvFileFromConfig("appcfg/config.go", ExampleEnvFileName, WithFileSystem(fsys)))
+	g := NewGenerator("regen", WithFileSystem(fsys), WithDefaultsFromValues(), WithoutTests(), WithoutGoGenerate())
+	_, err := g.GenerateConfigPackageFromEnvFile(ExampleEnvFileName)
+	require.NoError(t, err)
+	original, _, err := g.(*generator).readConfigStruct("appcfg/config.go", 0)
+	require.NoError(t, err)
+	regenerated, _, err := g.(*generator).readConfigStruct("regen/config.go", 0)
+	require.NoError(t, err)
+	require.Equal(t, envFileFromStruct(original, ""), envFileFromStruct(regenerated, ""))
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"regexp"
+	"strings"
+)
+
+// envrcFileName is the name of the files direnv loads, which
+// are parsed as such even without WithEnvrc.
+const envrcFileName = ".envrc"
+
+// envrcAssignment matches the shell assignments of '.envrc' files,
+// e.g. 'export PORT=8080' or 'PORT=8080'.
+var envrcAssignment = regexp.MustCompile(`^(export\s+)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// envrcExport matches the shell statements exporting variables that
+// were already assigned, e.g. 'export PORT HOST'.
+var envrcExport = regexp.MustCompile(`^export((\s+[A-Za-z_][A-Za-z0-9_]*)+)$`)
+
+// shellParamExpansion matches the parameter expansions of shell values,
+// e.g. '${VAR}' or '${VAR:-default}'.
+var shellParamExpansion = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// parseEnvrc parses the variable definitions of a direnv '.envrc' file,
+// which is a shell script, read by the given lineReader, the same way
+// parseEnvFile does for env files. Exported assignments, e.g. 'export
+// PORT=8080', define variables, while plain ones define shell variables,
+// which values may refer to, unless they're exported later on. The
+// values of the ones that can't be evaluated without running the shell,
+// like command substitutions, are left empty, and other statements, like
+// 'dotenv', 'PATH_add' or 'if' blocks, are skipped.
+func parseEnvrc(lineReader lineReader, values map[string]string) ([]envVar, error) {
+	r := &envrcLineReader{lineReader: lineReader, unexported: make(map[string]bool)}
+	vars, err := parseEnvFile(r, values)
+	if err != nil {
+		return nil, err
+	}
+	exported := vars[:0]
+	for _, v := range vars {
+		if !r.unexported[v.key] {
+			exported = append(exported, v)
+		}
+	}
+	return exported, nil
+}
+
+// envrcLineReader is a lineReader turning the lines of an '.envrc' file
+// into env file ones: the assignments, without 'export', and the comments.
+// Other lines are read as blank lines, so that line numbers are kept.
+type envrcLineReader struct {
+	lineReader
+	line string
+	// quote is the quote of the multiline quoted value being read, if any.
+	quote byte
+	// skippingQuotedValue tells whether that value is left out.
+	skippingQuotedValue bool
+	// unexported holds the names of the shell variables that
+	// are assigned but not exported.
+	unexported map[string]bool
+}
+
+// Scan reads the next line, turning it into an env file one.
+func (r *envrcLineReader) Scan() bool {
+	if !r.lineReader.Scan() {
+		return false
+	}
+	text := r.lineReader.Text()
+	if r.quote != 0 {
+		r.line = text
+		if r.skippingQuotedValue {
+			r.line = ""
+		}
+		// Escapes don't span lines, so only this line can close the value.
+		if quoteIndex(text, r.quote) >= 0 {
+			r.quote = 0
+		}
+		return true
+	}
+	line := strings.TrimSpace(text)
+	r.line = ""
+	if line == "" || strings.HasPrefix(line, "#") {
+		r.line = line
+		return true
+	}
+	if m := envrcExport.FindStringSubmatch(line); m != nil {
+		for _, name := range strings.Fields(m[1]) {
+			r.unexported[name] = false
+		}
+		return true
+	}
+	m := envrcAssignment.FindStringSubmatch(line)
+	if m == nil {
+		return true
+	}
+	key, rawValue := m[2], strings.TrimSpace(m[3])
+	if _, ok := r.unexported[key]; !ok || m[1] != "" {
+		r.unexported[key] = m[1] == ""
+	}
+	if isUnterminatedQuotedValue(rawValue) {
+		r.quote = rawValue[0]
+		r.skippingQuotedValue = !isEvaluableShellValue(rawValue)
+	}
+	if !isEvaluableShellValue(rawValue) {
+		r.line = key + "="
+		return true
+	}
+	r.line = key + "=" + rawValue
+	return true
+}
+
+// Text returns the line read, turned into an env file one.
+func (r *envrcLineReader) Text() string {
+	return r.line
+}
+
+// isEvaluableShellValue reports whether the given raw shell value can be
+// evaluated without running the shell: it's single quoted, or it holds no
+// command substitutions and no parameter expansions other than variable
+// references, like '${VAR}' and '$VAR'.
+func isEvaluableShellValue(rawValue string) bool {
+	if strings.HasPrefix(rawValue, "'") {
+		return true
+	}
+	if strings.Contains(rawValue, "$(") || strings.Contains(rawValue, "`") {
+		return false
+	}
+	for _, m := range shellParamExpansion.FindAllStringSubmatch(rawValue, -1) {
+		if !isVarName(m[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isVarName reports whether the given name is a valid shell variable name.
+func isVarName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isVarNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
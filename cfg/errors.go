@@ -0,0 +1,58 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import "github.com/pkg/errors"
+
+// Errors returned by the generator, which can be told apart with errors.Is,
+// since the returned errors wrap them with further context.
+var (
+	// ErrInvalidPackageName is returned when the package
+	// name is not a valid Go package name.
+	ErrInvalidPackageName = errors.New("invalid package name")
+	// ErrEnvFileNotFound is returned when an env file doesn't exist.
+	ErrEnvFileNotFound = errors.New("env file not found")
+	// ErrEnvParse is returned when an env file can't be parsed or
+	// defines invalid variables. The returned error is an *EnvParseError.
+	ErrEnvParse = errors.New("invalid env file")
+	// ErrFileExists is returned when a file that is not
+	// meant to be overwritten already exists.
+	ErrFileExists = errors.New("file already exists")
+)
+
+// EnvParseError is returned when an env file can't be parsed or defines
+// invalid variables, e.g. duplicate ones. It matches ErrEnvParse.
+type EnvParseError struct {
+	// Line is the line number of the first problem found.
+	Line int
+	msg  string
+}
+
+func (e *EnvParseError) Error() string {
+	return e.msg
+}
+
+func (e *EnvParseError) Is(target error) bool {
+	return target == ErrEnvParse
+}
+
+// sentinelError is an error that matches the given sentinel,
+// while keeping the message of the underlying error.
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+func (e *sentinelError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
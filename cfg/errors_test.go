@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mockClosure   func(mfs *mockFileSystem, mlr *mockLineReader)
+		generate      func(opts ...Option) error
+		expectedError error
+		expectedLine  int
+	}{
+		{
+			name:        "invalid package name",
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {},
+			generate: func(opts ...Option) error {
+				_, err := NewGenerator("", opts...).GenerateConfigPackage()
+				return err
+			},
+			expectedError: ErrInvalidPackageName,
+		},
+		{
+			name: "env file not found",
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.openErr = errors.New("no such file or directory")
+				mfs.isNotExistOutput = true
+			},
+			generate: func(opts ...Option) error {
+				_, err := NewGenerator("config", opts...).GenerateConfigPackageFromEnvFile(".env")
+				return err
+			},
+			expectedError: ErrEnvFileNotFound,
+		},
+		{
+			name: "unterminated quoted value",
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.openedFile = new(mockFile)
+				mlr.lines = []string{"PORT=8080", `KEY="value`}
+			},
+			generate: func(opts ...Option) error {
+				_, err := NewGenerator("config", opts...).GenerateConfigPackageFromEnvFile(".env")
+				return err
+			},
+			expectedError: ErrEnvParse,
+			expectedLine:  2,
+		},
+		{
+			name: "duplicate variable",
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.openedFile = new(mockFile)
+				mlr.lines = []string{"PORT=8080", "HOST=localhost", "PORT=8081"}
+			},
+			generate: func(opts ...Option) error {
+				_, err := NewGenerator("config", opts...).GenerateConfigPackageFromEnvFile(".env")
+				return err
+			},
+			expectedError: ErrEnvParse,
+			expectedLine:  3,
+		},
+		{
+			name: "env file exists",
+			mockClosure: func(mfs *mockFileSystem, mlr *mockLineReader) {
+				mfs.openedFile = new(mockFile)
+			},
+			generate: func(opts ...Option) error {
+				return GenerateEnvFileFromConfig("config/config.go", ExampleEnvFileName, opts...)
+			},
+			expectedError: ErrFileExists,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			mlr := new(mockLineReader)
+			tc.mockClosure(mfs, mlr)
+			err := tc.generate(WithFileSystem(mfs), withLineReader(func(_ io.Reader) lineReader {
+				return mlr
+			}))
+			require.ErrorIs(t, err, tc.expectedError)
+			if tc.expectedLine > 0 {
+				var parseErr *EnvParseError
+				require.ErrorAs(t, err, &parseErr)
+				require.Equal(t, tc.expectedLine, parseErr.Line)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import "strings"
+
+// featurePrefix is the prefix of the env vars of feature flags.
+const featurePrefix = "FEATURE_"
+
+// feature describes a feature flag of the generated package.
+type feature struct {
+	// Name is the name of the feature, used in its accessor,
+	// e.g. 'NewCheckout' for FEATURE_NEW_CHECKOUT.
+	Name string
+	// ID identifies the feature in IsEnabled, e.g. 'new-checkout'.
+	ID string
+	// EnvVar is the env var toggling the feature.
+	EnvVar string
+	// Field is the Config field holding the feature state.
+	Field string
+}
+
+// featureFlags returns the feature flags among the given variables:
+// the bool ones whose names, without prefix, start with FEATURE_.
+func (g *generator) featureFlags(vars []envVar) []feature {
+	var features []feature
+	for _, v := range vars {
+		key := g.unprefixedKey(v.key)
+		if !strings.HasPrefix(key, featurePrefix) || key == featurePrefix || fieldType(v) != "bool" {
+			continue
+		}
+		name := strings.TrimPrefix(key, featurePrefix)
+		features = append(features, feature{
+			Name:   g.fieldNamer(name),
+			ID:     strings.ToLower(strings.ReplaceAll(name, "_", "-")),
+			EnvVar: v.key,
+			Field:  g.fieldName(v.key),
+		})
+	}
+	return features
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	featuresFileName                 = "features.go"
+	featuresUnitTestFileName         = "features_test.go"
+	featuresFileTemplateName         = "featuresFile"
+	featuresUnitTestFileTemplateName = "featuresUnitTestFile"
+	featuresFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import "sync/atomic"
+
+// Feature identifies a feature flag, toggled by a FEATURE_* env var.
+type Feature string
+
+const (
+{{- range .Features }}
+	// Feature{{ .Name }} is toggled by {{ .EnvVar }}.
+	Feature{{ .Name }} Feature = "{{ .ID }}"
+{{- end }}
+)
+
+// Features holds the state of the feature flags, read from the FEATURE_*
+// fields of a {{ .StructName }}. It's safe for concurrent use.
+type Features struct {
+	enabled atomic.Pointer[map[Feature]bool]
+}
+
+// NewFeatures returns the feature flags of the given configuration.
+func NewFeatures(config *{{ .StructName }}) *Features {
+	f := new(Features)
+	f.Refresh(config)
+	return f
+}
+
+// Refresh updates the feature flags from the given configuration.
+{{- if .Reload }}
+// It can be given as the onChange function of Watch, so that feature
+// flags are refreshed whenever configuration is reloaded.
+{{- end }}
+func (f *Features) Refresh(config *{{ .StructName }}) {
+	enabled := map[Feature]bool{
+{{- range .Features }}
+		Feature{{ .Name }}: config.{{ if $.Immutable }}values.{{ end }}{{ .Field }},
+{{- end }}
+	}
+	f.enabled.Store(&enabled)
+}
+
+// IsEnabled reports whether the given feature is enabled.
+// Unknown features are disabled.
+func (f *Features) IsEnabled(feature Feature) bool {
+	return (*f.enabled.Load())[feature]
+}
+{{- range .Features }}
+
+// {{ .Name }}Enabled reports whether the {{ .ID }} feature is enabled.
+func (f *Features) {{ .Name }}Enabled() bool {
+	return f.IsEnabled(Feature{{ .Name }})
+}
+{{- end }}
+`
+
+	featuresUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatures(t *testing.T) {
+	config := new({{ .StructName }})
+{{- range .Features }}
+	config.{{ if $.Immutable }}values.{{ end }}{{ .Field }} = true
+{{- end }}
+	features := NewFeatures(config)
+{{- range .Features }}
+	require.True(t, features.IsEnabled(Feature{{ .Name }}))
+	require.True(t, features.{{ .Name }}Enabled())
+{{- end }}
+	require.False(t, features.IsEnabled("unknown"))
+	features.Refresh(new({{ .StructName }}))
+{{- range .Features }}
+	require.False(t, features.IsEnabled(Feature{{ .Name }}))
+	require.False(t, features.{{ .Name }}Enabled())
+{{- end }}
+}
+`
+)
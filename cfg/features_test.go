@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_featureFlags(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           []Option
+		vars           []envVar
+		expectedOutput []feature
+	}{
+		{
+			name: "happy path",
+			vars: []envVar{
+				{key: "FEATURE_NEW_CHECKOUT", value: "true"},
+				{key: "FEATURE_DARK_MODE", value: "", comment: "type: bool"},
+				{key: "FEATURE_LIMIT", value: "10"},
+				{key: "FEATURE_", value: "true"},
+				{key: "DEBUG", value: "false"},
+			},
+			expectedOutput: []feature{
+				{Name: "NewCheckout", ID: "new-checkout", EnvVar: "FEATURE_NEW_CHECKOUT", Field: "FeatureNewCheckout"},
+				{Name: "DarkMode", ID: "dark-mode", EnvVar: "FEATURE_DARK_MODE", Field: "FeatureDarkMode"},
+			},
+		},
+		{
+			name: "with prefix",
+			opts: []Option{WithPrefix("APP_")},
+			vars: []envVar{
+				{key: "APP_FEATURE_NEW_CHECKOUT", value: "false"},
+			},
+			expectedOutput: []feature{
+				{Name: "NewCheckout", ID: "new-checkout", EnvVar: "APP_FEATURE_NEW_CHECKOUT", Field: "FeatureNewCheckout"},
+			},
+		},
+		{
+			name: "no feature flags",
+			vars: []envVar{
+				{key: "PORT", value: "8080"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			require.Equal(t, tc.expectedOutput, g.featureFlags(tc.vars))
+		})
+	}
+}
+
+func TestGenerateFeatures(t *testing.T) {
+	testCases := []struct {
+		name          string
+		envFile       string
+		expectedError error
+	}{
+		{
+			name:    "happy path",
+			envFile: "PORT=8080\nFEATURE_NEW_CHECKOUT=true\n",
+		},
+		{
+			name:          "no feature flags",
+			envFile:       "PORT=8080\n",
+			expectedError: errors.New("feature flags require bool FEATURE_* variables"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte(tc.envFile)})
+			g := NewGenerator("appcfg", WithFileSystem(fsys), WithFeatures())
+			_, err := g.GenerateConfigPackageFromEnvFile(".env")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf(`expected no error, got "%v"`, err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf(`expected error "%v", got nil`, tc.expectedError)
+				}
+				require.Contains(t, string(fsys.Files()["appcfg/features.go"]), "func (f *Features) NewCheckoutEnabled() bool {")
+			}
+		})
+	}
+}
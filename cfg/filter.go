@@ -0,0 +1,53 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+// filterEnvVars returns the given variables that have the prefix of this
+// generator, if any, and that match any of its include patterns, or all
+// of them if there are none, and none of its exclude patterns.
+func (g *generator) filterEnvVars(vars []envVar) ([]envVar, error) {
+	if len(g.includes) == 0 && len(g.excludes) == 0 && g.prefix == "" {
+		return vars, nil
+	}
+	var filtered []envVar
+	for _, v := range vars {
+		if !g.hasPrefix(v.key) {
+			continue
+		}
+		included, err := matchesAny(g.includes, v.key)
+		if err != nil {
+			return nil, err
+		}
+		excluded, err := matchesAny(g.excludes, v.key)
+		if err != nil {
+			return nil, err
+		}
+		if (len(g.includes) == 0 || included) && !excluded {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered, nil
+}
+
+// matchesAny reports whether the given env var name matches any of
+// the given glob patterns, e.g. 'DB_*'.
+func matchesAny(patterns []string, key string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
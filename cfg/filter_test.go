@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_filterEnvVars(t *testing.T) {
+	testCases := []struct {
+		name          string
+		opts          []Option
+		expectedKeys  []string
+		expectedError error
+	}{
+		{
+			name:         "no filters",
+			expectedKeys: []string{"DB_HOST", "DB_PORT", "DB_NAME_DEPRECATED", "HTTP_PORT"},
+		},
+		{
+			name:         "include",
+			opts:         []Option{WithInclude("DB_*")},
+			expectedKeys: []string{"DB_HOST", "DB_PORT", "DB_NAME_DEPRECATED"},
+		},
+		{
+			name:         "exclude",
+			opts:         []Option{WithExclude("*_DEPRECATED")},
+			expectedKeys: []string{"DB_HOST", "DB_PORT", "HTTP_PORT"},
+		},
+		{
+			name:         "include and exclude",
+			opts:         []Option{WithInclude("DB_*", "HTTP_*"), WithExclude("*_DEPRECATED", "*_PORT")},
+			expectedKeys: []string{"DB_HOST"},
+		},
+		{
+			name:         "prefix",
+			opts:         []Option{WithPrefix("DB_"), WithExclude("*_DEPRECATED")},
+			expectedKeys: []string{"DB_HOST", "DB_PORT"},
+		},
+		{
+			name:          "invalid include pattern",
+			opts:          []Option{WithInclude("DB_[")},
+			expectedError: errors.New(`invalid pattern "DB_[": syntax error in pattern`),
+		},
+		{
+			name:          "invalid exclude pattern",
+			opts:          []Option{WithExclude("DB_[")},
+			expectedError: errors.New(`invalid pattern "DB_[": syntax error in pattern`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			vars := []envVar{{key: "DB_HOST"}, {key: "DB_PORT"}, {key: "DB_NAME_DEPRECATED"}, {key: "HTTP_PORT"}}
+			output, err := g.filterEnvVars(vars)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				var keys []string
+				for _, v := range output {
+					keys = append(keys, v.key)
+				}
+				require.Equal(t, tc.expectedKeys, keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import "strings"
+
+// FlagsLibrary identifies a command-line flags library the generated
+// package can register a flag per configuration field with.
+type FlagsLibrary string
+
+const (
+	// Cobra registers the flags on a github.com/spf13/cobra command.
+	Cobra FlagsLibrary = "cobra"
+)
+
+// flagsLibraryFiles maps each supported flags library
+// to the files generated for it.
+var flagsLibraryFiles = map[FlagsLibrary][]optionalFile{
+	Cobra: {
+		{cobraFlagsFileName, cobraFlagsFileTemplateName, cobraFlagsFileTemplate},
+		{cobraFlagsUnitTestFileName, cobraFlagsUnitTestFileTemplateName, cobraFlagsUnitTestFileTemplate},
+	},
+}
+
+// configFlag describes the command-line flag overriding an env var.
+type configFlag struct {
+	// Name is the flag name, e.g. 'db-host'.
+	Name string
+	// EnvVar is the env var the flag overrides, e.g. 'DB_HOST'.
+	EnvVar string
+	// Type is the type of the flag values shown in the usage.
+	Type string
+	// Usage is the flag usage, built from the env var comment.
+	Usage string
+}
+
+// configFlags returns the flags overriding the given variables.
+func (g *generator) configFlags(vars []envVar) []configFlag {
+	var flags []configFlag
+	for _, v := range vars {
+		usage := "overrides " + v.key
+		if d := description(v.comment); d != "" {
+			usage = strings.ReplaceAll(d, "\n", " ") + ", " + usage
+		}
+		flags = append(flags, configFlag{
+			Name:   g.flagName(v.key),
+			EnvVar: v.key,
+			Type:   flagType(fieldType(v)),
+			Usage:  usage,
+		})
+	}
+	return flags
+}
+
+// flagName returns the kebab-case name of the flag overriding the given
+// env var, leaving out the prefix of this generator, e.g. 'db-host' for
+// 'DB_HOST'.
+func (g *generator) flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(g.unprefixedKey(key), "_", "-"))
+}
+
+// flagType returns the type of the values of the flag overriding
+// a field of the given Go type, as shown in the flag usage.
+func flagType(fieldType string) string {
+	switch fieldType {
+	case "bool", "int":
+		return fieldType
+	case "time.Duration":
+		return "duration"
+	case "float64":
+		return "float"
+	case "[]string":
+		return "strings"
+	}
+	return "string"
+}
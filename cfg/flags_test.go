@@ -0,0 +1,56 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_configFlags(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           []Option
+		vars           []envVar
+		expectedOutput []configFlag
+	}{
+		{
+			name: "happy path",
+			vars: []envVar{
+				{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+				{key: "DB_PORT", value: "5432"},
+				{key: "DEBUG", value: "false"},
+				{key: "TIMEOUT", value: "5s", comment: "type: time.Duration"},
+				{key: "RATIO", value: "0.5"},
+				{key: "HOSTS", value: "a.com,b.com", inferredType: "[]string"},
+			},
+			expectedOutput: []configFlag{
+				{Name: "db-host", EnvVar: "DB_HOST", Type: "string", Usage: "database host, overrides DB_HOST"},
+				{Name: "db-port", EnvVar: "DB_PORT", Type: "int", Usage: "overrides DB_PORT"},
+				{Name: "debug", EnvVar: "DEBUG", Type: "bool", Usage: "overrides DEBUG"},
+				{Name: "timeout", EnvVar: "TIMEOUT", Type: "duration", Usage: "overrides TIMEOUT"},
+				{Name: "ratio", EnvVar: "RATIO", Type: "float", Usage: "overrides RATIO"},
+				{Name: "hosts", EnvVar: "HOSTS", Type: "strings", Usage: "overrides HOSTS"},
+			},
+		},
+		{
+			name: "with prefix",
+			opts: []Option{WithPrefix("APP_")},
+			vars: []envVar{
+				{key: "APP_DB_HOST", value: "localhost", comment: "database\nhost"},
+			},
+			expectedOutput: []configFlag{
+				{Name: "db-host", EnvVar: "APP_DB_HOST", Type: "string", Usage: "database host, overrides APP_DB_HOST"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			require.Equal(t, tc.expectedOutput, g.configFlags(tc.vars))
+		})
+	}
+}
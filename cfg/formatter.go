@@ -5,38 +5,50 @@
 package cfg
 
 import (
-	"go/format"
-
 	"github.com/pkg/errors"
+	"golang.org/x/tools/imports"
 )
 
-// formatter is an interface for formatting Go source code.
-type formatter interface {
+// Formatter is an interface for formatting Go source code.
+type Formatter interface {
 	// Source formats the provided Go source code.
 	Source(src []byte) ([]byte, error)
 }
 
-// coreFormatter implements the formatter interface using the default Go formatting.
+// importsOptions are the options goimports formats Go source code with.
+var importsOptions = &imports.Options{
+	Comments:  true,
+	TabIndent: true,
+	TabWidth:  8,
+	Fragment:  true,
+}
+
+// coreFormatter implements the Formatter interface the way goimports does:
+// on top of the default Go formatting, it adds the imports missing from
+// the source code and removes the unused ones, so that packages like
+// 'time' or 'net/url', needed by inferred field types, don't have
+// to be imported by the templates beforehand.
 type coreFormatter struct{}
 
 func (c coreFormatter) Source(src []byte) ([]byte, error) {
-	return format.Source(src)
+	if src == nil {
+		// imports.Process reads the source from the file when it's nil.
+		src = []byte{}
+	}
+	return imports.Process("", src, importsOptions)
 }
 
-// For ease of unit testing.
-var formatterProvider formatter = coreFormatter{}
-
 // formatGoFile formats the Go source code in the specified file.
-func formatGoFile(filePath string) error {
-	source, err := fsProvider.ReadFile(filePath)
+func (g *generator) formatGoFile(filePath string) error {
+	source, err := g.fs.ReadFile(filePath)
 	if err != nil {
 		return errors.Wrapf(err, "reading go file %s", filePath)
 	}
-	formattedSrc, err := formatterProvider.Source(source)
+	formattedSrc, err := g.formatter.Source(source)
 	if err != nil {
 		return errors.Wrapf(err, "formating go file %s", filePath)
 	}
-	err = fsProvider.WriteFile(filePath, formattedSrc, 0644)
+	err = g.fs.WriteFile(filePath, formattedSrc, 0644)
 	if err != nil {
 		return errors.Wrapf(err, "writing go file %s", filePath)
 	}
@@ -48,9 +48,8 @@ func Test_formatGoFile(t *testing.T) {
 		mf := new(mockFormatter)
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mockClosure(mfs, mf)
-			fsProvider = mfs
-			formatterProvider = mf
-			err := formatGoFile("file.go")
+			g := NewGenerator("config", WithFileSystem(mfs), WithFormatter(mf)).(*generator)
+			err := g.formatGoFile("file.go")
 			if err != nil {
 				if tc.expectedError == nil {
 					t.Fatalf("expected no error, got %v", err)
@@ -64,3 +63,78 @@ func Test_formatGoFile(t *testing.T) {
 		})
 	}
 }
+
+func Test_coreFormatter(t *testing.T) {
+	testCases := []struct {
+		name           string
+		src            string
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "happy path, adds missing imports",
+			src: `package config
+type Config struct {
+	Timeout time.Duration
+	BaseURL url.URL
+}
+`,
+			expectedOutput: `package config
+
+import (
+	"net/url"
+	"time"
+)
+
+type Config struct {
+	Timeout time.Duration
+	BaseURL url.URL
+}
+`,
+		},
+		{
+			name: "happy path, removes unused imports",
+			src: `package config
+
+import (
+	"os"
+	"time"
+)
+
+var timeout time.Duration
+`,
+			expectedOutput: `package config
+
+import (
+	"time"
+)
+
+var timeout time.Duration
+`,
+		},
+		{
+			name: "error",
+			src: `package config
+
+var timeout time.Duration =
+`,
+			expectedError: errors.New("3:29: expected operand, found 'EOF'"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := coreFormatter{}.Source([]byte(tc.src))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, string(output))
+			}
+		})
+	}
+}
@@ -43,10 +43,12 @@ func (f osFile) Close() error {
 	return f.File.Close()
 }
 
-// fileSystem interface abstracts the file system operations. This allows
-// for easier testing by mocking file system interactions. It includes
-// methods for opening, reading, writing files, and checking their status.
-type fileSystem interface {
+// FileSystem interface abstracts the file system operations. This allows
+// for easier testing by mocking file system interactions, as well as for
+// generating files somewhere else than the OS file system, e.g. in memory
+// with MemFileSystem. It includes methods for opening, reading, writing
+// files, and checking their status.
+type FileSystem interface {
 	Open(name string) (File, error)
 	Create(name string) (File, error)
 	ReadFile(name string) ([]byte, error)
@@ -55,7 +57,7 @@ type fileSystem interface {
 	Mkdir(dirName string) error
 }
 
-// osFileSystem struct implements the fileSystem interface using
+// osFileSystem struct implements the FileSystem interface using
 // the standard library's os package. This is the real implementation
 // that interacts with the actual file system.
 type osFileSystem struct{}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const ghaEnvFileName = "gha.env.yaml"
+
+// ghaEnv returns a GitHub Actions 'env' section listing the given
+// variables. Their values are taken from the repository secrets, for
+// sensitive variables, or from the repository variables otherwise,
+// named after them.
+func (g *generator) ghaEnv(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated by goprojconfig. Merge it into the 'env' section of a workflow,\n")
+	sb.WriteString("# job or step.\n")
+	sb.WriteString("env:\n")
+	for _, v := range vars {
+		writeComment(&sb, "  ", description(v.comment))
+		context := "vars"
+		if isSensitive(v) {
+			context = "secrets"
+		}
+		sb.WriteString(fmt.Sprintf("  %s: ${{ %s.%s }}\n", v.key, context, v.key))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ghaEnv(t *testing.T) {
+	g := NewGenerator("config").(*generator)
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+		{key: "DB_PASSWORD", value: "secret"},
+		{key: "API_URL", value: "https://api.example.com", comment: "sensitive"},
+		{key: "SESSION_TOKEN", comment: "not sensitive"},
+	}
+	expectedOutput := "# Generated by goprojconfig. Merge it into the 'env' section of a workflow,\n" +
+		"# job or step.\n" +
+		"env:\n" +
+		"  # database host\n" +
+		"  DB_HOST: ${{ vars.DB_HOST }}\n" +
+		"  DB_PASSWORD: ${{ secrets.DB_PASSWORD }}\n" +
+		"  API_URL: ${{ secrets.API_URL }}\n" +
+		"  SESSION_TOKEN: ${{ vars.SESSION_TOKEN }}\n"
+	output, err := g.ghaEnv(vars)
+	require.NoError(t, err)
+	require.Equal(t, expectedOutput, output)
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const gitignoreFileName = ".gitignore"
+
+// gitignoreFile returns the content of the project's '.gitignore' file with
+// an entry excluding the '.env' file appended to it, creating it if it
+// doesn't exist yet. Only '.env' is excluded, so that sample env files
+// like '.env.example' can still be committed. The content is left as
+// is when it already excludes '.env'.
+func (g *generator) gitignoreFile(vars []envVar) (string, error) {
+	content, err := g.fs.ReadFile(gitignoreFileName)
+	if err != nil && !g.fs.IsNotExist(err) {
+		return "", errors.Wrapf(err, "reading file %s", gitignoreFileName)
+	}
+	gitignore := string(content)
+	for _, line := range strings.Split(gitignore, "\n") {
+		if entry := strings.TrimSpace(line); entry == envFileName || entry == "/"+envFileName {
+			return gitignore, nil
+		}
+	}
+	if gitignore != "" && !strings.HasSuffix(gitignore, "\n") {
+		gitignore += "\n"
+	}
+	return gitignore + "# env file, which may hold secrets.\n" + envFileName + "\n", nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_gitignoreFile(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockClosure    func(mfs *mockFileSystem)
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "gitignore does not exist",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.readFileErr = errors.New("not found")
+				mfs.isNotExistOutput = true
+			},
+			expectedOutput: "# env file, which may hold secrets.\n.env\n",
+		},
+		{
+			name: "gitignore without trailing newline",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte("bin/\n.env.local")
+			},
+			expectedOutput: "bin/\n.env.local\n# env file, which may hold secrets.\n.env\n",
+		},
+		{
+			name: "gitignore already excludes env file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte("bin/\n/.env\n")
+			},
+			expectedOutput: "bin/\n/.env\n",
+		},
+		{
+			name: "error reading gitignore",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.readFileErr = errors.New("read error")
+			},
+			expectedError: errors.New("reading file .gitignore: read error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			tc.mockClosure(mfs)
+			g := NewGenerator("config", WithFileSystem(mfs), WithGitignore()).(*generator)
+			output, err := g.gitignoreFile(nil)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
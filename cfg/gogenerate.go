@@ -0,0 +1,33 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// goGenerateCommand returns the command of the 'go:generate' directive
+// written into the generated 'config.go', which regenerates the package
+// from the given env files when 'go generate' runs it from the package
+// dir, or an empty string when the directive is disabled or one of the
+// env files is read from the standard input, since it can't be read
+// again. Relative env file paths are made relative to the package dir.
+func (g *generator) goGenerateCommand(envFilePaths []string) string {
+	if g.noGoGenerate || len(withoutStdinEnvFile(envFilePaths)) < len(envFilePaths) {
+		return ""
+	}
+	args := []string{"goprojconfig", "generate", "-p", g.packageName}
+	for _, envFilePath := range envFilePaths {
+		args = append(args, "-e", g.relPath(g.packageDir(), envFilePath))
+	}
+	args = append(args, g.goGenerateFlags...)
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			args[i] = strconv.Quote(arg)
+		}
+	}
+	return strings.Join(args, " ")
+}
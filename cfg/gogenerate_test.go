@@ -0,0 +1,64 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_goGenerateCommand(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	testCases := []struct {
+		name           string
+		opts           []Option
+		envFilePaths   []string
+		expectedOutput string
+	}{
+		{
+			name:           "happy path",
+			envFilePaths:   []string{".env", "env/.env.local", "/etc/app/.env"},
+			expectedOutput: "goprojconfig generate -p config -e ../.env -e ../env/.env.local -e /etc/app/.env",
+		},
+		{
+			name:           "with flags",
+			opts:           []Option{WithGoGenerateFlags("--defaults-from-values", "--naming", "golint")},
+			envFilePaths:   []string{"my env/.env"},
+			expectedOutput: `goprojconfig generate -p config -e "../my env/.env" --defaults-from-values --naming golint`,
+		},
+		{
+			name:           "native separators",
+			envFilePaths:   []string{filepath.FromSlash("env/.env.local")},
+			expectedOutput: "goprojconfig generate -p config -e ../env/.env.local",
+		},
+		{
+			name:           "relative output dir",
+			opts:           []Option{WithOutputDir(filepath.FromSlash("internal/app"))},
+			envFilePaths:   []string{".env", filepath.FromSlash("internal/app/.env.local")},
+			expectedOutput: "goprojconfig generate -p config -e ../../../.env -e ../.env.local",
+		},
+		{
+			name:           "absolute output dir",
+			opts:           []Option{WithOutputDir(filepath.Join(wd, "internal"))},
+			envFilePaths:   []string{".env"},
+			expectedOutput: "goprojconfig generate -p config -e ../../.env",
+		},
+		{
+			name:         "without go generate",
+			opts:         []Option{WithoutGoGenerate()},
+			envFilePaths: []string{".env"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			require.Equal(t, tc.expectedOutput, g.goGenerateCommand(tc.envFilePaths))
+		})
+	}
+}
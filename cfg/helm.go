@@ -0,0 +1,71 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	helmValuesFileName = "helm/values.yaml"
+	helmEnvFileName    = "helm/_env.tpl"
+)
+
+// helmValues returns a Helm values.yaml fragment holding the
+// given variables, with their env file values, under 'env'.
+// The values of sensitive variables are left empty.
+func (g *generator) helmValues(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated by goprojconfig. Merge it into the values.yaml file of your chart.\n")
+	sb.WriteString("env:\n")
+	for _, v := range vars {
+		writeComment(&sb, "  ", v.comment)
+		value := v.value
+		if isSensitive(v) {
+			value = ""
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %q\n", v.key, value))
+	}
+	return sb.String(), nil
+}
+
+// helmEnv returns a Helm named template that maps the chart values
+// held under 'env' to the env vars of a container. Required variables
+// must have a value, while optional ones are omitted when empty.
+func (g *generator) helmEnv(vars []envVar) (string, error) {
+	name := g.packageName + ".env"
+	var sb strings.Builder
+	sb.WriteString("{{/*\n")
+	sb.WriteString("Generated by goprojconfig. Env vars expected by the '" + g.packageName + "' package,\n")
+	sb.WriteString("to be included in a container spec:\n\n")
+	sb.WriteString("env:\n")
+	sb.WriteString(fmt.Sprintf("  {{- include %q . | nindent 2 }}\n", name))
+	sb.WriteString("*/}}\n")
+	sb.WriteString(fmt.Sprintf("{{- define %q -}}\n", name))
+	for _, v := range vars {
+		if isRequired(v) {
+			sb.WriteString(fmt.Sprintf("- name: %s\n", v.key))
+			sb.WriteString(fmt.Sprintf("  value: {{ required \"env.%s is required\" .Values.env.%s | quote }}\n", v.key, v.key))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("{{- with .Values.env.%s }}\n", v.key))
+		sb.WriteString(fmt.Sprintf("- name: %s\n", v.key))
+		sb.WriteString("  value: {{ . | quote }}\n")
+		sb.WriteString("{{- end }}\n")
+	}
+	sb.WriteString("{{- end }}\n")
+	return sb.String(), nil
+}
+
+// writeComment writes the given comment as '#' comment
+// lines with the given indentation.
+func writeComment(sb *strings.Builder, indent, comment string) {
+	for _, line := range strings.Split(comment, "\n") {
+		if line != "" {
+			sb.WriteString(fmt.Sprintf("%s# %s\n", indent, line))
+		}
+	}
+}
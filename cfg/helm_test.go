@@ -0,0 +1,61 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_helmValues(t *testing.T) {
+	g := NewGenerator("config").(*generator)
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host"},
+		{key: "GREETING", value: `say "hi"`},
+		{key: "EMPTY"},
+		{key: "DB_PASSWORD", value: "s3cr3t"},
+		{key: "API_KEY", value: "k3y", comment: "sensitive"},
+	}
+	expectedOutput := "# Generated by goprojconfig. Merge it into the values.yaml file of your chart.\n" +
+		"env:\n" +
+		"  # database host\n" +
+		"  DB_HOST: \"localhost\"\n" +
+		"  GREETING: \"say \\\"hi\\\"\"\n" +
+		"  EMPTY: \"\"\n" +
+		"  DB_PASSWORD: \"\"\n" +
+		"  # sensitive\n" +
+		"  API_KEY: \"\"\n"
+	output, err := g.helmValues(vars)
+	require.NoError(t, err)
+	require.Equal(t, expectedOutput, output)
+	require.NotContains(t, output, "s3cr3t")
+	require.NotContains(t, output, "k3y")
+}
+
+func Test_helmEnv(t *testing.T) {
+	g := NewGenerator("config").(*generator)
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost"},
+		{key: "EMPTY"},
+	}
+	expectedOutput := "{{/*\n" +
+		"Generated by goprojconfig. Env vars expected by the 'config' package,\n" +
+		"to be included in a container spec:\n\n" +
+		"env:\n" +
+		"  {{- include \"config.env\" . | nindent 2 }}\n" +
+		"*/}}\n" +
+		"{{- define \"config.env\" -}}\n" +
+		"- name: DB_HOST\n" +
+		"  value: {{ required \"env.DB_HOST is required\" .Values.env.DB_HOST | quote }}\n" +
+		"{{- with .Values.env.EMPTY }}\n" +
+		"- name: EMPTY\n" +
+		"  value: {{ . | quote }}\n" +
+		"{{- end }}\n" +
+		"{{- end }}\n"
+	output, err := g.helmEnv(vars)
+	require.NoError(t, err)
+	require.Equal(t, expectedOutput, output)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// configValuesStructName is the name of the unexported struct holding
+// the values of an immutable 'Config', which are read into it by envconfig.
+const configValuesStructName = "configValues"
+
+// generateImmutableStruct generates an immutable 'Config' struct, whose
+// values, held by the unexported 'configValues' struct of the given model,
+// are only exposed through getters, so that they can't be changed once
+// read. A 'String' method is generated when it has secret fields. With
+// usage tracking, getters record that the env vars they return were read.
+func (g *generator) generateImmutableStruct(model *StructModel, hasSecretFields bool) (string, error) {
+	declaration, err := g.structDeclaration(model)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("// " + g.structName + " holds all configuration needed by this app.\n")
+	sb.WriteString("// Its values can't be changed once read: they're exposed through getters.\n")
+	sb.WriteString("type " + g.structName + " struct {\n")
+	sb.WriteString("\tvalues " + configValuesStructName + "\n")
+	sb.WriteString("}\n\n")
+	sb.WriteString("// " + configValuesStructName + " holds the values of " + g.structName + ", read from env vars.\n")
+	sb.WriteString(declaration)
+	for _, f := range model.Fields {
+		if f.EnvVar != "" {
+			fmt.Fprintf(&sb, "\n// %s returns the value of the %s env var.\n", f.Name, f.EnvVar)
+		} else {
+			fmt.Fprintf(&sb, "\n// %s returns the value of the %s field.\n", f.Name, f.Name)
+		}
+		fmt.Fprintf(&sb, "func (c *%s) %s() %s {\n", g.structName, f.Name, f.Type)
+		if g.usageTracking && f.EnvVar != "" {
+			fmt.Fprintf(&sb, "\tmarkUsed(%q)\n", f.EnvVar)
+		}
+		fmt.Fprintf(&sb, "\treturn c.values.%s\n}\n", f.Name)
+	}
+	if hasSecretFields {
+		// fmt doesn't call the String method of unexported fields,
+		// so the values are printed through their struct instead.
+		sb.WriteString("\n// String returns the values of the configuration, with secrets masked.\n")
+		sb.WriteString("func (c " + g.structName + ") String() string {\n\treturn fmt.Sprintf(\"%+v\", c.values)\n}\n")
+	}
+	return sb.String(), nil
+}
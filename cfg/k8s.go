@@ -0,0 +1,139 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// parseK8sManifest parses the data keys of the ConfigMaps and Secrets
+// defined in a Kubernetes manifest, read by the given lineReader, as
+// variables, the same way parseEnvFile does for env files. The manifest
+// may hold several YAML documents, e.g. along with a Deployment; those
+// of other kinds are skipped. The values of Secrets' 'data' are base64
+// decoded, 'stringData' taking precedence over them, and their variables
+// are annotated as sensitive. Keys that aren't valid env var names, like
+// 'app.properties', are skipped, as Kubernetes does with 'envFrom'.
+// Comments above and after keys are attached to their variables.
+func parseK8sManifest(lineReader lineReader, values map[string]string) ([]envVar, error) {
+	var lines []string
+	for lineReader.Scan() {
+		lines = append(lines, lineReader.Text())
+	}
+	if err := lineReader.Err(); err != nil {
+		return nil, err
+	}
+	var vars []envVar
+	var found bool
+	decoder := yaml.NewDecoder(strings.NewReader(strings.Join(lines, "\n")))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "parsing manifest")
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		manifest := doc.Content[0]
+		kind := mappingValue(manifest, "kind")
+		if kind == nil || kind.Value != "ConfigMap" && kind.Value != "Secret" {
+			continue
+		}
+		found = true
+		resource := kind.Value
+		if name := mappingValue(mappingValue(manifest, "metadata"), "name"); name != nil {
+			resource += " " + name.Value
+		}
+		dataVars, err := k8sDataVars(mappingValue(manifest, "data"), kind.Value == "Secret")
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading data of %s", resource)
+		}
+		if kind.Value == "Secret" {
+			stringDataVars, err := k8sDataVars(mappingValue(manifest, "stringData"), false)
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading stringData of %s", resource)
+			}
+			dataVars = mergeEnvVars(dataVars, stringDataVars)
+			for i := range dataVars {
+				dataVars[i].comment = strings.TrimPrefix(dataVars[i].comment+"\nsensitive", "\n")
+			}
+		}
+		vars = append(vars, dataVars...)
+	}
+	if !found {
+		return nil, errors.New("no ConfigMap or Secret found")
+	}
+	for _, v := range vars {
+		values[v.key] = v.value
+	}
+	return vars, nil
+}
+
+// k8sDataVars returns the variables defined by the keys of the given
+// 'data' or 'stringData' mapping, if any, base64 decoding their values
+// if asked to.
+func k8sDataVars(data *yaml.Node, base64Encoded bool) ([]envVar, error) {
+	if data == nil || data.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var vars []envVar
+	for i := 0; i+1 < len(data.Content); i += 2 {
+		key, value := data.Content[i], data.Content[i+1]
+		if !isVarName(key.Value) {
+			continue
+		}
+		v := envVar{
+			key:     key.Value,
+			value:   value.Value,
+			line:    key.Line,
+			comment: yamlComment(key.HeadComment, key.LineComment, value.LineComment),
+		}
+		if base64Encoded {
+			decoded, err := base64.StdEncoding.DecodeString(value.Value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d: decoding %s", key.Line, key.Value)
+			}
+			v.value = string(decoded)
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// mappingValue returns the value of the given key of the given
+// mapping node, or nil if it isn't a mapping or hasn't the key.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlComment returns the text of the given YAML comments, one line per
+// comment line, without the leading '#'.
+func yamlComment(comments ...string) string {
+	var lines []string
+	for _, c := range comments {
+		for _, line := range strings.Split(c, "\n") {
+			if line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#")); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseK8sManifest(t *testing.T) {
+	testCases := []struct {
+		name           string
+		lines          []string
+		expectedOutput []envVar
+		expectedValues map[string]string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			lines: []string{
+				"apiVersion: apps/v1",
+				"kind: Deployment",
+				"---",
+				"apiVersion: v1",
+				"kind: ConfigMap",
+				"metadata:",
+				"  name: app-config",
+				"data:",
+				"  # database host",
+				"  DB_HOST: localhost",
+				`  PORT: "8080" # http port`,
+				"  app.properties: |",
+				"    a=b",
+				"---",
+				"kind: Secret",
+				"metadata:",
+				"  name: app-secret",
+				"data:",
+				"  DB_PASSWORD: c2VjcmV0",
+				"  API_KEY: b2xk # api key",
+				"stringData:",
+				"  API_KEY: new",
+			},
+			expectedOutput: []envVar{
+				{key: "DB_HOST", value: "localhost", line: 10, comment: "database host"},
+				{key: "PORT", value: "8080", line: 11, comment: "http port"},
+				{key: "DB_PASSWORD", value: "secret", line: 19, comment: "sensitive"},
+				{key: "API_KEY", value: "new", line: 22, comment: "api key\nsensitive"},
+			},
+			expectedValues: map[string]string{"DB_HOST": "localhost", "PORT": "8080", "DB_PASSWORD": "secret", "API_KEY": "new"},
+		},
+		{
+			name:          "no ConfigMap or Secret",
+			lines:         []string{"kind: Deployment"},
+			expectedError: errors.New("no ConfigMap or Secret found"),
+		},
+		{
+			name:          "invalid YAML",
+			lines:         []string{"kind: ConfigMap", "data: [a"},
+			expectedError: errors.New("parsing manifest: yaml: line 1: did not find expected ',' or ']'"),
+		},
+		{
+			name:          "invalid base64 value",
+			lines:         []string{"kind: Secret", "metadata:", "  name: app-secret", "data:", "  DB_PASSWORD: secret"},
+			expectedError: errors.New("reading data of Secret app-secret: line 5: decoding DB_PASSWORD: illegal base64 data at input byte 4"),
+		},
+		{
+			name:          "error reading lines",
+			expectedError: errors.New("random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lineReader := &mockLineReader{lines: tc.lines}
+			if tc.lines == nil {
+				lineReader.err = errors.New("random error")
+			}
+			values := make(map[string]string)
+			output, err := parseK8sManifest(lineReader, values)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+				require.Equal(t, tc.expectedValues, values)
+			}
+		})
+	}
+}
+
+func TestGenerateConfigPackageFromK8sManifest(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{
+		"k8s/configmap.yaml": []byte("kind: ConfigMap\ndata:\n  PORT: \"8080\"\n  DEBUG: \"true\" # optional\n"),
+	})
+	g := NewGenerator("appcfg", WithFileSystem(fsys))
+	generatedFiles, err := g.GenerateConfigPackageFromK8sManifest("k8s/configmap.yaml")
+	require.NoError(t, err)
+	require.Equal(t, []string{"appcfg/config.go", "appcfg/config_test.go"}, generatedFiles)
+	configFile := string(fsys.Files()["appcfg/config.go"])
+	require.Contains(t, configFile, "\tPort  int  `envconfig:\"PORT\" required:\"true\"`\n")
+	require.Contains(t, configFile, "\tDebug bool `envconfig:\"DEBUG\"`\n")
+}
+
+func TestGenerateConfigPackageFromEnvFileAfterK8sManifest(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{
+		"k8s/configmap.yaml": []byte("kind: ConfigMap\ndata:\n  PORT: \"8080\"\n"),
+		".env":               []byte("HOST=localhost\n"),
+	})
+	g := NewGenerator("appcfg", WithFileSystem(fsys))
+	_, err := g.GenerateConfigPackageFromK8sManifest("k8s/configmap.yaml")
+	require.NoError(t, err)
+	_, err = g.GenerateConfigPackageFromEnvFile(".env")
+	require.NoError(t, err)
+	require.Contains(t, string(fsys.Files()["appcfg/config.go"]), "\tHost string `envconfig:\"HOST\" required:\"true\"`\n")
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// timeLayout returns the layout given to the given variable with
+// a '# layout' annotation, e.g. '# layout: 2006-01-02', if any.
+func timeLayout(v envVar) (string, bool) {
+	return annotationValue(v.comment, "layout")
+}
+
+// layoutTypeName returns the name of the type generated for the field
+// with the given name, read with a non-default layout, e.g. 'StartDateTime'
+// for 'StartDate'.
+func layoutTypeName(fieldName string) string {
+	if strings.HasSuffix(fieldName, "Time") {
+		return fieldName + "Value"
+	}
+	return fieldName + "Time"
+}
+
+// layoutTypes declares the types of the fields generated for the given
+// variables that are read with a non-default layout. Each one embeds
+// 'time.Time' and is an envconfig decoder parsing its layout.
+func (g *generator) layoutTypes(vars []envVar) string {
+	var sb strings.Builder
+	for _, v := range vars {
+		layout, ok := timeLayout(v)
+		if !ok || fieldType(v) != "time.Time" {
+			continue
+		}
+		typeName := layoutTypeName(g.fieldName(v.key))
+		fmt.Fprintf(&sb, "\n// %s is the time read from %s with the %q layout.\n", typeName, v.key, layout)
+		fmt.Fprintf(&sb, "type %s struct {\n\ttime.Time\n}\n", typeName)
+		fmt.Fprintf(&sb, "\n// Decode parses the given value with the %q layout.\n", layout)
+		fmt.Fprintf(&sb, "func (t *%s) Decode(value string) error {\n", typeName)
+		fmt.Fprintf(&sb, "\tparsed, err := time.Parse(%q, value)\n", layout)
+		sb.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
+		sb.WriteString("\tt.Time = parsed\n\treturn nil\n}\n")
+	}
+	return sb.String()
+}
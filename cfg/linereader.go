@@ -4,9 +4,135 @@
 
 package cfg
 
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+)
+
+// DefaultMaxLineLength is the default maximum length, in bytes, of the
+// lines of env files, well above the one of long values like base64
+// encoded certificates or certificate bundles.
+const DefaultMaxLineLength = 1024 * 1024
+
 // lineReader defines an interface for reading lines of text.
 type lineReader interface {
 	Scan() bool
 	Text() string
 	Err() error
 }
+
+// utf8BOM is the byte order mark that files edited
+// on Windows often start with.
+const utf8BOM = "\ufeff"
+
+// normalizedLineReader is a lineReader that strips the UTF-8 BOM from the
+// first line read by the wrapped lineReader, as well as the carriage return
+// of CRLF line endings from every line, so that env files edited on Windows
+// are read the same as the other ones.
+type normalizedLineReader struct {
+	lineReader
+	line    string
+	started bool
+}
+
+// Scan reads the next line, normalizing it.
+func (r *normalizedLineReader) Scan() bool {
+	if !r.lineReader.Scan() {
+		return false
+	}
+	line := r.lineReader.Text()
+	if !r.started {
+		line = strings.TrimPrefix(line, utf8BOM)
+		r.started = true
+	}
+	r.line = strings.TrimSuffix(line, "\r")
+	return true
+}
+
+// Text returns the normalized line read.
+func (r *normalizedLineReader) Text() string {
+	return r.line
+}
+
+// bufferedLineReader is a lineReader reading lines of up to maxLength
+// bytes with a bufio.Reader, whose buffer only grows with long lines,
+// unlike bufio.Scanner, whose 64KB default limit long values exceed.
+// Like bufio.Scanner, it strips the line endings, '\r\n' included.
+type bufferedLineReader struct {
+	reader     *bufio.Reader
+	maxLength  int
+	buf        []byte
+	line       string
+	lineNumber int
+	err        error
+}
+
+// newBufferedLineReader returns a bufferedLineReader reading
+// lines of up to the given length from the given reader.
+func newBufferedLineReader(r io.Reader, maxLength int) *bufferedLineReader {
+	return &bufferedLineReader{reader: bufio.NewReader(r), maxLength: maxLength}
+}
+
+// Scan reads the next line, failing if it's longer than maxLength.
+func (r *bufferedLineReader) Scan() bool {
+	if r.err != nil {
+		return false
+	}
+	r.buf = r.buf[:0]
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		r.buf = append(r.buf, chunk...)
+		if err == bufio.ErrBufferFull {
+			// The line ending isn't read yet.
+			if len(r.buf) > r.maxLength {
+				r.err = r.lineTooLongError()
+				return false
+			}
+			continue
+		}
+		if err != nil && (err != io.EOF || len(r.buf) == 0) {
+			r.err = err
+			return false
+		}
+		break
+	}
+	r.lineNumber++
+	line := bytes.TrimSuffix(bytes.TrimSuffix(r.buf, []byte("\n")), []byte("\r"))
+	if len(line) > r.maxLength {
+		r.lineNumber--
+		r.err = r.lineTooLongError()
+		return false
+	}
+	r.line = string(line)
+	return true
+}
+
+// Text returns the line read, without its line ending.
+func (r *bufferedLineReader) Text() string {
+	return r.line
+}
+
+// Err returns the error that stopped reading lines, if it's not io.EOF.
+func (r *bufferedLineReader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// lineTooLongError returns the error reporting that the line being
+// read, the one after the last line read, is longer than maxLength,
+// naming the variable it assigns, if any, so that it can be found.
+func (r *bufferedLineReader) lineTooLongError() error {
+	lineNumber := r.lineNumber + 1
+	key, _, ok := bytes.Cut(r.buf, []byte("="))
+	key = bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(bytes.TrimPrefix(key, []byte(utf8BOM))), []byte("export ")))
+	if ok && token.IsIdentifier(string(key)) {
+		return &EnvParseError{Line: lineNumber, msg: fmt.Sprintf("line %d: value of %s is longer than the max line length of %d bytes", lineNumber, key, r.maxLength)}
+	}
+	return &EnvParseError{Line: lineNumber, msg: fmt.Sprintf("line %d is longer than the max line length of %d bytes", lineNumber, r.maxLength)}
+}
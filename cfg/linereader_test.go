@@ -0,0 +1,165 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_normalizedLineReader(t *testing.T) {
+	testCases := []struct {
+		name          string
+		lines         []string
+		expectedLines []string
+	}{
+		{
+			name:          "unix line endings",
+			lines:         []string{"DB_HOST=localhost", "DB_PORT=5432"},
+			expectedLines: []string{"DB_HOST=localhost", "DB_PORT=5432"},
+		},
+		{
+			name:          "CRLF line endings",
+			lines:         []string{"DB_HOST=localhost\r", "\r", "DB_PORT=5432\r"},
+			expectedLines: []string{"DB_HOST=localhost", "", "DB_PORT=5432"},
+		},
+		{
+			name:          "BOM",
+			lines:         []string{"\ufeffDB_HOST=localhost", "DB_PORT=\ufeff"},
+			expectedLines: []string{"DB_HOST=localhost", "DB_PORT=\ufeff"},
+		},
+		{
+			name:          "BOM and CRLF line endings",
+			lines:         []string{"\ufeff# the database host.\r", "DB_HOST=localhost\r"},
+			expectedLines: []string{"# the database host.", "DB_HOST=localhost"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &normalizedLineReader{lineReader: &mockLineReader{lines: tc.lines}}
+			var lines []string
+			for r.Scan() {
+				lines = append(lines, r.Text())
+			}
+			require.NoError(t, r.Err())
+			require.Equal(t, tc.expectedLines, lines)
+		})
+	}
+}
+
+func Test_bufferedLineReader(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		maxLength     int
+		expectedLines []string
+		expectedError error
+	}{
+		{
+			name:          "happy path",
+			content:       "DB_HOST=localhost\n\nDB_PORT=5432\n",
+			maxLength:     DefaultMaxLineLength,
+			expectedLines: []string{"DB_HOST=localhost", "", "DB_PORT=5432"},
+		},
+		{
+			name:          "no trailing newline",
+			content:       "DB_HOST=localhost\r\nDB_PORT=5432",
+			maxLength:     DefaultMaxLineLength,
+			expectedLines: []string{"DB_HOST=localhost", "DB_PORT=5432"},
+		},
+		{
+			name:          "empty",
+			maxLength:     DefaultMaxLineLength,
+			expectedLines: nil,
+		},
+		{
+			name:          "lines longer than the bufio.Scanner limit",
+			content:       "TLS_CERT=" + strings.Repeat("A", 100000) + "\nDB_HOST=localhost\n",
+			maxLength:     DefaultMaxLineLength,
+			expectedLines: []string{"TLS_CERT=" + strings.Repeat("A", 100000), "DB_HOST=localhost"},
+		},
+		{
+			name:          "line of the max length",
+			content:       "DB_HOST=localhost\r\n",
+			maxLength:     17,
+			expectedLines: []string{"DB_HOST=localhost"},
+		},
+		{
+			name:          "line too long",
+			content:       "DB_HOST=localhost\nexport TLS_CERT=" + strings.Repeat("A", 5000) + "\nDB_PORT=5432\n",
+			maxLength:     4096,
+			expectedLines: []string{"DB_HOST=localhost"},
+			expectedError: errors.New("line 2: value of TLS_CERT is longer than the max line length of 4096 bytes"),
+		},
+		{
+			name:          "last line too long",
+			content:       "DB_HOST=localhost\nDB_PORT=5432",
+			maxLength:     11,
+			expectedError: errors.New("line 1: value of DB_HOST is longer than the max line length of 11 bytes"),
+		},
+		{
+			name:          "line too long without variable",
+			content:       "DB_HOST=localhost\n" + strings.Repeat("A", 5000) + "\n",
+			maxLength:     4096,
+			expectedLines: []string{"DB_HOST=localhost"},
+			expectedError: errors.New("line 2 is longer than the max line length of 4096 bytes"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newBufferedLineReader(strings.NewReader(tc.content), tc.maxLength)
+			var lines []string
+			for r.Scan() {
+				lines = append(lines, r.Text())
+			}
+			require.Equal(t, tc.expectedLines, lines)
+			err := r.Err()
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+				require.ErrorIs(t, err, ErrEnvParse)
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+			}
+			require.False(t, r.Scan())
+		})
+	}
+}
+
+func TestGenerateFromEnvFileWithLongLines(t *testing.T) {
+	cert := strings.Repeat("TUlJQm", 20000)
+	fsys := NewMemFileSystem(map[string][]byte{
+		".env":       []byte("TLS_CERT=" + cert + "\nCA_BUNDLE=\"" + cert + "\n" + cert + "\"\n"),
+		".env-local": []byte("PORT=8080\nCA_BUNDLE=\"-----BEGIN CERTIFICATE-----\n" + cert + "\"\n"),
+	})
+	_, err := NewGenerator("appcfg", WithFileSystem(fsys)).GenerateConfigPackageFromEnvFile(".env")
+	require.NoError(t, err)
+	configFile := string(fsys.Files()["appcfg/config.go"])
+	require.Contains(t, configFile, "TlsCert  string `envconfig:\"TLS_CERT\" required:\"true\"`")
+	require.Contains(t, configFile, "CaBundle string `envconfig:\"CA_BUNDLE\" required:\"true\"`")
+	_, err = NewGenerator("appcfg", WithFileSystem(fsys), WithMaxLineLength(64*1024)).GenerateConfigPackageFromEnvFile(".env")
+	require.EqualError(t, err, "generating struct from env file .env: scanning: line 1: value of TLS_CERT is longer than the max line length of 65536 bytes")
+	_, err = NewGenerator("appcfg", WithFileSystem(fsys), WithMaxLineLength(64*1024)).GenerateConfigPackageFromEnvFile(".env-local")
+	require.EqualError(t, err, "generating struct from env file .env-local: scanning quoted value of CA_BUNDLE: line 3 is longer than the max line length of 65536 bytes")
+}
+
+func TestGenerateFromWindowsEnvFile(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{".env": []byte("\ufeffPORT=8080\r\nHOSTS=a.com,b.com\r\nTLS_KEY=\"line1\r\nline2\"\r\n")})
+	g := NewGenerator("appcfg", WithFileSystem(fsys), WithInferCollections(), WithDefaultsFromValues())
+	_, err := g.GenerateConfigPackageFromEnvFile(".env")
+	require.NoError(t, err)
+	configFile := string(fsys.Files()["appcfg/config.go"])
+	require.Contains(t, configFile, "Port   int      `envconfig:\"PORT\" default:\"8080\"`")
+	require.Contains(t, configFile, "Hosts  []string `envconfig:\"HOSTS\" default:\"a.com,b.com\"`")
+	require.Contains(t, configFile, "TlsKey string   `envconfig:\"TLS_KEY\" required:\"true\"`")
+	require.NotContains(t, configFile, "\r")
+}
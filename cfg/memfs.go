@@ -0,0 +1,136 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// MemFileSystem is an in-memory FileSystem. Used with WithFileSystem,
+// it lets other tools, like scaffolding tools, generate configuration
+// packages without writing to the OS file system: the env files are
+// read from it and the generated files are written to it.
+type MemFileSystem struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemFileSystem returns an in-memory file system holding the given
+// files, e.g. the env files to generate configuration packages from,
+// keyed by their paths.
+func NewMemFileSystem(files map[string][]byte) *MemFileSystem {
+	m := &MemFileSystem{files: make(map[string][]byte, len(files))}
+	for name, content := range files {
+		m.files[memPath(name)] = append([]byte(nil), content...)
+	}
+	return m
+}
+
+// Files returns a copy of the files of the file system, keyed by their
+// paths, including the files generated into it.
+func (m *MemFileSystem) Files() map[string][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	files := make(map[string][]byte, len(m.files))
+	for name, content := range m.files {
+		files[name] = append([]byte(nil), content...)
+	}
+	return files
+}
+
+func (m *MemFileSystem) Open(name string) (File, error) {
+	content, err := m.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{name: name, reader: bytes.NewReader(content)}, nil
+}
+
+func (m *MemFileSystem) Create(name string) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[memPath(name)] = nil
+	return &memFile{name: name, fs: m}, nil
+}
+
+func (m *MemFileSystem) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content, ok := m.files[memPath(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), content...), nil
+}
+
+func (m *MemFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[memPath(name)] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *MemFileSystem) IsNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// Mkdir does nothing, since dirs are implied by the paths of the files.
+func (m *MemFileSystem) Mkdir(dirName string) error {
+	return nil
+}
+
+// append appends the given data to the named file.
+func (m *MemFileSystem) append(name string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = memPath(name)
+	m.files[name] = append(m.files[name], data...)
+}
+
+// memPath returns the key of the file at the given path.
+func memPath(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// memFile is a file of a MemFileSystem, either opened for reading
+// or created for writing, which writes right through to it.
+type memFile struct {
+	name   string
+	reader *bytes.Reader
+	fs     *MemFileSystem
+}
+
+func (f *memFile) WriteString(s string) (n int, err error) {
+	return f.Write([]byte(s))
+}
+
+func (f *memFile) Name() string {
+	return f.name
+}
+
+func (f *memFile) Read(p []byte) (n int, err error) {
+	if f.reader == nil {
+		return 0, io.EOF
+	}
+	return f.reader.Read(p)
+}
+
+func (f *memFile) Write(p []byte) (n int, err error) {
+	if f.fs == nil {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrPermission}
+	}
+	f.fs.append(f.name, p)
+	return len(p), nil
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateIntoMemFileSystem(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         map[string][]byte
+		expectedFiles []string
+		expectedError error
+	}{
+		{
+			name:          "happy path",
+			files:         map[string][]byte{".env": []byte("# the port.\nPORT=8080\n")},
+			expectedFiles: []string{"appcfg/config.go", "appcfg/config_test.go", ".gitignore"},
+		},
+		{
+			name:          "missing env file",
+			files:         map[string][]byte{},
+			expectedError: errors.New("opening env file .env: open .env: file does not exist"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(tc.files)
+			g := NewGenerator("appcfg", WithFileSystem(fsys), WithGitignore())
+			output, err := g.GenerateConfigPackageFromEnvFile(".env")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf(`expected no error, got "%v"`, err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf(`expected error "%v", got nil`, tc.expectedError)
+				}
+				require.ElementsMatch(t, tc.expectedFiles, output)
+				files := fsys.Files()
+				for _, name := range tc.expectedFiles {
+					require.Contains(t, files, name)
+				}
+				require.Contains(t, string(files["appcfg/config.go"]), "Port int `envconfig:\"PORT\" required:\"true\"`")
+			}
+		})
+	}
+}
+
+func TestMemFileSystem(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{"./dir/a.txt": []byte("a")})
+	content, err := fsys.ReadFile("dir/a.txt")
+	require.NoError(t, err)
+	require.Equal(t, "a", string(content))
+
+	f, err := fsys.Create("dir/b.txt")
+	require.NoError(t, err)
+	_, err = f.WriteString("b")
+	require.NoError(t, err)
+	content, err = fsys.ReadFile("dir/b.txt")
+	require.NoError(t, err)
+	require.Equal(t, "b", string(content))
+	require.NoError(t, f.Close())
+
+	f, err = fsys.Open("dir/b.txt")
+	require.NoError(t, err)
+	content, err = io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, "b", string(content))
+	_, err = f.Write([]byte("c"))
+	require.Error(t, err)
+
+	_, err = fsys.Open("missing.txt")
+	require.True(t, fsys.IsNotExist(err))
+	require.Equal(t, map[string][]byte{"dir/a.txt": []byte("a"), "dir/b.txt": []byte("b")}, fsys.Files())
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sourceCommentPrefix prefixes the header lines recording the env
+// files a package was generated from, along with their checksums.
+const sourceCommentPrefix = "// Source: "
+
+// checksumPrefix prefixes the checksums of the env files.
+const checksumPrefix = " sha256:"
+
+// source is an env file a package was generated from.
+type source struct {
+	path     string
+	checksum string
+}
+
+// checksum returns the checksum of the given content.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// readSources reads the given env files, returning them with their
+// checksums. Their paths are made relative to the output dir, which
+// is the dir StaleSources takes them as relative to.
+func (g *generator) readSources(envFilePaths []string) ([]source, error) {
+	sources := make([]source, 0, len(envFilePaths))
+	for _, envFilePath := range envFilePaths {
+		content, err := g.fs.ReadFile(envFilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading file %s", envFilePath)
+		}
+		sources = append(sources, source{path: g.relPath(g.outputDir, envFilePath), checksum: checksum(content)})
+	}
+	return sources, nil
+}
+
+// generatedHeader returns the header written at the top of the generated
+// Go files: the banner set with WithHeader, if any, then the standard
+// marker of generated code, with the version of goprojconfig, if known,
+// followed by a line for each of the given env files, recording their
+// checksums. It holds no timestamps, so that regenerating a package
+// from the same env files doesn't change it.
+func (g *generator) generatedHeader(sources []source) string {
+	var sb strings.Builder
+	if banner := commentLines(g.license); banner != "" {
+		sb.WriteString(banner + "\n")
+	}
+	sb.WriteString("// Code generated by goprojconfig")
+	if g.version != "" {
+		sb.WriteString(" " + g.version)
+	}
+	sb.WriteString("; DO NOT EDIT.\n")
+	for _, s := range sources {
+		sb.WriteString(sourceCommentPrefix + s.path + checksumPrefix + s.checksum + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// commentLines returns the given text as Go line comments, commenting
+// out the lines that aren't comments already, without trailing blank
+// lines. It returns an empty string if the text is blank.
+func commentLines(text string) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), " \t\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		switch {
+		case strings.HasPrefix(line, "//"):
+			sb.WriteString(line)
+		case line == "":
+			sb.WriteString("//")
+		default:
+			sb.WriteString("// " + line)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// StaleSources returns the env files that changed, or were removed, since
+// the package whose 'config.go' file is given, e.g. 'appcfg/config.go', was
+// generated from them, as recorded in its header. Env file paths are taken
+// as relative to the dir the package was generated from, i.e. the parent
+// of the package dir. Options other than WithFileSystem, which the files
+// are read from, are ignored.
+func StaleSources(configFilePath string, opts ...Option) ([]string, error) {
+	g := NewGenerator("config", opts...).(*generator)
+	content, err := g.fs.ReadFile(configFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading file %s", configFilePath)
+	}
+	sources := parseSources(string(content))
+	if len(sources) == 0 {
+		return nil, errors.Errorf("no env files recorded in %s", configFilePath)
+	}
+	rootDir := filepath.Dir(filepath.Dir(configFilePath))
+	var stale []string
+	for _, s := range sources {
+		envFilePath := filepath.FromSlash(s.path)
+		if !filepath.IsAbs(envFilePath) {
+			envFilePath = filepath.Join(rootDir, envFilePath)
+		}
+		envFileContent, err := g.fs.ReadFile(envFilePath)
+		if err != nil && !g.fs.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "reading file %s", envFilePath)
+		}
+		if err != nil || checksum(envFileContent) != s.checksum {
+			stale = append(stale, s.path)
+		}
+	}
+	return stale, nil
+}
+
+// parseSources parses the env files recorded in the header of the given
+// generated file, which may be preceded by a banner set with WithHeader.
+func parseSources(content string) []source {
+	var sources []source
+	for _, line := range strings.Split(content, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+		rest, ok := strings.CutPrefix(line, sourceCommentPrefix)
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(rest, checksumPrefix)
+		if i == -1 {
+			continue
+		}
+		sources = append(sources, source{path: rest[:i], checksum: rest[i+len(checksumPrefix):]})
+	}
+	return sources
+}
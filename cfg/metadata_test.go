@@ -0,0 +1,151 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_generatedHeader(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           []Option
+		sources        []source
+		expectedOutput string
+	}{
+		{
+			name:           "no sources",
+			expectedOutput: "// Code generated by goprojconfig; DO NOT EDIT.\n\n",
+		},
+		{
+			name:    "version and sources",
+			opts:    []Option{WithGeneratorVersion("v1.2.0")},
+			sources: []source{{path: ".env", checksum: "abc"}, {path: "config/.env.local", checksum: "def"}},
+			expectedOutput: `// Code generated by goprojconfig v1.2.0; DO NOT EDIT.
+// Source: .env sha256:abc
+// Source: config/.env.local sha256:def
+
+`,
+		},
+		{
+			name:    "header",
+			opts:    []Option{WithHeader("Copyright (c) 2024 Acme.\r\n\n// Licensed under the MIT License.  \n\n")},
+			sources: []source{{path: ".env", checksum: "abc"}},
+			expectedOutput: `// Copyright (c) 2024 Acme.
+//
+// Licensed under the MIT License.
+
+// Code generated by goprojconfig; DO NOT EDIT.
+// Source: .env sha256:abc
+
+`,
+		},
+		{
+			name:           "blank header",
+			opts:           []Option{WithHeader(" \n\n")},
+			expectedOutput: "// Code generated by goprojconfig; DO NOT EDIT.\n\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			require.Equal(t, tc.expectedOutput, g.generatedHeader(tc.sources))
+		})
+	}
+}
+
+func TestStaleSources(t *testing.T) {
+	header := "// Code generated by goprojconfig v1.2.0; DO NOT EDIT.\n" +
+		"// Source: .env sha256:" + checksum([]byte("PORT=8080\n")) + "\n" +
+		"// Source: .env.local sha256:" + checksum([]byte("DEBUG=true\n")) + "\n\n" +
+		"package appcfg\n"
+	testCases := []struct {
+		name           string
+		mockClosure    func(mfs *mockFileSystem)
+		expectedOutput []string
+		expectedError  error
+	}{
+		{
+			name: "up to date",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.files = map[string][]byte{
+					"appcfg/config.go": []byte(header),
+					".env":             []byte("PORT=8080\n"),
+					".env.local":       []byte("DEBUG=true\n"),
+				}
+			},
+		},
+		{
+			name: "changed env file, with header",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.files = map[string][]byte{
+					"appcfg/config.go": []byte("// Copyright (c) 2024 Acme.\n\n" + header),
+					".env":             []byte("PORT=8081\n"),
+					".env.local":       []byte("DEBUG=true\n"),
+				}
+			},
+			expectedOutput: []string{".env"},
+		},
+		{
+			name: "changed and removed env files",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.files = map[string][]byte{
+					"appcfg/config.go": []byte(header),
+					".env":             []byte("PORT=8081\n"),
+				}
+				mfs.readFileErr = errors.New("not found")
+				mfs.isNotExistOutput = true
+			},
+			expectedOutput: []string{".env", ".env.local"},
+		},
+		{
+			name: "no env files recorded",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.files = map[string][]byte{
+					"appcfg/config.go": []byte("// Code generated by goprojconfig; DO NOT EDIT.\n\npackage appcfg\n"),
+				}
+			},
+			expectedError: errors.New("no env files recorded in appcfg/config.go"),
+		},
+		{
+			name: "error reading config file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.readFileErr = errors.New("read error")
+			},
+			expectedError: errors.New("reading file appcfg/config.go: read error"),
+		},
+		{
+			name: "error reading env file",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.files = map[string][]byte{
+					"appcfg/config.go": []byte(header),
+				}
+				mfs.readFileErr = errors.New("read error")
+			},
+			expectedError: errors.New("reading file .env: read error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			tc.mockClosure(mfs)
+			output, err := StaleSources("appcfg/config.go", WithFileSystem(mfs))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
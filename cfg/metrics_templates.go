@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	metricsFileName                 = "metrics.go"
+	metricsUnitTestFileName         = "metrics_test.go"
+	metricsFileTemplateName         = "metricsFile"
+	metricsUnitTestFileTemplateName = "metricsUnitTestFile"
+	metricsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	configLoads = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "config_load_total",
+		Help: "Number of times the configuration was loaded.",
+	})
+	configReloadErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "config_reload_errors_total",
+		Help: "Number of times reloading the configuration failed.",
+	})
+	configLastReload = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "config_last_reload_timestamp",
+		Help: "Unix time, in seconds, of the last time the configuration was loaded.",
+	})
+)
+
+// RegisterMetrics registers the metrics of the loads of the configuration
+// against the given registerer, e.g. prometheus.DefaultRegisterer.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{configLoads, configReloadErrors, configLastReload} {
+		if err := registerer.Register(collector); err != nil {
+			return errors.Wrap(err, "registering configuration metrics")
+		}
+	}
+	return nil
+}
+
+// recordReload records a load of the configuration,
+// which failed if the given error isn't nil.
+func recordReload(err error) {
+	if err != nil {
+		configReloadErrors.Inc()
+		return
+	}
+	configLoads.Inc()
+	configLastReload.SetToCurrentTime()
+}
+`
+
+	metricsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, RegisterMetrics(registry))
+	err := RegisterMetrics(registry)
+	require.ErrorContains(t, err, "registering configuration metrics")
+}
+
+func TestRecordReload(t *testing.T) {
+	loads := testutil.ToFloat64(configLoads)
+	reloadErrors := testutil.ToFloat64(configReloadErrors)
+	recordReload(nil)
+	require.Equal(t, loads+1, testutil.ToFloat64(configLoads))
+	require.NotZero(t, testutil.ToFloat64(configLastReload))
+	recordReload(errors.New("random error"))
+	require.Equal(t, reloadErrors+1, testutil.ToFloat64(configReloadErrors))
+	require.Equal(t, loads+1, testutil.ToFloat64(configLoads))
+}
+`
+)
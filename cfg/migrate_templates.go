@@ -0,0 +1,243 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	migrateFileName                 = "migrate.go"
+	migrateUnitTestFileName         = "migrate_test.go"
+	migrateFileTemplateName         = "migrateFile"
+	migrateUnitTestFileTemplateName = "migrateUnitTestFile"
+	migrateFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaVersion is the version of the schema of the env vars read into
+// {{ .StructName }}, to be bumped whenever env vars are renamed, removed or
+// change format.
+const SchemaVersion = {{ .SchemaVersion }}
+
+// schemaVersionEnvVar is the environment variable that holds the version
+// of the schema the env vars were written for.
+const schemaVersionEnvVar = "{{ with .EnvPrefix }}{{ . }}_{{ end }}CONFIG_SCHEMA_VERSION"
+
+// For ease of unit testing.
+var environ = os.Environ
+
+// migrations holds the hooks migrating env vars from
+// each schema version to the next one, keyed by the former.
+var migrations = map[int][]func(env map[string]string){}
+
+// RegisterRename registers the rename of the oldKey env var, read by the
+// given schema version, to the newKey one, read by the next version. It's
+// meant to be called from init functions, as it isn't safe for concurrent use.
+func RegisterRename(fromVersion int, oldKey, newKey string) {
+	migrations[fromVersion] = append(migrations[fromVersion], func(env map[string]string) {
+		value, ok := env[oldKey]
+		if !ok {
+			return
+		}
+		delete(env, oldKey)
+		if _, ok := env[newKey]; !ok {
+			env[newKey] = value
+		}
+	})
+}
+
+// RegisterTransform registers the transform of the value of the key env var,
+// from the format of the given schema version to the one of the next version.
+// It's meant to be called from init functions, as it isn't safe for concurrent use.
+func RegisterTransform(fromVersion int, key string, transform func(value string) string) {
+	migrations[fromVersion] = append(migrations[fromVersion], func(env map[string]string) {
+		if value, ok := env[key]; ok {
+			env[key] = transform(value)
+		}
+	})
+}
+
+// Migrate returns the given env vars migrated to SchemaVersion, applying
+// the hooks registered for each version in turn, in the order they were
+// registered, starting from the version set in {{ with .EnvPrefix }}{{ . }}_{{ end }}CONFIG_SCHEMA_VERSION or,
+// if it isn't set, the previous one, so that services accept the env vars of
+// the previous schema during rollout windows. Renamed env vars don't replace
+// the ones already set with the new names. The given env vars are left untouched.
+func Migrate(old map[string]string) map[string]string {
+	fromVersion := SchemaVersion - 1
+	if version, err := strconv.Atoi(old[schemaVersionEnvVar]); err == nil {
+		fromVersion = version
+	}
+	env := make(map[string]string, len(old))
+	for key, value := range old {
+		env[key] = value
+	}
+	for version := fromVersion; version < SchemaVersion; version++ {
+		for _, migrate := range migrations[version] {
+			migrate(env)
+		}
+	}
+	env[schemaVersionEnvVar] = strconv.Itoa(SchemaVersion)
+	return env
+}
+
+// MigrateEnv migrates the env vars of the process with Migrate, setting
+// the ones it adds or changes. It's meant to be called before reading
+// configuration; the env vars of env files loaded while reading it are not
+// migrated.
+func MigrateEnv() error {
+	env := make(map[string]string)
+	for _, keyValue := range environ() {
+		if key, value, ok := strings.Cut(keyValue, "="); ok && key != "" {
+			env[key] = value
+		}
+	}
+	for key, value := range Migrate(env) {
+		if previous, ok := env[key]; ok && previous == value {
+			continue
+		}
+		if err := setenv(key, value); err != nil {
+			return errors.Wrapf(err, "setting %s", key)
+		}
+	}
+	return nil
+}
+`
+
+	migrateUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	defer func() {
+		migrations = map[int][]func(env map[string]string){}
+	}()
+	RegisterRename(SchemaVersion-2, "DATABASE_HOST", "DB_HOSTNAME")
+	RegisterRename(SchemaVersion-1, "DB_HOSTNAME", "DB_HOST")
+	RegisterTransform(SchemaVersion-1, "TIMEOUT", func(value string) string {
+		return value + "s"
+	})
+	testCases := []struct {
+		name           string
+		old            map[string]string
+		expectedOutput map[string]string
+	}{
+		{
+			name: "previous schema version",
+			old:  map[string]string{"DB_HOSTNAME": "localhost", "TIMEOUT": "5", "DEBUG": "true"},
+			expectedOutput: map[string]string{
+				"DB_HOST":           "localhost",
+				"TIMEOUT":           "5s",
+				"DEBUG":             "true",
+				schemaVersionEnvVar: strconv.Itoa(SchemaVersion),
+			},
+		},
+		{
+			name: "older schema version",
+			old:  map[string]string{"DATABASE_HOST": "localhost", schemaVersionEnvVar: strconv.Itoa(SchemaVersion - 2)},
+			expectedOutput: map[string]string{
+				"DB_HOST":           "localhost",
+				schemaVersionEnvVar: strconv.Itoa(SchemaVersion),
+			},
+		},
+		{
+			name: "current schema version",
+			old:  map[string]string{"DB_HOSTNAME": "localhost", "TIMEOUT": "5", schemaVersionEnvVar: strconv.Itoa(SchemaVersion)},
+			expectedOutput: map[string]string{
+				"DB_HOSTNAME":       "localhost",
+				"TIMEOUT":           "5",
+				schemaVersionEnvVar: strconv.Itoa(SchemaVersion),
+			},
+		},
+		{
+			name: "renamed env var already set",
+			old:  map[string]string{"DB_HOSTNAME": "localhost", "DB_HOST": "remotehost"},
+			expectedOutput: map[string]string{
+				"DB_HOST":           "remotehost",
+				schemaVersionEnvVar: strconv.Itoa(SchemaVersion),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := Migrate(tc.old)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+func TestMigrateEnv(t *testing.T) {
+	defer func() {
+		migrations = map[int][]func(env map[string]string){}
+	}()
+	RegisterRename(SchemaVersion-1, "DB_HOSTNAME", "DB_HOST")
+	testCases := []struct {
+		name          string
+		mockedEnviron func() []string
+		mockedSetenv  func(key, value string) error
+		expectedEnv   map[string]string
+		expectedError error
+	}{
+		{
+			name: "happy path",
+			mockedEnviron: func() []string {
+				return []string{"DB_HOSTNAME=localhost", "DEBUG=true", "=C:=C:\\app"}
+			},
+			expectedEnv: map[string]string{
+				"DB_HOST":           "localhost",
+				schemaVersionEnvVar: strconv.Itoa(SchemaVersion),
+			},
+		},
+		{
+			name: "error setting env var",
+			mockedEnviron: func() []string {
+				return []string{"DB_HOSTNAME=localhost", schemaVersionEnvVar + "=" + strconv.Itoa(SchemaVersion-1)}
+			},
+			mockedSetenv: func(key, value string) error {
+				if key == "DB_HOST" {
+					return errors.New("random error")
+				}
+				return nil
+			},
+			expectedError: errors.New("setting DB_HOST: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := make(map[string]string)
+			environ = tc.mockedEnviron
+			setenv = func(key, value string) error {
+				if tc.mockedSetenv != nil {
+					return tc.mockedSetenv(key, value)
+				}
+				env[key] = value
+				return nil
+			}
+			err := MigrateEnv()
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+`
+)
@@ -16,9 +16,11 @@ type mockFile struct {
 	writeStringErr error
 	readErr        error
 	closeErr       error
+	written        string
 }
 
 func (m *mockFile) WriteString(s string) (n int, err error) {
+	m.written += s
 	return 5, m.writeStringErr
 }
 
@@ -40,6 +42,7 @@ func (m *mockFile) Close() error {
 
 type mockFileSystem struct {
 	file                  []byte
+	files                 map[string][]byte
 	isNotExistOutput      bool
 	openedFile            *mockFile
 	createdFile           *mockFile
@@ -78,6 +81,9 @@ func (m *mockFileSystem) IsNotExist(err error) bool {
 }
 
 func (m *mockFileSystem) ReadFile(name string) ([]byte, error) {
+	if file, ok := m.files[name]; ok {
+		return file, nil
+	}
 	return m.file, m.readFileErr
 }
 
@@ -103,13 +109,14 @@ func (m *mockTemplateExecutor) Execute(wr io.Writer, data interface{}) error {
 }
 
 type mockTemplateProcessor struct {
-	te                               templateExecutor
+	te                               TemplateExecutor
 	err                              error
 	envFileTemplateParseErr          error
 	configReaderUnitTestFileParseErr error
+	optionalFileParseErr             error
 }
 
-func (m *mockTemplateProcessor) Parse(name, text string) (templateExecutor, error) {
+func (m *mockTemplateProcessor) Parse(name, text string) (TemplateExecutor, error) {
 	if strings.Contains(name, configReaderMainFileTemplateName) {
 		return m.te, m.err
 	}
@@ -119,6 +126,9 @@ func (m *mockTemplateProcessor) Parse(name, text string) (templateExecutor, erro
 	if strings.Contains(name, envFileTemplateName) {
 		return m.te, m.envFileTemplateParseErr
 	}
+	if m.optionalFileParseErr != nil {
+		return m.te, m.optionalFileParseErr
+	}
 	return m.te, m.err
 }
 
@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValidatePackageName checks that the given package name is a legal Go
+// package name: an identifier that is neither a keyword nor the blank one,
+// e.g. 'my-config' and 'type' are refused. The returned error matches
+// ErrInvalidPackageName.
+func ValidatePackageName(packageName string) error {
+	if packageName == "" {
+		return &sentinelError{sentinel: ErrInvalidPackageName, err: errors.New("package name is empty")}
+	}
+	if !token.IsIdentifier(packageName) || packageName == "_" {
+		return &sentinelError{
+			sentinel: ErrInvalidPackageName,
+			err:      errors.Errorf("invalid package name %q: it must be a Go identifier other than a keyword or '_'", packageName),
+		}
+	}
+	return nil
+}
+
+// importPath returns the import path of the generated package, computed
+// from the path of the module enclosing the package dir, whose go.mod file
+// is looked for in the output dir and its parents. It returns an empty
+// string when no module is found.
+func (g *generator) importPath() string {
+	pkgDir, err := g.absPath(g.packageDir())
+	if err != nil {
+		return ""
+	}
+	for dir := filepath.Dir(pkgDir); ; dir = filepath.Dir(dir) {
+		if gomod, err := g.fs.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			if modulePath := modulePath(gomod); modulePath != "" {
+				rel, err := filepath.Rel(dir, pkgDir)
+				if err != nil {
+					return ""
+				}
+				return path.Join(modulePath, filepath.ToSlash(rel))
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// modulePath returns the module path declared in the given
+// go.mod file, or an empty string if there's none.
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "module" {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+			return unquoted
+		}
+		return fields[1]
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePackageName(t *testing.T) {
+	testCases := []struct {
+		packageName   string
+		expectedError error
+	}{
+		{packageName: "appcfg"},
+		{packageName: "app_config2"},
+		{packageName: "", expectedError: errors.New("package name is empty")},
+		{packageName: "my-config", expectedError: errors.New(`invalid package name "my-config": it must be a Go identifier other than a keyword or '_'`)},
+		{packageName: "type", expectedError: errors.New(`invalid package name "type": it must be a Go identifier other than a keyword or '_'`)},
+		{packageName: "2config", expectedError: errors.New(`invalid package name "2config": it must be a Go identifier other than a keyword or '_'`)},
+		{packageName: "_", expectedError: errors.New(`invalid package name "_": it must be a Go identifier other than a keyword or '_'`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.packageName, func(t *testing.T) {
+			err := ValidatePackageName(tc.packageName)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+				require.ErrorIs(t, err, ErrInvalidPackageName)
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+			}
+		})
+	}
+}
+
+func Test_importPath(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockClosure    func(mfs *mockFileSystem)
+		getwdErr       error
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.file = []byte("// comment\nmodule \"example.com/app\"\n\ngo 1.22\n")
+			},
+			expectedOutput: "example.com/app/config",
+		},
+		{
+			name: "no module",
+			mockClosure: func(mfs *mockFileSystem) {
+				mfs.readFileErr = errors.New("not found")
+			},
+		},
+		{
+			name:        "error getting working dir",
+			mockClosure: func(mfs *mockFileSystem) {},
+			getwdErr:    errors.New("getwd error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfs := new(mockFileSystem)
+			tc.mockClosure(mfs)
+			getwd := func() (string, error) {
+				return "/home/user/app", tc.getwdErr
+			}
+			g := NewGenerator("config", WithFileSystem(mfs), withGetwd(getwd)).(*generator)
+			require.Equal(t, tc.expectedOutput, g.importPath())
+		})
+	}
+}
+
+func Test_modulePath(t *testing.T) {
+	testCases := []struct {
+		name           string
+		gomod          string
+		expectedOutput string
+	}{
+		{name: "unquoted", gomod: "module example.com/app\n", expectedOutput: "example.com/app"},
+		{name: "quoted", gomod: "module \"example.com/app\" // comment\n", expectedOutput: "example.com/app"},
+		{name: "no module directive", gomod: "go 1.22\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, modulePath([]byte(tc.gomod)))
+		})
+	}
+}
+
+func Test_importPathWithOutputDir(t *testing.T) {
+	getwd := func() (string, error) {
+		return filepath.FromSlash("/home/user/app"), nil
+	}
+	fsys := NewMemFileSystem(map[string][]byte{filepath.FromSlash("/home/user/app/go.mod"): []byte("module example.com/app\n")})
+	g := NewGenerator("config", WithFileSystem(fsys), WithOutputDir(filepath.FromSlash("internal/platform")), withGetwd(getwd)).(*generator)
+	require.Equal(t, "example.com/app/internal/platform/config", g.importPath())
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"go/token"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultStructName is the default name of the generated struct.
+	defaultStructName = "Config"
+	// defaultReaderName is the default name of the generated function
+	// reading configuration, which also prefixes the names of the other
+	// reading functions, e.g. 'ReadFromEnvFile' and 'MustRead'.
+	defaultReaderName = "Read"
+)
+
+// validateNames checks that the names of the generated struct and
+// reading functions are distinct exported Go identifiers.
+func (g *generator) validateNames() error {
+	if !isExportedIdentifier(g.structName) {
+		return errors.Errorf("invalid struct name %q: it must be an exported Go identifier", g.structName)
+	}
+	if !isExportedIdentifier(g.readerName) {
+		return errors.Errorf("invalid reader name %q: it must be an exported Go identifier", g.readerName)
+	}
+	if g.structName == g.readerName {
+		return errors.Errorf("the struct and the reader can't both be named %s", g.structName)
+	}
+	return nil
+}
+
+// isExportedIdentifier reports whether the given name
+// is an exported Go identifier.
+func isExportedIdentifier(name string) bool {
+	return token.IsIdentifier(name) && token.IsExported(name)
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWithNames(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{".env": []byte("# min: 1\nPORT=8080\n")})
+	opts := []Option{WithFileSystem(fsys), WithStructName("AppConfig"), WithReaderName("Load"), WithProfiles(), WithSingleton(), WithConfigProvider()}
+	_, err := NewGenerator("appcfg", opts...).GenerateConfigPackageFromEnvFile(".env")
+	require.NoError(t, err)
+	defaultNames := regexp.MustCompile(`\*Config\b|\bConfig\{|\b(Must)?Read(FromEnvFile|ForEnv|WithDefaults)?\(`)
+	for name, content := range fsys.Files() {
+		if name == ".env" {
+			continue
+		}
+		require.Nil(t, defaultNames.Find(content), "default names in %s:\n%s", name, content)
+	}
+	files := fsys.Files()
+	require.Contains(t, string(files["appcfg/config.go"]), "type AppConfig struct {")
+	require.Contains(t, string(files["appcfg/config.go"]), "func Load() (*AppConfig, error) {")
+	require.Contains(t, string(files["appcfg/config.go"]), "func LoadFromEnvFile(envFilePath string) (*AppConfig, error) {")
+	require.Contains(t, string(files["appcfg/config.go"]), "func MustLoad() *AppConfig {")
+	require.Contains(t, string(files["appcfg/config.go"]), "func (c *AppConfig) Validate() error {")
+	require.Contains(t, string(files["appcfg/config_test.go"]), "func TestLoadForEnv(t *testing.T) {")
+}
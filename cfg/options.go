@@ -0,0 +1,661 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Option configures a Generator.
+type Option func(*generator)
+
+// WithFieldNamer sets the function used to map env var names
+// to struct field names. Defaults to CamelCaseFieldNamer.
+func WithFieldNamer(fieldNamer func(envKey string) string) Option {
+	return func(g *generator) {
+		g.fieldNamer = fieldNamer
+	}
+}
+
+// WithDefaultsFromValues makes the values found in the env file the
+// defaults of the correspondent fields: they get a 'default' tag instead
+// of being required. It also generates a 'ReadWithDefaults' function,
+// which doesn't require an '.env' file to be present.
+func WithDefaultsFromValues() Option {
+	return func(g *generator) {
+		g.defaultsFromValues = true
+	}
+}
+
+// WithProfiles generates a 'ReadForEnv' function, which loads '.env.<name>'
+// layered over '.env', and makes 'Read' honor the APP_ENV variable,
+// so that apps can be run in multiple environments.
+func WithProfiles() Option {
+	return func(g *generator) {
+		g.profiles = true
+	}
+}
+
+// WithHotReload generates a 'Watch' function, which reloads the
+// configuration whenever the env file changes, so that long-running
+// services can pick up configuration changes without a restart.
+func WithHotReload() Option {
+	return func(g *generator) {
+		g.hotReload = true
+	}
+}
+
+// WithSIGHUPReload generates a 'ReloadOnSIGHUP' function, which reloads
+// the configuration whenever the process receives a SIGHUP, as daemons
+// usually do.
+func WithSIGHUPReload() Option {
+	return func(g *generator) {
+		g.sighupReload = true
+	}
+}
+
+// WithSingleton generates 'Get' and 'Set' functions, giving services
+// a single, race-free way to obtain the configuration, which is read
+// on first access.
+func WithSingleton() Option {
+	return func(g *generator) {
+		g.singleton = true
+	}
+}
+
+// WithSecretsBackends makes the generated package resolve env vars whose
+// values reference secrets stored in the given secrets managers, e.g.
+// 'aws-sm://db-password', when reading the configuration.
+func WithSecretsBackends(backends ...SecretsBackend) Option {
+	return func(g *generator) {
+		g.secretsBackends = append(g.secretsBackends, backends...)
+	}
+}
+
+// WithRemoteSources generates functions that read the configuration from
+// the given remote key/value stores, HTTP endpoints or custom sources,
+// e.g. 'ReadFromConsul'.
+func WithRemoteSources(sources ...RemoteSource) Option {
+	return func(g *generator) {
+		g.remoteSources = append(g.remoteSources, sources...)
+	}
+}
+
+// WithDockerCompose also generates a 'docker-compose.env.yaml' file, with
+// an 'environment' section listing all the env vars, to be merged into
+// a Docker Compose service definition.
+func WithDockerCompose() Option {
+	return func(g *generator) {
+		g.dockerCompose = true
+	}
+}
+
+// WithGHA also generates a 'gha.env.yaml' file, with a GitHub Actions
+// 'env' section mapping all the env vars to the repository secrets, for
+// sensitive ones, or variables named after them.
+func WithGHA() Option {
+	return func(g *generator) {
+		g.gha = true
+	}
+}
+
+// WithHelm also generates a Helm 'values.yaml' fragment holding all the
+// env vars and an '_env.tpl' named template mapping them to the env vars
+// of a container, under the 'helm' dir.
+func WithHelm() Option {
+	return func(g *generator) {
+		g.helm = true
+	}
+}
+
+// WithTerraform also generates, under the 'terraform' dir, a 'variables.tf'
+// file declaring a Terraform variable for every env var, with its type
+// mapped to a Terraform one, and a 'terraform.tfvars.example' file setting
+// them to their env file values, so that infrastructure code stays aligned
+// with the env vars the app expects.
+func WithTerraform() Option {
+	return func(g *generator) {
+		g.terraform = true
+	}
+}
+
+// WithSystemd also generates a 'systemd.env' file holding all the env
+// vars, with their env file values, in the format expected by the
+// 'EnvironmentFile' directive of systemd units.
+func WithSystemd() Option {
+	return func(g *generator) {
+		g.systemd = true
+	}
+}
+
+// WithJSONSchema also generates a 'config.schema.json' file alongside
+// the package, describing every env var with its type, whether it's
+// required, its description and its default value.
+func WithJSONSchema() Option {
+	return func(g *generator) {
+		g.jsonSchema = true
+	}
+}
+
+// WithCUE also generates a 'config.cue' file declaring a '#Config' CUE
+// definition of the env vars, along with a 'ValidateWithCUE' function
+// that validates the configuration against it.
+func WithCUE() Option {
+	return func(g *generator) {
+		g.cue = true
+	}
+}
+
+// WithReadme also generates a 'README.md' file in the package dir,
+// documenting what the package does, the env vars it reads and how
+// to use it, e.g. for monorepos whose packages are documented
+// independently.
+func WithReadme() Option {
+	return func(g *generator) {
+		g.readme = true
+	}
+}
+
+// WithGitignore also excludes the '.env' file from version control, creating
+// the project's '.gitignore' file or appending an entry to it, so that
+// secrets it may hold don't get committed by accident.
+func WithGitignore() Option {
+	return func(g *generator) {
+		g.gitignore = true
+	}
+}
+
+// WithoutGoGenerate disables the 'go:generate' directive that is written
+// into the 'config.go' file generated from env files, which regenerates
+// the package when running 'go generate ./...'.
+func WithoutGoGenerate() Option {
+	return func(g *generator) {
+		g.noGoGenerate = true
+	}
+}
+
+// WithGoGenerateFlags appends the given goprojconfig flags to the
+// 'go:generate' directive, so that the package gets regenerated with
+// the same options it was generated with, e.g. '--defaults-from-values'.
+func WithGoGenerateFlags(flags ...string) Option {
+	return func(g *generator) {
+		g.goGenerateFlags = append(g.goGenerateFlags, flags...)
+	}
+}
+
+// WithLogger sets the logger the generator reports its progress to, at
+// debug level, e.g. parsing env files, executing templates and formatting
+// generated files. Nothing is logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(g *generator) {
+		g.logger = logger
+	}
+}
+
+// WithTestStyle sets how the generated 'config_test.go' tests the package.
+// Defaults to MockTestStyle. RealTestStyle reads a temporary env file and
+// env vars set with 't.Setenv', asserting the values of the fields.
+func WithTestStyle(style TestStyle) Option {
+	return func(g *generator) {
+		g.testStyle = style
+	}
+}
+
+// WithoutTests disables the generation of unit test files, i.e.
+// 'config_test.go' and the ones testing optional files, for projects
+// that maintain their own test conventions.
+func WithoutTests() Option {
+	return func(g *generator) {
+		g.noTests = true
+	}
+}
+
+// WithGeneratorVersion sets the version of goprojconfig recorded in the
+// header of the generated Go files, e.g. 'v1.2.0'.
+func WithGeneratorVersion(version string) Option {
+	return func(g *generator) {
+		g.version = version
+	}
+}
+
+// WithHeader sets a banner, e.g. a copyright and license notice, written
+// at the top of the generated Go files, above the standard marker of
+// generated code. Lines that aren't comments already are commented out.
+func WithHeader(header string) Option {
+	return func(g *generator) {
+		g.license = header
+	}
+}
+
+// WithStructName sets the name of the generated struct, e.g. 'AppConfig',
+// instead of 'Config', to match the conventions of existing code bases.
+func WithStructName(name string) Option {
+	return func(g *generator) {
+		g.structName = name
+	}
+}
+
+// WithReaderName sets the name of the generated function reading
+// configuration, e.g. 'Load', instead of 'Read'. It also replaces 'Read'
+// in the names of the other reading functions, e.g. 'LoadFromEnvFile'
+// and 'MustLoad'.
+func WithReaderName(name string) Option {
+	return func(g *generator) {
+		g.readerName = name
+	}
+}
+
+// WithSortOrder sets the order in which the fields of the generated
+// 'Config' struct are emitted. Defaults to SourceOrder.
+func WithSortOrder(order SortOrder) Option {
+	return func(g *generator) {
+		g.sortOrder = order
+	}
+}
+
+// WithInclude restricts the generated 'Config' struct to the variables whose
+// names match any of the given glob patterns, e.g. 'DB_*', so that only
+// a subset of a large shared env file is read.
+func WithInclude(patterns ...string) Option {
+	return func(g *generator) {
+		g.includes = append(g.includes, patterns...)
+	}
+}
+
+// WithExclude leaves out of the generated 'Config' struct the variables
+// whose names match any of the given glob patterns, e.g. '*_DEPRECATED'.
+// Exclusions take precedence over inclusions.
+func WithExclude(patterns ...string) Option {
+	return func(g *generator) {
+		g.excludes = append(g.excludes, patterns...)
+	}
+}
+
+// WithPrefix namespaces the env vars read into the generated 'Config'
+// struct with the given prefix, e.g. 'APP_': 'APP_DB_HOST' is read into
+// a 'DbHost' field tagged 'DB_HOST', and the generated package passes
+// 'APP' to 'envconfig.Process'. Variables without the prefix are left out.
+func WithPrefix(prefix string) Option {
+	return func(g *generator) {
+		g.prefix = prefix
+	}
+}
+
+// WithImmutable generates an immutable 'Config' struct: its values are held
+// by unexported fields and exposed through getters, e.g. 'DbHost()', so that
+// they can't be changed once read.
+func WithImmutable() Option {
+	return func(g *generator) {
+		g.immutable = true
+	}
+}
+
+// WithBenchmarks generates 'config_bench_test.go', benchmarking reading
+// configuration from an env file with the values of the given one, and
+// reloading it with hot reload or SIGHUP reload, so that regressions in
+// startup latency can be tracked.
+func WithBenchmarks() Option {
+	return func(g *generator) {
+		g.benchmarks = true
+	}
+}
+
+// WithUsageTracking makes the getters of an immutable 'Config', which it
+// requires, record which env vars are read, and generates 'ReportUnused',
+// which logs the ones never read during a sample period, helping to prune
+// dead configuration.
+func WithUsageTracking() Option {
+	return func(g *generator) {
+		g.usageTracking = true
+	}
+}
+
+// WithClone generates 'Clone' and 'Equal' methods on 'Config', which deep
+// copy and deeply compare configurations, for services that snapshot and
+// diff their configuration across reloads.
+func WithClone() Option {
+	return func(g *generator) {
+		g.clone = true
+	}
+}
+
+// WithInferCollections infers the types of variables whose values are
+// comma delimited lists: '[]string' for values like 'a.com,b.com' and
+// 'map[string]string' for values like 'k1:v1,k2:v2', both of which
+// envconfig supports.
+func WithInferCollections() Option {
+	return func(g *generator) {
+		g.inferCollections = true
+	}
+}
+
+// WithEnvrc parses the env files as direnv '.envrc' files, which are shell
+// scripts: variables are taken from their 'export' statements, while the
+// shell constructs that can't be evaluated are skipped. Files named '.envrc'
+// are parsed as such without it.
+func WithEnvrc() Option {
+	return func(g *generator) {
+		g.envrc = true
+	}
+}
+
+// WithOutputDir sets the dir the package dir is created in, which must
+// exist, instead of the working dir. Artifacts like the Docker Compose and
+// Helm ones are written there too. Absolute dirs are supported, as well as,
+// on Windows, volume-rooted ones like '\src\app', which are resolved against
+// the volume of the working dir. Paths written into the generated files,
+// like the ones of the env files, are made relative to it, slash-separated.
+func WithOutputDir(dir string) Option {
+	return func(g *generator) {
+		g.outputDir = dir
+	}
+}
+
+// WithChangelog appends an entry to the 'CONFIG_CHANGELOG.md' file of the
+// package, creating it if needed, whenever it's generated from env files
+// with env vars added, removed or retyped since it was last generated,
+// listing them, so that the evolution of the configuration can be audited.
+func WithChangelog() Option {
+	return func(g *generator) {
+		g.changelog = true
+	}
+}
+
+// WithPlugins extends the generation from env files with the given plugins,
+// whose hooks are called in the given order: they can validate the parsed
+// variables, change the fields of the generated struct and generate extra
+// files in the package dir.
+func WithPlugins(plugins ...Plugin) Option {
+	return func(g *generator) {
+		g.plugins = append(g.plugins, plugins...)
+	}
+}
+
+// WithStructTemplate generates the struct holding the configuration values
+// with the given text/template, instead of the default one, e.g. to lay out
+// its fields differently. It's executed with the StructModel of the struct,
+// once mutated by the plugins, and can use the 'comment' function, which
+// turns text into a line comment of a field.
+func WithStructTemplate(text string) Option {
+	return func(g *generator) {
+		g.structTemplate = text
+	}
+}
+
+// WithSchemaVersion generates a 'SchemaVersion' constant, set to the given
+// version, which must be positive, along with 'Migrate', which migrates env
+// vars written for previous schema versions with the rename and transform
+// hooks registered with 'RegisterRename' and 'RegisterTransform', so that
+// services accept the env vars of the previous schema during rollout windows.
+func WithSchemaVersion(version int) Option {
+	return func(g *generator) {
+		g.schemaVersion = version
+	}
+}
+
+// WithDialect sets the rules the env files are interpreted with, both when
+// generating the package and by its readers. Defaults to GodotenvDialect.
+// DockerDialect reads values the way 'docker run --env-file' does, so that
+// the app gets the same values as the container runtime would give it.
+// Files named '.envrc' are still parsed as direnv ones.
+func WithDialect(dialect Dialect) Option {
+	return func(g *generator) {
+		g.dialect = dialect
+	}
+}
+
+// WithDocsFormat sets the format of the documentation returned by Docs.
+// Defaults to MarkdownDocsFormat.
+func WithDocsFormat(format DocsFormat) Option {
+	return func(g *generator) {
+		g.docsFormat = format
+	}
+}
+
+// WithMaskedSecrets generates a 'Secret' type, whose String method masks
+// its value, for the string fields of sensitive variables, e.g. 'DB_PASSWORD',
+// so that printing the 'Config' struct doesn't leak them. Variables are
+// sensitive when their names have words like 'PASSWORD', 'SECRET' or 'TOKEN',
+// or when annotated with '# sensitive' or '# vault'.
+func WithMaskedSecrets() Option {
+	return func(g *generator) {
+		g.maskSecrets = true
+	}
+}
+
+// WithValidatorTags makes the generated 'Validate' method, which checks the
+// '# min', '# max' and '# pattern' constraints annotated in the env file,
+// validate 'validate' struct tags with github.com/go-playground/validator
+// instead of hand-rolled checks.
+func WithValidatorTags() Option {
+	return func(g *generator) {
+		g.validatorTags = true
+	}
+}
+
+// WithPathChecks makes the generated 'Validate' method, called when reading
+// the configuration, check the paths held by variables named after them:
+// '_FILE' variables must be readable files, '_DIR' variables existing
+// directories and '_PATH' variables existing paths. It's opt-in since
+// some paths may only be created after the configuration is read.
+func WithPathChecks() Option {
+	return func(g *generator) {
+		g.checkPaths = true
+	}
+}
+
+// WithDebugHandler generates a 'Handler' function returning an HTTP
+// handler that serves the current configuration as JSON, e.g. at
+// '/debug/config', with the values of sensitive variables redacted.
+func WithDebugHandler() Option {
+	return func(g *generator) {
+		g.debugHandler = true
+	}
+}
+
+// WithMetrics generates a 'RegisterMetrics' function, registering
+// Prometheus metrics of the loads and reloads of the configuration.
+// It requires hot reload or SIGHUP reload.
+func WithMetrics() Option {
+	return func(g *generator) {
+		g.metrics = true
+	}
+}
+
+// WithTracing records OpenTelemetry spans of the reads of the
+// configuration, including the resolution of secrets, with attributes
+// that never hold the values of env vars. It implies WithContext, so
+// that contexts hold the parent spans.
+func WithTracing() Option {
+	return func(g *generator) {
+		g.tracing = true
+	}
+}
+
+// WithContext makes the functions reading the configuration take a
+// context as their first parameter, e.g. 'Read(ctx)', which is passed
+// to secrets managers and remote sources, so that reads honor its
+// cancellation and deadline.
+func WithContext() Option {
+	return func(g *generator) {
+		g.withContext = true
+	}
+}
+
+// WithFileSystem makes the generator read the env files from and write
+// the generated files to the given file system instead of the OS file
+// system, e.g. a MemFileSystem, to embed it in other tools.
+func WithFileSystem(fsys FileSystem) Option {
+	return func(g *generator) {
+		g.fs = fsys
+	}
+}
+
+// WithTemplateProcessor makes the generator parse its templates with
+// the given template processor instead of Go's text/template package.
+func WithTemplateProcessor(templateProcessor TemplateProcessor) Option {
+	return func(g *generator) {
+		g.templateProcessor = templateProcessor
+	}
+}
+
+// WithFormatter makes the generator format the generated Go files
+// with the given formatter instead of the default one, which
+// formats them the way goimports does.
+func WithFormatter(formatter Formatter) Option {
+	return func(g *generator) {
+		g.formatter = formatter
+	}
+}
+
+// withLineReader makes the generator read the lines of env files
+// with the line readers returned by the given function.
+func withLineReader(newLineReader func(r io.Reader) lineReader) Option {
+	return func(g *generator) {
+		g.newLineReader = newLineReader
+	}
+}
+
+// withGetwd makes the generator get the working dir,
+// which relative paths are resolved against, with the given function.
+func withGetwd(getwd func() (string, error)) Option {
+	return func(g *generator) {
+		g.getwd = getwd
+	}
+}
+
+// withNow makes the generator get the current time,
+// which dates the changelog entries, with the given function.
+func withNow(now func() time.Time) Option {
+	return func(g *generator) {
+		g.now = now
+	}
+}
+
+// WithStdin sets the reader the StdinEnvFile env file is read from.
+// Defaults to os.Stdin.
+func WithStdin(stdin io.Reader) Option {
+	return func(g *generator) {
+		g.stdin = stdin
+	}
+}
+
+// WithEmbeddedEnvFile generates a 'ReadEmbedded' function, which reads
+// configuration from env vars falling back to the values of an env file
+// embedded in the package with go:embed, so that binaries ship with
+// defaults. Sensitive variables are left out of the embedded env file.
+func WithEmbeddedEnvFile() Option {
+	return func(g *generator) {
+		g.embed = true
+	}
+}
+
+// WithOverrides generates a 'ReadWithOverrides' function, which reads
+// configuration from the given overrides, e.g. parsed from command-line
+// flags, env vars, the '.env' file and the defaults of 'Config', in this
+// order of precedence.
+func WithOverrides() Option {
+	return func(g *generator) {
+		g.overrides = true
+	}
+}
+
+// WithSecretFiles makes the generated package read the values of sensitive
+// variables, e.g. 'DB_PASSWORD', from the files whose paths are set by their
+// '_FILE' variants, e.g. 'DB_PASSWORD_FILE', as with the secrets Kubernetes
+// and Docker mount as files. '_FILE' variants take precedence.
+func WithSecretFiles() Option {
+	return func(g *generator) {
+		g.secretFiles = true
+	}
+}
+
+// WithMaxLineLength sets the maximum length, in bytes, of the lines of the
+// env files read, DefaultMaxLineLength by default. Reading env files fails
+// on longer lines, naming the variable they assign, if any. It also bounds
+// the lines of the Docker env files read by the generated package.
+func WithMaxLineLength(maxLineLength int) Option {
+	return func(g *generator) {
+		g.maxLineLength = maxLineLength
+	}
+}
+
+// WithSchemaChecksum makes the generated package pin the SHA-256 of its
+// config schema, i.e. its env vars and their types, and verify when reading
+// configuration that every env var of the schema is set, failing early on
+// misconfigured environments. 'SetStrictSchema' is also generated along with
+// WithPrefix, to reject unexpected env vars with the prefix as well.
+func WithSchemaChecksum() Option {
+	return func(g *generator) {
+		g.checksum = true
+	}
+}
+
+// WithStrict makes the generated package fail to read configuration when
+// env vars with the prefix set with WithPrefix, which it requires, don't map
+// to any field of the struct, e.g. misspelled ones. These are logged as
+// warnings instead when a logger is set with 'SetStrictLogger'.
+func WithStrict() Option {
+	return func(g *generator) {
+		g.strict = true
+	}
+}
+
+// WithAgeEnvFile generates a 'ReadFromAgeEnvFile' function, which reads
+// configuration from an env file encrypted with age, e.g. by EncryptEnvFile,
+// decrypting it in memory with the identities of the given identity file.
+func WithAgeEnvFile() Option {
+	return func(g *generator) {
+		g.ageEnvFile = true
+	}
+}
+
+// WithFlags generates a 'BindFlags' function, which registers a flag per
+// 'Config' field with the given flags library, e.g. Cobra. Flags are
+// named after their env vars in kebab-case and override them.
+func WithFlags(library FlagsLibrary) Option {
+	return func(g *generator) {
+		g.flagsLibrary = library
+	}
+}
+
+// WithFeatures generates a 'Features' type, holding the feature flags
+// read from the bool 'FEATURE_*' variables, with an 'IsEnabled' method
+// and an accessor per feature.
+func WithFeatures() Option {
+	return func(g *generator) {
+		g.withFeatures = true
+	}
+}
+
+// WithTestConfigPackage generates a '<packagename>test' package next to the
+// config package, with a 'NewTestConfig' function returning a 'Config'
+// prefilled with sample values, for the tests of the packages using it.
+// It can't be used along with WithImmutable.
+func WithTestConfigPackage() Option {
+	return func(g *generator) {
+		g.testConfigPackage = true
+	}
+}
+
+// WithConfigProvider generates a 'ConfigProvider' interface, with a getter
+// per 'Config' field, along with an implementation providing a given
+// configuration and 'ConfigProviderMock', a mock implementation.
+func WithConfigProvider() Option {
+	return func(g *generator) {
+		g.configProvider = true
+	}
+}
+
+// WithVerify makes the generator type-check the generated packages, unit
+// tests included, failing with their compilation errors, along with the
+// files and lines they're at, if they don't compile.
+func WithVerify() Option {
+	return func(g *generator) {
+		g.verify = true
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	otelFileName                 = "otel.go"
+	otelUnitTestFileName         = "otel_test.go"
+	otelFileTemplateName         = "otelFile"
+	otelUnitTestFileTemplateName = "otelUnitTestFile"
+	otelFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of the spans of configuration reads.
+const tracerName = "{{ if .ImportPath }}{{ .ImportPath }}{{ else }}{{ .ConfigReaderPkgName }}{{ end }}"
+
+// For ease of unit testing.
+var tracerProvider = otel.GetTracerProvider
+
+// startSpan starts a span of the given operation, e.g. '{{ .ReaderName }}', named
+// 'config.{{ .ReaderName }}', with the given attributes, which must never hold
+// values of env vars, as they may be secrets.
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracerProvider().Tracer(tracerName).Start(ctx, "config."+operation, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the given span, recording the given error, if any.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+`
+
+	otelUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpan(t *testing.T) {
+	testCases := []struct {
+		name               string
+		err                error
+		expectedStatusCode codes.Code
+	}{
+		{
+			name:               "happy path",
+			expectedStatusCode: codes.Unset,
+		},
+		{
+			name:               "error",
+			err:                errors.New("random error"),
+			expectedStatusCode: codes.Error,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracerProvider = func() trace.TracerProvider {
+				return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			}
+			_, span := startSpan(context.Background(), "{{ .ReaderName }}", attribute.String("config.env_file", ".env"))
+			endSpan(span, tc.err)
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			require.Equal(t, "config.{{ .ReaderName }}", spans[0].Name())
+			require.Equal(t, []attribute.KeyValue{attribute.String("config.env_file", ".env")}, spans[0].Attributes())
+			require.Equal(t, tc.expectedStatusCode, spans[0].Status().Code)
+		})
+	}
+}
+`
+)
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// packageDir returns the dir of the generated package, which is named
+// after it, in the output dir set with WithOutputDir, if any.
+func (g *generator) packageDir() string {
+	return filepath.Join(g.outputDir, g.packageName)
+}
+
+// outputPath returns the path of the given slash-separated file,
+// e.g. 'helm/values.yaml', in the output dir.
+func (g *generator) outputPath(fileName string) string {
+	return filepath.Join(g.outputDir, filepath.FromSlash(fileName))
+}
+
+// absPath returns the given path made absolute against the working dir.
+// On Windows, volume-rooted paths, like '\src\app', are made absolute
+// against the volume of the working dir, and paths relative to the
+// current dir of a volume, like 'C:app', against the working dir when
+// it's on that volume.
+func (g *generator) absPath(p string) (string, error) {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p), nil
+	}
+	wd, err := g.getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "getting working dir")
+	}
+	volume := filepath.VolumeName(p)
+	switch {
+	case volume == "" && p != "" && os.IsPathSeparator(p[0]):
+		return filepath.Clean(filepath.VolumeName(wd) + p), nil
+	case volume != "" && !strings.EqualFold(volume, filepath.VolumeName(wd)):
+		return "", errors.Errorf("can't resolve %s against working dir %s, which is on another volume", p, wd)
+	}
+	return filepath.Join(wd, p[len(volume):]), nil
+}
+
+// relPath returns the given path relative to the given dir, both made
+// absolute against the working dir, and slash-separated, as the paths
+// written into generated files are, so that they read the same on every
+// OS. Absolute paths are kept absolute, as well as the ones that can't
+// be made relative, e.g. because they're on another volume than the dir.
+func (g *generator) relPath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.ToSlash(filepath.Clean(p))
+	}
+	absDir, err := g.absPath(dir)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	absP, err := g.absPath(p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	rel, err := filepath.Rel(absDir, absP)
+	if err != nil {
+		return filepath.ToSlash(absP)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_absPath(t *testing.T) {
+	wd := filepath.FromSlash("/home/user/app")
+	if runtime.GOOS == "windows" {
+		wd = `C:\home\user\app`
+	}
+	testCases := []struct {
+		name           string
+		path           string
+		getwdErr       error
+		expectedOutput string
+		expectedError  error
+	}{
+		{name: "relative path", path: filepath.FromSlash("internal/config"), expectedOutput: filepath.Join(wd, "internal", "config")},
+		{name: "parent dir", path: filepath.FromSlash("../shared"), expectedOutput: filepath.Join(filepath.Dir(wd), "shared")},
+		{name: "absolute path", path: filepath.Join(wd, "internal", "..", "config"), expectedOutput: filepath.Join(wd, "config")},
+		{name: "error getting working dir", path: "internal", getwdErr: errors.New("getwd error"), expectedError: errors.New("getting working dir: getwd error")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getwd := func() (string, error) {
+				return wd, tc.getwdErr
+			}
+			g := NewGenerator("config", withGetwd(getwd)).(*generator)
+			output, err := g.absPath(tc.path)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func Test_absPathWindows(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("volumes only exist on Windows")
+	}
+	getwd := func() (string, error) {
+		return `C:\home\user\app`, nil
+	}
+	g := NewGenerator("config", withGetwd(getwd)).(*generator)
+	testCases := []struct {
+		name           string
+		path           string
+		expectedOutput string
+		expectedError  error
+	}{
+		{name: "relative path", path: `internal\config`, expectedOutput: `C:\home\user\app\internal\config`},
+		{name: "slash-separated path", path: "internal/config", expectedOutput: `C:\home\user\app\internal\config`},
+		{name: "absolute path", path: `D:\src\app`, expectedOutput: `D:\src\app`},
+		{name: "volume-rooted path", path: `\src\app`, expectedOutput: `C:\src\app`},
+		{name: "path relative to the working dir volume", path: `c:internal`, expectedOutput: `C:\home\user\app\internal`},
+		{name: "path relative to another volume", path: `D:internal`, expectedError: errors.New(`can't resolve D:internal against working dir C:\home\user\app, which is on another volume`)},
+		{name: "UNC path", path: `\\server\share\app`, expectedOutput: `\\server\share\app`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := g.absPath(tc.path)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func Test_relPath(t *testing.T) {
+	wd := filepath.FromSlash("/home/user/app")
+	if runtime.GOOS == "windows" {
+		wd = `C:\home\user\app`
+	}
+	getwd := func() (string, error) {
+		return wd, nil
+	}
+	g := NewGenerator("config", withGetwd(getwd)).(*generator)
+	testCases := []struct {
+		name           string
+		dir            string
+		path           string
+		expectedOutput string
+	}{
+		{name: "working dir", dir: "", path: filepath.FromSlash("env/.env"), expectedOutput: "env/.env"},
+		{name: "relative dir", dir: filepath.FromSlash("internal/config"), path: ".env", expectedOutput: "../../.env"},
+		{name: "absolute dir", dir: filepath.Join(wd, "internal"), path: filepath.FromSlash("internal/.env"), expectedOutput: ".env"},
+		{name: "absolute path", dir: "internal", path: filepath.Join(wd, ".env"), expectedOutput: filepath.ToSlash(filepath.Join(wd, ".env"))},
+	}
+	if runtime.GOOS == "windows" {
+		testCases = append(testCases,
+			struct {
+				name           string
+				dir            string
+				path           string
+				expectedOutput string
+			}{name: "volume-rooted dir", dir: `\home\user`, path: `internal\.env`, expectedOutput: "app/internal/.env"},
+			struct {
+				name           string
+				dir            string
+				path           string
+				expectedOutput string
+			}{name: "dir on another volume", dir: `D:\src`, path: `.env`, expectedOutput: "C:/home/user/app/.env"},
+		)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, g.relPath(tc.dir, tc.path))
+		})
+	}
+}
+
+func TestGenerateWithOutputDir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	testCases := []struct {
+		name             string
+		outputDir        string
+		envFilePath      string
+		expectedFiles    []string
+		expectedSource   string
+		expectedGenerate string
+	}{
+		{
+			name:             "relative output dir",
+			outputDir:        "internal",
+			envFilePath:      ".env",
+			expectedFiles:    []string{filepath.FromSlash("internal/appcfg/config.go"), filepath.FromSlash("internal/appcfg/config_test.go"), filepath.FromSlash("internal/systemd.env")},
+			expectedSource:   "// Source: ../.env sha256:",
+			expectedGenerate: "//go:generate goprojconfig generate -p appcfg -e ../../.env",
+		},
+		{
+			name:             "absolute output dir",
+			outputDir:        filepath.Join(wd, "internal"),
+			envFilePath:      filepath.Join(wd, "internal", ".env"),
+			expectedFiles:    []string{filepath.Join(wd, "internal", "appcfg", "config.go"), filepath.Join(wd, "internal", "appcfg", "config_test.go"), filepath.Join(wd, "internal", "systemd.env")},
+			expectedSource:   "// Source: " + filepath.ToSlash(filepath.Join(wd, "internal", ".env")) + " sha256:",
+			expectedGenerate: "//go:generate goprojconfig generate -p appcfg -e " + filepath.ToSlash(filepath.Join(wd, "internal", ".env")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{tc.envFilePath: []byte("PORT=8080\n")})
+			g := NewGenerator("appcfg", WithFileSystem(fsys), WithOutputDir(tc.outputDir), WithSystemd())
+			generatedFiles, err := g.GenerateConfigPackageFromEnvFile(tc.envFilePath)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedFiles, generatedFiles)
+			configFilePath := filepath.Join(tc.outputDir, "appcfg", "config.go")
+			configFile := string(fsys.Files()[filepath.ToSlash(configFilePath)])
+			require.Contains(t, configFile, tc.expectedSource)
+			require.Contains(t, configFile, tc.expectedGenerate)
+			stale, err := StaleSources(configFilePath, WithFileSystem(fsys))
+			require.NoError(t, err)
+			require.Empty(t, stale)
+		})
+	}
+}
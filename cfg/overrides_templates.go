@@ -0,0 +1,222 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	overridesFileName                 = "overrides.go"
+	overridesUnitTestFileName         = "overrides_test.go"
+	overridesFileTemplateName         = "overridesFile"
+	overridesUnitTestFileTemplateName = "overridesUnitTestFile"
+	overridesFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	stderrors "errors"
+	"io/fs"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// For ease of unit testing.
+var unsetenv = os.Unsetenv
+
+// {{ .ReaderName }}WithOverrides reads configuration merging, from the highest
+// to the lowest precedence:
+//
+//  1. the given overrides, keyed by env var name, e.g. the values of
+//     command-line flags or the ones injected by tests and tooling;
+//  2. env vars;
+//  3. the '.env' file present at current path, if any;
+//  4. the defaults of {{ .StructName }}.
+//
+// Overrides are set as env vars while configuration is read, then the
+// previous env vars are restored, so it shouldn't run concurrently with
+// other reads.
+func {{ .ReaderName }}WithOverrides({{ if .Context }}ctx context.Context, {{ end }}overrides map[string]string) ({{ if .Tracing }}_ *{{ .StructName }}, err error{{ else }}*{{ .StructName }}, error{{ end }}) {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "{{ .ReaderName }}WithOverrides")
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
+	restore, err := setOverrides(overrides)
+	defer restore()
+	if err != nil {
+		return nil, errors.Wrap(err, "setting overrides")
+	}
+	if err := godotenvLoad(); err != nil && !stderrors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Wrap(err, "loading env vars from .env file")
+	}
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
+		return nil, errors.Wrap(err, "processing env vars")
+	}
+	return config, nil
+}
+
+// setOverrides sets the given overrides as env vars, returning a
+// function that restores the env vars they replaced, which must be
+// called even if an error is returned.
+func setOverrides(overrides map[string]string) (func(), error) {
+	var restorers []func()
+	restore := func() {
+		for i := len(restorers) - 1; i >= 0; i-- {
+			restorers[i]()
+		}
+	}
+	for key, value := range overrides {
+		previous, ok := lookupEnv(key)
+		if err := setenv(key, value); err != nil {
+			return restore, errors.Wrapf(err, "setting %s", key)
+		}
+		restorers = append(restorers, func() {
+			if ok {
+				setenv(key, previous)
+				return
+			}
+			unsetenv(key)
+		})
+	}
+	return restore, nil
+}
+`
+
+	overridesUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test{{ .ReaderName }}WithOverrides(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		env                    map[string]string
+		overrides              map[string]string
+		mockedGodotenvLoad     func(filenames ...string) error
+		mockedSetenv           func(key, value string) error
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedProcessedEnv   map[string]string
+		expectedError          error
+	}{
+		{
+			name:      "happy path",
+			env:       map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432"},
+			overrides: map[string]string{"DB_PORT": "6543", "DB_NAME": "test"},
+			mockedGodotenvLoad: func(filenames ...string) error {
+				return nil
+			},
+			expectedProcessedEnv: map[string]string{"DB_HOST": "localhost", "DB_PORT": "6543", "DB_NAME": "test"},
+		},
+		{
+			name:      "missing .env file",
+			overrides: map[string]string{"DB_HOST": "localhost"},
+			mockedGodotenvLoad: func(filenames ...string) error {
+				return fs.ErrNotExist
+			},
+			expectedProcessedEnv: map[string]string{"DB_HOST": "localhost"},
+		},
+		{
+			name:      "error setting override",
+			env:       map[string]string{"DB_HOST": "localhost"},
+			overrides: map[string]string{"DB_PORT": "6543"},
+			mockedSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("setting overrides: setting DB_PORT: random error"),
+		},
+		{
+			name:      "error loading env vars",
+			overrides: map[string]string{"DB_HOST": "localhost"},
+			mockedGodotenvLoad: func(filenames ...string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("loading env vars from .env file: random error"),
+		},
+		{
+			name:      "error processing env vars",
+			env:       map[string]string{"DB_HOST": "localhost"},
+			overrides: map[string]string{"DB_HOST": "remotehost"},
+			mockedGodotenvLoad: func(filenames ...string) error {
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := make(map[string]string)
+			initialEnv := make(map[string]string)
+			for key, value := range tc.env {
+				env[key] = value
+				initialEnv[key] = value
+			}
+			lookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			setenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			if tc.mockedSetenv != nil {
+				setenv = tc.mockedSetenv
+			}
+			unsetenv = func(key string) error {
+				delete(env, key)
+				return nil
+			}
+			godotenvLoad = tc.mockedGodotenvLoad
+			var processedEnv map[string]string
+			envconfigProcess = func(prefix string, spec interface{}) error {
+				if processedEnv == nil {
+					processedEnv = make(map[string]string)
+					for key, value := range env {
+						processedEnv[key] = value
+					}
+				}
+				if tc.mockedEnvconfigProcess != nil {
+					return tc.mockedEnvconfigProcess(prefix, spec)
+				}
+				return nil
+			}
+			config, err := {{ .ReaderName }}WithOverrides({{ if .Context }}context.Background(), {{ end }}tc.overrides)
+			require.Equal(t, initialEnv, env)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedProcessedEnv, processedEnv)
+			}
+		})
+	}
+}
+`
+)
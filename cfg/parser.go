@@ -0,0 +1,415 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// envVar represents a variable definition parsed from an env file.
+type envVar struct {
+	// key is the variable name, as defined in the env file.
+	key string
+	// value is the variable value, with quotes removed and
+	// variable references resolved.
+	value string
+	// line is the line number where the variable is defined.
+	line int
+	// comment holds the comment lines right above the variable
+	// definition, followed by its inline comment, if any.
+	comment string
+	// inferredType, if set, is the type inferred from value beyond
+	// the default inference, e.g. '[]string' for delimited values.
+	inferredType string
+}
+
+// parseEnvFile parses the variable definitions read by the given lineReader.
+// Blank lines and lines without an assignment are skipped, and comments
+// are attached to the variable they precede or follow on the same line.
+// References to other variables in values ('${VAR}' and '$VAR') are
+// resolved against the given values, which hold the variables already
+// known, e.g. from previously parsed env files, and get updated with
+// the parsed ones. Lines are streamed, so that the allocations of large
+// env files, like dumps of container environments, grow linearly with
+// their size, multiline values included.
+func parseEnvFile(lineReader lineReader, values map[string]string) ([]envVar, error) {
+	var vars []envVar
+	var comments []string
+	lineNumber := 0
+	for lineReader.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(lineReader.Text())
+		if line == "" {
+			comments = nil
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			comments = append(comments, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+		key, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			comments = nil
+			continue // skip invalid lines.
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		keyLine := lineNumber
+		rawValue = strings.TrimSpace(rawValue)
+		if isUnterminatedQuotedValue(rawValue) {
+			// Only the continuation lines are looked at for the closing
+			// quote, as escapes don't span lines.
+			var sb strings.Builder
+			sb.WriteString(rawValue)
+			for closed := false; !closed; {
+				if !lineReader.Scan() {
+					if err := lineReader.Err(); err != nil {
+						return nil, errors.Wrapf(err, "scanning quoted value of %s", key)
+					}
+					return nil, &EnvParseError{Line: keyLine, msg: fmt.Sprintf("line %d: unterminated quoted value for %s", keyLine, key)}
+				}
+				lineNumber++
+				text := lineReader.Text()
+				sb.WriteByte('\n')
+				sb.WriteString(text)
+				closed = quoteIndex(text, rawValue[0]) >= 0
+			}
+			rawValue = sb.String()
+		}
+		value, inlineComment := parseValue(rawValue, values)
+		if inlineComment != "" {
+			comments = append(comments, inlineComment)
+		}
+		values[key] = value
+		vars = append(vars, envVar{key: key, value: value, line: keyLine, comment: strings.Join(comments, "\n")})
+		comments = nil
+	}
+	if err := lineReader.Err(); err != nil {
+		return nil, errors.Wrap(err, "scanning")
+	}
+	return vars, nil
+}
+
+// mergeEnvVars merges the given variables, with overrides taking
+// precedence over base. Overridden variables keep their original
+// position, while new ones are appended.
+func mergeEnvVars(base, overrides []envVar) []envVar {
+	merged := append([]envVar(nil), base...)
+	index := make(map[string]int, len(merged))
+	for i, v := range merged {
+		index[v.key] = i
+	}
+	for _, v := range overrides {
+		i, ok := index[v.key]
+		if !ok {
+			index[v.key] = len(merged)
+			merged = append(merged, v)
+			continue
+		}
+		if v.comment == "" {
+			v.comment = merged[i].comment
+		}
+		merged[i] = v
+	}
+	return merged
+}
+
+// validateEnvVars reports duplicate variables and variables whose names
+// map to the same struct field name, which would otherwise generate
+// a struct that fails to compile, as well as variables annotated with
+// unsupported types or with values that don't match their types, their
+// time layouts, their enums or their constraints.
+func validateEnvVars(vars []envVar, fieldName func(envKey string) string) error {
+	var problems []string
+	firstProblemLine := 0
+	addProblem := func(line int, format string, args ...interface{}) {
+		if len(problems) == 0 {
+			firstProblemLine = line
+		}
+		problems = append(problems, fmt.Sprintf("line %d: "+format, append([]interface{}{line}, args...)...))
+	}
+	keys := make(map[string]envVar)
+	fields := make(map[string]envVar)
+	for _, v := range vars {
+		if first, ok := keys[v.key]; ok {
+			addProblem(v.line, "duplicate variable %s, first defined at line %d", v.key, first.line)
+			continue
+		}
+		keys[v.key] = v
+		name := fieldName(v.key)
+		if first, ok := fields[name]; ok {
+			addProblem(v.line, "variable %s conflicts with %s (line %d), both map to field %s", v.key, first.key, first.line, name)
+			continue
+		}
+		fields[name] = v
+		layout, hasLayout := timeLayout(v)
+		if t, ok := annotationValue(v.comment, "type"); ok {
+			switch {
+			case !annotatedTypes[t]:
+				addProblem(v.line, "unsupported type %s for variable %s", t, v.key)
+			case hasLayout && t != "time.Time":
+				addProblem(v.line, "layout given for variable %s of type %s", v.key, t)
+			case v.value != "" && !hasLayout:
+				if err := checkValue(t, v.value); err != nil {
+					addProblem(v.line, "invalid value %q for variable %s of type %s: %v", v.value, v.key, t, err)
+				}
+			}
+		}
+		if hasLayout && v.value != "" {
+			if _, err := time.Parse(layout, v.value); err != nil {
+				addProblem(v.line, "invalid value %q for variable %s with layout %s", v.value, v.key, layout)
+			}
+		}
+		if values, ok := enumValues(v); ok {
+			validateEnumValues(v, values, fieldName, addProblem)
+		}
+		if c := variableConstraints(v); !c.isEmpty() {
+			validateConstraints(v, c, addProblem)
+		}
+	}
+	if len(problems) > 0 {
+		return &EnvParseError{Line: firstProblemLine, msg: strings.Join(problems, "; ")}
+	}
+	return nil
+}
+
+// validateEnumValues reports, with the given addProblem function, enum
+// annotations of variables that aren't strings, without values or with
+// values that map to the same constant name, as well as variable values
+// that aren't among the given enum values.
+func validateEnumValues(v envVar, values []string, fieldName func(envKey string) string, addProblem func(line int, format string, args ...interface{})) {
+	if t := fieldType(v); t != "string" {
+		addProblem(v.line, "enum given for variable %s of type %s", v.key, t)
+		return
+	}
+	if len(values) == 0 {
+		addProblem(v.line, "no enum values for variable %s", v.key)
+		return
+	}
+	constants := make(map[string]string, len(values))
+	for _, value := range values {
+		suffix := enumConstantSuffix(value, fieldName)
+		if suffix == "" {
+			addProblem(v.line, "invalid enum value %q for variable %s", value, v.key)
+			continue
+		}
+		if first, ok := constants[suffix]; ok {
+			addProblem(v.line, "enum values %q and %q of variable %s map to the same constant", first, value, v.key)
+			continue
+		}
+		constants[suffix] = value
+	}
+	if v.value == "" {
+		return
+	}
+	for _, value := range values {
+		if v.value == value {
+			return
+		}
+	}
+	addProblem(v.line, "invalid value %q for variable %s, must be one of: %s", v.value, v.key, strings.Join(values, ", "))
+}
+
+// parseValue removes surrounding quotes and inline comments from the given
+// raw value and resolves variable references, returning the value and its
+// inline comment. Single quoted values are taken literally, as godotenv
+// does, while double quoted values may contain escaped quotes and newlines.
+func parseValue(rawValue string, values map[string]string) (string, string) {
+	if len(rawValue) >= 2 {
+		switch quote := rawValue[0]; quote {
+		case '\'':
+			if end := closingQuoteIndex(rawValue); end > 0 {
+				return rawValue[1:end], inlineComment(rawValue[end+1:])
+			}
+		case '"':
+			if end := closingQuoteIndex(rawValue); end > 0 {
+				value := rawValue[1:end]
+				if strings.IndexByte(value, '\\') >= 0 {
+					value = unescapeDoubleQuoted(value)
+				}
+				return expandVars(value, values), inlineComment(rawValue[end+1:])
+			}
+		}
+	}
+	if strings.HasPrefix(rawValue, "#") {
+		return "", inlineComment(rawValue)
+	}
+	var comment string
+	if i := strings.Index(rawValue, " #"); i >= 0 {
+		comment = inlineComment(rawValue[i:])
+		rawValue = strings.TrimSpace(rawValue[:i])
+	}
+	return expandVars(rawValue, values), comment
+}
+
+// unescapeDoubleQuoted unescapes the given double quoted value the way
+// godotenv does, so that the generated defaults match the values read at
+// runtime: '\n' and '\r' become newlines and carriage returns, and then
+// any other backslash is dropped, keeping the character it escapes, e.g.
+// '\\' becomes '\' and '\"' becomes '"'. '\$' is kept for expandVars.
+func unescapeDoubleQuoted(value string) string {
+	var sb strings.Builder
+	sb.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) || value[i+1] == '\n' {
+			sb.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(value[i])
+		}
+	}
+	value = sb.String()
+	sb.Reset()
+	sb.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && value[i+1] != '$' {
+			i++
+		}
+		sb.WriteByte(value[i])
+	}
+	return sb.String()
+}
+
+// inlineComment returns the comment found in the given
+// remainder of a line, if any.
+func inlineComment(remainder string) string {
+	i := strings.IndexByte(remainder, '#')
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(remainder[i+1:])
+}
+
+// hasAnnotation reports whether the given comment has a line
+// consisting of the given annotation, e.g. '# optional'.
+func hasAnnotation(comment, annotation string) bool {
+	for _, line := range strings.Split(comment, "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), annotation) {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationValue returns the value of the first line of the given comment
+// consisting of the given annotation followed by a colon and a value,
+// e.g. '# vault: secret/data/db#password'.
+func annotationValue(comment, annotation string) (string, bool) {
+	for _, line := range strings.Split(comment, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), annotation) {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
+
+// closingQuoteIndex returns the index of the quote that closes the
+// quoted raw value, or -1 if there's none. Quotes escaped with a
+// backslash don't close double quoted values.
+func closingQuoteIndex(rawValue string) int {
+	if i := quoteIndex(rawValue[1:], rawValue[0]); i >= 0 {
+		return i + 1
+	}
+	return -1
+}
+
+// quoteIndex returns the index of the first occurrence of the given quote
+// in s that isn't escaped with a backslash, which only escapes double
+// quotes, or -1 if there's none.
+func quoteIndex(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// isUnterminatedQuotedValue reports whether the given raw value opens
+// a quoted value that continues on the following lines.
+func isUnterminatedQuotedValue(rawValue string) bool {
+	if rawValue == "" || (rawValue[0] != '"' && rawValue[0] != '\'') {
+		return false
+	}
+	return !isClosedQuotedValue(rawValue)
+}
+
+// isClosedQuotedValue reports whether the given quoted raw value
+// has its closing quote.
+func isClosedQuotedValue(rawValue string) bool {
+	return closingQuoteIndex(rawValue) > 0
+}
+
+// expandVars replaces '${VAR}' and '$VAR' references in the given value
+// with the correspondent values. References to unknown variables are
+// replaced by an empty string, and '\$' escapes a literal dollar sign.
+func expandVars(value string, values map[string]string) string {
+	if strings.IndexByte(value, '$') < 0 {
+		return value
+	}
+	var sb strings.Builder
+	sb.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			sb.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 == len(value) {
+			sb.WriteByte(c)
+			continue
+		}
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				sb.WriteByte(c)
+				continue
+			}
+			sb.WriteString(values[value[i+2:i+2+end]])
+			i += end + 2
+			continue
+		}
+		end := i + 1
+		for end < len(value) && isVarNameChar(value[end]) {
+			end++
+		}
+		if end == i+1 {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteString(values[value[i+1:end]])
+		i = end - 1
+	}
+	return sb.String()
+}
+
+// isVarNameChar reports whether c may be part of a variable name.
+func isVarNameChar(c byte) bool {
+	return c == '_' ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		('0' <= c && c <= '9')
+}
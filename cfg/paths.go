@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"strings"
+)
+
+// pathCheck describes how the values of path variables are checked.
+type pathCheck struct {
+	// suffix is the suffix of the names of the variables it checks.
+	suffix string
+	// function is the generated function checking values.
+	function string
+	// tag is the validator tag checking values with function.
+	tag string
+}
+
+// pathChecks are the checks of the values of path variables, which must
+// be readable files for '_FILE' variables, existing directories for
+// '_DIR' variables and existing paths for '_PATH' variables.
+var pathChecks = []pathCheck{
+	{suffix: "_FILE", function: "checkFile", tag: "readable_file"},
+	{suffix: "_DIR", function: "checkDir", tag: "existing_dir"},
+	{suffix: "_PATH", function: "checkPath", tag: "existing_path"},
+}
+
+// variablePathCheck returns the check of the value of the given
+// variable, if path checks are enabled and it's a path variable.
+func (g *generator) variablePathCheck(v envVar) (pathCheck, bool) {
+	fieldType, _ := g.structField(v)
+	return g.fieldPathCheck(v, fieldType)
+}
+
+// fieldPathCheck returns the check of the value of the field of the
+// given type generated for the given variable, if path checks are
+// enabled and it's a path variable of a string type.
+func (g *generator) fieldPathCheck(v envVar, fieldType string) (pathCheck, bool) {
+	if !g.checkPaths {
+		return pathCheck{}, false
+	}
+	switch fieldType {
+	case "string", optionalFieldType, secretTypeName, "*" + secretTypeName:
+	default:
+		return pathCheck{}, false
+	}
+	for _, check := range pathChecks {
+		if strings.HasSuffix(strings.ToUpper(v.key), check.suffix) {
+			return check, true
+		}
+	}
+	return pathCheck{}, false
+}
+
+// hasPathChecks reports whether the values of any of
+// the given variables are checked as paths.
+func (g *generator) hasPathChecks(vars []envVar) bool {
+	for _, v := range vars {
+		if _, ok := g.variablePathCheck(v); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	pathsFileName                 = "paths.go"
+	pathsUnitTestFileName         = "paths_test.go"
+	pathsFileTemplateName         = "pathsFile"
+	pathsUnitTestFileTemplateName = "pathsUnitTestFile"
+	pathsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"fmt"
+	"os"
+)
+
+// For ease of unit testing.
+var (
+	checkFile = checkReadableFile
+	checkDir  = checkDirectory
+	checkPath = checkExistingPath
+)
+
+// checkReadableFile checks that the file at the given path can be read.
+func checkReadableFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// checkDirectory checks that the given path is an existing directory.
+func checkDirectory(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+// checkExistingPath checks that the given path exists.
+func checkExistingPath(path string) error {
+	_, err := os.Stat(path)
+	return err
+}
+`
+
+	pathsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("content"), 0644))
+	missing := filepath.Join(dir, "missing")
+	testCases := []struct {
+		name          string
+		check         func(path string) error
+		path          string
+		expectedError bool
+	}{
+		{name: "readable file", check: checkReadableFile, path: file},
+		{name: "missing file", check: checkReadableFile, path: missing, expectedError: true},
+		{name: "directory", check: checkDirectory, path: dir},
+		{name: "file instead of directory", check: checkDirectory, path: file, expectedError: true},
+		{name: "missing directory", check: checkDirectory, path: missing, expectedError: true},
+		{name: "existing path", check: checkExistingPath, path: dir},
+		{name: "missing path", check: checkExistingPath, path: missing, expectedError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.check(tc.path)
+			if tc.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+`
+)
@@ -0,0 +1,38 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fieldPathCheck(t *testing.T) {
+	testCases := []struct {
+		name           string
+		v              envVar
+		fieldType      string
+		checkPaths     bool
+		expectedOutput string
+	}{
+		{name: "file", v: envVar{key: "CERT_FILE"}, fieldType: "string", checkPaths: true, expectedOutput: "checkFile"},
+		{name: "directory", v: envVar{key: "DATA_DIR"}, fieldType: "string", checkPaths: true, expectedOutput: "checkDir"},
+		{name: "path", v: envVar{key: "log_path"}, fieldType: "string", checkPaths: true, expectedOutput: "checkPath"},
+		{name: "optional", v: envVar{key: "DATA_DIR"}, fieldType: optionalFieldType, checkPaths: true, expectedOutput: "checkDir"},
+		{name: "secret", v: envVar{key: "TOKEN_FILE"}, fieldType: secretTypeName, checkPaths: true, expectedOutput: "checkFile"},
+		{name: "not a string", v: envVar{key: "MAX_DIR"}, fieldType: "int", checkPaths: true},
+		{name: "not a path", v: envVar{key: "FILE_NAME"}, fieldType: "string", checkPaths: true},
+		{name: "path checks disabled", v: envVar{key: "CERT_FILE"}, fieldType: "string"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &generator{checkPaths: tc.checkPaths}
+			check, ok := g.fieldPathCheck(tc.v, tc.fieldType)
+			require.Equal(t, tc.expectedOutput != "", ok)
+			require.Equal(t, tc.expectedOutput, check.function)
+		})
+	}
+}
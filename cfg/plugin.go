@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Plugin extends the generation of the config package from env files,
+// e.g. to add struct tags, generate extra files or validate variables
+// against custom rules, without forking the templates. Plugins are set
+// with WithPlugins, and their hooks are called in that order.
+type Plugin interface {
+	// AfterParse is called with the variables parsed from the env files,
+	// once they're filtered, merged and sorted. Returning an error, e.g.
+	// because a variable breaks a custom rule, fails the generation.
+	AfterParse(vars []Variable) error
+	// MutateStruct is called with the model of the struct holding the
+	// configuration values before it's generated, so that its fields
+	// can be changed, e.g. to add struct tags.
+	MutateStruct(model *StructModel) error
+	// ExtraFiles returns the files to be generated in the package dir
+	// along with the config package.
+	ExtraFiles() ([]ExtraFile, error)
+}
+
+// BasePlugin implements Plugin with hooks that do nothing,
+// so that plugins can embed it and implement only the
+// hooks they need.
+type BasePlugin struct{}
+
+// AfterParse does nothing.
+func (BasePlugin) AfterParse(vars []Variable) error {
+	return nil
+}
+
+// MutateStruct does nothing.
+func (BasePlugin) MutateStruct(model *StructModel) error {
+	return nil
+}
+
+// ExtraFiles returns no files.
+func (BasePlugin) ExtraFiles() ([]ExtraFile, error) {
+	return nil, nil
+}
+
+// StructModel is the model of a generated struct.
+type StructModel struct {
+	// Name is the name of the struct, e.g. 'Config'.
+	Name string
+	// Fields are the fields of the struct, in declaration order.
+	Fields []StructField
+}
+
+// StructField is a field of a generated struct.
+type StructField struct {
+	// Name is the name of the field, e.g. 'DbHost'.
+	Name string
+	// Type is the Go type of the field, e.g. 'string'.
+	Type string
+	// Tags are the struct tags of the field, without
+	// backquotes, e.g. 'envconfig:"DB_HOST" required:"true"'.
+	Tags string
+	// Doc is the comment written above the field, if any.
+	Doc string
+	// Comment is the comment written after the field, if any.
+	Comment string
+	// EnvVar is the env var the field is read from, if any.
+	EnvVar string
+}
+
+// ExtraFile is a file generated by a plugin.
+type ExtraFile struct {
+	// Name is the slash-separated path of the file,
+	// relative to the package dir, e.g. 'config.yaml'.
+	Name string
+	// Content is the content of the file. Go files
+	// are formatted once written.
+	Content []byte
+}
+
+// afterParse calls the AfterParse hook of the plugins with the given variables.
+func (g *generator) afterParse(vars []envVar) error {
+	for _, p := range g.plugins {
+		if err := p.AfterParse(variables(vars)); err != nil {
+			return errors.Wrapf(err, "plugin %T", p)
+		}
+	}
+	return nil
+}
+
+// mutateStruct calls the MutateStruct hook of the plugins with the given model.
+func (g *generator) mutateStruct(model *StructModel) error {
+	for _, p := range g.plugins {
+		if err := p.MutateStruct(model); err != nil {
+			return errors.Wrapf(err, "plugin %T", p)
+		}
+	}
+	return nil
+}
+
+// generateExtraFiles writes the extra files of the plugins
+// into the package dir, returning their paths.
+func (g *generator) generateExtraFiles() ([]string, error) {
+	var paths []string
+	for _, p := range g.plugins {
+		files, err := p.ExtraFiles()
+		if err != nil {
+			return nil, errors.Wrapf(err, "plugin %T", p)
+		}
+		for _, f := range files {
+			name := path.Clean(f.Name)
+			if f.Name == "" || path.IsAbs(name) || filepath.IsAbs(f.Name) || name == ".." || strings.HasPrefix(name, "../") {
+				return nil, errors.Errorf("plugin %T: invalid extra file name %q: it must be relative to the package dir", p, f.Name)
+			}
+			filePath := filepath.Join(g.packageDir(), filepath.FromSlash(name))
+			if err := writeArtifact(g.fs, filePath, string(f.Content)); err != nil {
+				return nil, err
+			}
+			if strings.HasSuffix(name, ".go") {
+				if err := g.formatGoFile(filePath); err != nil {
+					return nil, err
+				}
+			}
+			paths = append(paths, filePath)
+		}
+	}
+	return paths, nil
+}
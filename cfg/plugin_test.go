@@ -0,0 +1,159 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// jsonTagsPlugin adds json tags to the fields of the
+// generated struct and generates a listing of the variables.
+type jsonTagsPlugin struct {
+	BasePlugin
+	vars []Variable
+}
+
+func (p *jsonTagsPlugin) AfterParse(vars []Variable) error {
+	p.vars = vars
+	return nil
+}
+
+func (p *jsonTagsPlugin) MutateStruct(model *StructModel) error {
+	for i, f := range model.Fields {
+		model.Fields[i].Tags += fmt.Sprintf(" json:%q", strings.ToLower(f.EnvVar))
+	}
+	model.Fields[0].Doc = "Port is the port to listen on."
+	return nil
+}
+
+func (p *jsonTagsPlugin) ExtraFiles() ([]ExtraFile, error) {
+	var sb strings.Builder
+	sb.WriteString("package appcfg\n\nvar envVars = []string{\n")
+	for _, v := range p.vars {
+		fmt.Fprintf(&sb, "%q,\n", v.Name)
+	}
+	sb.WriteString("}\n")
+	return []ExtraFile{
+		{Name: "envvars.go", Content: []byte(sb.String())},
+		{Name: "docs/vars.txt", Content: []byte("PORT\n")},
+	}, nil
+}
+
+// mockPlugin is a plugin whose hooks return the given errors and files.
+type mockPlugin struct {
+	afterParseErr   error
+	mutateStructErr error
+	extraFiles      []ExtraFile
+	extraFilesErr   error
+}
+
+func (p *mockPlugin) AfterParse(vars []Variable) error {
+	return p.afterParseErr
+}
+
+func (p *mockPlugin) MutateStruct(model *StructModel) error {
+	return p.mutateStructErr
+}
+
+func (p *mockPlugin) ExtraFiles() ([]ExtraFile, error) {
+	return p.extraFiles, p.extraFilesErr
+}
+
+func TestGenerateWithPlugins(t *testing.T) {
+	testCases := []struct {
+		name               string
+		opts               []Option
+		expectedConfigFile []string
+	}{
+		{
+			name: "mutable struct",
+			expectedConfigFile: []string{
+				"\t// Port is the port to listen on.\n\tPort   int    `envconfig:\"PORT\" required:\"true\" json:\"port\"`\n",
+				"\tDbHost string `envconfig:\"DB_HOST\" required:\"true\" json:\"db_host\"`\n",
+			},
+		},
+		{
+			name: "immutable struct",
+			opts: []Option{WithImmutable()},
+			expectedConfigFile: []string{
+				"type configValues struct {",
+				"\tPort   int    `envconfig:\"PORT\" required:\"true\" json:\"port\"`\n",
+				"// DbHost returns the value of the DB_HOST env var.\nfunc (c *Config) DbHost() string {",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte("PORT=8080\nDB_HOST=localhost\n")})
+			opts := append([]Option{WithFileSystem(fsys), WithPlugins(&jsonTagsPlugin{})}, tc.opts...)
+			g := NewGenerator("appcfg", opts...)
+			generatedFiles, err := g.GenerateConfigPackageFromEnvFile(".env")
+			require.NoError(t, err)
+			require.Contains(t, generatedFiles, "appcfg/envvars.go")
+			require.Contains(t, generatedFiles, "appcfg/docs/vars.txt")
+			files := fsys.Files()
+			for _, s := range tc.expectedConfigFile {
+				require.Contains(t, string(files["appcfg/config.go"]), s)
+			}
+			require.Equal(t, "package appcfg\n\nvar envVars = []string{\n\t\"PORT\",\n\t\"DB_HOST\",\n}\n", string(files["appcfg/envvars.go"]))
+			require.Equal(t, "PORT\n", string(files["appcfg/docs/vars.txt"]))
+		})
+	}
+}
+
+func TestGenerateWithPluginsErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		plugin        *mockPlugin
+		expectedError error
+	}{
+		{
+			name:          "error after parsing",
+			plugin:        &mockPlugin{afterParseErr: errors.New("random error")},
+			expectedError: errors.New("plugin *cfg.mockPlugin: random error"),
+		},
+		{
+			name:          "error mutating struct",
+			plugin:        &mockPlugin{mutateStructErr: errors.New("random error")},
+			expectedError: errors.New("plugin *cfg.mockPlugin: random error"),
+		},
+		{
+			name:          "error getting extra files",
+			plugin:        &mockPlugin{extraFilesErr: errors.New("random error")},
+			expectedError: errors.New("plugin *cfg.mockPlugin: random error"),
+		},
+		{
+			name:          "extra file outside the package dir",
+			plugin:        &mockPlugin{extraFiles: []ExtraFile{{Name: "../main.go"}}},
+			expectedError: errors.New(`plugin *cfg.mockPlugin: invalid extra file name "../main.go": it must be relative to the package dir`),
+		},
+		{
+			name:          "extra file with absolute path",
+			plugin:        &mockPlugin{extraFiles: []ExtraFile{{Name: "/etc/app.conf"}}},
+			expectedError: errors.New(`plugin *cfg.mockPlugin: invalid extra file name "/etc/app.conf": it must be relative to the package dir`),
+		},
+		{
+			name:          "extra file with invalid Go code",
+			plugin:        &mockPlugin{extraFiles: []ExtraFile{{Name: "extra.go", Content: []byte("package appcfg\n\nfunc {")}}},
+			expectedError: errors.New("formating go file appcfg/extra.go: 3:6: expected 'IDENT', found '{'"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte("PORT=8080\n")})
+			g := NewGenerator("appcfg", WithFileSystem(fsys), WithPlugins(tc.plugin))
+			_, err := g.GenerateConfigPackageFromEnvFile(".env")
+			if err == nil {
+				t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+			}
+			require.Equal(t, tc.expectedError.Error(), err.Error())
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"go/token"
+	"strings"
+)
+
+// envPrefix returns the prefix given to 'envconfig.Process' by the
+// generated package, e.g. 'APP' when the prefix is set to 'APP_'.
+func (g *generator) envPrefix() string {
+	return strings.TrimSuffix(g.prefix, "_")
+}
+
+// hasPrefix reports whether the given env var name has the prefix
+// of this generator, if any.
+func (g *generator) hasPrefix(key string) bool {
+	return g.prefix == "" || strings.HasPrefix(key, g.envPrefix()+"_")
+}
+
+// unprefixedKey returns the given env var name without the prefix of this
+// generator, e.g. 'DB_HOST' for 'APP_DB_HOST', which is the name given
+// to the 'envconfig' tag of the correspondent field.
+func (g *generator) unprefixedKey(key string) string {
+	if g.prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, g.envPrefix()+"_")
+}
+
+// prefixedKey returns the name of the env var read for the given one,
+// with the prefix of this generator, if any, e.g. 'APP_DB_HOST' for
+// both 'DB_HOST' and 'APP_DB_HOST'.
+func (g *generator) prefixedKey(key string) string {
+	if g.prefix == "" {
+		return key
+	}
+	return g.envPrefix() + "_" + g.unprefixedKey(key)
+}
+
+// fieldName returns the name of the field generated for the
+// given env var, leaving out the prefix of this generator.
+func (g *generator) fieldName(key string) string {
+	return g.fieldNamer(g.unprefixedKey(key))
+}
+
+// isValidPrefix reports whether the given prefix can prefix env var names.
+func isValidPrefix(prefix string) bool {
+	return token.IsIdentifier(strings.TrimSuffix(prefix, "_"))
+}
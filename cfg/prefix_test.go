@@ -0,0 +1,49 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fieldName(t *testing.T) {
+	testCases := []struct {
+		name              string
+		opts              []Option
+		key               string
+		expectedKey       string
+		expectedFieldName string
+	}{
+		{
+			name:              "no prefix",
+			key:               "APP_DB_HOST",
+			expectedKey:       "APP_DB_HOST",
+			expectedFieldName: "AppDbHost",
+		},
+		{
+			name:              "prefix with trailing underscore",
+			opts:              []Option{WithPrefix("APP_")},
+			key:               "APP_DB_HOST",
+			expectedKey:       "DB_HOST",
+			expectedFieldName: "DbHost",
+		},
+		{
+			name:              "prefix without trailing underscore",
+			opts:              []Option{WithPrefix("APP"), WithFieldNamer(GolintFieldNamer)},
+			key:               "APP_API_URL",
+			expectedKey:       "API_URL",
+			expectedFieldName: "APIURL",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			require.Equal(t, tc.expectedKey, g.unprefixedKey(tc.key))
+			require.Equal(t, tc.expectedFieldName, g.fieldName(tc.key))
+		})
+	}
+}
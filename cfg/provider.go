@@ -0,0 +1,59 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"sort"
+	"strings"
+)
+
+// providerMethod describes a getter of the generated ConfigProvider
+// interface, returning the value of a Config field.
+type providerMethod struct {
+	// Name is the method name, e.g. 'GetDbHost'.
+	Name string
+	// Field is the name of the Config field the method returns.
+	Field string
+	// Type is the Go type of the field.
+	Type string
+	// EnvVar is the env var the field is read from.
+	EnvVar string
+}
+
+// providerGetters returns the getters of the generated ConfigProvider
+// interface, one per field generated for the given variables.
+func (g *generator) providerGetters(vars []envVar) []providerMethod {
+	var methods []providerMethod
+	for _, v := range vars {
+		fieldName := g.fieldName(v.key)
+		fieldType, _ := g.structField(v)
+		if fieldType == "" {
+			fieldType = defaultFieldType
+		}
+		methods = append(methods, providerMethod{
+			Name:   "Get" + fieldName,
+			Field:  fieldName,
+			Type:   fieldType,
+			EnvVar: v.key,
+		})
+	}
+	return methods
+}
+
+// providerImports returns the packages imported by the
+// types returned by the given getters, sorted.
+func providerImports(methods []providerMethod) []string {
+	imported := make(map[string]bool)
+	var imports []string
+	for _, m := range methods {
+		pkg, _, ok := strings.Cut(strings.TrimLeft(m.Type, "*[]"), ".")
+		if importPath, known := typePackages[pkg]; ok && known && !imported[importPath] {
+			imported[importPath] = true
+			imports = append(imports, importPath)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
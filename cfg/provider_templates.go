@@ -0,0 +1,164 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	providerFileName                 = "provider.go"
+	providerUnitTestFileName         = "provider_test.go"
+	providerMockFileName             = "provider_mock.go"
+	providerFileTemplateName         = "providerFile"
+	providerUnitTestFileTemplateName = "providerUnitTestFile"
+	providerMockFileTemplateName     = "providerMockFile"
+	providerFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+{{- if .ProviderImports }}
+
+import (
+{{- range .ProviderImports }}
+	"{{ . }}"
+{{- end }}
+)
+{{- end }}
+
+// ConfigProvider provides the app configuration, for code that gets it
+// injected through an interface rather than as a concrete struct. Mocks
+// can be generated for it with tools like gomock or moq, and
+// ConfigProviderMock is one already.
+type ConfigProvider interface {
+	// Config returns the whole configuration.
+	Config() *{{ .StructName }}
+{{- range .ProviderMethods }}
+	// {{ .Name }} returns the value of the {{ .EnvVar }} env var.
+	{{ .Name }}() {{ .Type }}
+{{- end }}
+}
+
+// configProvider implements ConfigProvider, getting
+// the configuration it provides from a function.
+type configProvider struct {
+	config func() *{{ .StructName }}
+}
+
+// NewConfigProvider returns a ConfigProvider providing the given configuration.
+func NewConfigProvider(config *{{ .StructName }}) ConfigProvider {
+	return configProvider{config: func() *{{ .StructName }} {
+		return config
+	}}
+}
+{{- if .Reload }}
+
+// NewCurrentConfigProvider returns a ConfigProvider providing the most
+// recently loaded configuration, the one returned by Current, so that
+// its values change whenever configuration is reloaded.
+func NewCurrentConfigProvider() ConfigProvider {
+	return configProvider{config: Current}
+}
+{{- end }}
+
+// Config returns the whole configuration.
+func (p configProvider) Config() *{{ .StructName }} {
+	return p.config()
+}
+{{- range .ProviderMethods }}
+
+// {{ .Name }} returns the value of the {{ .EnvVar }} env var.
+func (p configProvider) {{ .Name }}() {{ .Type }} {
+	return p.config().{{ if $.Immutable }}{{ .Field }}(){{ else }}{{ .Field }}{{ end }}
+}
+{{- end }}
+`
+
+	providerMockFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+{{- if .ProviderImports }}
+
+import (
+{{- range .ProviderImports }}
+	"{{ . }}"
+{{- end }}
+)
+{{- end }}
+
+// ConfigProviderMock is a mock implementation of ConfigProvider, in the
+// style of moq: each method calls the function of the same name, which
+// must be set, or else it panics.
+type ConfigProviderMock struct {
+	// ConfigFunc mocks the Config method.
+	ConfigFunc func() *{{ .StructName }}
+{{- range .ProviderMethods }}
+	// {{ .Name }}Func mocks the {{ .Name }} method.
+	{{ .Name }}Func func() {{ .Type }}
+{{- end }}
+}
+
+// Config calls ConfigFunc.
+func (m *ConfigProviderMock) Config() *{{ .StructName }} {
+	if m.ConfigFunc == nil {
+		panic("ConfigProviderMock.ConfigFunc: method is nil but ConfigProvider.Config was just called")
+	}
+	return m.ConfigFunc()
+}
+{{- range .ProviderMethods }}
+
+// {{ .Name }} calls {{ .Name }}Func.
+func (m *ConfigProviderMock) {{ .Name }}() {{ .Type }} {
+	if m.{{ .Name }}Func == nil {
+		panic("ConfigProviderMock.{{ .Name }}Func: method is nil but ConfigProvider.{{ .Name }} was just called")
+	}
+	return m.{{ .Name }}Func()
+}
+{{- end }}
+`
+
+	providerUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ConfigProviderMock must implement ConfigProvider.
+var _ ConfigProvider = (*ConfigProviderMock)(nil)
+
+func TestNewConfigProvider(t *testing.T) {
+	config := new({{ .StructName }})
+	provider := NewConfigProvider(config)
+	require.Same(t, config, provider.Config())
+{{- range .ProviderMethods }}
+	require.Equal(t, config.{{ if $.Immutable }}{{ .Field }}(){{ else }}{{ .Field }}{{ end }}, provider.{{ .Name }}())
+{{- end }}
+}
+{{- if .Reload }}
+
+func TestNewCurrentConfigProvider(t *testing.T) {
+	previous := current.Load()
+	t.Cleanup(func() {
+		current.Store(previous)
+	})
+	provider := NewCurrentConfigProvider()
+	config := new({{ .StructName }})
+	current.Store(config)
+	require.Same(t, config, provider.Config())
+}
+{{- end }}
+
+func TestConfigProviderMock(t *testing.T) {
+	config := new({{ .StructName }})
+	mock := &ConfigProviderMock{
+		ConfigFunc: func() *{{ .StructName }} {
+			return config
+		},
+	}
+	require.Same(t, config, mock.Config())
+	require.Panics(t, func() {
+		(&ConfigProviderMock{}).Config()
+	})
+{{- range .ProviderMethods }}
+	require.Panics(t, func() {
+		mock.{{ .Name }}()
+	})
+{{- end }}
+}
+`
+)
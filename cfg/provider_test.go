@@ -0,0 +1,36 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_providerGetters(t *testing.T) {
+	g := NewGenerator("config", WithPrefix("APP_"), WithMaskedSecrets()).(*generator)
+	vars := []envVar{
+		{key: "APP_PORT", value: "8080"},
+		{key: "APP_DB_PASSWORD", value: "secret"},
+		{key: "APP_START", value: "2024-03-04", comment: "layout: 2006-01-02"},
+		{key: "APP_BASE_URL", value: "https://example.com"},
+		{key: "APP_API_TOKEN", comment: "optional"},
+		{key: "APP_HOSTS", value: "a.com,b.com", inferredType: "[]string"},
+		{key: "APP_CREATED", value: "2024-01-02T15:04:05Z"},
+	}
+	expectedOutput := []providerMethod{
+		{Name: "GetPort", Field: "Port", Type: "int", EnvVar: "APP_PORT"},
+		{Name: "GetDbPassword", Field: "DbPassword", Type: "Secret", EnvVar: "APP_DB_PASSWORD"},
+		{Name: "GetStart", Field: "Start", Type: "StartTime", EnvVar: "APP_START"},
+		{Name: "GetBaseUrl", Field: "BaseUrl", Type: "*url.URL", EnvVar: "APP_BASE_URL"},
+		{Name: "GetApiToken", Field: "ApiToken", Type: "*Secret", EnvVar: "APP_API_TOKEN"},
+		{Name: "GetHosts", Field: "Hosts", Type: "[]string", EnvVar: "APP_HOSTS"},
+		{Name: "GetCreated", Field: "Created", Type: "time.Time", EnvVar: "APP_CREATED"},
+	}
+	output := g.providerGetters(vars)
+	require.Equal(t, expectedOutput, output)
+	require.Equal(t, []string{"net/url", "time"}, providerImports(output))
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const packageReadmeFileName = "README.md"
+
+// packageReadmeFile returns a README.md documenting the generated package:
+// what it does, the given variables, as documented by 'docs', and how to
+// read configuration with it.
+func (g *generator) packageReadmeFile(vars []envVar) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", g.packageName)
+	fmt.Fprintf(&sb, "Package `%s` reads the app configuration from env vars into `%s`.\n", g.packageName, g.structName)
+	sb.WriteString("It was generated by [goprojconfig](https://github.com/tiagomelo/go-project-config).\n\n")
+	sb.WriteString("## env vars\n\n")
+	sb.WriteString(g.markdownDocs(vars))
+	sb.WriteString("\n## usage\n\n```go\n")
+	if g.modImportPath != "" {
+		fmt.Fprintf(&sb, "import %q\n\n", g.modImportPath)
+	}
+	// The variable holding the configuration mustn't shadow the package.
+	configVar := "cfg"
+	if g.packageName == configVar {
+		configVar = "config"
+	}
+	var ctx string
+	if g.withContext || g.tracing {
+		ctx = "ctx"
+	}
+	fmt.Fprintf(&sb, "%s, err := %s.%s(%s)\n", configVar, g.packageName, g.readerName, ctx)
+	sb.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n```\n")
+	return sb.String(), nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_packageReadmeFile(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host"},
+		{key: "DEBUG", value: "true", comment: "optional"},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		importPath     string
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			expectedOutput: "# config\n\n" +
+				"Package `config` reads the app configuration from env vars into `Config`.\n" +
+				"It was generated by [goprojconfig](https://github.com/tiagomelo/go-project-config).\n\n" +
+				"## env vars\n\n" +
+				"| Variable | Type | Required | Default | Description |\n" +
+				"|----------|------|----------|---------|-------------|\n" +
+				"| `DB_HOST` | `string` | yes |  | database host |\n" +
+				"| `DEBUG` | `bool` | no |  |  |\n" +
+				"\n## usage\n\n" +
+				"```go\n" +
+				"cfg, err := config.Read()\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"```\n",
+		},
+		{
+			name:       "import path, reader name and context",
+			opts:       []Option{WithStructName("AppConfig"), WithReaderName("Load"), WithContext()},
+			importPath: "example.com/app/config",
+			expectedOutput: "# config\n\n" +
+				"Package `config` reads the app configuration from env vars into `AppConfig`.\n" +
+				"It was generated by [goprojconfig](https://github.com/tiagomelo/go-project-config).\n\n" +
+				"## env vars\n\n" +
+				"| Variable | Type | Required | Default | Description |\n" +
+				"|----------|------|----------|---------|-------------|\n" +
+				"| `DB_HOST` | `string` | yes |  | database host |\n" +
+				"| `DEBUG` | `bool` | no |  |  |\n" +
+				"\n## usage\n\n" +
+				"```go\n" +
+				"import \"example.com/app/config\"\n\n" +
+				"cfg, err := config.Load(ctx)\n" +
+				"if err != nil {\n\tlog.Fatal(err)\n}\n" +
+				"```\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			g.modImportPath = tc.importPath
+			output, err := g.packageReadmeFile(vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
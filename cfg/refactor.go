@@ -0,0 +1,1271 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// configSource is a parsed Go file declaring the 'Config' struct
+// of a generated package.
+type configSource struct {
+	path         string
+	src          []byte
+	fset         *token.FileSet
+	file         *ast.File
+	configStruct *ast.StructType
+	prefix       string
+}
+
+// parseConfigSource parses the given Go file declaring the 'Config' struct.
+func (g *generator) parseConfigSource(configFilePath string) (*configSource, error) {
+	src, err := g.fs.ReadFile(configFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading file %s", configFilePath)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, configFilePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing file %s", configFilePath)
+	}
+	configStruct := findConfigStruct(file, g.structName)
+	if configStruct == nil {
+		return nil, errors.Errorf("struct %s not found in %s", g.structName, configFilePath)
+	}
+	return &configSource{
+		path:         configFilePath,
+		src:          src,
+		fset:         fset,
+		file:         file,
+		configStruct: configStruct,
+		prefix:       findEnvPrefix(file),
+	}, nil
+}
+
+// tagKey returns the given env var name without the prefix of the package.
+func (s *configSource) tagKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, s.prefix+"_")
+}
+
+// findField returns the field reading the given env var, given with or
+// without the prefix of the package, and its name.
+func (s *configSource) findField(key string) (*ast.Field, *ast.Ident, error) {
+	envKey := fieldEnvKey(s.tagKey(key), "", s.prefix)
+	for _, field := range s.configStruct.Fields.List {
+		tag := fieldTag(field)
+		if tag.Get("ignored") == "true" {
+			continue
+		}
+		for _, name := range field.Names {
+			if fieldEnvKey(name.Name, tag, s.prefix) == envKey {
+				return field, name, nil
+			}
+		}
+	}
+	return nil, nil, errors.Errorf("no field reads %s in %s", envKey, s.path)
+}
+
+// offset returns the offset in the source of the given position.
+func (s *configSource) offset(pos token.Pos) int {
+	return s.fset.Position(pos).Offset
+}
+
+// lines returns an edit removing the lines between the given positions.
+func (s *configSource) lines(start, end token.Pos) sourceEdit {
+	return linesEdit(s.src, s.offset(start), s.offset(end))
+}
+
+// tracksUsage reports whether the getters of the immutable config
+// record the env vars they return as read, with 'markUsed'.
+func (s *configSource) tracksUsage() bool {
+	tracks := false
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "markUsed" {
+				tracks = true
+			}
+		}
+		return !tracks
+	})
+	return tracks
+}
+
+// getter returns the getter of the given field of immutable configs,
+// or nil if there's none.
+func (s *configSource) getter(structName, fieldName string) *ast.FuncDecl {
+	if findStruct(s.file, configValuesStructName) == nil {
+		return nil
+	}
+	for _, decl := range s.file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Name.Name == fieldName && receiverTypeName(funcDecl) == structName {
+			return funcDecl
+		}
+	}
+	return nil
+}
+
+// fieldSelectors returns the selectors of the given field, like 'c.Port'
+// or 'c.values.Port', in the methods of the given struct and of the
+// struct holding the values of immutable configs.
+func (s *configSource) fieldSelectors(structName, fieldName string) []*ast.SelectorExpr {
+	var selectors []*ast.SelectorExpr
+	for _, decl := range s.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		if name := receiverTypeName(funcDecl); name != structName && name != configValuesStructName || len(funcDecl.Recv.List[0].Names) == 0 {
+			continue
+		}
+		receiver := funcDecl.Recv.List[0].Names[0].Name
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			if selector, ok := n.(*ast.SelectorExpr); ok && selector.Sel.Name == fieldName && isReceiverValues(selector.X, receiver) {
+				selectors = append(selectors, selector)
+			}
+			return true
+		})
+	}
+	return selectors
+}
+
+// patternVar returns the declaration of the variable holding the pattern
+// the values of the given field are validated against, if there's one.
+func (s *configSource) patternVar(fieldName string) *ast.GenDecl {
+	name := patternVarName(fieldName)
+	for _, decl := range s.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+			continue
+		}
+		if valueSpec := genDecl.Specs[0].(*ast.ValueSpec); len(valueSpec.Names) == 1 && valueSpec.Names[0].Name == name {
+			return genDecl
+		}
+	}
+	return nil
+}
+
+// receiverTypeName returns the name of the type of the receiver of
+// the given function, or an empty string if it's not a method.
+func receiverTypeName(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return ""
+	}
+	typ := funcDecl.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// isReceiverValues reports whether the given expression is the given
+// receiver, or the values it holds if it's an immutable config.
+func isReceiverValues(expr ast.Expr, receiver string) bool {
+	if selector, ok := expr.(*ast.SelectorExpr); ok && selector.Sel.Name == "values" {
+		expr = selector.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == receiver
+}
+
+// sourceEdit replaces the bytes between two offsets of a source.
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits returns the given source with the given edits, which must
+// not overlap, applied.
+func applyEdits(src []byte, edits []sourceEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		buf.Write(src[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
+
+// linesEdit returns an edit removing the whole lines holding the
+// bytes between the given offsets of the given source.
+func linesEdit(src []byte, start, end int) sourceEdit {
+	start = bytes.LastIndexByte(src[:start], '\n') + 1
+	if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+		end += i + 1
+	} else {
+		end = len(src)
+	}
+	return sourceEdit{start: start, end: end}
+}
+
+// declEdit returns an edit removing the given declaration, along with
+// its doc comment and the blank line preceding it.
+func (s *configSource) declEdit(decl ast.Decl, doc *ast.CommentGroup) sourceEdit {
+	start := decl.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	e := s.lines(start, decl.End())
+	if e.start >= 2 && s.src[e.start-2] == '\n' {
+		e.start--
+	}
+	return e
+}
+
+// wordPattern returns a regular expression matching the given word.
+func wordPattern(word string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(word) + `\b`)
+}
+
+// RenameEnvVar renames the given env var, given with or without the prefix
+// of the package, read into the 'Config' struct declared in the given Go
+// file, e.g. 'appcfg/config.go', to the given one: the field reading it is
+// renamed after it, along with its uses in the methods of the struct, e.g.
+// validations, and its getter in immutable configs, and the env var is
+// renamed in the given env files, e.g. '.env', where it's defined, along
+// with the '${OLD}' and '$OLD' references to it in the values of the others,
+// returning the env files that were rewritten. The files generated along
+// with the Go file, like 'config_test.go' or the config provider, are
+// rewritten too. Code using the field elsewhere, which FieldUsages reports,
+// isn't changed.
+// Options other than WithFileSystem, which the files are read from and
+// written to, WithFieldNamer, WithStructName and WithFormatter are ignored.
+func RenameEnvVar(configFilePath string, envFilePaths []string, oldKey, newKey string, opts ...Option) ([]string, error) {
+	g := NewGenerator("config", opts...).(*generator)
+	if !token.IsIdentifier(newKey) {
+		return nil, errors.Errorf("invalid env var name %q", newKey)
+	}
+	s, err := g.parseConfigSource(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	field, name, err := s.findField(oldKey)
+	if err != nil {
+		return nil, err
+	}
+	oldEnvKey := fieldEnvKey(name.Name, fieldTag(field), s.prefix)
+	newTagKey := s.tagKey(newKey)
+	newEnvKey := fieldEnvKey(newTagKey, "", s.prefix)
+	newName := g.fieldNamer(newTagKey)
+	if err := checkNewField(s.configStruct, newName, newTagKey, name); err != nil {
+		return nil, errors.Wrapf(err, "renaming %s to %s in %s", oldEnvKey, newEnvKey, configFilePath)
+	}
+	edits := []sourceEdit{
+		{start: s.offset(name.Pos()), end: s.offset(name.End()), text: newName},
+		fieldTagEdit(s, field, newTagKey),
+	}
+	for _, selector := range s.fieldSelectors(g.structName, name.Name) {
+		edits = append(edits, sourceEdit{start: s.offset(selector.Sel.Pos()), end: s.offset(selector.Sel.End()), text: newName})
+	}
+	oldKeyWord := wordPattern(oldEnvKey)
+	docRenames := make(map[*ast.CommentGroup][2]string)
+	if getter := s.getter(g.structName, name.Name); getter != nil {
+		edits = append(edits, sourceEdit{start: s.offset(getter.Name.Pos()), end: s.offset(getter.Name.End()), text: newName})
+		docRenames[getter.Doc] = [2]string{name.Name, newName}
+	}
+	if patternVar := s.patternVar(name.Name); patternVar != nil {
+		oldVarName, newVarName := patternVarName(name.Name), patternVarName(newName)
+		ast.Inspect(s.file, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && ident.Name == oldVarName {
+				edits = append(edits, sourceEdit{start: s.offset(ident.Pos()), end: s.offset(ident.End()), text: newVarName})
+			}
+			return true
+		})
+		docRenames[patternVar.Doc] = [2]string{oldVarName, newVarName}
+	}
+	for _, group := range s.file.Comments {
+		for _, c := range group.List {
+			text := oldKeyWord.ReplaceAllLiteralString(c.Text, newEnvKey)
+			if rename, ok := docRenames[group]; ok {
+				text = wordPattern(rename[0]).ReplaceAllLiteralString(text, rename[1])
+			}
+			if text != c.Text {
+				edits = append(edits, sourceEdit{start: s.offset(c.Pos()), end: s.offset(c.End()), text: text})
+			}
+		}
+	}
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && n != field.Tag {
+			if text := replaceInStringLit(lit.Value, oldKeyWord, newEnvKey); text != lit.Value {
+				edits = append(edits, sourceEdit{start: s.offset(lit.Pos()), end: s.offset(lit.End()), text: text})
+			}
+		}
+		return true
+	})
+	envFiles := make(map[string][]byte)
+	for _, envFilePath := range envFilePaths {
+		content, err := g.fs.ReadFile(envFilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading file %s", envFilePath)
+		}
+		if renamed, ok := renameEnvFileVar(content, oldEnvKey, newEnvKey); ok {
+			envFiles[envFilePath] = renamed
+		}
+	}
+	files, err := g.parseEnvVarFiles(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	renames := make(map[string]string)
+	newNames := derivedNames(newName)
+	for i, oldName := range derivedNames(name.Name) {
+		renames[oldName] = newNames[i]
+	}
+	goFiles, err := refactorEnvVarFiles(files, func(f *configSource) []sourceEdit {
+		return f.renameEdits(renames, oldKeyWord, newEnvKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := g.writeRefactoredFiles(configFilePath, applyEdits(s.src, edits), envFiles, goFiles); err != nil {
+		return nil, err
+	}
+	return sortedKeys(envFiles), nil
+}
+
+// fieldTagEdit returns the edit setting the 'envconfig' key of the tag
+// of the given field to the given env var name.
+func fieldTagEdit(s *configSource, field *ast.Field, tagKey string) sourceEdit {
+	envconfigTag := fmt.Sprintf("envconfig:%q", tagKey)
+	if field.Tag == nil {
+		end := s.offset(field.Type.End())
+		return sourceEdit{start: end, end: end, text: " `" + envconfigTag + "`"}
+	}
+	edit := sourceEdit{start: s.offset(field.Tag.Pos()), end: s.offset(field.Tag.End())}
+	tag := string(fieldTag(field))
+	if _, ok := reflect.StructTag(tag).Lookup("envconfig"); ok {
+		tag = regexp.MustCompile(`envconfig:"(\\.|[^"])*"`).ReplaceAllLiteralString(tag, envconfigTag)
+	} else {
+		tag = strings.TrimSpace(envconfigTag + " " + tag)
+	}
+	edit.text = "`" + tag + "`"
+	if strings.Contains(tag, "`") {
+		edit.text = strconv.Quote(tag)
+	}
+	return edit
+}
+
+// RemoveEnvVar removes the given env var, given with or without the
+// prefix of the package, from the 'Config' struct declared in the given
+// Go file, e.g. 'appcfg/config.go': the field reading it is removed, along
+// with the statements using it in the methods of the struct, e.g.
+// validations, and its getter in immutable configs, and the env var is
+// removed from the given env files, e.g. '.env', with the comments above
+// it, returning the env files that were rewritten. The '${KEY}' and '$KEY'
+// references to it in the values of other env vars, which EnvVarReferences
+// reports, are left as they are. The files generated along with the Go file,
+// like 'config_test.go' or the config provider, are rewritten too. Code
+// using the field elsewhere, which FieldUsages reports, isn't changed.
+// Options other than WithFileSystem, which the files are read from and
+// written to, WithStructName and WithFormatter are ignored.
+func RemoveEnvVar(configFilePath string, envFilePaths []string, key string, opts ...Option) ([]string, error) {
+	g := NewGenerator("config", opts...).(*generator)
+	s, err := g.parseConfigSource(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	field, name, err := s.findField(key)
+	if err != nil {
+		return nil, err
+	}
+	envKey := fieldEnvKey(name.Name, fieldTag(field), s.prefix)
+	if len(field.Names) > 1 {
+		return nil, errors.Errorf("removing %s from %s: field %s is declared along with other fields", envKey, configFilePath, name.Name)
+	}
+	start := field.Pos()
+	if field.Doc != nil && strings.TrimSpace(field.Doc.Text()) != structTagsTODO {
+		start = field.Doc.Pos()
+	}
+	edits := []sourceEdit{s.lines(start, field.End())}
+	getter := s.getter(g.structName, name.Name)
+	if getter != nil {
+		edits = append(edits, s.declEdit(getter, getter.Doc))
+	}
+	if patternVar := s.patternVar(name.Name); patternVar != nil {
+		edits = append(edits, s.declEdit(patternVar, patternVar.Doc))
+	}
+	edits = append(edits, s.statementEdits(g.structName, name.Name, getter)...)
+	envFiles := make(map[string][]byte)
+	for _, envFilePath := range envFilePaths {
+		content, err := g.fs.ReadFile(envFilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading file %s", envFilePath)
+		}
+		if removed, ok := removeEnvFileVar(content, envKey); ok {
+			envFiles[envFilePath] = removed
+		}
+	}
+	files, err := g.parseEnvVarFiles(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, derivedName := range derivedNames(name.Name) {
+		names[derivedName] = true
+	}
+	for added := true; added; {
+		added = false
+		for _, f := range files {
+			for _, accessor := range f.accessors(names) {
+				names[accessor] = true
+				added = true
+			}
+		}
+	}
+	goFiles, err := refactorEnvVarFiles(files, func(f *configSource) []sourceEdit {
+		return f.removalEdits(names, envKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := g.writeRefactoredFiles(configFilePath, applyEdits(s.src, edits), envFiles, goFiles); err != nil {
+		return nil, err
+	}
+	return sortedKeys(envFiles), nil
+}
+
+// statementEdits returns the edits removing the statements of the methods
+// of the given struct, like the checks of validations, using the given
+// field, leaving out its getter, which is removed as a whole.
+func (s *configSource) statementEdits(structName, fieldName string, getter *ast.FuncDecl) []sourceEdit {
+	selectors := make(map[ast.Node]bool)
+	for _, selector := range s.fieldSelectors(structName, fieldName) {
+		selectors[selector] = true
+	}
+	var edits []sourceEdit
+	for _, decl := range s.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl == getter || funcDecl.Body == nil {
+			continue
+		}
+		for _, stmt := range funcDecl.Body.List {
+			uses := false
+			ast.Inspect(stmt, func(n ast.Node) bool {
+				uses = uses || selectors[n]
+				return !uses
+			})
+			if uses {
+				edits = append(edits, s.lines(stmt.Pos(), stmt.End()))
+			}
+		}
+	}
+	return edits
+}
+
+// envFileVarName returns the name of the env var defined in the given
+// line of an env file, if it defines one. The UTF-8 BOM the first line
+// may start with is ignored.
+func envFileVarName(line string) (string, bool) {
+	line = strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(line, utf8BOM)), "export ")
+	name, _, ok := strings.Cut(line, "=")
+	return strings.TrimSpace(name), ok
+}
+
+// renameEnvFileVar returns the given env file content with the given env
+// var renamed, along with the references to it, reporting whether it's
+// defined or referenced there.
+func renameEnvFileVar(content []byte, oldKey, newKey string) ([]byte, bool) {
+	var edits []sourceEdit
+	for _, offset := range envFileVarRefs(content, oldKey) {
+		edits = append(edits, sourceEdit{start: offset, end: offset + len(oldKey), text: newKey})
+	}
+	lines := strings.SplitAfter(string(applyEdits(content, edits)), "\n")
+	found := len(edits) > 0
+	for i, line := range lines {
+		if name, ok := envFileVarName(line); ok && name == oldKey {
+			lines[i] = strings.Replace(line, oldKey, newKey, 1)
+			found = true
+		}
+	}
+	return []byte(strings.Join(lines, "")), found
+}
+
+// envFileVarRefs returns the offsets of the names of the '${KEY}' and
+// '$KEY' references to the given env var in the values of the given env
+// file content, the ones expanded when parsing it: references in single
+// quoted values and comments, as well as the ones escaped with '\$',
+// are left out.
+func envFileVarRefs(content []byte, key string) []int {
+	var refs []int
+	lines := strings.SplitAfter(string(content), "\n")
+	offset := 0
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineOffset := offset
+		offset += len(line)
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 || strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(line, utf8BOM)), "#") {
+			continue
+		}
+		valueOffset := lineOffset + eq + 1
+		rawValue := strings.TrimLeft(line[eq+1:], " \t")
+		valueOffset += len(line[eq+1:]) - len(rawValue)
+		rawValue = strings.TrimRight(rawValue, " \t\r\n")
+		if isUnterminatedQuotedValue(rawValue) {
+			for closed := false; !closed && i+1 < len(lines); {
+				i++
+				offset += len(lines[i])
+				closed = quoteIndex(lines[i], rawValue[0]) >= 0
+			}
+			rawValue = strings.TrimRight(string(content[valueOffset:offset]), " \t\r\n")
+		}
+		switch {
+		case strings.HasPrefix(rawValue, "'"), strings.HasPrefix(rawValue, "#"):
+		case strings.HasPrefix(rawValue, `"`):
+			if end := closingQuoteIndex(rawValue); end > 0 {
+				refs = appendVarRefs(refs, rawValue[1:end], valueOffset+1, key)
+			}
+		default:
+			if comment := strings.Index(rawValue, " #"); comment >= 0 {
+				rawValue = rawValue[:comment]
+			}
+			refs = appendVarRefs(refs, rawValue, valueOffset, key)
+		}
+	}
+	return refs
+}
+
+// appendVarRefs appends to refs the offsets of the names of the references
+// to the given env var in the given value, starting at the given offset,
+// the way expandVars resolves them.
+func appendVarRefs(refs []int, value string, offset int, key string) []int {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == '$':
+			i++
+		case value[i] != '$' || i+1 == len(value):
+		case value[i+1] == '{':
+			if end := strings.IndexByte(value[i+2:], '}'); end >= 0 {
+				if value[i+2:i+2+end] == key {
+					refs = append(refs, offset+i+2)
+				}
+				i += end + 2
+			}
+		default:
+			end := i + 1
+			for end < len(value) && isVarNameChar(value[end]) {
+				end++
+			}
+			if value[i+1:end] == key {
+				refs = append(refs, offset+i+1)
+			}
+			i = end - 1
+		}
+	}
+	return refs
+}
+
+// removeEnvFileVar returns the given env file content without the given
+// env var and the comments right above it, which document it, reporting
+// whether it's defined there.
+func removeEnvFileVar(content []byte, key string) ([]byte, bool) {
+	lines := strings.SplitAfter(string(content), "\n")
+	var kept []string
+	found := false
+	for _, line := range lines {
+		if name, ok := envFileVarName(line); ok && name == key {
+			for len(kept) > 0 && strings.HasPrefix(strings.TrimSpace(kept[len(kept)-1]), "#") {
+				kept = kept[:len(kept)-1]
+			}
+			found = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return []byte(strings.Join(kept, "")), found
+}
+
+// writeRefactoredFiles writes the given env files, then the given files of
+// envVarFileNames, and then the given source of the given Go file. Go files
+// are formatted, with the checksums of the env files in their headers updated.
+func (g *generator) writeRefactoredFiles(configFilePath string, src []byte, envFiles, goFiles map[string][]byte) error {
+	envFilePaths := sortedKeys(envFiles)
+	goFilePaths := append(sortedKeys(goFiles), configFilePath)
+	formattedFiles := make(map[string][]byte)
+	for _, goFilePath := range goFilePaths {
+		source := string(goFiles[goFilePath])
+		if goFilePath == configFilePath {
+			source = string(src)
+		}
+		for _, envFilePath := range envFilePaths {
+			source = updateSourceChecksum(source, configFilePath, envFilePath, checksum(envFiles[envFilePath]))
+		}
+		formattedSrc, err := g.formatter.Source([]byte(source))
+		if err != nil {
+			return errors.Wrapf(err, "formating go file %s", goFilePath)
+		}
+		formattedFiles[goFilePath] = formattedSrc
+	}
+	for _, envFilePath := range envFilePaths {
+		if err := g.fs.WriteFile(envFilePath, envFiles[envFilePath], 0644); err != nil {
+			return errors.Wrapf(err, "writing file %s", envFilePath)
+		}
+	}
+	for _, goFilePath := range goFilePaths {
+		if err := g.fs.WriteFile(goFilePath, formattedFiles[goFilePath], 0644); err != nil {
+			return errors.Wrapf(err, "writing go file %s", goFilePath)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the given files by path, sorted.
+func sortedKeys(files map[string][]byte) []string {
+	var keys []string
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// envVarFileNames are the names of the files generated in the package dir,
+// along with the config file, holding code for each env var, like the
+// getters of the config provider or the env file read by the tests, which
+// RenameEnvVar and RemoveEnvVar rewrite too.
+var envVarFileNames = []string{
+	configReaderUnitTestFileName,
+	benchFileName,
+	providerFileName,
+	providerMockFileName,
+	providerUnitTestFileName,
+	featuresFileName,
+	featuresUnitTestFileName,
+	strictFileName,
+	cobraFlagsFileName,
+	checksumFileName,
+	debugFileName,
+	reloadLogFileName,
+	secretFilesFileName,
+}
+
+// parseEnvVarFiles parses the files of envVarFileNames generated in the dir
+// of the given config file. Missing files, and the ones not generated, are
+// skipped.
+func (g *generator) parseEnvVarFiles(configFilePath string) ([]*configSource, error) {
+	var sources []*configSource
+	for _, fileName := range envVarFileNames {
+		filePath := filepath.Join(filepath.Dir(configFilePath), fileName)
+		src, err := g.fs.ReadFile(filePath)
+		if g.fs.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading file %s", filePath)
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing file %s", filePath)
+		}
+		if ast.IsGenerated(file) {
+			sources = append(sources, &configSource{path: filePath, src: src, fset: fset, file: file})
+		}
+	}
+	return sources, nil
+}
+
+// derivedNames returns the names the files of envVarFileNames derive from
+// the given field name: the field name itself, the getter of the config
+// provider and the field of its mock stubbing it.
+func derivedNames(fieldName string) []string {
+	return []string{fieldName, "Get" + fieldName, "Get" + fieldName + "Func"}
+}
+
+// envVarLits returns the string literals of the values of the package-level
+// declarations, like the env file read by the tests, and the env vars the
+// strict mode knows about.
+func (s *configSource) envVarLits() []*ast.BasicLit {
+	var lits []*ast.BasicLit
+	for _, decl := range s.file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && (genDecl.Tok == token.VAR || genDecl.Tok == token.CONST) {
+			ast.Inspect(genDecl, func(n ast.Node) bool {
+				if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					lits = append(lits, lit)
+				}
+				return true
+			})
+		}
+	}
+	return lits
+}
+
+// renameEdits returns the edits renaming, in a file of envVarFileNames, the
+// identifiers with the given names, along with them in the doc comments of
+// their declarations, and the given env var in the string literals of the
+// package-level declarations.
+func (s *configSource) renameEdits(names map[string]string, oldKeyWord *regexp.Regexp, newEnvKey string) []sourceEdit {
+	var edits []sourceEdit
+	var docs []*ast.CommentGroup
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			if newName, ok := names[n.Name]; ok {
+				edits = append(edits, sourceEdit{start: s.offset(n.Pos()), end: s.offset(n.End()), text: newName})
+			}
+		case *ast.FuncDecl:
+			docs = addRenamedDoc(docs, n.Doc, names, n.Name)
+			if _, ok := names[n.Name.Name]; ok && n.Body != nil {
+				edits = append(edits, s.renameInLits(n.Body, names)...)
+			}
+		case *ast.Field:
+			docs = addRenamedDoc(docs, n.Doc, names, n.Names...)
+		case *ast.ValueSpec:
+			docs = addRenamedDoc(docs, n.Doc, names, n.Names...)
+		}
+		return true
+	})
+	for _, group := range docs {
+		for _, c := range group.List {
+			text := oldKeyWord.ReplaceAllLiteralString(c.Text, newEnvKey)
+			for oldName, newName := range names {
+				text = wordPattern(oldName).ReplaceAllLiteralString(text, newName)
+			}
+			if text != c.Text {
+				edits = append(edits, sourceEdit{start: s.offset(c.Pos()), end: s.offset(c.End()), text: text})
+			}
+		}
+	}
+	for _, lit := range s.envVarLits() {
+		if text := replaceInStringLit(lit.Value, oldKeyWord, newEnvKey); text != lit.Value {
+			edits = append(edits, sourceEdit{start: s.offset(lit.Pos()), end: s.offset(lit.End()), text: text})
+		}
+	}
+	return edits
+}
+
+// renameInLits returns the edits renaming the identifiers with the given
+// names in the string literals of the given node, like the messages of the
+// panics of the mock of the config provider.
+func (s *configSource) renameInLits(node ast.Node, names map[string]string) []sourceEdit {
+	var edits []sourceEdit
+	ast.Inspect(node, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			text := lit.Value
+			for oldName, newName := range names {
+				text = replaceInStringLit(text, wordPattern(oldName), newName)
+			}
+			if text != lit.Value {
+				edits = append(edits, sourceEdit{start: s.offset(lit.Pos()), end: s.offset(lit.End()), text: text})
+			}
+		}
+		return true
+	})
+	return edits
+}
+
+// addRenamedDoc returns the given doc comments, along with the given one
+// if it documents a declaration of identifiers with the given names.
+func addRenamedDoc(docs []*ast.CommentGroup, doc *ast.CommentGroup, names map[string]string, idents ...*ast.Ident) []*ast.CommentGroup {
+	if doc == nil {
+		return docs
+	}
+	for _, ident := range idents {
+		if _, ok := names[ident.Name]; ok {
+			return append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// replaceInStringLit returns the given Go string literal with the matches of
+// the given pattern replaced. The pattern is matched against the unquoted
+// value, so that escapes like '\n' don't hide the words right after them.
+func replaceInStringLit(lit string, pattern *regexp.Regexp, repl string) string {
+	if strings.HasPrefix(lit, "`") {
+		return pattern.ReplaceAllLiteralString(lit, repl)
+	}
+	value, err := strconv.Unquote(lit)
+	if err != nil || !pattern.MatchString(value) {
+		return lit
+	}
+	return strconv.Quote(pattern.ReplaceAllLiteralString(value, repl))
+}
+
+// accessors returns the names of the functions of a file of envVarFileNames
+// returning a value computed from the identifiers with the given names, in
+// a single statement, like the methods reporting whether a feature flag is
+// enabled, which are removed along with them.
+func (s *configSource) accessors(names map[string]bool) []string {
+	var accessors []string
+	for _, decl := range s.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || names[funcDecl.Name.Name] || funcDecl.Body == nil || len(funcDecl.Body.List) != 1 {
+			continue
+		}
+		if _, ok := funcDecl.Body.List[0].(*ast.ReturnStmt); ok && usesNames(funcDecl.Body, names) {
+			accessors = append(accessors, funcDecl.Name.Name)
+		}
+	}
+	return accessors
+}
+
+// usesNames reports whether the given node uses identifiers with the given names.
+func usesNames(node ast.Node, names map[string]bool) bool {
+	uses := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && names[ident.Name] {
+			uses = true
+		}
+		return !uses
+	})
+	return uses
+}
+
+// removalEdits returns the edits removing, from a file of envVarFileNames,
+// the declarations with the given names, e.g. the getter of the config
+// provider, along with their doc comments, the elements of composite
+// literals and the statements of functions using them, and the given env
+// var from the package-level declarations, like the env file read by
+// the tests.
+func (s *configSource) removalEdits(names map[string]bool, envKey string) []sourceEdit {
+	var edits []sourceEdit
+	declared := make(map[*ast.Ident]bool)
+	for _, decl := range s.file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if names[decl.Name.Name] {
+				edits = append(edits, s.declEdit(decl, decl.Doc))
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(valueSpec.Names) != 1 || !names[valueSpec.Names[0].Name] {
+					continue
+				}
+				declared[valueSpec.Names[0]] = true
+				if decl.Lparen.IsValid() {
+					edits = append(edits, s.lines(docPos(valueSpec, valueSpec.Doc), valueSpec.End()))
+				} else {
+					edits = append(edits, s.declEdit(decl, decl.Doc))
+				}
+			}
+		}
+	}
+	var stack []ast.Node
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			declared[n.Name] = true
+			if names[n.Name.Name] {
+				stack = stack[:len(stack)-1]
+				return false
+			}
+		case *ast.StructType, *ast.InterfaceType:
+			fields := n.(ast.Expr)
+			ast.Inspect(fields, func(f ast.Node) bool {
+				if field, ok := f.(*ast.Field); ok && len(field.Names) == 1 && names[field.Names[0].Name] {
+					declared[field.Names[0]] = true
+					edits = append(edits, s.lines(docPos(field, field.Doc), field.End()))
+				}
+				return true
+			})
+		case *ast.Ident:
+			if names[n.Name] && !declared[n] {
+				if e, ok := s.useEdit(stack); ok {
+					edits = append(edits, e)
+				}
+			}
+		}
+		return true
+	})
+	quotedKey := strconv.Quote(envKey)
+	for _, lit := range s.envVarLits() {
+		if value, err := strconv.Unquote(lit.Value); err == nil {
+			if removed, ok := removeEnvFileVar([]byte(value), envKey); ok {
+				text := strconv.Quote(string(removed))
+				if strings.HasPrefix(lit.Value, "`") && !strings.Contains(string(removed), "`") {
+					text = "`" + string(removed) + "`"
+				}
+				edits = append(edits, sourceEdit{start: s.offset(lit.Pos()), end: s.offset(lit.End()), text: text})
+			}
+		}
+	}
+	for _, decl := range s.file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+			ast.Inspect(genDecl, func(n ast.Node) bool {
+				if lit, ok := n.(*ast.CompositeLit); ok {
+					for i, elt := range lit.Elts {
+						if isEnvVarElt(elt, quotedKey) {
+							edits = append(edits, s.eltEdit(lit, i))
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	return mergeRemovals(edits)
+}
+
+// docPos returns the position of the given doc comment, if any,
+// or else the one of the given node.
+func docPos(node ast.Node, doc *ast.CommentGroup) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return node.Pos()
+}
+
+// useEdit returns the edit removing the use of a removed identifier at
+// the top of the given stack of nodes: the element of the innermost
+// composite literal holding it, or else the statement of the function
+// holding it.
+func (s *configSource) useEdit(stack []ast.Node) (sourceEdit, bool) {
+	for i := len(stack) - 1; i > 0; i-- {
+		switch parent := stack[i-1].(type) {
+		case *ast.CompositeLit:
+			for j, elt := range parent.Elts {
+				if elt == stack[i] {
+					return s.eltEdit(parent, j), true
+				}
+			}
+		case *ast.BlockStmt:
+			if i < 2 {
+				continue
+			}
+			if _, ok := stack[i-2].(*ast.FuncDecl); ok {
+				return s.lines(stack[i].Pos(), stack[i].End()), true
+			}
+		}
+	}
+	return sourceEdit{}, false
+}
+
+// isEnvVarElt reports whether the given element of a composite literal
+// is the given quoted env var, is keyed by it, or is a composite literal
+// holding it, like a row of the config schema.
+func isEnvVarElt(elt ast.Expr, quotedKey string) bool {
+	if kv, ok := elt.(*ast.KeyValueExpr); ok {
+		elt = kv.Key
+	}
+	if lit, ok := elt.(*ast.CompositeLit); ok {
+		for _, e := range lit.Elts {
+			if isEnvVarElt(e, quotedKey) {
+				return true
+			}
+		}
+		return false
+	}
+	lit, ok := elt.(*ast.BasicLit)
+	return ok && lit.Kind == token.STRING && lit.Value == quotedKey
+}
+
+// eltEdit returns an edit removing the given element of the given composite
+// literal, along with the comma separating it from the others.
+func (s *configSource) eltEdit(lit *ast.CompositeLit, i int) sourceEdit {
+	switch {
+	case i+1 < len(lit.Elts):
+		return sourceEdit{start: s.offset(lit.Elts[i].Pos()), end: s.offset(lit.Elts[i+1].Pos())}
+	case i > 0:
+		return sourceEdit{start: s.offset(lit.Elts[i-1].End()), end: s.offset(lit.Elts[i].End())}
+	default:
+		return sourceEdit{start: s.offset(lit.Lbrace) + 1, end: s.offset(lit.Rbrace)}
+	}
+}
+
+// mergeRemovals returns the given edits removing bytes, merging the
+// overlapping ones, so that they can be applied with applyEdits.
+func mergeRemovals(edits []sourceEdit) []sourceEdit {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var merged []sourceEdit
+	for _, e := range edits {
+		if last := len(merged) - 1; last >= 0 && e.start < merged[last].end {
+			merged[last].end = max(merged[last].end, e.end)
+			continue
+		}
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// repinSchemaChecksum returns the given source of the generated checksum
+// file with the env vars of the config schema sorted by name, and the
+// checksum pinned for them updated, after renaming or removing one.
+func repinSchemaChecksum(filePath string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing file %s", filePath)
+	}
+	values := make(map[string]ast.Expr)
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+			for _, spec := range genDecl.Specs {
+				if valueSpec := spec.(*ast.ValueSpec); len(valueSpec.Names) == 1 && len(valueSpec.Values) == 1 {
+					values[valueSpec.Names[0].Name] = valueSpec.Values[0]
+				}
+			}
+		}
+	}
+	rows, ok := values["schemaEnvVars"].(*ast.CompositeLit)
+	pinned, pinnedOK := values["schemaChecksum"].(*ast.BasicLit)
+	if !ok || !pinnedOK {
+		return src, nil
+	}
+	var schema []schemaEnvVar
+	for _, elt := range rows.Elts {
+		row, ok := elt.(*ast.CompositeLit)
+		if !ok || len(row.Elts) != 3 {
+			return nil, errors.Errorf("unexpected config schema in %s", filePath)
+		}
+		var fields [2]string
+		for i, e := range row.Elts[:2] {
+			lit, ok := e.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return nil, errors.Errorf("unexpected config schema in %s", filePath)
+			}
+			fields[i], _ = strconv.Unquote(lit.Value)
+		}
+		required, ok := row.Elts[2].(*ast.Ident)
+		if !ok || required.Name != "true" && required.Name != "false" {
+			return nil, errors.Errorf("unexpected config schema in %s", filePath)
+		}
+		schema = append(schema, schemaEnvVar{Name: fields[0], Type: fields[1], Required: required.Name == "true"})
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Name < schema[j].Name })
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, v := range schema {
+		fmt.Fprintf(&sb, "{%q, %q, %t},\n", v.Name, v.Type, v.Required)
+	}
+	sb.WriteString("}")
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	return applyEdits(src, []sourceEdit{
+		{start: offset(rows.Lbrace), end: offset(rows.Rbrace) + 1, text: sb.String()},
+		{start: offset(pinned.Pos()), end: offset(pinned.End()), text: strconv.Quote(schemaChecksum(schema))},
+	}), nil
+}
+
+// refactorEnvVarFiles applies to each of the given files of envVarFileNames
+// the edits returned by the given function, returning the sources of the
+// ones that changed by path.
+func refactorEnvVarFiles(files []*configSource, edits func(*configSource) []sourceEdit) (map[string][]byte, error) {
+	refactored := make(map[string][]byte)
+	for _, f := range files {
+		fileEdits := edits(f)
+		if len(fileEdits) == 0 {
+			continue
+		}
+		src := applyEdits(f.src, fileEdits)
+		if filepath.Base(f.path) == checksumFileName {
+			var err error
+			if src, err = repinSchemaChecksum(f.path, src); err != nil {
+				return nil, err
+			}
+		}
+		refactored[f.path] = src
+	}
+	return refactored, nil
+}
+
+// EnvVarReferences returns the positions, e.g. '.env:3:18', of the '${KEY}'
+// and '$KEY' references to the given env var, given with or without the
+// prefix of the package whose 'Config' struct is declared in the given Go
+// file, in the values of the given env files, e.g. the ones left behind by
+// RemoveEnvVar, which expand to empty strings. Options other than
+// WithFileSystem, which the files are read from, and WithStructName are
+// ignored.
+func EnvVarReferences(configFilePath string, envFilePaths []string, key string, opts ...Option) ([]string, error) {
+	g := NewGenerator("config", opts...).(*generator)
+	s, err := g.parseConfigSource(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	envKey := fieldEnvKey(s.tagKey(key), "", s.prefix)
+	var refs []string
+	for _, envFilePath := range envFilePaths {
+		content, err := g.fs.ReadFile(envFilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading file %s", envFilePath)
+		}
+		for _, offset := range envFileVarRefs(content, envKey) {
+			line := bytes.Count(content[:offset], []byte("\n")) + 1
+			column := offset - bytes.LastIndexByte(content[:offset], '\n')
+			refs = append(refs, fmt.Sprintf("%s:%d:%d", envFilePath, line, column))
+		}
+	}
+	return refs, nil
+}
+
+// FieldUsages returns the positions, e.g. 'cmd/api/main.go:12:24', of
+// the uses of the field reading the given env var, given with or without
+// the prefix of the package, of the 'Config' struct declared in the given
+// Go file, and of its getter in immutable configs, in the packages of the
+// module enclosing it, tests included, so that they can be fixed before
+// renaming or removing the env var. Uses in the Go file itself, and in the
+// files generated along with it, which RenameEnvVar and RemoveEnvVar take
+// care of, are left out. Positions are
+// relative to the working dir, which must be in the module, since the
+// imported packages are resolved from it, and as when verifying generated
+// code, imports that can't be resolved are left out. Hidden dirs, nested
+// modules, as well as 'vendor', 'node_modules' and 'testdata' dirs, are
+// skipped. Packages are read from disk, so options other than
+// WithStructName are ignored.
+func FieldUsages(configFilePath, key string, opts ...Option) ([]string, error) {
+	g := NewGenerator("config", opts...).(*generator)
+	g.fs = osFileSystem{}
+	s, err := g.parseConfigSource(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	_, name, err := s.findField(key)
+	if err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(configFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving path of %s", configFilePath)
+	}
+	files, err := g.parseEnvVarFiles(absPath)
+	if err != nil {
+		return nil, err
+	}
+	refactored := map[string]bool{absPath: true}
+	for _, f := range files {
+		refactored[f.path] = true
+	}
+	declarations := map[int]bool{s.offset(name.Pos()): true}
+	if getter := s.getter(g.structName, name.Name); getter != nil {
+		declarations[s.offset(getter.Name.Pos())] = true
+	}
+	wd, err := g.getwd()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting working dir")
+	}
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil)
+	seen := make(map[token.Position]bool)
+	var positions []token.Position
+	root := g.moduleRoot(filepath.Dir(absPath))
+	err = filepath.WalkDir(root, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if dir != root {
+			if strings.HasPrefix(d.Name(), ".") || skippedDirNames[d.Name()] {
+				return filepath.SkipDir
+			}
+			if _, err := g.fs.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		pkg, err := build.ImportDir(dir, 0)
+		if err != nil {
+			// no Go files, or files of several packages.
+			return nil
+		}
+		for _, fileNames := range [][]string{append(pkg.GoFiles, pkg.TestGoFiles...), pkg.XTestGoFiles} {
+			uses, err := packageUses(fset, imp, dir, fileNames)
+			if err != nil {
+				return err
+			}
+			for ident, obj := range uses {
+				declaration := fset.Position(obj.Pos())
+				if declaration.Filename != absPath || !declarations[declaration.Offset] {
+					continue
+				}
+				position := fset.Position(ident.Pos())
+				if refactored[position.Filename] {
+					continue
+				}
+				position.Offset = 0
+				if rel, err := filepath.Rel(wd, position.Filename); err == nil {
+					position.Filename = rel
+				}
+				if !seen[position] {
+					seen[position] = true
+					positions = append(positions, position)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "walking dir %s", root)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	usages := make([]string, len(positions))
+	for i, position := range positions {
+		usages[i] = position.String()
+	}
+	return usages, nil
+}
+
+// packageUses type-checks the package made of the given Go files of the
+// given dir, returning the objects its identifiers use. Type errors are
+// left out, so that what could be checked is returned.
+func packageUses(fset *token.FileSet, imp types.Importer, dir string, fileNames []string) (map[*ast.Ident]types.Object, error) {
+	if len(fileNames) == 0 {
+		return nil, nil
+	}
+	var files []*ast.File
+	for _, fileName := range fileNames {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, fileName), nil, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing file %s", filepath.Join(dir, fileName))
+		}
+		files = append(files, file)
+	}
+	info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{Importer: imp, Error: func(error) {}}
+	conf.Check(files[0].Name.Name, fset, files, info)
+	return info.Uses, nil
+}
+
+// moduleRoot returns the dir of the module enclosing the given dir, whose
+// go.mod file is looked for in the dir and its parents, or the dir itself
+// when no module is found.
+func (g *generator) moduleRoot(dir string) string {
+	for root := dir; ; root = filepath.Dir(root) {
+		if _, err := g.fs.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			return root
+		}
+		if filepath.Dir(root) == root {
+			return dir
+		}
+	}
+}
@@ -0,0 +1,589 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameEnvVar(t *testing.T) {
+	configFile := "// Code generated by goprojconfig; DO NOT EDIT.\n" +
+		"// Source: .env sha256:" + checksum([]byte("# min: 1\nPORT=8080\nHOST=localhost\n")) + "\n\n" +
+		"package appcfg\n\n" +
+		"import \"fmt\"\n\n" +
+		"type Config struct {\n" +
+		"\tPort int    `envconfig:\"PORT\" required:\"true\"`\n" +
+		"\tHost string `envconfig:\"HOST\"`\n" +
+		"}\n\n" +
+		"// Validate checks the values of the configuration.\n" +
+		"func (c *Config) Validate() error {\n" +
+		"\tif c.Port < 1 {\n" +
+		"\t\treturn fmt.Errorf(\"PORT: %v is less than 1\", c.Port)\n" +
+		"\t}\n" +
+		"\treturn nil\n" +
+		"}\n"
+	immutableConfigFile := "package appcfg\n\n" +
+		"import \"regexp\"\n\n" +
+		"const envPrefix = \"APP\"\n\n" +
+		"type Config struct {\n\tvalues configValues\n}\n\n" +
+		"type configValues struct {\n" +
+		"\tDataDir string `envconfig:\"DATA_DIR\"`\n" +
+		"}\n\n" +
+		"// DataDir returns the value of the APP_DATA_DIR env var.\n" +
+		"func (c *Config) DataDir() string {\n\treturn c.values.DataDir\n}\n\n" +
+		"// dataDirPattern is the pattern of APP_DATA_DIR values.\n" +
+		"var dataDirPattern = regexp.MustCompile(`^/`)\n\n" +
+		"// Validate checks the values of the configuration.\n" +
+		"func (c *Config) Validate() bool {\n" +
+		"\treturn dataDirPattern.MatchString(c.values.DataDir)\n" +
+		"}\n"
+	testCases := []struct {
+		name           string
+		files          map[string][]byte
+		envFiles       []string
+		oldKey         string
+		newKey         string
+		expectedOutput []string
+		expectedFiles  map[string]string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(configFile),
+				".env":             []byte("# min: 1\nPORT=8080\nHOST=localhost\n"),
+				".env.prod":        []byte("export PORT=80\n"),
+				".env.test":        []byte("HOST=localhost\n"),
+			},
+			envFiles:       []string{".env", ".env.prod", ".env.test"},
+			oldKey:         "PORT",
+			newKey:         "HTTP_PORT",
+			expectedOutput: []string{".env", ".env.prod"},
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "// Code generated by goprojconfig; DO NOT EDIT.\n" +
+					"// Source: .env sha256:" + checksum([]byte("# min: 1\nHTTP_PORT=8080\nHOST=localhost\n")) + "\n\n" +
+					"package appcfg\n\n" +
+					"import \"fmt\"\n\n" +
+					"type Config struct {\n" +
+					"\tHttpPort int    `envconfig:\"HTTP_PORT\" required:\"true\"`\n" +
+					"\tHost     string `envconfig:\"HOST\"`\n" +
+					"}\n\n" +
+					"// Validate checks the values of the configuration.\n" +
+					"func (c *Config) Validate() error {\n" +
+					"\tif c.HttpPort < 1 {\n" +
+					"\t\treturn fmt.Errorf(\"HTTP_PORT: %v is less than 1\", c.HttpPort)\n" +
+					"\t}\n" +
+					"\treturn nil\n" +
+					"}\n",
+				".env":      "# min: 1\nHTTP_PORT=8080\nHOST=localhost\n",
+				".env.prod": "export HTTP_PORT=80\n",
+				".env.test": "HOST=localhost\n",
+			},
+		},
+		{
+			name:           "happy path, immutable config with prefix",
+			files:          map[string][]byte{"appcfg/config.go": []byte(immutableConfigFile), ".env": []byte("APP_DATA_DIR=/tmp\n")},
+			envFiles:       []string{".env"},
+			oldKey:         "APP_DATA_DIR",
+			newKey:         "STORAGE_DIR",
+			expectedOutput: []string{".env"},
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "package appcfg\n\n" +
+					"import \"regexp\"\n\n" +
+					"const envPrefix = \"APP\"\n\n" +
+					"type Config struct {\n\tvalues configValues\n}\n\n" +
+					"type configValues struct {\n" +
+					"\tStorageDir string `envconfig:\"STORAGE_DIR\"`\n" +
+					"}\n\n" +
+					"// StorageDir returns the value of the APP_STORAGE_DIR env var.\n" +
+					"func (c *Config) StorageDir() string {\n\treturn c.values.StorageDir\n}\n\n" +
+					"// storageDirPattern is the pattern of APP_STORAGE_DIR values.\n" +
+					"var storageDirPattern = regexp.MustCompile(`^/`)\n\n" +
+					"// Validate checks the values of the configuration.\n" +
+					"func (c *Config) Validate() bool {\n" +
+					"\treturn storageDirPattern.MatchString(c.values.StorageDir)\n" +
+					"}\n",
+				".env": "APP_STORAGE_DIR=/tmp\n",
+			},
+		},
+		{
+			name:   "happy path, field without envconfig tag",
+			files:  map[string][]byte{"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {\n\tHost string\n}\n")},
+			oldKey: "HOST",
+			newKey: "HOST_NAME",
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "package appcfg\n\ntype Config struct {\n\tHostName string `envconfig:\"HOST_NAME\"`\n}\n",
+			},
+		},
+		{
+			name: "happy path, env file edited on Windows",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {\n\tHost string\n}\n"),
+				".env":             []byte("\ufeffHOST=localhost\r\nPORT=8080\r\n"),
+			},
+			envFiles:       []string{".env"},
+			oldKey:         "HOST",
+			newKey:         "HOST_NAME",
+			expectedOutput: []string{".env"},
+			expectedFiles: map[string]string{
+				".env": "\ufeffHOST_NAME=localhost\r\nPORT=8080\r\n",
+			},
+		},
+		{
+			name: "happy path, references to the env var",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {\n\tHost string\n}\n"),
+				".env": []byte("HOST=localhost\n" +
+					"URL=http://${HOST}:8080/$HOST/$HOSTS\n" +
+					"RAW='${HOST}'\n" +
+					"ESCAPED=\\$HOST # ${HOST}\n" +
+					"# DOC=${HOST}\n"),
+				".env.local": []byte("BASE_URL=\"https://$HOST\"\nCERT=\"---\n${HOST}\n---\"\n"),
+			},
+			envFiles:       []string{".env", ".env.local"},
+			oldKey:         "HOST",
+			newKey:         "HOST_NAME",
+			expectedOutput: []string{".env", ".env.local"},
+			expectedFiles: map[string]string{
+				".env": "HOST_NAME=localhost\n" +
+					"URL=http://${HOST_NAME}:8080/$HOST_NAME/$HOSTS\n" +
+					"RAW='${HOST}'\n" +
+					"ESCAPED=\\$HOST # ${HOST}\n" +
+					"# DOC=${HOST}\n",
+				".env.local": "BASE_URL=\"https://$HOST_NAME\"\nCERT=\"---\n${HOST_NAME}\n---\"\n",
+			},
+		},
+		{
+			name:          "invalid env var name",
+			oldKey:        "PORT",
+			newKey:        "HTTP-PORT",
+			expectedError: errors.New(`invalid env var name "HTTP-PORT"`),
+		},
+		{
+			name:          "error reading config file",
+			oldKey:        "PORT",
+			newKey:        "HTTP_PORT",
+			expectedError: errors.New("reading file appcfg/config.go: open appcfg/config.go: file does not exist"),
+		},
+		{
+			name:          "env var not found",
+			files:         map[string][]byte{"appcfg/config.go": []byte(configFile)},
+			oldKey:        "TIMEOUT",
+			newKey:        "REQUEST_TIMEOUT",
+			expectedError: errors.New("no field reads TIMEOUT in appcfg/config.go"),
+		},
+		{
+			name:          "new env var already read",
+			files:         map[string][]byte{"appcfg/config.go": []byte(configFile)},
+			oldKey:        "PORT",
+			newKey:        "HOST",
+			expectedError: errors.New("renaming PORT to HOST in appcfg/config.go: field Host already exists"),
+		},
+		{
+			name:          "error reading env file",
+			files:         map[string][]byte{"appcfg/config.go": []byte(configFile)},
+			envFiles:      []string{".env"},
+			oldKey:        "PORT",
+			newKey:        "HTTP_PORT",
+			expectedError: errors.New("reading file .env: open .env: file does not exist"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(tc.files)
+			output, err := RenameEnvVar("appcfg/config.go", tc.envFiles, tc.oldKey, tc.newKey, WithFileSystem(fsys), WithFieldNamer(PascalCaseFieldNamer))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+				files := fsys.Files()
+				for name, content := range tc.expectedFiles {
+					require.Equal(t, content, string(files[name]), name)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveEnvVar(t *testing.T) {
+	configFile := "package appcfg\n\n" +
+		"import (\n\t\"fmt\"\n\t\"regexp\"\n)\n\n" +
+		"// Config holds all configuration needed by this app.\n" +
+		"type Config struct {\n" +
+		"\t// " + strings.ReplaceAll(structTagsTODO, "\n", "\n\t// ") + "\n" +
+		"\tPort int    `envconfig:\"PORT\" required:\"true\"`\n" +
+		"\tHost string `envconfig:\"HOST\"`\n" +
+		"}\n\n" +
+		"// hostPattern is the pattern of HOST values.\n" +
+		"var hostPattern = regexp.MustCompile(`^[a-z]+$`)\n\n" +
+		"// Validate checks the values of the configuration.\n" +
+		"func (c *Config) Validate() error {\n" +
+		"\tif c.Port < 1 {\n" +
+		"\t\treturn fmt.Errorf(\"PORT: %v is less than 1\", c.Port)\n" +
+		"\t}\n" +
+		"\tif !hostPattern.MatchString(c.Host) {\n" +
+		"\t\treturn fmt.Errorf(\"HOST: value doesn't match ^[a-z]+$\")\n" +
+		"\t}\n" +
+		"\treturn nil\n" +
+		"}\n"
+	immutableConfigFile := "package appcfg\n\n" +
+		"type Config struct {\n\tvalues configValues\n}\n\n" +
+		"type configValues struct {\n" +
+		"\tPort int `envconfig:\"PORT\"`\n" +
+		"\tHost string `envconfig:\"HOST\"`\n" +
+		"}\n\n" +
+		"// Port returns the value of the PORT env var.\n" +
+		"func (c *Config) Port() int {\n\treturn c.values.Port\n}\n\n" +
+		"// Host returns the value of the HOST env var.\n" +
+		"func (c *Config) Host() string {\n\treturn c.values.Host\n}\n"
+	testCases := []struct {
+		name           string
+		files          map[string][]byte
+		envFiles       []string
+		key            string
+		expectedOutput []string
+		expectedFiles  map[string]string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(configFile),
+				".env":             []byte("# the port.\n# min: 1\nPORT=8080\n\n# the host.\nHOST=localhost\n"),
+				".env.prod":        []byte("PORT=80\n"),
+			},
+			envFiles:       []string{".env", ".env.prod"},
+			key:            "HOST",
+			expectedOutput: []string{".env"},
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "package appcfg\n\n" +
+					"import (\n\t\"fmt\"\n)\n\n" +
+					"// Config holds all configuration needed by this app.\n" +
+					"type Config struct {\n" +
+					"\t// " + strings.ReplaceAll(structTagsTODO, "\n", "\n\t// ") + "\n" +
+					"\tPort int `envconfig:\"PORT\" required:\"true\"`\n" +
+					"}\n\n" +
+					"// Validate checks the values of the configuration.\n" +
+					"func (c *Config) Validate() error {\n" +
+					"\tif c.Port < 1 {\n" +
+					"\t\treturn fmt.Errorf(\"PORT: %v is less than 1\", c.Port)\n" +
+					"\t}\n" +
+					"\treturn nil\n" +
+					"}\n",
+				".env": "# the port.\n# min: 1\nPORT=8080\n\n",
+			},
+		},
+		{
+			name: "happy path, first field",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(configFile),
+				".env":             []byte("# the port.\n# min: 1\nPORT=8080\n\n# the host.\nHOST=localhost\n"),
+			},
+			envFiles:       []string{".env"},
+			key:            "port",
+			expectedOutput: []string{".env"},
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "package appcfg\n\n" +
+					"import (\n\t\"fmt\"\n\t\"regexp\"\n)\n\n" +
+					"// Config holds all configuration needed by this app.\n" +
+					"type Config struct {\n" +
+					"\t// " + strings.ReplaceAll(structTagsTODO, "\n", "\n\t// ") + "\n" +
+					"\tHost string `envconfig:\"HOST\"`\n" +
+					"}\n\n" +
+					"// hostPattern is the pattern of HOST values.\n" +
+					"var hostPattern = regexp.MustCompile(`^[a-z]+$`)\n\n" +
+					"// Validate checks the values of the configuration.\n" +
+					"func (c *Config) Validate() error {\n" +
+					"\tif !hostPattern.MatchString(c.Host) {\n" +
+					"\t\treturn fmt.Errorf(\"HOST: value doesn't match ^[a-z]+$\")\n" +
+					"\t}\n" +
+					"\treturn nil\n" +
+					"}\n",
+				".env": "\n# the host.\nHOST=localhost\n",
+			},
+		},
+		{
+			name:  "happy path, immutable config",
+			files: map[string][]byte{"appcfg/config.go": []byte(immutableConfigFile)},
+			key:   "PORT",
+			expectedFiles: map[string]string{
+				"appcfg/config.go": "package appcfg\n\n" +
+					"type Config struct {\n\tvalues configValues\n}\n\n" +
+					"type configValues struct {\n" +
+					"\tHost string `envconfig:\"HOST\"`\n" +
+					"}\n\n" +
+					"// Host returns the value of the HOST env var.\n" +
+					"func (c *Config) Host() string {\n\treturn c.values.Host\n}\n",
+			},
+		},
+		{
+			name:          "error parsing config file",
+			files:         map[string][]byte{"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {")},
+			key:           "HOST",
+			expectedError: errors.New("parsing file appcfg/config.go: appcfg/config.go:3:21: expected '}', found 'EOF'"),
+		},
+		{
+			name:          "env var not found",
+			files:         map[string][]byte{"appcfg/config.go": []byte(configFile)},
+			key:           "TIMEOUT",
+			expectedError: errors.New("no field reads TIMEOUT in appcfg/config.go"),
+		},
+		{
+			name:          "field declared along with other fields",
+			files:         map[string][]byte{"appcfg/config.go": []byte("package appcfg\n\ntype Config struct {\n\tHost, Port string\n}\n")},
+			key:           "HOST",
+			expectedError: errors.New("removing HOST from appcfg/config.go: field Host is declared along with other fields"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(tc.files)
+			output, err := RemoveEnvVar("appcfg/config.go", tc.envFiles, tc.key, WithFileSystem(fsys))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+				files := fsys.Files()
+				for name, content := range tc.expectedFiles {
+					require.Equal(t, content, string(files[name]), name)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvVarReferences(t *testing.T) {
+	configFile := "package appcfg\n\nconst envPrefix = \"APP\"\n\ntype Config struct {\n\tPort int `envconfig:\"PORT\"`\n}\n"
+	testCases := []struct {
+		name           string
+		files          map[string][]byte
+		key            string
+		expectedOutput []string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(configFile),
+				".env":             []byte("APP_URL=http://localhost:${APP_HOST}\nAPP_RAW='$APP_HOST'\n"),
+				".env.prod":        []byte("APP_PORT=80\nAPP_URL=\"http://$APP_HOST:$APP_PORT\"\n"),
+			},
+			key:            "HOST",
+			expectedOutput: []string{".env:1:28", ".env.prod:2:18"},
+		},
+		{
+			name: "no references",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(configFile),
+				".env":             []byte("APP_URL=http://localhost:${APP_HOSTNAME}\n"),
+				".env.prod":        []byte("APP_PORT=80\n"),
+			},
+			key: "APP_HOST",
+		},
+		{
+			name:          "error reading config file",
+			key:           "HOST",
+			expectedError: errors.New("reading file appcfg/config.go: open appcfg/config.go: file does not exist"),
+		},
+		{
+			name:          "error reading env file",
+			files:         map[string][]byte{"appcfg/config.go": []byte(configFile)},
+			key:           "HOST",
+			expectedError: errors.New("reading file .env: open .env: file does not exist"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(tc.files)
+			output, err := EnvVarReferences("appcfg/config.go", []string{".env", ".env.prod"}, tc.key, WithFileSystem(fsys))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestRefactorGeneratedPackage(t *testing.T) {
+	const envFile = "APP_API_HOST=localhost\n# min: 1\nAPP_PORT=8080\nAPP_FEATURE_BETA=true\nAPP_DB_PASSWORD=secret\n"
+	options := []Option{
+		WithPrefix("APP"),
+		WithConfigProvider(),
+		WithFeatures(),
+		WithBenchmarks(),
+		WithSchemaChecksum(),
+		WithStrict(),
+		WithDebugHandler(),
+		WithMaskedSecrets(),
+		WithSecretFiles(),
+	}
+	testCases := []struct {
+		name     string
+		options  []Option
+		refactor func(fsys FileSystem) error
+		oldNames []string
+	}{
+		{
+			name:    "rename, real tests",
+			options: []Option{WithTestStyle(RealTestStyle)},
+			refactor: func(fsys FileSystem) error {
+				_, err := RenameEnvVar("appcfg/config.go", []string{".env"}, "API_HOST", "API_HOSTNAME", WithFileSystem(fsys))
+				return err
+			},
+			oldNames: []string{"APP_API_HOST", "ApiHost", "GetApiHost"},
+		},
+		{
+			name:    "rename, immutable config, real tests",
+			options: []Option{WithImmutable(), WithTestStyle(RealTestStyle)},
+			refactor: func(fsys FileSystem) error {
+				_, err := RenameEnvVar("appcfg/config.go", []string{".env"}, "APP_FEATURE_BETA", "APP_FEATURE_GAMMA", WithFileSystem(fsys))
+				return err
+			},
+			oldNames: []string{"FEATURE_BETA", "FeatureBeta"},
+		},
+		{
+			name:    "remove, real tests",
+			options: []Option{WithTestStyle(RealTestStyle)},
+			refactor: func(fsys FileSystem) error {
+				_, err := RemoveEnvVar("appcfg/config.go", []string{".env"}, "APP_API_HOST", WithFileSystem(fsys))
+				return err
+			},
+			oldNames: []string{"APP_API_HOST", "ApiHost"},
+		},
+		{
+			name:    "remove, immutable config, real tests",
+			options: []Option{WithImmutable(), WithTestStyle(RealTestStyle)},
+			refactor: func(fsys FileSystem) error {
+				_, err := RemoveEnvVar("appcfg/config.go", []string{".env"}, "APP_FEATURE_BETA", WithFileSystem(fsys))
+				return err
+			},
+			oldNames: []string{"FEATURE_BETA", "FeatureBeta", "BetaEnabled"},
+		},
+		{
+			name:    "remove, mock tests",
+			options: []Option{WithTestStyle(MockTestStyle)},
+			refactor: func(fsys FileSystem) error {
+				_, err := RemoveEnvVar("appcfg/config.go", []string{".env"}, "PORT", WithFileSystem(fsys))
+				return err
+			},
+			oldNames: []string{"APP_PORT", "GetPort"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte(envFile)})
+			g := NewGenerator("appcfg", append([]Option{WithFileSystem(fsys)}, append(options, tc.options...)...)...)
+			_, err := g.GenerateConfigPackageFromEnvFile(".env")
+			require.NoError(t, err)
+			require.NoError(t, tc.refactor(fsys))
+			var goFiles []string
+			for name, content := range fsys.Files() {
+				if strings.HasPrefix(name, "appcfg/") && strings.HasSuffix(name, ".go") {
+					goFiles = append(goFiles, name)
+					for _, oldName := range tc.oldNames {
+						require.False(t, wordPattern(oldName).Match(content), "%s still has %s", name, oldName)
+					}
+				}
+			}
+			require.NoError(t, g.(*generator).verifyPackages(goFiles))
+		})
+	}
+}
+
+func TestFieldUsages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/usages\n\ngo 1.22\n",
+		"appcfg/config.go": "package appcfg\n\n" +
+			"type Config struct {\n" +
+			"\tPort int    `envconfig:\"PORT\"`\n" +
+			"\tHost string `envconfig:\"HOST\"`\n" +
+			"}\n\n" +
+			"func (c *Config) Address() string {\n\treturn c.Host\n}\n",
+		"appcfg/config_test.go": "package appcfg\n\nvar testConfig = Config{Port: 8080}\n",
+		"cmd/app/main.go": "package main\n\n" +
+			"import \"example.com/usages/appcfg\"\n\n" +
+			"func main() {\n\tc := new(appcfg.Config)\n\tprintln(c.Port, c.Host)\n\tprintln(c.Port)\n}\n",
+		"testdata/main.go": "package main\n\nimport \"example.com/usages/appcfg\"\n\nvar _ = appcfg.Config{}.Port\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+	}()
+	testCases := []struct {
+		name           string
+		key            string
+		expectedOutput []string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			key:  "PORT",
+			expectedOutput: []string{
+				"appcfg/config_test.go:3:25",
+				"cmd/app/main.go:7:12",
+				"cmd/app/main.go:8:12",
+			},
+		},
+		{
+			name:           "happy path, field used by its struct's methods only",
+			key:            "HOST",
+			expectedOutput: []string{"cmd/app/main.go:7:20"},
+		},
+		{
+			name:          "env var not found",
+			key:           "TIMEOUT",
+			expectedError: errors.New("no field reads TIMEOUT in appcfg/config.go"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := FieldUsages(filepath.Join("appcfg", "config.go"), tc.key)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
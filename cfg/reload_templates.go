@@ -0,0 +1,635 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	reloadFileName                 = "reload.go"
+	reloadUnitTestFileName         = "reload_test.go"
+	reloadFileTemplateName         = "reloadFile"
+	reloadUnitTestFileTemplateName = "reloadUnitTestFile"
+	reloadFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"sync/atomic"
+{{ if not .DockerDialect }}
+	"github.com/joho/godotenv"
+{{- end }}
+	"github.com/pkg/errors"
+{{- if .Tracing }}
+	"go.opentelemetry.io/otel/attribute"
+{{- end }}
+)
+
+// current holds the most recently loaded configuration.
+var current atomic.Pointer[{{ .StructName }}]
+
+// For ease of unit testing.
+var (
+	godotenvOverload   = {{ if .DockerDialect }}overloadDockerEnvFiles{{ else }}godotenv.Overload{{ end }}
+	reloadErrorHandler = func(err error) {}
+)
+
+// Current returns the most recently loaded configuration,
+// or nil if it wasn't loaded yet.
+func Current() *{{ .StructName }} {
+	return current.Load()
+}
+
+// SetReloadErrorHandler sets the function called when reloading the
+// configuration fails. The previous configuration is kept in that case.
+func SetReloadErrorHandler(handler func(err error)) {
+	reloadErrorHandler = handler
+}
+
+// reload reads configuration from the specified environment file,
+// overriding env vars that are already set, and swaps the current one.
+// onChange, if not nil, is called with the new configuration.
+func reload({{ if .Context }}ctx context.Context, {{ end }}envFilePath string, onChange func(*{{ .StructName }})) {{ if or .Metrics .Tracing }}(err error){{ else }}error{{ end }} {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "reload", attribute.String("config.env_file", envFilePath))
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
+{{- if .Metrics }}
+	defer func() {
+		recordReload(err)
+	}()
+{{- end }}
+	if err := godotenvOverload(envFilePath); err != nil {
+		return errors.Wrapf(err, "loading env vars from %s", envFilePath)
+	}
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
+		return errors.Wrap(err, "processing env vars")
+	}
+	previous := current.Swap(config)
+	logConfigChanges({{ if .Context }}ctx, {{ end }}previous, config)
+	if onChange != nil {
+		onChange(config)
+	}
+	return nil
+}
+`
+
+	reloadUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		mockedGodotenvOverload func(filenames ...string) (err error)
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedError          error
+	}{
+		{
+			name: "happy path",
+			mockedGodotenvOverload: func(filenames ...string) (err error) {
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+		},
+		{
+			name: "error loading env vars",
+			mockedGodotenvOverload: func(filenames ...string) (err error) {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("loading env vars from path/to/.env: random error"),
+		},
+		{
+			name: "error processing env vars",
+			mockedGodotenvOverload: func(filenames ...string) (err error) {
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			godotenvOverload = tc.mockedGodotenvOverload
+			envconfigProcess = tc.mockedEnvconfigProcess
+			var changed *{{ .StructName }}
+			err := reload({{ if .Context }}context.Background(), {{ end }}"path/to/.env", func(config *{{ .StructName }}) {
+				changed = config
+			})
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, changed)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, changed)
+				require.Same(t, changed, Current())
+			}
+		})
+	}
+}
+`
+
+	reloadLogFileName                 = "reloadlog.go"
+	reloadLogUnitTestFileName         = "reloadlog_test.go"
+	reloadLogFileTemplateName         = "reloadLogFile"
+	reloadLogUnitTestFileTemplateName = "reloadLogUnitTestFile"
+	reloadLogFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+{{- if not .DebugHandler }}
+
+// redactedValue replaces the values of sensitive env vars.
+const redactedValue = "****"
+
+// sensitiveVars are the env vars whose values are redacted.
+var sensitiveVars = map[string]bool{
+{{- range .SensitiveVars }}
+	{{ printf "%q" . }}: true,
+{{- end }}
+}
+{{- end }}
+
+// reloadLogger logs the changes of the configuration on reload, if not nil.
+var reloadLogger *slog.Logger
+
+// SetReloadLogger sets the logger the changes of the configuration are
+// logged with when it's reloaded: a record per env var whose value changed,
+// with the values of sensitive env vars redacted. Nothing is logged if it's
+// nil, the default. It must be set before reloading starts.
+func SetReloadLogger(logger *slog.Logger) {
+	reloadLogger = logger
+}
+
+// configChange is the change of the value of an env var.
+type configChange struct {
+	envVar   string
+	oldValue interface{}
+	newValue interface{}
+}
+
+// logConfigChanges logs the changes from the given previous configuration,
+// if any, to the given new one with the reload logger, if any.
+func logConfigChanges({{ if .Context }}ctx context.Context, {{ end }}previous, config *{{ .StructName }}) {
+	if reloadLogger == nil || previous == nil {
+		return
+	}
+	for _, change := range configChanges(previous, config) {
+		reloadLogger.{{ if .Context }}InfoContext(ctx, {{ else }}Info({{ end }}"configuration changed",
+			slog.String("env_var", change.envVar),
+			slog.Any("old", change.oldValue),
+			slog.Any("new", change.newValue),
+		)
+	}
+}
+
+// configChanges returns the changes of the values of env vars from the given
+// previous configuration to the given new one, in field order, with the
+// values of sensitive env vars redacted.
+func configChanges(previous, config *{{ .StructName }}) []configChange {
+	pv := reflect.ValueOf({{ if .Immutable }}previous.values{{ else }}*previous{{ end }})
+	v := reflect.ValueOf({{ if .Immutable }}config.values{{ else }}*config{{ end }})
+	var changes []configChange
+	for i := 0; i < v.NumField(); i++ {
+		key, ok := v.Type().Field(i).Tag.Lookup("envconfig")
+		if !ok || reflect.DeepEqual(pv.Field(i).Interface(), v.Field(i).Interface()) {
+			continue
+		}
+{{- if .EnvPrefix }}
+		key = "{{ .EnvPrefix }}_" + key
+{{- end }}
+		if sensitiveVars[key] {
+			changes = append(changes, configChange{envVar: key, oldValue: redactedValue, newValue: redactedValue})
+			continue
+		}
+		changes = append(changes, configChange{envVar: key, oldValue: logValue(pv.Field(i)), newValue: logValue(v.Field(i))})
+	}
+	return changes
+}
+
+// logValue returns the given value as it's logged: printable
+// values, e.g. durations and URLs, are logged as printed.
+func logValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return v.Interface()
+}
+`
+
+	reloadLogUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"bytes"
+{{- if .Context }}
+	"context"
+{{- end }}
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// changeField changes the value of the first field of a basic kind of the
+// given configuration, returning its env var, or false if it has none.
+func changeField(config *{{ .StructName }}) (string, bool) {
+	v := reflect.ValueOf({{ if .Immutable }}&config.values{{ else }}config{{ end }}).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		key, ok := v.Type().Field(i).Tag.Lookup("envconfig")
+		if !ok {
+			continue
+		}
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(f.String() + "changed")
+		case reflect.Bool:
+			f.SetBool(!f.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.SetInt(f.Int() + 1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.SetUint(f.Uint() + 1)
+		case reflect.Float32, reflect.Float64:
+			f.SetFloat(f.Float() + 1)
+		default:
+			continue
+		}
+		return {{ if .EnvPrefix }}"{{ .EnvPrefix }}_" + {{ end }}key, true
+	}
+	return "", false
+}
+
+func TestLogConfigChanges(t *testing.T) {
+	changed := new({{ .StructName }})
+	envVar, ok := changeField(changed)
+	if !ok {
+		t.Skip("no field of a basic kind to change")
+	}
+	testCases := []struct {
+		name            string
+		previous        *{{ .StructName }}
+		config          *{{ .StructName }}
+		sensitive       bool
+		expectedRecords []map[string]interface{}
+	}{
+		{
+			name:     "changed env var",
+			previous: new({{ .StructName }}),
+			config:   changed,
+			expectedRecords: []map[string]interface{}{
+				{"msg": "configuration changed", "env_var": envVar},
+			},
+		},
+		{
+			name:      "changed sensitive env var",
+			previous:  new({{ .StructName }}),
+			config:    changed,
+			sensitive: true,
+			expectedRecords: []map[string]interface{}{
+				{"msg": "configuration changed", "env_var": envVar, "old": redactedValue, "new": redactedValue},
+			},
+		},
+		{
+			name:     "unchanged",
+			previous: new({{ .StructName }}),
+			config:   new({{ .StructName }}),
+		},
+		{
+			name:   "first load",
+			config: changed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wasSensitive, ok := sensitiveVars[envVar]
+			defer func() {
+				if !ok {
+					delete(sensitiveVars, envVar)
+					return
+				}
+				sensitiveVars[envVar] = wasSensitive
+			}()
+			sensitiveVars[envVar] = tc.sensitive
+			var buf bytes.Buffer
+			SetReloadLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+			defer SetReloadLogger(nil)
+			logConfigChanges({{ if .Context }}context.Background(), {{ end }}tc.previous, tc.config)
+			var records []map[string]interface{}
+			dec := json.NewDecoder(&buf)
+			for dec.More() {
+				var record map[string]interface{}
+				require.NoError(t, dec.Decode(&record))
+				records = append(records, record)
+			}
+			require.Len(t, records, len(tc.expectedRecords))
+			for i, expectedRecord := range tc.expectedRecords {
+				for key, value := range expectedRecord {
+					require.Equal(t, value, records[i][key])
+				}
+				require.Contains(t, records[i], "old")
+				require.Contains(t, records[i], "new")
+			}
+		})
+	}
+}
+
+func TestLogConfigChangesWithoutLogger(t *testing.T) {
+	changed := new({{ .StructName }})
+	changeField(changed)
+	SetReloadLogger(nil)
+	require.NotPanics(t, func() {
+		logConfigChanges({{ if .Context }}context.Background(), {{ end }}new({{ .StructName }}), changed)
+	})
+}
+
+func TestLogValue(t *testing.T) {
+	var nilTime *time.Time
+	testCases := []struct {
+		name           string
+		value          interface{}
+		expectedOutput interface{}
+	}{
+		{name: "string", value: "value", expectedOutput: "value"},
+		{name: "duration", value: time.Second, expectedOutput: "1s"},
+		{name: "nil pointer", value: nilTime, expectedOutput: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, logValue(reflect.ValueOf(tc.value)))
+		})
+	}
+}
+`
+
+	hotReloadFileName                 = "watch.go"
+	hotReloadUnitTestFileName         = "watch_test.go"
+	hotReloadFileTemplateName         = "hotReloadFile"
+	hotReloadUnitTestFileTemplateName = "hotReloadUnitTestFile"
+	hotReloadFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// Watch works like WatchEnvFile for the '.env' file present at current path.
+func Watch(ctx context.Context, onChange func(*{{ .StructName }})) error {
+	return WatchEnvFile(ctx, ".env", onChange)
+}
+
+// WatchEnvFile loads configuration from the specified environment file and
+// reloads it whenever the file changes, until ctx is done. The loaded
+// configuration is available through Current, and onChange, if not nil,
+// is called after every successful load, including the first one.
+// It blocks until ctx is done or watching the file fails.
+func WatchEnvFile(ctx context.Context, envFilePath string, onChange func(*{{ .StructName }})) error {
+	if err := reload({{ if .Context }}ctx, {{ end }}envFilePath, onChange); err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "creating watcher")
+	}
+	defer watcher.Close()
+	// The directory is watched instead of the file itself, since
+	// editors and orchestrators usually replace files instead of
+	// writing to them.
+	if err := watcher.Add(filepath.Dir(envFilePath)); err != nil {
+		return errors.Wrapf(err, "watching %s", envFilePath)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(envFilePath) || !event.Has(fsnotify.Write|fsnotify.Create) {
+				continue
+			}
+			if err := reload({{ if .Context }}ctx, {{ end }}envFilePath, onChange); err != nil {
+				reloadErrorHandler(err)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return errors.Wrapf(err, "watching %s", envFilePath)
+		}
+	}
+}
+`
+
+	hotReloadUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+{{ if not .DockerDialect }}
+	"github.com/joho/godotenv"
+{{- end }}
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchEnvFile(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	godotenvOverload = {{ if .DockerDialect }}overloadDockerEnvFiles{{ else }}godotenv.Overload{{ end }}
+	envFilePath := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFilePath, []byte("WATCH_TEST_VAR=1"), 0644))
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan *{{ .StructName }}, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchEnvFile(ctx, envFilePath, func(config *{{ .StructName }}) {
+			changes <- config
+		})
+	}()
+	select {
+	case config := <-changes:
+		require.Same(t, config, Current())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for configuration to be loaded")
+	}
+	require.Eventually(t, func() bool {
+		if err := os.WriteFile(envFilePath, []byte("WATCH_TEST_VAR=2"), 0644); err != nil {
+			return false
+		}
+		select {
+		case <-changes:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "2", os.Getenv("WATCH_TEST_VAR"))
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatchEnvFileError(t *testing.T) {
+	godotenvOverload = func(filenames ...string) (err error) {
+		return errors.New("random error")
+	}
+	err := WatchEnvFile(context.Background(), "path/to/.env", nil)
+	require.EqualError(t, err, "loading env vars from path/to/.env: random error")
+}
+`
+
+	sighupReloadFileName                 = "sighup.go"
+	sighupReloadUnitTestFileName         = "sighup_test.go"
+	sighupReloadFileTemplateName         = "sighupReloadFile"
+	sighupReloadUnitTestFileTemplateName = "sighupReloadUnitTestFile"
+	sighupReloadFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ReloadOnSIGHUP works like ReloadEnvFileOnSIGHUP for the '.env'
+// file present at current path.
+func ReloadOnSIGHUP(ctx context.Context) error {
+	return ReloadEnvFileOnSIGHUP(ctx, ".env")
+}
+
+// ReloadEnvFileOnSIGHUP loads configuration from the specified environment
+// file and reloads it whenever the process receives a SIGHUP, until ctx is
+// done. The loaded configuration is available through Current.
+// It blocks until ctx is done.
+func ReloadEnvFileOnSIGHUP(ctx context.Context, envFilePath string) error {
+	if err := reload({{ if .Context }}ctx, {{ end }}envFilePath, nil); err != nil {
+		return err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			if err := reload({{ if .Context }}ctx, {{ end }}envFilePath, nil); err != nil {
+				reloadErrorHandler(err)
+			}
+		}
+	}
+}
+`
+
+	sighupReloadUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadEnvFileOnSIGHUP(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	loads := make(chan struct{}, 10)
+	godotenvOverload = func(filenames ...string) (err error) {
+		loads <- struct{}{}
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ReloadEnvFileOnSIGHUP(ctx, "path/to/.env")
+	}()
+	<-loads
+	require.NotNil(t, Current())
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		if err := process.Signal(syscall.SIGHUP); err != nil {
+			return false
+		}
+		select {
+		case <-loads:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestReloadEnvFileOnSIGHUPError(t *testing.T) {
+	godotenvOverload = func(filenames ...string) (err error) {
+		return errors.New("random error")
+	}
+	err := ReloadEnvFileOnSIGHUP(context.Background(), "path/to/.env")
+	require.EqualError(t, err, "loading env vars from path/to/.env: random error")
+}
+`
+)
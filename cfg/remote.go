@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+// RemoteSource identifies a remote key/value store the generated
+// package can read configuration from.
+type RemoteSource string
+
+const (
+	// Consul reads configuration from a Consul KV prefix.
+	Consul RemoteSource = "consul"
+	// Etcd reads configuration from an etcd v3 prefix.
+	Etcd RemoteSource = "etcd"
+	// HTTP reads configuration from the env file or
+	// JSON payload served at an HTTP(S) URL.
+	HTTP RemoteSource = "http"
+	// PluginSource reads configuration from custom sources, e.g. company
+	// config services, registered with the generated 'RegisterSource'.
+	PluginSource RemoteSource = "plugin"
+)
+
+// remoteSourceFiles maps each supported remote source
+// to the files generated for it.
+var remoteSourceFiles = map[RemoteSource][]optionalFile{
+	Consul: {
+		{consulFileName, consulFileTemplateName, consulFileTemplate},
+		{consulUnitTestFileName, consulUnitTestFileTemplateName, consulUnitTestFileTemplate},
+	},
+	Etcd: {
+		{etcdFileName, etcdFileTemplateName, etcdFileTemplate},
+		{etcdUnitTestFileName, etcdUnitTestFileTemplateName, etcdUnitTestFileTemplate},
+	},
+	HTTP: {
+		{httpRemoteFileName, httpRemoteFileTemplateName, httpRemoteFileTemplate},
+		{httpRemoteUnitTestFileName, httpRemoteUnitTestFileTemplateName, httpRemoteUnitTestFileTemplate},
+	},
+	PluginSource: {
+		{pluginRemoteFileName, pluginRemoteFileTemplateName, pluginRemoteFileTemplate},
+		{pluginRemoteUnitTestFileName, pluginRemoteUnitTestFileTemplateName, pluginRemoteUnitTestFileTemplate},
+	},
+}
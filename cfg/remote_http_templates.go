@@ -0,0 +1,265 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	httpRemoteFileName                 = "remote_http.go"
+	httpRemoteUnitTestFileName         = "remote_http_test.go"
+	httpRemoteFileTemplateName         = "httpRemoteFile"
+	httpRemoteUnitTestFileTemplateName = "httpRemoteUnitTestFile"
+	httpRemoteFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/joho/godotenv"
+	"github.com/pkg/errors"
+)
+
+// urlPayload holds the values fetched from a URL, along with their
+// ETag, which are reused while the URL reports them as not modified.
+type urlPayload struct {
+	etag   string
+	values map[string]string
+}
+
+var (
+	httpClient   = http.DefaultClient
+	urlPayloadMu sync.Mutex
+	urlPayloads  = map[string]urlPayload{}
+)
+
+// SetHTTPClient sets the client used by {{ .ReaderName }}FromURL, e.g. to set
+// timeouts, TLS settings or authentication. It defaults to
+// http.DefaultClient.
+func SetHTTPClient(client *http.Client) {
+	httpClient = client
+}
+
+// {{ .ReaderName }}FromURL reads configuration from the payload served at the given
+// URL, either an env file or, when served as 'application/json', a JSON
+// object keyed by env var name. Env vars that are already set take
+// precedence. Payloads served with an ETag are cached, so that reading
+// again only fetches the payload if it changed.
+func {{ .ReaderName }}FromURL(ctx context.Context, url string) (*{{ .StructName }}, error) {
+	values, err := fetchURLValues(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return readFromRemoteValues({{ if .Context }}ctx, {{ end }}values)
+}
+
+// fetchURLValues returns the values of the payload served at the given
+// URL, reusing the cached ones if the URL reports they're not modified.
+func fetchURLValues(ctx context.Context, url string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	urlPayloadMu.Lock()
+	cached, ok := urlPayloads[url]
+	urlPayloadMu.Unlock()
+	if ok {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", url)
+	}
+	defer resp.Body.Close()
+	if ok && resp.StatusCode == http.StatusNotModified {
+		return cached.values, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", url)
+	}
+	values, err := parseURLPayload(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", url)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		urlPayloadMu.Lock()
+		urlPayloads[url] = urlPayload{etag: etag, values: values}
+		urlPayloadMu.Unlock()
+	}
+	return values, nil
+}
+
+// parseURLPayload parses the given payload, served with the given content
+// type: JSON objects, whose values must be strings, numbers or booleans,
+// for 'application/json', and env files otherwise.
+func parseURLPayload(contentType string, payload []byte) (map[string]string, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+		return godotenv.Parse(bytes.NewReader(payload))
+	}
+	var object map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&object); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		switch value := value.(type) {
+		case string:
+			values[key] = value
+		case json.Number, bool:
+			values[key] = fmt.Sprint(value)
+		default:
+			return nil, errors.Errorf("unsupported value for %s", key)
+		}
+	}
+	return values, nil
+}
+`
+
+	httpRemoteUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test{{ .ReaderName }}FromURL(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		contentType            string
+		status                 int
+		payload                string
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name:        "env file",
+			contentType: "text/plain",
+			status:      http.StatusOK,
+			payload:     "DB_HOST=db.remote\nDB_PORT=5432\n",
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "db.remote", "DB_PORT": "5432"},
+		},
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			status:      http.StatusOK,
+			payload:     ` + "`" + `{"DB_HOST": "db.remote", "DB_PORT": 5432, "DEBUG": true}` + "`" + `,
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "db.remote", "DB_PORT": "5432", "DEBUG": "true"},
+		},
+		{
+			name:          "unsupported json value",
+			contentType:   "application/json",
+			status:        http.StatusOK,
+			payload:       ` + "`" + `{"DB_HOSTS": ["a", "b"]}` + "`" + `,
+			expectedError: errors.New("unsupported value for DB_HOSTS"),
+		},
+		{
+			name:          "invalid json",
+			contentType:   "application/json",
+			status:        http.StatusOK,
+			payload:       "{",
+			expectedError: errors.New("unexpected EOF"),
+		},
+		{
+			name:          "unexpected status",
+			status:        http.StatusNotFound,
+			expectedError: errors.New("unexpected status 404 Not Found"),
+		},
+		{
+			name:    "error reading config",
+			status:  http.StatusOK,
+			payload: "DB_HOST=db.remote",
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.payload))
+			}))
+			defer server.Close()
+			SetHTTPClient(server.Client())
+			env := make(map[string]string)
+			remoteEnvVars = make(map[string]bool)
+			remoteLookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			remoteSetenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}FromURL(context.Background(), server.URL)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+
+func Test{{ .ReaderName }}FromURLETag(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == ` + "`" + `"v1"` + "`" + ` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", ` + "`" + `"v1"` + "`" + `)
+		w.Write([]byte("DB_HOST=db.remote"))
+	}))
+	defer server.Close()
+	SetHTTPClient(server.Client())
+	for i := 0; i < 2; i++ {
+		values, err := fetchURLValues(context.Background(), server.URL)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"DB_HOST": "db.remote"}, values)
+	}
+	require.Equal(t, 2, requests)
+}
+`
+)
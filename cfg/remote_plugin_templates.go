@@ -0,0 +1,214 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	pluginRemoteFileName                 = "remote_plugin.go"
+	pluginRemoteUnitTestFileName         = "remote_plugin_test.go"
+	pluginRemoteFileTemplateName         = "pluginRemoteFile"
+	pluginRemoteUnitTestFileTemplateName = "pluginRemoteUnitTestFile"
+	pluginRemoteFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Source is a source of configuration values, e.g. a client of a company
+// config service, plugged in with RegisterSource.
+type Source interface {
+	// Name returns the name the source is registered under.
+	Name() string
+	// Load returns the configuration values of the source,
+	// keyed by env var name.
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]Source{}
+)
+
+// RegisterSource registers the given source under its name, so that
+// {{ .ReaderName }}FromSources can read configuration from it. It's meant to be
+// called from init functions, and panics if the source is nil or a
+// source is already registered under its name.
+func RegisterSource(source Source) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if source == nil {
+		panic("registering source: source is nil")
+	}
+	if _, ok := sources[source.Name()]; ok {
+		panic("registering source: " + source.Name() + " is already registered")
+	}
+	sources[source.Name()] = source
+}
+
+// Sources returns the names of the registered sources, sorted.
+func Sources() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// {{ .ReaderName }}FromSources reads configuration from the values loaded from
+// the registered sources with the given names, in order, the values of
+// later sources taking precedence over the ones of earlier sources. Env
+// vars that are already set take precedence over all of them.
+func {{ .ReaderName }}FromSources(ctx context.Context, names ...string) (*{{ .StructName }}, error) {
+	values := make(map[string]string)
+	for _, name := range names {
+		sourcesMu.RLock()
+		source, ok := sources[name]
+		sourcesMu.RUnlock()
+		if !ok {
+			return nil, errors.Errorf("unknown source %s", name)
+		}
+		sourceValues, err := source.Load(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading values from source %s", name)
+		}
+		for key, value := range sourceValues {
+			values[key] = value
+		}
+	}
+	return readFromRemoteValues({{ if .Context }}ctx, {{ end }}values)
+}
+`
+
+	pluginRemoteUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockSource struct {
+	name   string
+	values map[string]string
+	err    error
+}
+
+func (m *mockSource) Name() string {
+	return m.name
+}
+
+func (m *mockSource) Load(ctx context.Context) (map[string]string, error) {
+	return m.values, m.err
+}
+
+func TestRegisterSource(t *testing.T) {
+	sources = map[string]Source{}
+	RegisterSource(&mockSource{name: "vault"})
+	RegisterSource(&mockSource{name: "company"})
+	require.Equal(t, []string{"company", "vault"}, Sources())
+	require.PanicsWithValue(t, "registering source: company is already registered", func() {
+		RegisterSource(&mockSource{name: "company"})
+	})
+	require.PanicsWithValue(t, "registering source: source is nil", func() {
+		RegisterSource(nil)
+	})
+}
+
+func Test{{ .ReaderName }}FromSources(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		sources                []Source
+		names                  []string
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name: "happy path",
+			sources: []Source{
+				&mockSource{name: "company", values: map[string]string{"DB_HOST": "db.company", "DB_PORT": "5432"}},
+				&mockSource{name: "override", values: map[string]string{"DB_HOST": "db.override"}},
+			},
+			names: []string{"company", "override"},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "db.override", "DB_PORT": "5432"},
+		},
+		{
+			name:          "unknown source",
+			names:         []string{"company"},
+			expectedError: errors.New("unknown source company"),
+		},
+		{
+			name: "error loading values",
+			sources: []Source{
+				&mockSource{name: "company", err: errors.New("random error")},
+			},
+			names:         []string{"company"},
+			expectedError: errors.New("loading values from source company: random error"),
+		},
+		{
+			name: "error reading config",
+			sources: []Source{
+				&mockSource{name: "company"},
+			},
+			names: []string{"company"},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sources = map[string]Source{}
+			for _, source := range tc.sources {
+				RegisterSource(source)
+			}
+			env := make(map[string]string)
+			remoteEnvVars = make(map[string]bool)
+			remoteLookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			remoteSetenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}FromSources(context.Background(), tc.names...)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+`
+)
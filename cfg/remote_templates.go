@@ -0,0 +1,637 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	remoteFileName                 = "remote.go"
+	remoteUnitTestFileName         = "remote_test.go"
+	remoteFileTemplateName         = "remoteFile"
+	remoteUnitTestFileTemplateName = "remoteUnitTestFile"
+	remoteFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// For ease of unit testing.
+var (
+	remoteLookupEnv = os.LookupEnv
+	remoteSetenv    = os.Setenv
+	remoteUnsetenv  = os.Unsetenv
+)
+
+var (
+	remoteMu sync.Mutex
+	// remoteEnvVars holds the env vars set from remote values, which,
+	// unlike the ones set otherwise, are overwritten on every read.
+	remoteEnvVars = map[string]bool{}
+)
+
+// readFromRemoteValues reads configuration from the given values, keyed
+// by env var name. Env vars that are already set take precedence, and the
+// ones set from values that are gone since the previous read are unset.
+func readFromRemoteValues({{ if .Context }}ctx context.Context, {{ end }}values map[string]string) (*{{ .StructName }}, error) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	for key := range remoteEnvVars {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if err := remoteUnsetenv(key); err != nil {
+			return nil, errors.Wrapf(err, "unsetting %s", key)
+		}
+		delete(remoteEnvVars, key)
+	}
+	for key, value := range values {
+		if _, ok := remoteLookupEnv(key); ok && !remoteEnvVars[key] {
+			continue
+		}
+		if err := remoteSetenv(key, value); err != nil {
+			return nil, errors.Wrapf(err, "setting %s", key)
+		}
+		remoteEnvVars[key] = true
+	}
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
+		return nil, errors.Wrap(err, "processing env vars")
+	}
+	return config, nil
+}
+
+// remoteEnvVarName returns the env var name of the given remote key,
+// which is the key without the given prefix, e.g. 'app/config/DB_HOST'
+// maps to 'DB_HOST'. It returns an empty string for nested keys.
+func remoteEnvVarName(prefix, key string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(key, prefix), "/")
+	if strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+`
+
+	remoteUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test{{ .ReaderName }}FromRemoteValues(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		env                    map[string]string
+		remoteEnvVars          map[string]bool
+		values                 map[string]string
+		mockedRemoteSetenv     func(key, value string) error
+		mockedRemoteUnsetenv   func(key string) error
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name:          "happy path",
+			env:           map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432", "DB_USER": "admin"},
+			remoteEnvVars: map[string]bool{"DB_PORT": true, "DB_USER": true},
+			values:        map[string]string{"DB_HOST": "db.remote", "DB_PORT": "6543", "DB_NAME": "app"},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "localhost", "DB_PORT": "6543", "DB_NAME": "app"},
+		},
+		{
+			name:   "error setting env var",
+			values: map[string]string{"DB_HOST": "db.remote"},
+			mockedRemoteSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("setting DB_HOST: random error"),
+		},
+		{
+			name:          "error unsetting env var",
+			env:           map[string]string{"DB_HOST": "db.remote"},
+			remoteEnvVars: map[string]bool{"DB_HOST": true},
+			mockedRemoteUnsetenv: func(key string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("unsetting DB_HOST: random error"),
+		},
+		{
+			name: "error processing env vars",
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := make(map[string]string)
+			for key, value := range tc.env {
+				env[key] = value
+			}
+			remoteEnvVars = make(map[string]bool)
+			for key, value := range tc.remoteEnvVars {
+				remoteEnvVars[key] = value
+			}
+			remoteLookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			remoteSetenv = func(key, value string) error {
+				if tc.mockedRemoteSetenv != nil {
+					return tc.mockedRemoteSetenv(key, value)
+				}
+				env[key] = value
+				return nil
+			}
+			remoteUnsetenv = func(key string) error {
+				if tc.mockedRemoteUnsetenv != nil {
+					return tc.mockedRemoteUnsetenv(key)
+				}
+				delete(env, key)
+				return nil
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := readFromRemoteValues({{ if .Context }}context.Background(), {{ end }}tc.values)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+
+func TestRemoteEnvVarName(t *testing.T) {
+	require.Equal(t, "DB_HOST", remoteEnvVarName("app/config", "app/config/DB_HOST"))
+	require.Equal(t, "DB_HOST", remoteEnvVarName("app/config/", "app/config/DB_HOST"))
+	require.Equal(t, "", remoteEnvVarName("app/config", "app/config/db/HOST"))
+	require.Equal(t, "", remoteEnvVarName("app/config/", "app/config/"))
+}
+`
+
+	consulFileName                 = "remote_consul.go"
+	consulUnitTestFileName         = "remote_consul_test.go"
+	consulFileTemplateName         = "consulFile"
+	consulUnitTestFileTemplateName = "consulUnitTestFile"
+	consulFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+)
+
+// consulKV abstracts the Consul KV client.
+type consulKV interface {
+	List(prefix string, q *api.QueryOptions) (api.KVPairs, *api.QueryMeta, error)
+}
+
+// For ease of unit testing.
+var newConsulKV = func() (consulKV, error) {
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	return client.KV(), nil
+}
+
+// {{ .ReaderName }}FromConsul reads configuration from the keys under the given Consul
+// KV prefix, e.g. 'app/config/DB_HOST' for 'app/config', which are mapped
+// to the env vars with the same names. Env vars that are already set take
+// precedence. The Consul agent is set through the standard env vars, e.g.
+// CONSUL_HTTP_ADDR and CONSUL_HTTP_TOKEN.
+func {{ .ReaderName }}FromConsul(ctx context.Context, prefix string) (*{{ .StructName }}, error) {
+	kv, err := newConsulKV()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating Consul client")
+	}
+	pairs, _, err := kv.List(prefix, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing Consul keys under %s", prefix)
+	}
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if name := remoteEnvVarName(prefix, pair.Key); name != "" {
+			values[name] = string(pair.Value)
+		}
+	}
+	return readFromRemoteValues({{ if .Context }}ctx, {{ end }}values)
+}
+`
+
+	consulUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConsulKV struct {
+	pairs api.KVPairs
+	err   error
+}
+
+func (m *mockConsulKV) List(prefix string, q *api.QueryOptions) (api.KVPairs, *api.QueryMeta, error) {
+	return m.pairs, nil, m.err
+}
+
+func Test{{ .ReaderName }}FromConsul(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		kv                     *mockConsulKV
+		kvErr                  error
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name: "happy path",
+			kv: &mockConsulKV{
+				pairs: api.KVPairs{
+					{Key: "app/config/"},
+					{Key: "app/config/DB_HOST", Value: []byte("db.remote")},
+					{Key: "app/config/nested/DB_PORT", Value: []byte("5432")},
+				},
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "db.remote"},
+		},
+		{
+			name:          "error creating client",
+			kvErr:         errors.New("random error"),
+			expectedError: errors.New("creating Consul client: random error"),
+		},
+		{
+			name:          "error listing keys",
+			kv:            &mockConsulKV{err: errors.New("random error")},
+			expectedError: errors.New("listing Consul keys under app/config: random error"),
+		},
+		{
+			name: "error reading config",
+			kv:   &mockConsulKV{},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newConsulKV = func() (consulKV, error) {
+				return tc.kv, tc.kvErr
+			}
+			env := make(map[string]string)
+			remoteEnvVars = make(map[string]bool)
+			remoteLookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			remoteSetenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}FromConsul(context.Background(), "app/config")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+`
+
+	etcdFileName                 = "remote_etcd.go"
+	etcdUnitTestFileName         = "remote_etcd_test.go"
+	etcdFileTemplateName         = "etcdFile"
+	etcdUnitTestFileTemplateName = "etcdUnitTestFile"
+	etcdFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdClient abstracts the etcd v3 client.
+type etcdClient interface {
+	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+	Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan
+	Close() error
+}
+
+// For ease of unit testing.
+var newEtcdClient = func(endpoints []string) (etcdClient, error) {
+	return clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+}
+
+// {{ .ReaderName }}FromEtcd reads configuration from the keys under the given etcd
+// prefix, e.g. '/app/config/DB_HOST' for '/app/config', which are mapped
+// to the env vars with the same names. Env vars that are already set take
+// precedence.
+func {{ .ReaderName }}FromEtcd(ctx context.Context, endpoints []string, prefix string) (*{{ .StructName }}, error) {
+	client, err := newEtcdClient(endpoints)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating etcd client")
+	}
+	defer client.Close()
+	return readFromEtcd(ctx, client, prefix)
+}
+
+// readFromEtcd reads configuration from the keys under the given prefix.
+func readFromEtcd(ctx context.Context, client etcdClient, prefix string) (*{{ .StructName }}, error) {
+	resp, err := client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting etcd keys under %s", prefix)
+	}
+	values := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if name := remoteEnvVarName(prefix, string(kv.Key)); name != "" {
+			values[name] = string(kv.Value)
+		}
+	}
+	return readFromRemoteValues({{ if .Context }}ctx, {{ end }}values)
+}
+{{- if .HotReload }}
+
+// WatchEtcd reads configuration from the keys under the given etcd prefix
+// and reads it again whenever any of them changes, until ctx is done. The
+// loaded configuration is available through Current, and onChange, if not
+// nil, is called after every successful load, including the first one.
+// It blocks until ctx is done or watching the keys fails.
+func WatchEtcd(ctx context.Context, endpoints []string, prefix string, onChange func(*{{ .StructName }})) error {
+	client, err := newEtcdClient(endpoints)
+	if err != nil {
+		return errors.Wrap(err, "creating etcd client")
+	}
+	defer client.Close()
+	reloadFromEtcd := func() error {
+		config, err := readFromEtcd(ctx, client, prefix)
+		if err != nil {
+			return err
+		}
+		current.Store(config)
+		if onChange != nil {
+			onChange(config)
+		}
+		return nil
+	}
+	if err := reloadFromEtcd(); err != nil {
+		return err
+	}
+	for resp := range client.Watch(ctx, prefix, clientv3.WithPrefix()) {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := resp.Err(); err != nil {
+			return errors.Wrapf(err, "watching %s", prefix)
+		}
+		if err := reloadFromEtcd(); err != nil {
+			reloadErrorHandler(err)
+		}
+	}
+	return nil
+}
+{{- end }}
+`
+
+	etcdUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type mockEtcdClient struct {
+	kvs       map[string]string
+	getErr    error
+	watchChan chan clientv3.WatchResponse
+	closed    bool
+}
+
+func (m *mockEtcdClient) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	resp := new(clientv3.GetResponse)
+	for k, v := range m.kvs {
+		resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+	}
+	return resp, nil
+}
+
+func (m *mockEtcdClient) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	return m.watchChan
+}
+
+func (m *mockEtcdClient) Close() error {
+	m.closed = true
+	return nil
+}
+
+func mockRemoteEnv() map[string]string {
+	env := make(map[string]string)
+	remoteEnvVars = make(map[string]bool)
+	remoteLookupEnv = func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	remoteSetenv = func(key, value string) error {
+		env[key] = value
+		return nil
+	}
+	remoteUnsetenv = func(key string) error {
+		delete(env, key)
+		return nil
+	}
+	return env
+}
+
+func Test{{ .ReaderName }}FromEtcd(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		client                 *mockEtcdClient
+		clientErr              error
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name: "happy path",
+			client: &mockEtcdClient{
+				kvs: map[string]string{
+					"/app/config/DB_HOST":        "db.remote",
+					"/app/config/nested/DB_PORT": "5432",
+				},
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "db.remote"},
+		},
+		{
+			name:          "error creating client",
+			clientErr:     errors.New("random error"),
+			expectedError: errors.New("creating etcd client: random error"),
+		},
+		{
+			name:          "error getting keys",
+			client:        &mockEtcdClient{getErr: errors.New("random error")},
+			expectedError: errors.New("getting etcd keys under /app/config: random error"),
+		},
+		{
+			name:   "error reading config",
+			client: &mockEtcdClient{},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newEtcdClient = func(endpoints []string) (etcdClient, error) {
+				require.Equal(t, []string{"localhost:2379"}, endpoints)
+				if tc.clientErr != nil {
+					return nil, tc.clientErr
+				}
+				return tc.client, nil
+			}
+			env := mockRemoteEnv()
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}FromEtcd(context.Background(), []string{"localhost:2379"}, "/app/config")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
+				require.True(t, tc.client.closed)
+			}
+		})
+	}
+}
+{{- if .HotReload }}
+
+func TestWatchEtcd(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	client := &mockEtcdClient{
+		kvs:       map[string]string{"/app/config/DB_HOST": "db.remote"},
+		watchChan: make(chan clientv3.WatchResponse),
+	}
+	newEtcdClient = func(endpoints []string) (etcdClient, error) {
+		return client, nil
+	}
+	env := mockRemoteEnv()
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	changes := make(chan *{{ .StructName }}, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchEtcd(context.Background(), nil, "/app/config", func(config *{{ .StructName }}) {
+			changes <- config
+		})
+	}()
+	config := <-changes
+	require.Same(t, config, Current())
+	require.Equal(t, map[string]string{"DB_HOST": "db.remote"}, env)
+	client.kvs = map[string]string{"/app/config/DB_PORT": "5432"}
+	client.watchChan <- clientv3.WatchResponse{}
+	config = <-changes
+	require.Same(t, config, Current())
+	require.Equal(t, map[string]string{"DB_PORT": "5432"}, env)
+	client.watchChan <- clientv3.WatchResponse{CompactRevision: 1}
+	require.ErrorContains(t, <-done, "watching /app/config: ")
+	require.True(t, client.closed)
+}
+
+func TestWatchEtcdError(t *testing.T) {
+	newEtcdClient = func(endpoints []string) (etcdClient, error) {
+		return &mockEtcdClient{getErr: errors.New("random error")}, nil
+	}
+	err := WatchEtcd(context.Background(), nil, "/app/config", nil)
+	require.EqualError(t, err, "getting etcd keys under /app/config: random error")
+}
+{{- end }}
+`
+)
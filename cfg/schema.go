@@ -0,0 +1,193 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const jsonSchemaFileName = "config.schema.json"
+
+// jsonSchemaTypes maps the inferred Go field types to JSON Schema types.
+var jsonSchemaTypes = map[string]string{
+	"int":               "integer",
+	"float64":           "number",
+	"bool":              "boolean",
+	"string":            "string",
+	"[]string":          "array",
+	"map[string]string": "object",
+}
+
+// jsonSchema describes the configuration as a JSON Schema.
+type jsonSchema struct {
+	Schema      string               `json:"$schema"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Type        string               `json:"type"`
+	Properties  jsonSchemaProperties `json:"properties"`
+	Required    []string             `json:"required,omitempty"`
+}
+
+// jsonSchemaProperty describes an env var in the JSON Schema.
+type jsonSchemaProperty struct {
+	Type        string          `json:"type"`
+	Description string          `json:"description,omitempty"`
+	Enum        []string        `json:"enum,omitempty"`
+	Minimum     *float64        `json:"minimum,omitempty"`
+	Maximum     *float64        `json:"maximum,omitempty"`
+	Pattern     string          `json:"pattern,omitempty"`
+	Default     json.RawMessage `json:"default,omitempty"`
+}
+
+// jsonSchemaProperties holds the properties of the JSON Schema,
+// which are marshaled in the same order as the env vars.
+type jsonSchemaProperties struct {
+	keys       []string
+	properties map[string]jsonSchemaProperty
+}
+
+func (p jsonSchemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range p.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(p.properties[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonSchemaFile returns a JSON Schema describing every given variable,
+// with its type, whether it's required, its description, taken from its
+// comment, and its default value, if any.
+func (g *generator) jsonSchemaFile(vars []envVar) (string, error) {
+	schema := jsonSchema{
+		Schema:      "https://json-schema.org/draft/2020-12/schema",
+		Title:       g.packageName + "." + g.structName,
+		Description: "Environment variables read by the " + g.packageName + " package.",
+		Type:        "object",
+		Properties:  jsonSchemaProperties{properties: make(map[string]jsonSchemaProperty)},
+	}
+	for _, v := range vars {
+		fieldType := fieldType(v)
+		property := jsonSchemaProperty{
+			Type:        "string",
+			Description: description(v.comment),
+		}
+		if t, ok := jsonSchemaTypes[fieldType]; ok {
+			property.Type = t
+		}
+		if values, ok := enumValues(v); ok && fieldType == "string" {
+			property.Enum = values
+		}
+		c := variableConstraints(v)
+		if c.min != "" {
+			minimum := parseBound(c.min)
+			property.Minimum = &minimum
+		}
+		if c.max != "" {
+			maximum := parseBound(c.max)
+			property.Maximum = &maximum
+		}
+		property.Pattern = c.pattern
+		switch {
+		case g.hasDefault(v):
+			defaultValue, err := jsonValue(fieldType, v.value)
+			if err != nil {
+				return "", err
+			}
+			property.Default = defaultValue
+		case isRequired(v):
+			schema.Required = append(schema.Required, v.key)
+		}
+		schema.Properties.keys = append(schema.Properties.keys, v.key)
+		schema.Properties.properties[v.key] = property
+	}
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content) + "\n", nil
+}
+
+// jsonValue returns the JSON encoding of the given value,
+// according to the given inferred Go type.
+func jsonValue(fieldType, value string) (json.RawMessage, error) {
+	switch fieldType {
+	case "int":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(n)
+	case "float64":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(f)
+	case "bool":
+		return json.Marshal(strings.EqualFold(value, "true"))
+	case "[]string":
+		return json.Marshal(strings.Split(value, ","))
+	case "map[string]string":
+		return json.Marshal(mapValue(value))
+	}
+	return json.Marshal(value)
+}
+
+// description returns the given comment without annotation lines, e.g.
+// '# required', '# sensitive', '# type: int', '# layout: 2006-01-02',
+// '# enum: debug,info', '# min: 1 max: 10' or '# vault: secret/path#key'.
+func description(comment string) string {
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		if line == "" || isAnnotation(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isAnnotation reports whether the given comment line is an annotation.
+func isAnnotation(line string) bool {
+	for _, annotation := range []string{"required", "optional", "sensitive", "not sensitive"} {
+		if hasAnnotation(line, annotation) {
+			return true
+		}
+	}
+	for _, annotation := range []string{"vault", "type", "layout", "enum", "min", "max", "pattern"} {
+		if _, ok := annotationValue(line, annotation); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mapValue parses the given 'k1:v1,k2:v2' value as envconfig does.
+func mapValue(value string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, _ := strings.Cut(pair, ":")
+		m[k] = v
+	}
+	return m
+}
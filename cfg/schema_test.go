@@ -0,0 +1,159 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_jsonSchemaFile(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+		{key: "PORT", value: "08080"},
+		{key: "RATIO", value: ".5"},
+		{key: "DEBUG", value: "TRUE"},
+		{key: "EMPTY"},
+		{key: "HOSTS", value: "a,b", inferredType: "[]string"},
+		{key: "LABELS", value: "k:v", inferredType: "map[string]string"},
+		{key: "LOG_LEVEL", value: "info", comment: "enum: debug,info"},
+		{key: "NAME", value: "app", comment: "pattern: ^[a-z]+$"},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			expectedOutput: `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "config.Config",
+  "description": "Environment variables read by the config package.",
+  "type": "object",
+  "properties": {
+    "DB_HOST": {
+      "type": "string",
+      "description": "database host"
+    },
+    "PORT": {
+      "type": "integer"
+    },
+    "RATIO": {
+      "type": "number"
+    },
+    "DEBUG": {
+      "type": "boolean"
+    },
+    "EMPTY": {
+      "type": "string"
+    },
+    "HOSTS": {
+      "type": "array"
+    },
+    "LABELS": {
+      "type": "object"
+    },
+    "LOG_LEVEL": {
+      "type": "string",
+      "enum": [
+        "debug",
+        "info"
+      ]
+    },
+    "NAME": {
+      "type": "string",
+      "pattern": "^[a-z]+$"
+    }
+  },
+  "required": [
+    "DB_HOST",
+    "PORT",
+    "RATIO",
+    "DEBUG",
+    "HOSTS",
+    "LABELS",
+    "LOG_LEVEL",
+    "NAME"
+  ]
+}
+`,
+		},
+		{
+			name: "defaults from values",
+			opts: []Option{WithDefaultsFromValues()},
+			expectedOutput: `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "config.Config",
+  "description": "Environment variables read by the config package.",
+  "type": "object",
+  "properties": {
+    "DB_HOST": {
+      "type": "string",
+      "description": "database host",
+      "default": "localhost"
+    },
+    "PORT": {
+      "type": "integer",
+      "default": 8080
+    },
+    "RATIO": {
+      "type": "number",
+      "default": 0.5
+    },
+    "DEBUG": {
+      "type": "boolean",
+      "default": true
+    },
+    "EMPTY": {
+      "type": "string"
+    },
+    "HOSTS": {
+      "type": "array",
+      "default": [
+        "a",
+        "b"
+      ]
+    },
+    "LABELS": {
+      "type": "object",
+      "default": {
+        "k": "v"
+      }
+    },
+    "LOG_LEVEL": {
+      "type": "string",
+      "enum": [
+        "debug",
+        "info"
+      ],
+      "default": "info"
+    },
+    "NAME": {
+      "type": "string",
+      "pattern": "^[a-z]+$",
+      "default": "app"
+    }
+  }
+}
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			output, err := g.jsonSchemaFile(vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+func Test_description(t *testing.T) {
+	require.Equal(t, "database password\nrotated monthly", description("database password\nRequired\nvault: secret/data/db#password\nrotated monthly"))
+	require.Equal(t, "", description("optional"))
+	require.Equal(t, "log level", description("log level\nenum: debug,info\nsensitive"))
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"strings"
+)
+
+// secretTypeName is the name of the type generated for sensitive
+// string fields, whose values are masked when printed.
+const secretTypeName = "Secret"
+
+// secretTypeDeclaration declares the 'Secret' type.
+const secretTypeDeclaration = `
+// Secret is a sensitive value, e.g. a password. It's masked when printed,
+// so that printing the configuration doesn't leak it: use Unmask to get it.
+type Secret string
+
+// String returns the masked value.
+func (s Secret) String() string {
+	return "****"
+}
+
+// GoString returns the masked value, which is printed by the '%#v' verb.
+func (s Secret) GoString() string {
+	return ` + "`" + `"****"` + "`" + `
+}
+
+// Unmask returns the actual value.
+func (s Secret) Unmask() string {
+	return string(s)
+}
+`
+
+// sensitiveKeyWords are the words of variable names, split by
+// underscores, that make a variable sensitive, e.g. 'DB_PASSWORD'.
+var sensitiveKeyWords = map[string]bool{
+	"PASSWORD":    true,
+	"PASSWD":      true,
+	"SECRET":      true,
+	"TOKEN":       true,
+	"CREDENTIALS": true,
+	"APIKEY":      true,
+}
+
+// sensitiveKeyQualifiers are the words that make a variable sensitive
+// when followed by 'KEY', e.g. 'STRIPE_API_KEY'.
+var sensitiveKeyQualifiers = map[string]bool{
+	"API":        true,
+	"PRIVATE":    true,
+	"ACCESS":     true,
+	"SIGNING":    true,
+	"ENCRYPTION": true,
+}
+
+// isSensitive reports whether the given variable holds a sensitive value:
+// it's annotated with '# sensitive' or '# vault', or its name has a word
+// like 'PASSWORD' or 'TOKEN', unless it's annotated with '# not sensitive'.
+func isSensitive(v envVar) bool {
+	if hasAnnotation(v.comment, "not sensitive") {
+		return false
+	}
+	if hasAnnotation(v.comment, "sensitive") {
+		return true
+	}
+	if _, ok := annotationValue(v.comment, "vault"); ok {
+		return true
+	}
+	words := strings.Split(strings.ToUpper(v.key), "_")
+	for i, word := range words {
+		if sensitiveKeyWords[word] {
+			return true
+		}
+		if word == "KEY" && i > 0 && sensitiveKeyQualifiers[words[i-1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// secretField reports whether the field of the given type, generated
+// for the given variable, gets the 'Secret' type instead of a string.
+func (g *generator) secretField(v envVar, fieldType string) bool {
+	return g.maskSecrets && (fieldType == "string" || fieldType == optionalFieldType) && isSensitive(v)
+}
+
+// hasSecretFields reports whether any field generated for
+// the given variables has the 'Secret' type.
+func (g *generator) hasSecretFields(vars []envVar) bool {
+	for _, v := range vars {
+		if fieldType, _ := g.structField(v); fieldType == secretTypeName || fieldType == "*"+secretTypeName {
+			return true
+		}
+	}
+	return false
+}
+
+// secretTypes declares the 'Secret' type if any field generated
+// for the given variables has it.
+func (g *generator) secretTypes(vars []envVar) string {
+	if !g.hasSecretFields(vars) {
+		return ""
+	}
+	return secretTypeDeclaration
+}
+
+// sensitiveVarKeys returns the names of the given variables
+// that are sensitive, whose values mustn't be exposed.
+func sensitiveVarKeys(vars []envVar) []string {
+	var keys []string
+	for _, v := range vars {
+		if isSensitive(v) {
+			keys = append(keys, v.key)
+		}
+	}
+	return keys
+}
+
+// secretFileVarKeys returns the names of the given sensitive variables
+// whose values can be read from the files set by their '_FILE' variants,
+// leaving out the ones that already hold file paths.
+func secretFileVarKeys(vars []envVar) []string {
+	var keys []string
+	for _, key := range sensitiveVarKeys(vars) {
+		if !strings.HasSuffix(key, "_FILE") {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	secretFilesFileName                 = "secret_files.go"
+	secretFilesUnitTestFileName         = "secret_files_test.go"
+	secretFilesFileTemplateName         = "secretFilesFile"
+	secretFilesUnitTestFileTemplateName = "secretFilesUnitTestFile"
+	secretFilesFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// secretFileVars are the sensitive env vars whose values can be read from
+// files, e.g. DB_PASSWORD from the file whose path is set by DB_PASSWORD_FILE,
+// as with the secrets Kubernetes and Docker mount as files.
+var secretFileVars = []string{
+{{- range .SecretFileVars }}
+	{{ printf "%q" . }},
+{{- end }}
+}
+
+// For ease of unit testing.
+var osReadFile = os.ReadFile
+
+// loadSecretFiles sets the env vars of secretFileVars whose '_FILE'
+// variants are set to the contents of the files these point to, without
+// trailing newlines. '_FILE' variants take precedence, so that secrets
+// mounted as files win over the values of the .env file.
+func loadSecretFiles() error {
+	for _, key := range secretFileVars {
+		path, ok := lookupEnv(key + "_FILE")
+		if !ok || path == "" {
+			continue
+		}
+		content, err := osReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s_FILE", key)
+		}
+		if err := setenv(key, strings.TrimRight(string(content), "\r\n")); err != nil {
+			return errors.Wrapf(err, "setting %s", key)
+		}
+	}
+	return nil
+}
+`
+
+	secretFilesUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecretFiles(t *testing.T) {
+	testCases := []struct {
+		name          string
+		env           map[string]string
+		files         map[string]string
+		mockedSetenv  func(key, value string) error
+		expectedEnv   map[string]string
+		expectedError error
+	}{
+		{
+			name:        "happy path",
+			env:         map[string]string{"DB_PASSWORD": "dev", "DB_PASSWORD_FILE": "/run/secrets/db-password"},
+			files:       map[string]string{"/run/secrets/db-password": "s3cr3t\n"},
+			expectedEnv: map[string]string{"DB_PASSWORD": "s3cr3t", "DB_PASSWORD_FILE": "/run/secrets/db-password"},
+		},
+		{
+			name:        "_FILE variant not set",
+			env:         map[string]string{"DB_PASSWORD": "dev"},
+			expectedEnv: map[string]string{"DB_PASSWORD": "dev"},
+		},
+		{
+			name:          "missing file",
+			env:           map[string]string{"DB_PASSWORD_FILE": "/run/secrets/db-password"},
+			expectedError: errors.New("reading DB_PASSWORD_FILE: file does not exist"),
+		},
+		{
+			name:  "error setting env var",
+			env:   map[string]string{"DB_PASSWORD_FILE": "/run/secrets/db-password"},
+			files: map[string]string{"/run/secrets/db-password": "s3cr3t"},
+			mockedSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("setting DB_PASSWORD: random error"),
+		},
+	}
+	vars := secretFileVars
+	t.Cleanup(func() {
+		secretFileVars = vars
+	})
+	secretFileVars = []string{"DB_PASSWORD"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := make(map[string]string)
+			for key, value := range tc.env {
+				env[key] = value
+			}
+			lookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			setenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			if tc.mockedSetenv != nil {
+				setenv = tc.mockedSetenv
+			}
+			osReadFile = func(name string) ([]byte, error) {
+				content, ok := tc.files[name]
+				if !ok {
+					return nil, fs.ErrNotExist
+				}
+				return []byte(content), nil
+			}
+			err := loadSecretFiles()
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedEnv, env)
+			}
+		})
+	}
+}
+`
+)
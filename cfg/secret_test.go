@@ -0,0 +1,57 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isSensitive(t *testing.T) {
+	testCases := []struct {
+		name           string
+		v              envVar
+		expectedOutput bool
+	}{
+		{name: "password", v: envVar{key: "DB_PASSWORD"}, expectedOutput: true},
+		{name: "lowercase", v: envVar{key: "db_passwd"}, expectedOutput: true},
+		{name: "secret", v: envVar{key: "CLIENT_SECRET"}, expectedOutput: true},
+		{name: "token", v: envVar{key: "GITHUB_TOKEN"}, expectedOutput: true},
+		{name: "api key", v: envVar{key: "STRIPE_API_KEY"}, expectedOutput: true},
+		{name: "private key", v: envVar{key: "PRIVATE_KEY"}, expectedOutput: true},
+		{name: "key", v: envVar{key: "CACHE_KEY"}},
+		{name: "word within another", v: envVar{key: "TOKENIZER_MODEL"}},
+		{name: "not sensitive", v: envVar{key: "HOST"}},
+		{name: "sensitive annotation", v: envVar{key: "DSN", comment: "sensitive"}, expectedOutput: true},
+		{name: "vault annotation", v: envVar{key: "DSN", comment: "vault: secret/data/db#dsn"}, expectedOutput: true},
+		{name: "not sensitive annotation", v: envVar{key: "TOKEN_URL", comment: "not sensitive"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, isSensitive(tc.v))
+		})
+	}
+}
+
+func Test_sensitiveVarKeys(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST"},
+		{key: "DB_PASSWORD"},
+		{key: "DSN", comment: "sensitive"},
+		{key: "API_TOKEN", comment: "not sensitive"},
+	}
+	require.Equal(t, []string{"DB_PASSWORD", "DSN"}, sensitiveVarKeys(vars))
+}
+
+func Test_secretFileVarKeys(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST"},
+		{key: "DB_PASSWORD"},
+		{key: "TLS_KEY_FILE", comment: "sensitive"},
+		{key: "API_TOKEN", comment: "not sensitive"},
+	}
+	require.Equal(t, []string{"DB_PASSWORD"}, secretFileVarKeys(vars))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+// SecretsBackend identifies a secrets manager the generated
+// package can resolve secret references from.
+type SecretsBackend string
+
+const (
+	// AWSSecretsManager resolves 'aws-sm://secret-name' references
+	// through AWS Secrets Manager.
+	AWSSecretsManager SecretsBackend = "aws"
+	// GCPSecretManager resolves 'gcp-sm://projects/x/secrets/y'
+	// references through Google Secret Manager.
+	GCPSecretManager SecretsBackend = "gcp"
+	// AzureKeyVault resolves 'akv://vault-name/secret-name'
+	// references through Azure Key Vault.
+	AzureKeyVault SecretsBackend = "azure"
+	// HashiCorpVault fetches the fields annotated with
+	// '# vault: secret/path#key' from HashiCorp Vault.
+	HashiCorpVault SecretsBackend = "vault"
+	// Doppler fetches the secrets of the Doppler config set
+	// through DOPPLER_TOKEN, DOPPLER_PROJECT and DOPPLER_CONFIG.
+	Doppler SecretsBackend = "doppler"
+	// OnePassword resolves 'op://vault/item/field' references
+	// through 1Password Connect.
+	OnePassword SecretsBackend = "1password"
+)
+
+// secretsBackendFiles maps each supported secrets backend
+// to the files generated for it.
+var secretsBackendFiles = map[SecretsBackend][]optionalFile{
+	AWSSecretsManager: {
+		{awsSecretsFileName, awsSecretsFileTemplateName, awsSecretsFileTemplate},
+		{awsSecretsUnitTestFileName, awsSecretsUnitTestFileTemplateName, awsSecretsUnitTestFileTemplate},
+	},
+	GCPSecretManager: {
+		{gcpSecretsFileName, gcpSecretsFileTemplateName, gcpSecretsFileTemplate},
+		{gcpSecretsUnitTestFileName, gcpSecretsUnitTestFileTemplateName, gcpSecretsUnitTestFileTemplate},
+	},
+	AzureKeyVault: {
+		{azureSecretsFileName, azureSecretsFileTemplateName, azureSecretsFileTemplate},
+		{azureSecretsUnitTestFileName, azureSecretsUnitTestFileTemplateName, azureSecretsUnitTestFileTemplate},
+	},
+	HashiCorpVault: {
+		{vaultFileName, vaultFileTemplateName, vaultFileTemplate},
+		{vaultUnitTestFileName, vaultUnitTestFileTemplateName, vaultUnitTestFileTemplate},
+	},
+	Doppler: {
+		{dopplerFileName, dopplerFileTemplateName, dopplerFileTemplate},
+		{dopplerUnitTestFileName, dopplerUnitTestFileTemplateName, dopplerUnitTestFileTemplate},
+	},
+	OnePassword: {
+		{onePasswordSecretsFileName, onePasswordSecretsFileTemplateName, onePasswordSecretsFileTemplate},
+		{onePasswordSecretsUnitTestFileName, onePasswordSecretsUnitTestFileTemplateName, onePasswordSecretsUnitTestFileTemplate},
+	},
+}
@@ -0,0 +1,948 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	secretsFileName                 = "secrets.go"
+	secretsUnitTestFileName         = "secrets_test.go"
+	secretsFileTemplateName         = "secretsFile"
+	secretsUnitTestFileTemplateName = "secretsUnitTestFile"
+	secretsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+{{- if .Tracing }}
+	"go.opentelemetry.io/otel/attribute"
+{{- end }}
+)
+
+// secretResolver resolves references to secrets stored in a secrets manager.
+type secretResolver interface {
+	// resolve returns the value of the secret referenced by ref,
+	// which doesn't include the reference prefix.
+	resolve(ctx context.Context, ref string) (string, error)
+}
+
+// secretResolvers maps reference prefixes, e.g. 'aws-sm://', to the
+// resolvers of the correspondent secrets managers.
+var secretResolvers = map[string]secretResolver{}
+
+// secretLoaders set env vars from secrets managers that are
+// not referenced by env var values, e.g. through struct tags.
+var secretLoaders []func(ctx context.Context) error
+
+// secretsTimeout bounds the time spent resolving secrets on every read.
+var secretsTimeout = 30 * time.Second
+
+// For ease of unit testing.
+var (
+	osEnviron   = os.Environ
+	osLookupEnv = os.LookupEnv
+	osSetenv    = os.Setenv
+)
+
+// resolveSecretRefs runs the secret loaders and then replaces the values of
+// env vars that reference secrets, e.g. 'aws-sm://db-password', with the
+// values of the referenced secrets.
+func resolveSecretRefs({{ if .Context }}ctx context.Context{{ end }}) {{ if .Tracing }}(err error){{ else }}error{{ end }} {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "resolveSecretRefs")
+	// Only the names of the env vars are recorded, not their secrets.
+	var resolved []string
+	defer func() {
+		span.SetAttributes(attribute.StringSlice("config.secrets", resolved))
+		endSpan(span, err)
+	}()
+{{- end }}
+	ctx, cancel := context.WithTimeout({{ if .Context }}ctx{{ else }}context.Background(){{ end }}, secretsTimeout)
+	defer cancel()
+	for _, load := range secretLoaders {
+		if err := load(ctx); err != nil {
+			return err
+		}
+	}
+	for _, envVar := range osEnviron() {
+		key, value, _ := strings.Cut(envVar, "=")
+		for prefix, resolver := range secretResolvers {
+			if !strings.HasPrefix(value, prefix) {
+				continue
+			}
+			secret, err := resolver.resolve(ctx, strings.TrimPrefix(value, prefix))
+			if err != nil {
+				return errors.Wrapf(err, "resolving %s", key)
+			}
+			if err := osSetenv(key, secret); err != nil {
+				return errors.Wrapf(err, "setting %s", key)
+			}
+{{- if .Tracing }}
+			resolved = append(resolved, key)
+{{- end }}
+		}
+	}
+	return nil
+}
+
+// lazyClient creates a client on first use, so that clients of secrets
+// managers that are not referenced by any env var are never created.
+type lazyClient[T any] struct {
+	mu     sync.Mutex
+	client T
+	ok     bool
+	create func(ctx context.Context) (T, error)
+}
+
+// get returns the client, creating it if needed.
+func (l *lazyClient[T]) get(ctx context.Context) (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.ok {
+		client, err := l.create(ctx)
+		if err != nil {
+			return client, err
+		}
+		l.client, l.ok = client, true
+	}
+	return l.client, nil
+}
+`
+
+	secretsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockSecretResolver struct {
+	secrets map[string]string
+	err     error
+}
+
+func (m *mockSecretResolver) resolve(ctx context.Context, ref string) (string, error) {
+	return m.secrets[ref], m.err
+}
+
+func TestResolveSecretRefs(t *testing.T) {
+	testCases := []struct {
+		name            string
+		environ         []string
+		loaders         []func(ctx context.Context) error
+		resolver        *mockSecretResolver
+		mockedOsSetenv  func(key, value string) error
+		expectedEnviron map[string]string
+		expectedError   error
+	}{
+		{
+			name:    "happy path",
+			environ: []string{"DB_HOST=localhost", "DB_PASSWORD=mock://db-password"},
+			resolver: &mockSecretResolver{
+				secrets: map[string]string{"db-password": "s3cr3t"},
+			},
+			expectedEnviron: map[string]string{"DB_PASSWORD": "s3cr3t"},
+		},
+		{
+			name:    "secret loaders",
+			environ: []string{"DB_PASSWORD=mock://db-password"},
+			loaders: []func(ctx context.Context) error{
+				func(ctx context.Context) error {
+					return osSetenv("API_KEY", "k3y")
+				},
+			},
+			resolver: &mockSecretResolver{
+				secrets: map[string]string{"db-password": "s3cr3t"},
+			},
+			expectedEnviron: map[string]string{"API_KEY": "k3y", "DB_PASSWORD": "s3cr3t"},
+		},
+		{
+			name:    "error loading secrets",
+			environ: []string{"DB_PASSWORD=mock://db-password"},
+			loaders: []func(ctx context.Context) error{
+				func(ctx context.Context) error {
+					return errors.New("random error")
+				},
+			},
+			resolver:      &mockSecretResolver{},
+			expectedError: errors.New("random error"),
+		},
+		{
+			name:          "error resolving secret",
+			environ:       []string{"DB_PASSWORD=mock://db-password"},
+			resolver:      &mockSecretResolver{err: errors.New("random error")},
+			expectedError: errors.New("resolving DB_PASSWORD: random error"),
+		},
+		{
+			name:    "error setting env var",
+			environ: []string{"DB_PASSWORD=mock://db-password"},
+			resolver: &mockSecretResolver{
+				secrets: map[string]string{"db-password": "s3cr3t"},
+			},
+			mockedOsSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("setting DB_PASSWORD: random error"),
+		},
+	}
+	loaders, resolvers, environFunc, setenvFunc := secretLoaders, secretResolvers, osEnviron, osSetenv
+	t.Cleanup(func() {
+		secretLoaders, secretResolvers, osEnviron, osSetenv = loaders, resolvers, environFunc, setenvFunc
+	})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secretLoaders = tc.loaders
+			secretResolvers = map[string]secretResolver{"mock://": tc.resolver}
+			osEnviron = func() []string {
+				return tc.environ
+			}
+			environ := make(map[string]string)
+			osSetenv = func(key, value string) error {
+				if tc.mockedOsSetenv != nil {
+					return tc.mockedOsSetenv(key, value)
+				}
+				environ[key] = value
+				return nil
+			}
+			err := resolveSecretRefs({{ if .Context }}context.Background(){{ end }})
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedEnviron, environ)
+			}
+		})
+	}
+}
+
+func TestLazyClient(t *testing.T) {
+	var creations int
+	l := &lazyClient[string]{
+		create: func(ctx context.Context) (string, error) {
+			creations++
+			if creations == 1 {
+				return "", errors.New("random error")
+			}
+			return "client", nil
+		},
+	}
+	_, err := l.get(context.Background())
+	require.EqualError(t, err, "random error")
+	for i := 0; i < 2; i++ {
+		client, err := l.get(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "client", client)
+	}
+	require.Equal(t, 2, creations)
+}
+`
+
+	awsSecretsFileName                 = "secrets_aws.go"
+	awsSecretsUnitTestFileName         = "secrets_aws_test.go"
+	awsSecretsFileTemplateName         = "awsSecretsFile"
+	awsSecretsUnitTestFileTemplateName = "awsSecretsUnitTestFile"
+	awsSecretsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// awsSecretRefPrefix is the prefix of references to secrets
+// stored in AWS Secrets Manager, e.g. 'aws-sm://db-password'.
+const awsSecretRefPrefix = "aws-sm://"
+
+// awsSecretsManagerClient abstracts the AWS Secrets Manager client.
+type awsSecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// For ease of unit testing.
+var newAWSSecretsManagerClient = func(ctx context.Context) (awsSecretsManagerClient, error) {
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading AWS config")
+	}
+	return secretsmanager.NewFromConfig(awsConfig), nil
+}
+
+func init() {
+	secretResolvers[awsSecretRefPrefix] = &awsSecretResolver{
+		client: lazyClient[awsSecretsManagerClient]{
+			create: func(ctx context.Context) (awsSecretsManagerClient, error) {
+				return newAWSSecretsManagerClient(ctx)
+			},
+		},
+	}
+}
+
+// awsSecretResolver resolves references to secrets stored in
+// AWS Secrets Manager, using the default credential chain.
+type awsSecretResolver struct {
+	client lazyClient[awsSecretsManagerClient]
+}
+
+func (r *awsSecretResolver) resolve(ctx context.Context, ref string) (string, error) {
+	client, err := r.client.get(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "creating AWS Secrets Manager client")
+	}
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(ref),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "getting secret %s from AWS Secrets Manager", ref)
+	}
+	if output.SecretString == nil {
+		return "", errors.Errorf("secret %s has no string value", ref)
+	}
+	return *output.SecretString, nil
+}
+`
+
+	awsSecretsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAWSSecretsManagerClient struct {
+	output *secretsmanager.GetSecretValueOutput
+	err    error
+}
+
+func (m *mockAWSSecretsManagerClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	return m.output, m.err
+}
+
+func TestAWSSecretResolver(t *testing.T) {
+	testCases := []struct {
+		name           string
+		client         *mockAWSSecretsManagerClient
+		clientErr      error
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			client: &mockAWSSecretsManagerClient{
+				output: &secretsmanager.GetSecretValueOutput{SecretString: aws.String("s3cr3t")},
+			},
+			expectedOutput: "s3cr3t",
+		},
+		{
+			name:          "error creating client",
+			clientErr:     errors.New("random error"),
+			expectedError: errors.New("creating AWS Secrets Manager client: random error"),
+		},
+		{
+			name:          "error getting secret",
+			client:        &mockAWSSecretsManagerClient{err: errors.New("random error")},
+			expectedError: errors.New("getting secret db-password from AWS Secrets Manager: random error"),
+		},
+		{
+			name: "secret without string value",
+			client: &mockAWSSecretsManagerClient{
+				output: &secretsmanager.GetSecretValueOutput{SecretBinary: []byte("s3cr3t")},
+			},
+			expectedError: errors.New("secret db-password has no string value"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &awsSecretResolver{
+				client: lazyClient[awsSecretsManagerClient]{
+					create: func(ctx context.Context) (awsSecretsManagerClient, error) {
+						return tc.client, tc.clientErr
+					},
+				},
+			}
+			output, err := r.resolve(context.Background(), "db-password")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+`
+
+	gcpSecretsFileName                 = "secrets_gcp.go"
+	gcpSecretsUnitTestFileName         = "secrets_gcp_test.go"
+	gcpSecretsFileTemplateName         = "gcpSecretsFile"
+	gcpSecretsUnitTestFileTemplateName = "gcpSecretsUnitTestFile"
+	gcpSecretsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/pkg/errors"
+)
+
+// gcpSecretRefPrefix is the prefix of references to secrets stored in
+// Google Secret Manager, e.g. 'gcp-sm://projects/my-project/secrets/db-password'.
+// The latest version is used unless one is given, e.g. '.../versions/2'.
+const gcpSecretRefPrefix = "gcp-sm://"
+
+// gcpSecretManagerClient abstracts the Google Secret Manager client.
+type gcpSecretManagerClient interface {
+	AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error)
+}
+
+// For ease of unit testing.
+var newGCPSecretManagerClient = func(ctx context.Context) (gcpSecretManagerClient, error) {
+	return secretmanager.NewClient(ctx)
+}
+
+func init() {
+	secretResolvers[gcpSecretRefPrefix] = &gcpSecretResolver{
+		client: lazyClient[gcpSecretManagerClient]{
+			create: func(ctx context.Context) (gcpSecretManagerClient, error) {
+				return newGCPSecretManagerClient(ctx)
+			},
+		},
+	}
+}
+
+// gcpSecretResolver resolves references to secrets stored in Google
+// Secret Manager, using Application Default Credentials.
+type gcpSecretResolver struct {
+	client lazyClient[gcpSecretManagerClient]
+}
+
+func (r *gcpSecretResolver) resolve(ctx context.Context, ref string) (string, error) {
+	client, err := r.client.get(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "creating Google Secret Manager client")
+	}
+	name := ref
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	output, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+		Name: name,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "accessing secret %s in Google Secret Manager", name)
+	}
+	return string(output.GetPayload().GetData()), nil
+}
+`
+
+	gcpSecretsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGCPSecretManagerClient struct {
+	versions map[string]string
+	err      error
+}
+
+func (m *mockGCPSecretManagerClient) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(m.versions[req.Name])},
+	}, nil
+}
+
+func TestGCPSecretResolver(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ref            string
+		client         *mockGCPSecretManagerClient
+		clientErr      error
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			ref:  "projects/app/secrets/db-password",
+			client: &mockGCPSecretManagerClient{
+				versions: map[string]string{"projects/app/secrets/db-password/versions/latest": "s3cr3t"},
+			},
+			expectedOutput: "s3cr3t",
+		},
+		{
+			name: "specific version",
+			ref:  "projects/app/secrets/db-password/versions/2",
+			client: &mockGCPSecretManagerClient{
+				versions: map[string]string{"projects/app/secrets/db-password/versions/2": "0ld"},
+			},
+			expectedOutput: "0ld",
+		},
+		{
+			name:          "error creating client",
+			ref:           "projects/app/secrets/db-password",
+			clientErr:     errors.New("random error"),
+			expectedError: errors.New("creating Google Secret Manager client: random error"),
+		},
+		{
+			name:          "error accessing secret",
+			ref:           "projects/app/secrets/db-password",
+			client:        &mockGCPSecretManagerClient{err: errors.New("random error")},
+			expectedError: errors.New("accessing secret projects/app/secrets/db-password/versions/latest in Google Secret Manager: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &gcpSecretResolver{
+				client: lazyClient[gcpSecretManagerClient]{
+					create: func(ctx context.Context) (gcpSecretManagerClient, error) {
+						return tc.client, tc.clientErr
+					},
+				},
+			}
+			output, err := r.resolve(context.Background(), tc.ref)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+`
+
+	azureSecretsFileName                 = "secrets_azure.go"
+	azureSecretsUnitTestFileName         = "secrets_azure_test.go"
+	azureSecretsFileTemplateName         = "azureSecretsFile"
+	azureSecretsUnitTestFileTemplateName = "azureSecretsUnitTestFile"
+	azureSecretsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/pkg/errors"
+)
+
+// azureSecretRefPrefix is the prefix of references to secrets stored in
+// Azure Key Vault, e.g. 'akv://vault-name/db-password'. The latest version
+// is used unless one is given, e.g. 'akv://vault-name/db-password/version'.
+const azureSecretRefPrefix = "akv://"
+
+// azureKeyVaultClient abstracts the Azure Key Vault secrets client.
+type azureKeyVaultClient interface {
+	GetSecret(ctx context.Context, name string, version string, options *azsecrets.GetSecretOptions) (azsecrets.GetSecretResponse, error)
+}
+
+// For ease of unit testing.
+var newAzureKeyVaultClient = func(vaultName string) (azureKeyVaultClient, error) {
+	credential, err := azidentity.NewManagedIdentityCredential(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating managed identity credential")
+	}
+	return azsecrets.NewClient(fmt.Sprintf("https://%s.vault.azure.net/", vaultName), credential, nil)
+}
+
+func init() {
+	secretResolvers[azureSecretRefPrefix] = &azureSecretResolver{}
+}
+
+// azureSecretResolver resolves references to secrets stored in
+// Azure Key Vault, authenticating with a managed identity.
+type azureSecretResolver struct {
+	mu      sync.Mutex
+	clients map[string]azureKeyVaultClient
+}
+
+// client returns the client of the given vault, creating it if needed.
+func (r *azureSecretResolver) client(vaultName string) (azureKeyVaultClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client, ok := r.clients[vaultName]; ok {
+		return client, nil
+	}
+	client, err := newAzureKeyVaultClient(vaultName)
+	if err != nil {
+		return nil, err
+	}
+	if r.clients == nil {
+		r.clients = make(map[string]azureKeyVaultClient)
+	}
+	r.clients[vaultName] = client
+	return client, nil
+}
+
+func (r *azureSecretResolver) resolve(ctx context.Context, ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", errors.Errorf("invalid Azure Key Vault reference %s, expected vault-name/secret-name[/version]", ref)
+	}
+	vaultName, name, version := parts[0], parts[1], ""
+	if len(parts) == 3 {
+		version = parts[2]
+	}
+	client, err := r.client(vaultName)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating Azure Key Vault client for %s", vaultName)
+	}
+	output, err := client.GetSecret(ctx, name, version, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting secret %s from Azure Key Vault %s", name, vaultName)
+	}
+	if output.Value == nil {
+		return "", errors.Errorf("secret %s has no value", name)
+	}
+	return *output.Value, nil
+}
+`
+
+	azureSecretsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAzureKeyVaultClient struct {
+	secrets map[string]*string
+	err     error
+}
+
+func (m *mockAzureKeyVaultClient) GetSecret(ctx context.Context, name string, version string, options *azsecrets.GetSecretOptions) (azsecrets.GetSecretResponse, error) {
+	var resp azsecrets.GetSecretResponse
+	resp.Value = m.secrets[name+"@"+version]
+	return resp, m.err
+}
+
+func TestAzureSecretResolver(t *testing.T) {
+	secret := func(value string) *string {
+		return &value
+	}
+	testCases := []struct {
+		name           string
+		ref            string
+		client         *mockAzureKeyVaultClient
+		clientErr      error
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "happy path",
+			ref:  "app-vault/db-password",
+			client: &mockAzureKeyVaultClient{
+				secrets: map[string]*string{"db-password@": secret("s3cr3t")},
+			},
+			expectedOutput: "s3cr3t",
+		},
+		{
+			name: "specific version",
+			ref:  "app-vault/db-password/v2",
+			client: &mockAzureKeyVaultClient{
+				secrets: map[string]*string{"db-password@v2": secret("0ld")},
+			},
+			expectedOutput: "0ld",
+		},
+		{
+			name:          "invalid reference",
+			ref:           "app-vault",
+			expectedError: errors.New("invalid Azure Key Vault reference app-vault, expected vault-name/secret-name[/version]"),
+		},
+		{
+			name:          "error creating client",
+			ref:           "app-vault/db-password",
+			clientErr:     errors.New("random error"),
+			expectedError: errors.New("creating Azure Key Vault client for app-vault: random error"),
+		},
+		{
+			name:          "error getting secret",
+			ref:           "app-vault/db-password",
+			client:        &mockAzureKeyVaultClient{err: errors.New("random error")},
+			expectedError: errors.New("getting secret db-password from Azure Key Vault app-vault: random error"),
+		},
+		{
+			name:          "secret without value",
+			ref:           "app-vault/db-password",
+			client:        &mockAzureKeyVaultClient{},
+			expectedError: errors.New("secret db-password has no value"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var vaultNames []string
+			newAzureKeyVaultClient = func(vaultName string) (azureKeyVaultClient, error) {
+				vaultNames = append(vaultNames, vaultName)
+				return tc.client, tc.clientErr
+			}
+			r := &azureSecretResolver{}
+			output, err := r.resolve(context.Background(), tc.ref)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedOutput, output)
+				_, err = r.resolve(context.Background(), tc.ref)
+				require.NoError(t, err)
+				require.Equal(t, []string{"app-vault"}, vaultNames)
+			}
+		})
+	}
+}
+`
+
+	onePasswordSecretsFileName                 = "secrets_1password.go"
+	onePasswordSecretsUnitTestFileName         = "secrets_1password_test.go"
+	onePasswordSecretsFileTemplateName         = "onePasswordSecretsFile"
+	onePasswordSecretsUnitTestFileTemplateName = "onePasswordSecretsUnitTestFile"
+	onePasswordSecretsFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"strings"
+
+	"github.com/1Password/connect-sdk-go/connect"
+	"github.com/1Password/connect-sdk-go/onepassword"
+	"github.com/pkg/errors"
+)
+
+// onePasswordSecretRefPrefix is the prefix of references to fields of
+// 1Password items, e.g. 'op://vault/item/field', or
+// 'op://vault/item/section/field' for fields of a section. Vaults, items,
+// sections and fields are given by either their titles or their IDs.
+const onePasswordSecretRefPrefix = "op://"
+
+// onePasswordClient abstracts the 1Password Connect client.
+type onePasswordClient interface {
+	GetItem(itemQuery, vaultQuery string) (*onepassword.Item, error)
+}
+
+// For ease of unit testing.
+var newOnePasswordClient = func() (onePasswordClient, error) {
+	return connect.NewClientFromEnvironment()
+}
+
+func init() {
+	secretResolvers[onePasswordSecretRefPrefix] = &onePasswordSecretResolver{
+		client: lazyClient[onePasswordClient]{
+			create: func(ctx context.Context) (onePasswordClient, error) {
+				return newOnePasswordClient()
+			},
+		},
+	}
+}
+
+// onePasswordSecretResolver resolves references to fields of 1Password
+// items through 1Password Connect, whose server and token are set by the
+// OP_CONNECT_HOST and OP_CONNECT_TOKEN env vars.
+type onePasswordSecretResolver struct {
+	client lazyClient[onePasswordClient]
+}
+
+func (r *onePasswordSecretResolver) resolve(ctx context.Context, ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	valid := len(parts) == 3 || len(parts) == 4
+	for _, part := range parts {
+		valid = valid && part != ""
+	}
+	if !valid {
+		return "", errors.Errorf("invalid 1Password reference %s, expected vault/item/[section/]field", ref)
+	}
+	vault, item, section, field := parts[0], parts[1], "", parts[len(parts)-1]
+	if len(parts) == 4 {
+		section = parts[2]
+	}
+	client, err := r.client.get(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "creating 1Password Connect client")
+	}
+	output, err := client.GetItem(item, vault)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting item %s from 1Password vault %s", item, vault)
+	}
+	for _, f := range output.Fields {
+		if f.Label != field && f.ID != field {
+			continue
+		}
+		if section != "" && (f.Section == nil || f.Section.Label != section && f.Section.ID != section) {
+			continue
+		}
+		return f.Value, nil
+	}
+	return "", errors.Errorf("field %s not found in item %s", strings.Join(parts[2:], "/"), item)
+}
+`
+
+	onePasswordSecretsUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOnePasswordClient struct {
+	items map[string]*onepassword.Item
+	err   error
+}
+
+func (m *mockOnePasswordClient) GetItem(itemQuery, vaultQuery string) (*onepassword.Item, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	item, ok := m.items[vaultQuery+"/"+itemQuery]
+	if !ok {
+		return nil, errors.New("item not found")
+	}
+	return item, nil
+}
+
+func TestOnePasswordSecretResolver(t *testing.T) {
+	client := &mockOnePasswordClient{
+		items: map[string]*onepassword.Item{
+			"app/db": {
+				Fields: []*onepassword.ItemField{
+					{ID: "username", Label: "username", Value: "admin"},
+					{ID: "password", Label: "password", Value: "s3cr3t"},
+					{ID: "x7kd2", Label: "password", Value: "r3pl1c4", Section: &onepassword.ItemSection{ID: "s1", Label: "replica"}},
+				},
+			},
+		},
+	}
+	testCases := []struct {
+		name           string
+		ref            string
+		client         *mockOnePasswordClient
+		clientErr      error
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:           "happy path",
+			ref:            "app/db/password",
+			client:         client,
+			expectedOutput: "s3cr3t",
+		},
+		{
+			name:           "field of a section",
+			ref:            "app/db/replica/password",
+			client:         client,
+			expectedOutput: "r3pl1c4",
+		},
+		{
+			name:           "field given by ID",
+			ref:            "app/db/s1/x7kd2",
+			client:         client,
+			expectedOutput: "r3pl1c4",
+		},
+		{
+			name:          "invalid reference",
+			ref:           "app/db",
+			expectedError: errors.New("invalid 1Password reference app/db, expected vault/item/[section/]field"),
+		},
+		{
+			name:          "empty field",
+			ref:           "app/db/",
+			expectedError: errors.New("invalid 1Password reference app/db/, expected vault/item/[section/]field"),
+		},
+		{
+			name:          "error creating client",
+			ref:           "app/db/password",
+			clientErr:     errors.New("random error"),
+			expectedError: errors.New("creating 1Password Connect client: random error"),
+		},
+		{
+			name:          "error getting item",
+			ref:           "app/api/key",
+			client:        client,
+			expectedError: errors.New("getting item api from 1Password vault app: item not found"),
+		},
+		{
+			name:          "field not found",
+			ref:           "app/db/primary/password",
+			client:        client,
+			expectedError: errors.New("field primary/password not found in item db"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &onePasswordSecretResolver{
+				client: lazyClient[onePasswordClient]{
+					create: func(ctx context.Context) (onePasswordClient, error) {
+						return tc.client, tc.clientErr
+					},
+				},
+			}
+			output, err := r.resolve(context.Background(), tc.ref)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+`
+)
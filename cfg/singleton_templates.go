@@ -0,0 +1,148 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	singletonFileName                 = "singleton.go"
+	singletonUnitTestFileName         = "singleton_test.go"
+	singletonFileTemplateName         = "singletonFile"
+	singletonUnitTestFileTemplateName = "singletonUnitTestFile"
+	singletonFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+{{- if .Context }}
+
+import (
+	"context"
+	"sync"
+)
+{{- else }}
+
+import "sync"
+{{- end }}
+
+var (
+	instanceOnce sync.Once
+	instanceMu   sync.RWMutex
+	instance     *{{ .StructName }}
+	instanceErr  error
+)
+
+// Get returns the app configuration. It's read with {{ .ReaderName }} on first
+// access, and the same configuration (or error) is returned afterwards.
+// It's safe for concurrent use.
+func Get() (*{{ .StructName }}, error) {
+	instanceOnce.Do(func() {
+		config, err := {{ .ReaderName }}({{ if .Context }}context.Background(){{ end }})
+		instanceMu.Lock()
+		defer instanceMu.Unlock()
+		instance, instanceErr = config, err
+	})
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	return instance, instanceErr
+}
+
+// Set sets the configuration returned by Get, which then won't read it.
+// It's meant to be used by tests.
+func Set(config *{{ .StructName }}) {
+	instanceOnce.Do(func() {})
+	instanceMu.Lock()
+	defer instanceMu.Unlock()
+	instance, instanceErr = config, nil
+}
+`
+
+	singletonUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		mockedGodotenvLoad     func(filenames ...string) (err error)
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedError          error
+	}{
+		{
+			name: "happy path",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+		},
+		{
+			name: "error reading config",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("loading env vars from .env file: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			instanceOnce = sync.Once{}
+			var loads int
+			godotenvLoad = func(filenames ...string) (err error) {
+				loads++
+				return tc.mockedGodotenvLoad(filenames...)
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+{{- if .Profiles }}
+			osGetenv = func(key string) string {
+				return ""
+			}
+{{- end }}
+			configs := make([]*{{ .StructName }}, 10)
+			errs := make([]error, 10)
+			var wg sync.WaitGroup
+			for i := range configs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					configs[i], errs[i] = Get()
+				}(i)
+			}
+			wg.Wait()
+			require.Equal(t, 1, loads)
+			for i := range configs {
+				if tc.expectedError != nil {
+					require.Nil(t, configs[i])
+					require.ErrorContains(t, errs[i], tc.expectedError.Error())
+					continue
+				}
+				require.NoError(t, errs[i])
+				require.Same(t, configs[0], configs[i])
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	instanceOnce = sync.Once{}
+	godotenvLoad = func(filenames ...string) (err error) {
+		return errors.New("random error")
+	}
+	config := new({{ .StructName }})
+	Set(config)
+	got, err := Get()
+	require.NoError(t, err)
+	require.Same(t, config, got)
+}
+`
+)
@@ -0,0 +1,31 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import "sort"
+
+// SortOrder identifies the order in which the fields of the
+// generated 'Config' struct are emitted.
+type SortOrder string
+
+const (
+	// SourceOrder emits fields in the order their variables are
+	// defined in the env files. It's the default.
+	SourceOrder SortOrder = "source"
+	// FieldsOrder emits fields sorted alphabetically by their names,
+	// so that reordering variables in the env files doesn't change
+	// the generated code.
+	FieldsOrder SortOrder = "fields"
+)
+
+// sortEnvVars sorts the given variables in the sort order of this generator.
+func (g *generator) sortEnvVars(vars []envVar) {
+	if g.sortOrder != FieldsOrder {
+		return
+	}
+	sort.SliceStable(vars, func(i, j int) bool {
+		return g.fieldName(vars[i].key) < g.fieldName(vars[j].key)
+	})
+}
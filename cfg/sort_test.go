@@ -0,0 +1,46 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sortEnvVars(t *testing.T) {
+	testCases := []struct {
+		name         string
+		opts         []Option
+		expectedKeys []string
+	}{
+		{
+			name:         "source order",
+			expectedKeys: []string{"PORT", "DB_HOST", "API_KEY", "DB_PORT"},
+		},
+		{
+			name:         "fields order",
+			opts:         []Option{WithSortOrder(FieldsOrder)},
+			expectedKeys: []string{"API_KEY", "DB_HOST", "DB_PORT", "PORT"},
+		},
+		{
+			name:         "fields order with custom field namer",
+			opts:         []Option{WithSortOrder(FieldsOrder), WithFieldNamer(func(envKey string) string { return "X" + envKey[len(envKey)-2:] })},
+			expectedKeys: []string{"API_KEY", "PORT", "DB_PORT", "DB_HOST"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			vars := []envVar{{key: "PORT"}, {key: "DB_HOST"}, {key: "API_KEY"}, {key: "DB_PORT"}}
+			g.sortEnvVars(vars)
+			var keys []string
+			for _, v := range vars {
+				keys = append(keys, v.key)
+			}
+			require.Equal(t, tc.expectedKeys, keys)
+		})
+	}
+}
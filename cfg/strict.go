@@ -0,0 +1,31 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import "sort"
+
+// knownEnvVarKeys returns the names of the env vars the generated package
+// reads, sorted: the ones of the given variables, along with the '_FILE'
+// variants of the sensitive ones, the schema version env var and APP_ENV
+// when these are read as well.
+func (g *generator) knownEnvVarKeys(vars []envVar) []string {
+	keys := make([]string, 0, len(vars))
+	for _, v := range vars {
+		keys = append(keys, g.prefixedKey(v.key))
+	}
+	if g.secretFiles {
+		for _, key := range secretFileVarKeys(vars) {
+			keys = append(keys, g.prefixedKey(key)+"_FILE")
+		}
+	}
+	if g.schemaVersion != 0 {
+		keys = append(keys, g.envPrefix()+"_CONFIG_SCHEMA_VERSION")
+	}
+	if g.profiles {
+		keys = append(keys, "APP_ENV")
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	strictFileName                 = "strict.go"
+	strictUnitTestFileName         = "strict_test.go"
+	strictFileTemplateName         = "strictFile"
+	strictUnitTestFileTemplateName = "strictUnitTestFile"
+	strictFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// knownEnvVars are the env vars with the {{ .EnvPrefix }}_ prefix
+// that are read by the package.
+var knownEnvVars = map[string]bool{
+{{- range .KnownEnvVars }}
+	{{ printf "%q" . }}: true,
+{{- end }}
+}
+
+// For ease of unit testing.
+var (
+	checkEnvVars  = checkUnknownEnvVars
+	strictEnviron = os.Environ
+)
+
+// strictLogger logs unknown env vars instead of failing, if not nil.
+var strictLogger *slog.Logger
+
+// SetStrictLogger sets the logger unknown env vars are logged to as
+// warnings, instead of failing to read configuration, e.g. while rolling
+// out strict mode. It must be set before reading configuration.
+func SetStrictLogger(logger *slog.Logger) {
+	strictLogger = logger
+}
+
+// checkUnknownEnvVars fails when env vars with the {{ .EnvPrefix }}_ prefix
+// that don't map to any field of {{ .StructName }} are set, e.g. misspelled
+// ones like '{{ .EnvPrefix }}_DB_HOSTT', which would otherwise be silently
+// ignored. With strictLogger, these are logged instead.
+func checkUnknownEnvVars({{ if .Context }}ctx context.Context{{ end }}) error {
+	var unknown []string
+	for _, envVar := range strictEnviron() {
+		name, _, _ := strings.Cut(envVar, "=")
+		if strings.HasPrefix(name, envPrefix+"_") && !knownEnvVars[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	if strictLogger == nil {
+		return errors.Errorf("unknown env vars %s", strings.Join(unknown, ", "))
+	}
+	for _, name := range unknown {
+		strictLogger.{{ if .Context }}WarnContext(ctx, {{ else }}Warn({{ end }}"unknown env var", slog.String("env_var", name))
+	}
+	return nil
+}
+`
+
+	strictUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"bytes"
+{{- if .Context }}
+	"context"
+{{- end }}
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// The other tests don't depend on the env vars of the environment
+	// running them.
+	checkEnvVars = func({{ if .Context }}ctx context.Context{{ end }}) error {
+		return nil
+	}
+}
+
+func TestCheckUnknownEnvVars(t *testing.T) {
+	testCases := []struct {
+		name          string
+		env           []string
+		withLogger    bool
+		expectedLog   string
+		expectedError error
+	}{
+		{
+			name: "happy path",
+			env:  []string{"{{ .EnvPrefix }}_DB_HOST=localhost", "HOME=/root", "{{ .EnvPrefix }}DB_HOSTT=localhost"},
+		},
+		{
+			name:          "unknown env vars",
+			env:           []string{"{{ .EnvPrefix }}_DB_HOST=localhost", "{{ .EnvPrefix }}_DB_PORTT=5432", "{{ .EnvPrefix }}_DB_HOSTT=localhost"},
+			expectedError: errors.New("unknown env vars {{ .EnvPrefix }}_DB_HOSTT, {{ .EnvPrefix }}_DB_PORTT"),
+		},
+		{
+			name:        "unknown env vars with logger",
+			env:         []string{"{{ .EnvPrefix }}_DB_HOST=localhost", "{{ .EnvPrefix }}_DB_HOSTT=localhost"},
+			withLogger:  true,
+			expectedLog: "level=WARN msg=\"unknown env var\" env_var={{ .EnvPrefix }}_DB_HOSTT\n",
+		},
+	}
+	known := knownEnvVars
+	t.Cleanup(func() {
+		knownEnvVars = known
+		strictLogger = nil
+	})
+	knownEnvVars = map[string]bool{"{{ .EnvPrefix }}_DB_HOST": true}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var log bytes.Buffer
+			SetStrictLogger(nil)
+			if tc.withLogger {
+				SetStrictLogger(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{
+					ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+						if a.Key == slog.TimeKey {
+							return slog.Attr{}
+						}
+						return a
+					},
+				})))
+			}
+			strictEnviron = func() []string {
+				return tc.env
+			}
+			err := checkUnknownEnvVars({{ if .Context }}context.Background(){{ end }})
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.Equal(t, tc.expectedLog, log.String())
+			}
+		})
+	}
+}
+`
+)
@@ -0,0 +1,48 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_knownEnvVarKeys(t *testing.T) {
+	vars := []envVar{
+		{key: "PORT"},
+		{key: "APP_DB_PASSWORD"},
+		{key: "APP_DB_HOST"},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput []string
+	}{
+		{
+			name:           "env vars",
+			expectedOutput: []string{"APP_DB_HOST", "APP_DB_PASSWORD", "APP_PORT"},
+		},
+		{
+			name:           "secret files",
+			opts:           []Option{WithSecretFiles()},
+			expectedOutput: []string{"APP_DB_HOST", "APP_DB_PASSWORD", "APP_DB_PASSWORD_FILE", "APP_PORT"},
+		},
+		{
+			name:           "schema version and profiles",
+			opts:           []Option{WithSchemaVersion(2), WithProfiles()},
+			expectedOutput: []string{"APP_CONFIG_SCHEMA_VERSION", "APP_DB_HOST", "APP_DB_PASSWORD", "APP_ENV", "APP_PORT"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &generator{prefix: "APP_"}
+			for _, opt := range tc.opts {
+				opt(g)
+			}
+			require.Equal(t, tc.expectedOutput, g.knownEnvVarKeys(vars))
+		})
+	}
+}
@@ -6,11 +6,25 @@ package cfg
 
 import (
 	"strings"
+	"unicode"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+// commonInitialisms is the set of initialisms that golint
+// expects to be written in upper case.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true,
+	"QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true,
+	"SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+	"XMPP": true, "XSRF": true, "XSS": true,
+}
+
 // toCamelCase converts a string to camel case.
 func toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
@@ -20,3 +34,46 @@ func toCamelCase(s string) string {
 	}
 	return strings.Join(parts, "")
 }
+
+// CamelCaseFieldNamer maps an env var name to a struct field name by
+// title casing each of its underscore separated parts,
+// e.g. 'DB_HOST' becomes 'DbHost'. This is the default field namer.
+func CamelCaseFieldNamer(envKey string) string {
+	return toCamelCase(envKey)
+}
+
+// PascalCaseFieldNamer works like CamelCaseFieldNamer, but keeps the
+// capitalization of mixed case parts, e.g. 'apiKey_ID' becomes 'ApiKeyId'.
+func PascalCaseFieldNamer(envKey string) string {
+	parts := strings.Split(envKey, "_")
+	c := cases.Title(language.Und)
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if strings.ToUpper(part) == part || strings.ToLower(part) == part {
+			parts[i] = c.String(strings.ToLower(part))
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		parts[i] = string(runes)
+	}
+	return strings.Join(parts, "")
+}
+
+// GolintFieldNamer works like CamelCaseFieldNamer, but writes common
+// initialisms in upper case as golint expects, e.g. 'DATABASE_URL'
+// becomes 'DatabaseURL'.
+func GolintFieldNamer(envKey string) string {
+	parts := strings.Split(envKey, "_")
+	c := cases.Title(language.Und)
+	for i, part := range parts {
+		if commonInitialisms[strings.ToUpper(part)] {
+			parts[i] = strings.ToUpper(part)
+			continue
+		}
+		parts[i] = c.String(strings.ToLower(part))
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldNamers(t *testing.T) {
+	testCases := []struct {
+		envKey         string
+		expectedCamel  string
+		expectedPascal string
+		expectedGolint string
+	}{
+		{envKey: "DB_HOST", expectedCamel: "DbHost", expectedPascal: "DbHost", expectedGolint: "DbHost"},
+		{envKey: "DATABASE_URL", expectedCamel: "DatabaseUrl", expectedPascal: "DatabaseUrl", expectedGolint: "DatabaseURL"},
+		{envKey: "user_id", expectedCamel: "UserId", expectedPascal: "UserId", expectedGolint: "UserID"},
+		{envKey: "apiKey_ID", expectedCamel: "ApikeyId", expectedPascal: "ApiKeyId", expectedGolint: "ApikeyID"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.envKey, func(t *testing.T) {
+			require.Equal(t, tc.expectedCamel, CamelCaseFieldNamer(tc.envKey))
+			require.Equal(t, tc.expectedPascal, PascalCaseFieldNamer(tc.envKey))
+			require.Equal(t, tc.expectedGolint, GolintFieldNamer(tc.envKey))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const systemdEnvFileName = "systemd.env"
+
+// systemdEscaper escapes the characters that keep a special meaning
+// in double quoted values of systemd environment files.
+var systemdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+
+// systemdEnvFile returns the given variables, with their env file values,
+// in the format expected by the 'EnvironmentFile' directive of systemd
+// units. Unlike in env files, values are not interpolated, and a backslash
+// escapes any character, so every value is double quoted and escaped.
+func (g *generator) systemdEnvFile(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated by goprojconfig. Reference it from the [Service] section\n")
+	sb.WriteString("# of your unit file with 'EnvironmentFile=/path/to/" + systemdEnvFileName + "'.\n")
+	for _, v := range vars {
+		writeComment(&sb, "", v.comment)
+		sb.WriteString(fmt.Sprintf("%s=\"%s\"\n", v.key, systemdEscaper.Replace(v.value)))
+	}
+	return sb.String(), nil
+}
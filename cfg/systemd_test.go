@@ -0,0 +1,36 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_systemdEnvFile(t *testing.T) {
+	g := NewGenerator("config").(*generator)
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+		{key: "GREETING", value: `say "hi" to $USER`},
+		{key: "WINDOWS_PATH", value: `C:\app`},
+		{key: "COMMAND", value: "`date`"},
+		{key: "PRIVATE_KEY", value: "line1\nline2"},
+		{key: "EMPTY"},
+	}
+	expectedOutput := "# Generated by goprojconfig. Reference it from the [Service] section\n" +
+		"# of your unit file with 'EnvironmentFile=/path/to/systemd.env'.\n" +
+		"# database host\n" +
+		"# required\n" +
+		"DB_HOST=\"localhost\"\n" +
+		"GREETING=\"say \\\"hi\\\" to \\$USER\"\n" +
+		"WINDOWS_PATH=\"C:\\\\app\"\n" +
+		"COMMAND=\"\\`date\\`\"\n" +
+		"PRIVATE_KEY=\"line1\nline2\"\n" +
+		"EMPTY=\"\"\n"
+	output, err := g.systemdEnvFile(vars)
+	require.NoError(t, err)
+	require.Equal(t, expectedOutput, output)
+}
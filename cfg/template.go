@@ -9,26 +9,26 @@ import (
 	"text/template"
 )
 
-// templateExecutor interface abstracts the execution of a parsed template.
+// TemplateExecutor interface abstracts the execution of a parsed template.
 // It requires an Execute method that writes the executed template to an io.Writer.
-type templateExecutor interface {
+type TemplateExecutor interface {
 	Execute(wr io.Writer, data interface{}) error
 }
 
-// templateProcessor interface abstracts the parsing of a template.
+// TemplateProcessor interface abstracts the parsing of a template.
 // It requires a Parse method that takes a template name and text, and returns
-// a templateExecutor and an error, if any.
-type templateProcessor interface {
-	Parse(name, text string) (templateExecutor, error)
+// a TemplateExecutor and an error, if any.
+type TemplateProcessor interface {
+	Parse(name, text string) (TemplateExecutor, error)
 }
 
 // textTemplateProcessor struct is an empty struct that implements the
-// templateProcessor interface using Go's text/template package.
+// TemplateProcessor interface using Go's text/template package.
 type textTemplateProcessor struct{}
 
-// Parse implements the templateProcessor interface. It creates a new text
+// Parse implements the TemplateProcessor interface. It creates a new text
 // template with the provided name and text and returns an textTemplateExecutor.
-func (textTemplateProcessor) Parse(name, text string) (templateExecutor, error) {
+func (textTemplateProcessor) Parse(name, text string) (TemplateExecutor, error) {
 	tmpl, err := template.New(name).Parse(text)
 	if err != nil {
 		return nil, err
@@ -41,7 +41,7 @@ type textTemplateExecutor struct {
 	tmpl *template.Template
 }
 
-// Execute implements the templateExecutor interface. It executes the template
+// Execute implements the TemplateExecutor interface. It executes the template
 // using the provided data and writes the output to the specified io.Writer.
 func (r textTemplateExecutor) Execute(wr io.Writer, data interface{}) error {
 	return r.tmpl.Execute(wr, data)
@@ -5,67 +5,444 @@
 package cfg
 
 const (
-	configReaderPkgPlaceHolder  = "ConfigReaderPkgName"
-	configStructTemplateName    = "ConfigStruct"
-	defaultConfigStructTemplate = `// Config holds all configuration needed by this app.
-type Config struct {
+	configReaderPkgPlaceHolder    = "ConfigReaderPkgName"
+	defaultsFromValuesPlaceHolder = "DefaultsFromValues"
+	profilesPlaceHolder           = "Profiles"
+	secretsPlaceHolder            = "Secrets"
+	hotReloadPlaceHolder          = "HotReload"
+	importPathPlaceHolder         = "ImportPath"
+	configStructTemplateName      = "ConfigStruct"
+	goGeneratePlaceHolder         = "GoGenerate"
+	testEnvFilePlaceHolder        = "TestEnvFile"
+	testEnvVarsPlaceHolder        = "TestEnvVars"
+	testConfigPlaceHolder         = "TestConfig"
+	headerPlaceHolder             = "Header"
+	envPrefixPlaceHolder          = "EnvPrefix"
+	immutablePlaceHolder          = "Immutable"
+	importsPlaceHolder            = "Imports"
+	testImportsPlaceHolder        = "TestImports"
+	testHelpersPlaceHolder        = "TestHelpers"
+	validationPlaceHolder         = "Validation"
+	validatorTagsPlaceHolder      = "ValidatorTags"
+	pathChecksPlaceHolder         = "PathChecks"
+	reloadPlaceHolder             = "Reload"
+	dockerDialectPlaceHolder      = "DockerDialect"
+	schemaVersionPlaceHolder      = "SchemaVersion"
+	debugHandlerPlaceHolder       = "DebugHandler"
+	singletonPlaceHolder          = "Singleton"
+	metricsPlaceHolder            = "Metrics"
+	tracingPlaceHolder            = "Tracing"
+	contextPlaceHolder            = "Context"
+	sensitiveVarsPlaceHolder      = "SensitiveVars"
+	secretFilesPlaceHolder        = "SecretFiles"
+	secretFileVarsPlaceHolder     = "SecretFileVars"
+	schemaEnvVarsPlaceHolder      = "SchemaEnvVars"
+	schemaChecksumPlaceHolder     = "SchemaChecksum"
+	strictPlaceHolder             = "Strict"
+	usageTrackingPlaceHolder      = "UsageTracking"
+	benchEnvFilePlaceHolder       = "BenchEnvFile"
+	maxLineLengthPlaceHolder      = "MaxLineLength"
+	knownEnvVarsPlaceHolder       = "KnownEnvVars"
+	flagsPlaceHolder              = "Flags"
+	featuresPlaceHolder           = "Features"
+	testConfigPackagePlaceHolder  = "TestConfigPackage"
+	providerMethodsPlaceHolder    = "ProviderMethods"
+	providerImportsPlaceHolder    = "ProviderImports"
+	structNamePlaceHolder         = "StructName"
+	readerNamePlaceHolder         = "ReaderName"
+	defaultConfigStructTemplate   = `// %[1]s holds all configuration needed by this app.
+type %[1]s struct {
 	SampleEnvVar string ` + "`envconfig:\"SAMPLE_ENV_VAR\" required:\"true\"`" + `
 }`
 
+	structTemplateName = "struct"
+	structTemplate     = `type {{ .Name }} struct {
+	// TODO: see https://github.com/kelseyhightower/envconfig for all available options
+	// for struct tags.
+{{- range .Fields }}
+{{- with .Doc }}
+	{{ comment . }}
+{{- end }}
+	{{ .Name }} {{ .Type }}{{ with .Tags }} ` + "`{{ . }}`" + `{{ end }}{{ with .Comment }} {{ comment . }}{{ end }}
+{{- end }}
+}
+`
+
 	configReaderUnitTestFileTemplateName    = "configReaderUnitTestFile"
 	configReaderMainFileTemplateName        = "configReaderMainFile"
-	configReaderMainFileTemplatePlaceHolder = `package {{ .ConfigReaderPkgName }}
+	configReaderMainFileTemplatePlaceHolder = `{{ .Header }}// Package {{ .ConfigReaderPkgName }} reads the app configuration from env vars.
+{{- if .ImportPath }}
+//
+// Usage:
+//
+//	import "{{ .ImportPath }}"
+//
+//	config, err := {{ .ConfigReaderPkgName }}.{{ .ReaderName }}({{ if .Context }}ctx{{ end }})
+{{- end }}
+package {{ .ConfigReaderPkgName }}
+{{- if .GoGenerate }}
+
+//go:generate {{ .GoGenerate }}
+{{- end }}
 
 import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+{{- range .Imports }}
+	"{{ . }}"
+{{- end }}
+{{ if .ValidatorTags }}
+	"github.com/go-playground/validator/v10"
+{{- end }}
+{{- if not .DockerDialect }}
 	"github.com/joho/godotenv"
+{{- end }}
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+{{- if .Tracing }}
+	"go.opentelemetry.io/otel/attribute"
+{{- end }}
 )
 
 {{ .ConfigStruct }}
 
+{{- if .EnvPrefix }}
+
+// envPrefix prefixes the names of the env vars read into {{ .StructName }},
+// e.g. '{{ .EnvPrefix }}_DB_HOST' for the 'DB_HOST' tag.
+const envPrefix = "{{ .EnvPrefix }}"
+{{- end }}
+
+{{- if .Profiles }}
+
+// appEnvVar is the environment variable that holds the name
+// of the environment the app is running in.
+const appEnvVar = "APP_ENV"
+{{- end }}
+
 // For ease of unit testing.
 var (
+{{- if .DockerDialect }}
+	godotenvLoad     = loadDockerEnvFiles
+	godotenvParse    = parseDockerEnv
+{{- else }}
 	godotenvLoad     = godotenv.Load
+	godotenvParse    = godotenv.Parse
+{{- end }}
 	envconfigProcess = envconfig.Process
+	lookupEnv        = os.LookupEnv
+	setenv           = os.Setenv
+{{- if .Profiles }}
+	osGetenv         = os.Getenv
+{{- end }}
+{{- if .Validation }}
+	validateConfig   = (*{{ .StructName }}).Validate
+{{- end }}
 )
 
-// Read reads configuration from environment variables.
+// {{ .ReaderName }} reads configuration from environment variables.
 // It assumes that an '.env' file is present at current path.
-func Read() (*Config, error) {
+{{- if .Profiles }}
+// If APP_ENV is set, it works like {{ .ReaderName }}ForEnv for that environment.
+{{- end }}
+func {{ .ReaderName }}({{ if .Context }}ctx context.Context{{ end }}) ({{ if .Tracing }}_ *{{ .StructName }}, err error{{ else }}*{{ .StructName }}, error{{ end }}) {
+{{- if .Profiles }}
+	if appEnv := osGetenv(appEnvVar); appEnv != "" {
+		return {{ .ReaderName }}ForEnv({{ if .Context }}ctx, {{ end }}appEnv)
+	}
+{{- end }}
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "{{ .ReaderName }}", attribute.String("config.env_file", ".env"))
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
 	if err := godotenvLoad(); err != nil {
 		return nil, errors.Wrap(err, "loading env vars from .env file")
 	}
-	config := new(Config)
-	if err := envconfigProcess("", config); err != nil {
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
 		return nil, errors.Wrap(err, "processing env vars")
 	}
 	return config, nil
 }
 
-// ReadFromEnvFile reads configuration from the specified environment file.
-func ReadFromEnvFile(envFilePath string) (*Config, error) {
+// {{ .ReaderName }}FromEnvFile reads configuration from the specified environment file.
+func {{ .ReaderName }}FromEnvFile({{ if .Context }}ctx context.Context, {{ end }}envFilePath string) ({{ if .Tracing }}_ *{{ .StructName }}, err error{{ else }}*{{ .StructName }}, error{{ end }}) {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "{{ .ReaderName }}FromEnvFile", attribute.String("config.env_file", envFilePath))
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
 	if err := godotenvLoad(envFilePath); err != nil {
 		return nil, errors.Wrapf(err, "loading env vars from %s", envFilePath)
 	}
-	config := new(Config)
-	if err := envconfigProcess("", config); err != nil {
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
 		return nil, errors.Wrap(err, "processing env vars")
 	}
 	return config, nil
 }
+
+// {{ .ReaderName }}FromReader reads configuration from the env file read from the given
+// reader, without touching the working dir. As with the other functions,
+// env vars that are already set take precedence over the env file ones.
+func {{ .ReaderName }}FromReader({{ if .Context }}ctx context.Context, {{ end }}r io.Reader) ({{ if .Tracing }}_ *{{ .StructName }}, err error{{ else }}*{{ .StructName }}, error{{ end }}) {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "{{ .ReaderName }}FromReader")
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
+	values, err := godotenvParse(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing env vars")
+	}
+	for key, value := range values {
+		if _, ok := lookupEnv(key); ok {
+			continue
+		}
+		if err := setenv(key, value); err != nil {
+			return nil, errors.Wrapf(err, "setting %s", key)
+		}
+	}
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
+		return nil, errors.Wrap(err, "processing env vars")
+	}
+	return config, nil
+}
+
+// {{ .ReaderName }}FromFS reads configuration from the named env file of the given
+// file system, e.g. an embed.FS, without touching the working dir.
+func {{ .ReaderName }}FromFS({{ if .Context }}ctx context.Context, {{ end }}fsys fs.FS, name string) (*{{ .StructName }}, error) {
+	envFile, err := fsys.Open(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", name)
+	}
+	defer envFile.Close()
+	config, err := {{ .ReaderName }}FromReader({{ if .Context }}ctx, {{ end }}envFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", name)
+	}
+	return config, nil
+}
+
+{{- if .Profiles }}
+
+// {{ .ReaderName }}ForEnv reads configuration for the given environment, e.g. 'dev',
+// 'staging' or 'prod'. It assumes that both '.env' and '.env.<name>' files
+// are present at current path, with the latter taking precedence.
+func {{ .ReaderName }}ForEnv({{ if .Context }}ctx context.Context, {{ end }}name string) ({{ if .Tracing }}_ *{{ .StructName }}, err error{{ else }}*{{ .StructName }}, error{{ end }}) {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "{{ .ReaderName }}ForEnv", attribute.String("config.env", name))
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
+	envFilePath := ".env." + name
+	if err := godotenvLoad(envFilePath, ".env"); err != nil {
+		return nil, errors.Wrapf(err, "loading env vars from %s and .env files", envFilePath)
+	}
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
+		return nil, errors.Wrap(err, "processing env vars")
+	}
+	return config, nil
+}
+{{- end }}
+
+{{- if .DefaultsFromValues }}
+
+// {{ .ReaderName }}WithDefaults reads configuration from environment variables, loading
+// the '.env' file present at current path, if any. Variables that are
+// not set fall back to their defaults.
+func {{ .ReaderName }}WithDefaults({{ if .Context }}ctx context.Context{{ end }}) ({{ if .Tracing }}_ *{{ .StructName }}, err error{{ else }}*{{ .StructName }}, error{{ end }}) {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "{{ .ReaderName }}WithDefaults", attribute.String("config.env_file", ".env"))
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
+	if err := godotenvLoad(); err != nil && !stderrors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Wrap(err, "loading env vars from .env file")
+	}
+	config := new({{ .StructName }})
+	if err := processEnvVars({{ if .Context }}ctx, {{ end }}config); err != nil {
+		return nil, errors.Wrap(err, "processing env vars")
+	}
+	return config, nil
+}
+{{- end }}
+
+// Must{{ .ReaderName }} is like {{ .ReaderName }}, but panics if the configuration can't be read.
+func Must{{ .ReaderName }}({{ if .Context }}ctx context.Context{{ end }}) *{{ .StructName }} {
+	config, err := {{ .ReaderName }}({{ if .Context }}ctx{{ end }})
+	if err != nil {
+		panic(fmt.Sprintf("reading configuration: %v", err))
+	}
+	return config
+}
+
+// Must{{ .ReaderName }}FromEnvFile is like {{ .ReaderName }}FromEnvFile, but panics if
+// the configuration can't be read.
+func Must{{ .ReaderName }}FromEnvFile({{ if .Context }}ctx context.Context, {{ end }}envFilePath string) *{{ .StructName }} {
+	config, err := {{ .ReaderName }}FromEnvFile({{ if .Context }}ctx, {{ end }}envFilePath)
+	if err != nil {
+		panic(fmt.Sprintf("reading configuration from %s: %v", envFilePath, err))
+	}
+	return config
+}
+
+// processEnvVars populates the given config from environment variables.
+{{- if .Context }}
+// It fails right away if the given context is done.
+{{- end }}
+{{- if .SecretFiles }}
+// Sensitive env vars whose '_FILE' variants are set are read from files first.
+{{- end }}
+{{- if .Secrets }}
+// Env vars referencing secrets are resolved first.
+{{- end }}
+{{- if .SchemaChecksum }}
+// Env vars are verified against the config schema before being processed.
+{{- end }}
+{{- if .Strict }}
+// Unknown env vars with the prefix are rejected, or logged with strictLogger.
+{{- end }}
+// Instead of stopping at the first missing or invalid variable, it
+// processes every field and returns all the errors joined together.
+{{- if .Validation }}
+// Once read, the configuration is validated against its constraints.
+{{- end }}
+func processEnvVars({{ if .Context }}ctx context.Context, {{ end }}config *{{ .StructName }}) {{ if .Tracing }}(err error){{ else }}error{{ end }} {
+{{- if .Tracing }}
+	ctx, span := startSpan(ctx, "processEnvVars")
+	defer func() {
+		endSpan(span, err)
+	}()
+{{- end }}
+{{- if .Context }}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+{{- end }}
+{{- if .SecretFiles }}
+	if err := loadSecretFiles(); err != nil {
+		return errors.Wrap(err, "loading secret files")
+	}
+{{- end }}
+{{- if .Secrets }}
+	if err := resolveSecretRefs({{ if .Context }}ctx{{ end }}); err != nil {
+		return errors.Wrap(err, "resolving secrets")
+	}
+{{- end }}
+{{- if .SchemaChecksum }}
+	if err := verifySchema(); err != nil {
+		return errors.Wrap(err, "verifying env vars")
+	}
+{{- end }}
+{{- if .Strict }}
+	if err := checkEnvVars({{ if .Context }}ctx{{ end }}); err != nil {
+		return errors.Wrap(err, "checking env vars")
+	}
+{{- end }}
+	var errs []error
+	v := reflect.ValueOf({{ if .Immutable }}&config.values{{ else }}config{{ end }}).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldConfig := reflect.New(reflect.StructOf([]reflect.StructField{field}))
+		if err := envconfigProcess({{ if .EnvPrefix }}envPrefix{{ else }}""{{ end }}, fieldConfig.Interface()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		value := fieldConfig.Elem().Field(0)
+		// envconfig allocates pointers to structs, e.g. *url.URL, even
+		// when their env vars are not set. They're left nil instead.
+		if value.Kind() == reflect.Pointer && value.Elem().Kind() == reflect.Struct && value.Elem().IsZero() {
+			continue
+		}
+		v.Field(i).Set(value)
+	}
+{{- if .Validation }}
+	if len(errs) > 0 {
+		return stderrors.Join(errs...)
+	}
+	return validateConfig(config)
+{{- else }}
+	return stderrors.Join(errs...)
+{{- end }}
+}
 `
 
-	configReaderUnitTestFileTemplate = `package {{ .ConfigReaderPkgName }}
+	configReaderUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
 
 import (
+{{- if .Context }}
+	"context"
+{{- end }}
 	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
 	"testing"
-
+	"testing/fstest"
+{{ if not .DockerDialect }}
+	"github.com/joho/godotenv"
+{{- end }}
 	"github.com/stretchr/testify/require"
 )
 
-func TestRead(t *testing.T) {
+{{- if .Validation }}
+
+// skipValidation skips the validation of the configuration until the test
+// finishes, for the tests mocking envconfigProcess, which leaves fields
+// with their zero values, which may not satisfy their constraints.
+func skipValidation(t *testing.T) {
+	validate := validateConfig
+	t.Cleanup(func() {
+		validateConfig = validate
+	})
+	validateConfig = func(config *{{ .StructName }}) error {
+		return nil
+	}
+}
+{{- end }}
+{{- if .SchemaChecksum }}
+
+// skipSchemaVerification skips the verification of the env vars against
+// the config schema until the test finishes, for the tests that don't set
+// the required env vars of the schema, e.g. the ones mocking envconfigProcess.
+func skipSchemaVerification(t *testing.T) {
+	verify := verifySchema
+	t.Cleanup(func() {
+		verifySchema = verify
+	})
+	verifySchema = func() error {
+		return nil
+	}
+}
+{{- end }}
+
+func Test{{ .ReaderName }}(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
 	testCases := []struct {
 		name                   string
 		mockedGodotenvLoad     func(filenames ...string) (err error)
@@ -103,13 +480,18 @@ func TestRead(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			godotenvLoad = tc.mockedGodotenvLoad
 			envconfigProcess = tc.mockedEnvconfigProcess
-			config, err := Read()
+{{- if .Profiles }}
+			osGetenv = func(key string) string {
+				return ""
+			}
+{{- end }}
+			config, err := {{ .ReaderName }}({{ if .Context }}context.Background(){{ end }})
 			if err != nil {
 				if tc.expectedError == nil {
 					t.Fatalf("expected no error, got %v", err)
 				}
 				require.Nil(t, config)
-				require.Equal(t, tc.expectedError.Error(), err.Error())
+				require.ErrorContains(t, err, tc.expectedError.Error())
 			} else {
 				if tc.expectedError != nil {
 					t.Fatalf("expected error, got nil")
@@ -120,7 +502,13 @@ func TestRead(t *testing.T) {
 	}
 }
 
-func TestReadFromEnvFile(t *testing.T) {
+func Test{{ .ReaderName }}FromEnvFile(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
 	testCases := []struct {
 		name                   string
 		mockedGodotenvLoad     func(filenames ...string) (err error)
@@ -158,22 +546,607 @@ func TestReadFromEnvFile(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			godotenvLoad = tc.mockedGodotenvLoad
 			envconfigProcess = tc.mockedEnvconfigProcess
-			config, err := ReadFromEnvFile("path/to/.env")
+			config, err := {{ .ReaderName }}FromEnvFile({{ if .Context }}context.Background(), {{ end }}"path/to/.env")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+			}
+		})
+	}
+}
+
+func Test{{ .ReaderName }}FromReader(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		envFile                string
+		env                    map[string]string
+		mockedSetenv           func(key, value string) error
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedEnv            map[string]string
+		expectedError          error
+	}{
+		{
+			name:    "happy path",
+			envFile: "DB_HOST=localhost\nDB_PORT=5432\n",
+			env:     map[string]string{"DB_PORT": "6543"},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+			expectedEnv: map[string]string{"DB_HOST": "localhost", "DB_PORT": "6543"},
+		},
+		{
+			name:          "error parsing env vars",
+			envFile:       {{ if .DockerDialect }}"DB HOST=localhost"{{ else }}"DB_HOST='localhost"{{ end }},
+			expectedError: errors.New("parsing env vars"),
+		},
+		{
+			name:    "error setting env var",
+			envFile: "DB_HOST=localhost\n",
+			mockedSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("setting DB_HOST: random error"),
+		},
+		{
+			name:    "error processing env vars",
+			envFile: "DB_HOST=localhost\n",
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := make(map[string]string)
+			for key, value := range tc.env {
+				env[key] = value
+			}
+			godotenvParse = {{ if .DockerDialect }}parseDockerEnv{{ else }}godotenv.Parse{{ end }}
+			lookupEnv = func(key string) (string, bool) {
+				value, ok := env[key]
+				return value, ok
+			}
+			setenv = func(key, value string) error {
+				env[key] = value
+				return nil
+			}
+			if tc.mockedSetenv != nil {
+				setenv = tc.mockedSetenv
+			}
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}FromReader({{ if .Context }}context.Background(), {{ end }}strings.NewReader(tc.envFile))
 			if err != nil {
 				if tc.expectedError == nil {
 					t.Fatalf("expected no error, got %v", err)
 				}
 				require.Nil(t, config)
-				require.Equal(t, tc.expectedError.Error(), err.Error())
+				require.ErrorContains(t, err, tc.expectedError.Error())
 			} else {
 				if tc.expectedError != nil {
 					t.Fatalf("expected error, got nil")
 				}
 				require.NotNil(t, config)
+				require.Equal(t, tc.expectedEnv, env)
 			}
 		})
 	}
 }
+
+func Test{{ .ReaderName }}FromFS(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	fsys := fstest.MapFS{"config/.env": {Data: []byte("DB_HOST=localhost\n")}}
+	godotenvParse = {{ if .DockerDialect }}parseDockerEnv{{ else }}godotenv.Parse{{ end }}
+	lookupEnv = func(key string) (string, bool) {
+		return "", false
+	}
+	setenv = func(key, value string) error {
+		return nil
+	}
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	config, err := {{ .ReaderName }}FromFS({{ if .Context }}context.Background(), {{ end }}fsys, "config/.env")
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	_, err = {{ .ReaderName }}FromFS({{ if .Context }}context.Background(), {{ end }}fsys, "missing/.env")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return errors.New("random error")
+	}
+	_, err = {{ .ReaderName }}FromFS({{ if .Context }}context.Background(), {{ end }}fsys, "config/.env")
+	require.ErrorContains(t, err, "reading config/.env: processing env vars: random error")
+}
+
+{{- if .DefaultsFromValues }}
+
+func Test{{ .ReaderName }}WithDefaults(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		mockedGodotenvLoad     func(filenames ...string) (err error)
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedError          error
+	}{
+		{
+			name: "happy path",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+		},
+		{
+			name: "missing .env file",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return fs.ErrNotExist
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+		},
+		{
+			name: "error loading env vars",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("loading env vars from .env file: random error"),
+		},
+		{
+			name: "error processing env vars",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			godotenvLoad = tc.mockedGodotenvLoad
+			envconfigProcess = tc.mockedEnvconfigProcess
+			config, err := {{ .ReaderName }}WithDefaults({{ if .Context }}context.Background(){{ end }})
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Nil(t, config)
+				require.ErrorContains(t, err, tc.expectedError.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				require.NotNil(t, config)
+			}
+		})
+	}
+}
+{{- end }}
+
+{{- if .Profiles }}
+
+func Test{{ .ReaderName }}ForEnv(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	testCases := []struct {
+		name                   string
+		appEnv                 string
+		mockedGodotenvLoad     func(filenames ...string) (err error)
+		mockedEnvconfigProcess func(prefix string, spec interface{}) error
+		expectedError          error
+	}{
+		{
+			name:   "happy path",
+			appEnv: "staging",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				if len(filenames) != 2 || filenames[0] != ".env.staging" || filenames[1] != ".env" {
+					return fmt.Errorf("unexpected env files %v", filenames)
+				}
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return nil
+			},
+		},
+		{
+			name:   "error loading env vars",
+			appEnv: "staging",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("loading env vars from .env.staging and .env files: random error"),
+		},
+		{
+			name:   "error processing env vars",
+			appEnv: "staging",
+			mockedGodotenvLoad: func(filenames ...string) (err error) {
+				return nil
+			},
+			mockedEnvconfigProcess: func(prefix string, spec interface{}) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("processing env vars: random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			godotenvLoad = tc.mockedGodotenvLoad
+			envconfigProcess = tc.mockedEnvconfigProcess
+			osGetenv = func(key string) string {
+				return tc.appEnv
+			}
+			for _, read := range []func() (*{{ .StructName }}, error){
+				func() (*{{ .StructName }}, error) { return {{ .ReaderName }}ForEnv({{ if .Context }}context.Background(), {{ end }}tc.appEnv) },
+{{- if .Context }}
+				func() (*{{ .StructName }}, error) { return {{ .ReaderName }}(context.Background()) },
+{{- else }}
+				{{ .ReaderName }},
+{{- end }}
+			} {
+				config, err := read()
+				if err != nil {
+					if tc.expectedError == nil {
+						t.Fatalf("expected no error, got %v", err)
+					}
+					require.Nil(t, config)
+					require.ErrorContains(t, err, tc.expectedError.Error())
+				} else {
+					if tc.expectedError != nil {
+						t.Fatalf("expected error, got nil")
+					}
+					require.NotNil(t, config)
+				}
+			}
+		})
+	}
+}
+{{- end }}
+
+func TestMust{{ .ReaderName }}(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+{{- if .Profiles }}
+	osGetenv = func(key string) string {
+		return ""
+	}
+{{- end }}
+	godotenvLoad = func(filenames ...string) (err error) {
+		return nil
+	}
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	require.NotPanics(t, func() {
+		require.NotNil(t, Must{{ .ReaderName }}({{ if .Context }}context.Background(){{ end }}))
+	})
+	godotenvLoad = func(filenames ...string) (err error) {
+		return errors.New("random error")
+	}
+	require.PanicsWithValue(t, "reading configuration: loading env vars from .env file: random error", func() {
+		Must{{ .ReaderName }}({{ if .Context }}context.Background(){{ end }})
+	})
+}
+
+func TestMust{{ .ReaderName }}FromEnvFile(t *testing.T) {
+{{- if .Validation }}
+	skipValidation(t)
+{{- end }}
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	godotenvLoad = func(filenames ...string) (err error) {
+		return nil
+	}
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	require.NotPanics(t, func() {
+		require.NotNil(t, Must{{ .ReaderName }}FromEnvFile({{ if .Context }}context.Background(), {{ end }}"path/to/.env"))
+	})
+	godotenvLoad = func(filenames ...string) (err error) {
+		return errors.New("random error")
+	}
+	require.PanicsWithValue(t, "reading configuration from path/to/.env: loading env vars from path/to/.env: random error", func() {
+		Must{{ .ReaderName }}FromEnvFile({{ if .Context }}context.Background(), {{ end }}"path/to/.env")
+	})
+}
+
+func TestProcessEnvVars(t *testing.T) {
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	var calls int
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		calls++
+		return fmt.Errorf("error processing field %d", calls)
+	}
+	err := processEnvVars({{ if .Context }}context.Background(), {{ end }}new({{ .StructName }}))
+	require.Error(t, err)
+	require.Equal(t, reflect.TypeOf({{ if .Immutable }}configValues{{ else }}{{ .StructName }}{{ end }}{}).NumField(), calls)
+	for i := 1; i <= calls; i++ {
+		require.ErrorContains(t, err, fmt.Sprintf("error processing field %d", i))
+	}
+}
+{{- if .Validation }}
+
+func TestProcessEnvVarsValidation(t *testing.T) {
+{{- if .SchemaChecksum }}
+	skipSchemaVerification(t)
+{{- end }}
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	validate := validateConfig
+	t.Cleanup(func() {
+		validateConfig = validate
+	})
+	validateConfig = func(config *{{ .StructName }}) error {
+		return errors.New("random error")
+	}
+	require.EqualError(t, processEnvVars({{ if .Context }}context.Background(), {{ end }}new({{ .StructName }})), "random error")
+}
+{{- end }}
+{{- if .Context }}
+
+func TestProcessEnvVarsCanceledContext(t *testing.T) {
+	envconfigProcess = func(prefix string, spec interface{}) error {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, processEnvVars(ctx, new({{ .StructName }})), context.Canceled)
+}
+{{- end }}
+`
+	realConfigReaderUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+{{- range .TestImports }}
+	"{{ . }}"
+{{- end }}
+
+	"github.com/stretchr/testify/require"
+)
+
+// testEnvFile is the env file read by the tests.
+const testEnvFile = {{ .TestEnvFile }}
+
+// testEnvVars are the env vars defined in testEnvFile.
+var testEnvVars = {{ .TestEnvVars }}
+
+// expectedConfig is the configuration read from testEnvFile.
+var expectedConfig = {{ .TestConfig }}
+{{- if .PathChecks }}
+
+func init() {
+	// The paths in testEnvFile may not exist where the tests run.
+	checkFile = func(path string) error {
+		return nil
+	}
+	checkDir = func(path string) error {
+		return nil
+	}
+	checkPath = func(path string) error {
+		return nil
+	}
+}
+{{- end }}
+{{- range .TestHelpers }}
+
+{{ . }}
+{{- end }}
+
+// unsetEnvVars unsets the env vars read into {{ .StructName }} until the test
+// finishes, so that the ones set by the environment running the tests
+// are not read.
+func unsetEnvVars(t *testing.T) {
+	keys := []string{
+{{- if .Profiles }}
+		appEnvVar,
+{{- end }}
+	}
+	configType := reflect.TypeOf({{ if .Immutable }}configValues{{ else }}{{ .StructName }}{{ end }}{})
+	for i := 0; i < configType.NumField(); i++ {
+		if key := configType.Field(i).Tag.Get("envconfig"); key != "" {
+			keys = append(keys, {{ if .EnvPrefix }}envPrefix+"_"+{{ end }}key)
+		}
+	}
+	for _, key := range keys {
+		t.Setenv(key, "")
+		require.NoError(t, os.Unsetenv(key))
+	}
+}
+
+// setEnvVars sets the env vars defined in testEnvFile until the test finishes.
+func setEnvVars(t *testing.T) {
+	for key, value := range testEnvVars {
+		t.Setenv(key, value)
+	}
+}
+
+// writeEnvFile writes the given env file into the given dir.
+func writeEnvFile(t *testing.T, dir, name, content string) string {
+	envFilePath := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(envFilePath, []byte(content), 0644))
+	return envFilePath
+}
+{{- if .Validation }}
+
+// skipValidation skips the validation of the configuration until the test
+// finishes, for the tests mocking envconfigProcess, which leaves fields
+// with their zero values, which may not satisfy their constraints.
+func skipValidation(t *testing.T) {
+	validate := validateConfig
+	t.Cleanup(func() {
+		validateConfig = validate
+	})
+	validateConfig = func(config *{{ .StructName }}) error {
+		return nil
+	}
+}
+{{- end }}
+{{- if .SchemaChecksum }}
+
+// skipSchemaVerification skips the verification of the env vars against
+// the config schema until the test finishes, for the tests that don't set
+// the required env vars of the schema, e.g. the ones mocking envconfigProcess.
+func skipSchemaVerification(t *testing.T) {
+	verify := verifySchema
+	t.Cleanup(func() {
+		verifySchema = verify
+	})
+	verifySchema = func() error {
+		return nil
+	}
+}
+{{- end }}
+
+// chdir changes the working dir to the given dir until the test finishes.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+}
+
+func Test{{ .ReaderName }}(t *testing.T) {
+	unsetEnvVars(t)
+	dir := t.TempDir()
+	writeEnvFile(t, dir, ".env", testEnvFile)
+	chdir(t, dir)
+	config, err := {{ .ReaderName }}({{ if .Context }}context.Background(){{ end }})
+	require.NoError(t, err)
+	require.Equal(t, expectedConfig, config)
+}
+
+func Test{{ .ReaderName }}FromEnvVars(t *testing.T) {
+	unsetEnvVars(t)
+	setEnvVars(t)
+	dir := t.TempDir()
+	writeEnvFile(t, dir, ".env", "")
+	chdir(t, dir)
+	config, err := {{ .ReaderName }}({{ if .Context }}context.Background(){{ end }})
+	require.NoError(t, err)
+	require.Equal(t, expectedConfig, config)
+}
+
+func Test{{ .ReaderName }}FromEnvFile(t *testing.T) {
+	unsetEnvVars(t)
+	config, err := {{ .ReaderName }}FromEnvFile({{ if .Context }}context.Background(), {{ end }}writeEnvFile(t, t.TempDir(), ".env", testEnvFile))
+	require.NoError(t, err)
+	require.Equal(t, expectedConfig, config)
+	_, err = {{ .ReaderName }}FromEnvFile({{ if .Context }}context.Background(), {{ end }}filepath.Join(t.TempDir(), ".env"))
+	require.Error(t, err)
+}
+
+func Test{{ .ReaderName }}FromReader(t *testing.T) {
+	unsetEnvVars(t)
+	config, err := {{ .ReaderName }}FromReader({{ if .Context }}context.Background(), {{ end }}strings.NewReader(testEnvFile))
+	require.NoError(t, err)
+	require.Equal(t, expectedConfig, config)
+}
+
+func Test{{ .ReaderName }}FromFS(t *testing.T) {
+	unsetEnvVars(t)
+	fsys := fstest.MapFS{"config/.env": {Data: []byte(testEnvFile)}}
+	config, err := {{ .ReaderName }}FromFS({{ if .Context }}context.Background(), {{ end }}fsys, "config/.env")
+	require.NoError(t, err)
+	require.Equal(t, expectedConfig, config)
+	_, err = {{ .ReaderName }}FromFS({{ if .Context }}context.Background(), {{ end }}fsys, "missing/.env")
+	require.Error(t, err)
+}
+
+{{- if .DefaultsFromValues }}
+
+func Test{{ .ReaderName }}WithDefaults(t *testing.T) {
+	unsetEnvVars(t)
+	setEnvVars(t)
+	chdir(t, t.TempDir())
+	config, err := {{ .ReaderName }}WithDefaults({{ if .Context }}context.Background(){{ end }})
+	require.NoError(t, err)
+	require.Equal(t, expectedConfig, config)
+}
+{{- end }}
+
+{{- if .Profiles }}
+
+func Test{{ .ReaderName }}ForEnv(t *testing.T) {
+	unsetEnvVars(t)
+	dir := t.TempDir()
+	writeEnvFile(t, dir, ".env", "")
+	writeEnvFile(t, dir, ".env.staging", testEnvFile)
+	chdir(t, dir)
+	config, err := {{ .ReaderName }}ForEnv({{ if .Context }}context.Background(), {{ end }}"staging")
+	require.NoError(t, err)
+	require.Equal(t, expectedConfig, config)
+}
+{{- end }}
+
+func TestMust{{ .ReaderName }}(t *testing.T) {
+	unsetEnvVars(t)
+	dir := t.TempDir()
+	chdir(t, dir)
+	require.Panics(t, func() {
+		Must{{ .ReaderName }}({{ if .Context }}context.Background(){{ end }})
+	})
+	writeEnvFile(t, dir, ".env", testEnvFile)
+	require.Equal(t, expectedConfig, Must{{ .ReaderName }}({{ if .Context }}context.Background(){{ end }}))
+}
+
+func TestMust{{ .ReaderName }}FromEnvFile(t *testing.T) {
+	unsetEnvVars(t)
+	dir := t.TempDir()
+	require.Panics(t, func() {
+		Must{{ .ReaderName }}FromEnvFile({{ if .Context }}context.Background(), {{ end }}filepath.Join(dir, ".env"))
+	})
+	require.Equal(t, expectedConfig, Must{{ .ReaderName }}FromEnvFile({{ if .Context }}context.Background(), {{ end }}writeEnvFile(t, dir, ".env", testEnvFile)))
+}
 `
 	envFileTemplateName = "envFile"
 	envFileTemplate     = `SAMPLE_ENV_VAR=some value`
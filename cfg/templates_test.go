@@ -0,0 +1,142 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// withValidation makes the generator generate the validation of
+// constraints, as it does for env files annotated with them.
+func withValidation(g *generator) {
+	g.validation = true
+}
+
+// withPathChecks makes the generator generate the checks of paths,
+// as it does for env files with path variables and path checks enabled.
+func withPathChecks(g *generator) {
+	g.validation = true
+	g.checkPaths = true
+	g.pathChecks = true
+}
+
+// withFeatureFlags makes the generator generate the feature flags,
+// as it does for env files with FEATURE_* variables.
+func withFeatureFlags(g *generator) {
+	g.withFeatures = true
+	g.features = []feature{
+		{Name: "NewCheckout", ID: "new-checkout", EnvVar: "FEATURE_NEW_CHECKOUT", Field: "FeatureNewCheckout"},
+		{Name: "DarkMode", ID: "dark-mode", EnvVar: "FEATURE_DARK_MODE", Field: "FeatureDarkMode"},
+	}
+}
+
+// withProviderMethods makes the generator generate the config provider,
+// as it does for env files with variables of several types.
+func withProviderMethods(g *generator) {
+	g.configProvider = true
+	g.providerMethods = []providerMethod{
+		{Name: "GetPort", Field: "Port", Type: "int", EnvVar: "PORT"},
+		{Name: "GetBaseUrl", Field: "BaseUrl", Type: "*url.URL", EnvVar: "BASE_URL"},
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "defaults from values", opts: []Option{WithDefaultsFromValues()}},
+		{name: "profiles", opts: []Option{WithProfiles()}},
+		{name: "hot reload", opts: []Option{WithHotReload()}},
+		{name: "sighup reload", opts: []Option{WithSIGHUPReload()}},
+		{name: "metrics", opts: []Option{WithMetrics(), WithHotReload()}},
+		{name: "context", opts: []Option{WithContext(), WithProfiles(), WithDefaultsFromValues(), WithSingleton(), WithSecretsBackends(HashiCorpVault)}},
+		{name: "tracing", opts: []Option{WithTracing(), WithProfiles(), WithDefaultsFromValues(), withValidation}},
+		{name: "tracing with reload", opts: []Option{WithTracing(), WithHotReload(), WithSIGHUPReload(), WithMetrics(), WithSingleton(), WithDebugHandler()}},
+		{name: "tracing with secrets", opts: []Option{WithTracing(), WithSecretsBackends(AWSSecretsManager, HashiCorpVault), WithRemoteSources(Consul, Etcd), WithHotReload()}},
+		{name: "singleton", opts: []Option{WithSingleton(), WithProfiles()}},
+		{name: "clone", opts: []Option{WithClone()}},
+		{name: "debug handler", opts: []Option{WithDebugHandler()}},
+		{name: "embedded env file", opts: []Option{WithEmbeddedEnvFile()}},
+		{name: "embedded env file with context", opts: []Option{WithEmbeddedEnvFile(), WithContext(), WithSecretsBackends(AWSSecretsManager)}},
+		{name: "embedded env file with tracing", opts: []Option{WithEmbeddedEnvFile(), WithTracing()}},
+		{name: "overrides", opts: []Option{WithOverrides()}},
+		{name: "schema version", opts: []Option{WithSchemaVersion(2), WithPrefix("APP_")}},
+		{name: "cobra flags", opts: []Option{WithFlags(Cobra), WithPrefix("APP_")}},
+		{name: "features", opts: []Option{withFeatureFlags}},
+		{name: "config provider", opts: []Option{withProviderMethods}},
+		{name: "config provider with reload", opts: []Option{withProviderMethods, WithHotReload(), WithImmutable()}},
+		{name: "features with reload", opts: []Option{withFeatureFlags, WithHotReload(), WithImmutable()}},
+		{name: "overrides with tracing", opts: []Option{WithOverrides(), WithTracing(), WithImmutable(), WithSecretsBackends(HashiCorpVault)}},
+		{name: "debug handler with reload", opts: []Option{WithDebugHandler(), WithHotReload(), WithImmutable(), WithPrefix("APP_")}},
+		{name: "reload with context", opts: []Option{WithSIGHUPReload(), WithContext(), WithImmutable()}},
+		{name: "debug handler with singleton", opts: []Option{WithDebugHandler(), WithSingleton()}},
+		{name: "immutable", opts: []Option{WithImmutable(), WithClone(), WithSecretsBackends(HashiCorpVault), WithCUE()}},
+		{name: "aws secrets", opts: []Option{WithSecretsBackends(AWSSecretsManager)}},
+		{name: "gcp secrets", opts: []Option{WithSecretsBackends(GCPSecretManager)}},
+		{name: "azure secrets", opts: []Option{WithSecretsBackends(AzureKeyVault)}},
+		{name: "vault secrets", opts: []Option{WithSecretsBackends(HashiCorpVault)}},
+		{name: "schema checksum", opts: []Option{WithSchemaChecksum(), WithSecretFiles()}},
+		{name: "schema checksum with prefix", opts: []Option{WithSchemaChecksum(), WithPrefix("APP_"), WithImmutable()}},
+		{name: "benchmarks", opts: []Option{WithBenchmarks(), WithProfiles(), WithSecretsBackends(AWSSecretsManager)}},
+		{name: "benchmarks with reload", opts: []Option{WithBenchmarks(), WithHotReload(), WithContext(), WithImmutable(), WithPrefix("APP_")}},
+		{name: "usage tracking", opts: []Option{WithUsageTracking(), WithImmutable(), WithPrefix("APP_")}},
+		{name: "strict mode", opts: []Option{WithStrict(), WithPrefix("APP_"), WithSecretFiles()}},
+		{name: "strict mode with context", opts: []Option{WithStrict(), WithPrefix("APP_"), WithContext(), WithSchemaChecksum()}},
+		{name: "age env file", opts: []Option{WithAgeEnvFile()}},
+		{name: "age env file with context", opts: []Option{WithAgeEnvFile(), WithTracing(), WithDialect(DockerDialect)}},
+		{name: "secret files", opts: []Option{WithSecretFiles(), WithSecretsBackends(AWSSecretsManager)}},
+		{name: "secret files with prefix", opts: []Option{WithSecretFiles(), WithPrefix("APP_"), WithTracing()}},
+		{name: "1password secrets", opts: []Option{WithSecretsBackends(OnePassword, AWSSecretsManager)}},
+		{name: "doppler secrets", opts: []Option{WithSecretsBackends(Doppler, HashiCorpVault), WithPrefix("APP_")}},
+		{name: "consul remote source", opts: []Option{WithRemoteSources(Consul)}},
+		{name: "cue", opts: []Option{WithCUE()}},
+		{name: "without go generate", opts: []Option{WithoutGoGenerate()}},
+		{name: "etcd remote source", opts: []Option{WithRemoteSources(Etcd)}},
+		{name: "http remote source", opts: []Option{WithRemoteSources(HTTP)}},
+		{name: "http remote source with context", opts: []Option{WithRemoteSources(HTTP), WithContext()}},
+		{name: "plugin remote source", opts: []Option{WithRemoteSources(PluginSource)}},
+		{name: "plugin remote source with context", opts: []Option{WithRemoteSources(PluginSource, HTTP), WithContext(), WithStructName("AppConfig"), WithReaderName("Load")}},
+		{name: "etcd remote source with hot reload", opts: []Option{WithRemoteSources(Etcd), WithHotReload()}},
+		{name: "prefix", opts: []Option{WithPrefix("APP_"), WithSecretsBackends(HashiCorpVault), WithCUE()}},
+		{name: "validation", opts: []Option{withValidation}},
+		{name: "validation with reload", opts: []Option{withValidation, WithSingleton(), WithHotReload(), WithSIGHUPReload(), WithOverrides(), WithEmbeddedEnvFile(), WithAgeEnvFile(), WithRemoteSources(Consul, Etcd, HTTP, PluginSource)}},
+		{name: "validator tags", opts: []Option{withValidation, WithValidatorTags(), WithImmutable()}},
+		{name: "path checks", opts: []Option{withPathChecks}},
+		{name: "path checks with validator tags", opts: []Option{withPathChecks, WithValidatorTags()}},
+		{name: "struct and reader names", opts: []Option{WithStructName("AppConfig"), WithReaderName("Load"), WithProfiles(), WithEmbeddedEnvFile(), WithOverrides(), WithRemoteSources(HTTP), WithSingleton()}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			g.modImportPath = "example.com/app/config"
+			templates := map[string]string{
+				configReaderMainFileTemplateName:     configReaderMainFileTemplatePlaceHolder,
+				configReaderUnitTestFileTemplateName: configReaderUnitTestFileTemplate,
+			}
+			for _, f := range g.optionalFiles() {
+				templates[f.templateName] = f.templateText
+			}
+			templateValues := g.templateValues()
+			templateValues[configStructTemplateName] = fmt.Sprintf(defaultConfigStructTemplate, g.structName)
+			templateValues[goGeneratePlaceHolder] = g.goGenerateCommand([]string{".env"})
+			templateValues[benchEnvFilePlaceHolder], _, _ = realTestValues(sampleEnvVars, CamelCaseFieldNamer, g.structName, g.immutable, g.dialect)
+			for name, text := range templates {
+				te, err := textTemplateProcessor{}.Parse(name, text)
+				require.NoError(t, err)
+				var buf bytes.Buffer
+				require.NoError(t, te.Execute(&buf, templateValues))
+				_, err = format.Source(buf.Bytes())
+				require.NoError(t, err, "template %s:\n%s", name, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	terraformVariablesFileName = "terraform/variables.tf"
+	terraformTfvarsFileName    = "terraform/terraform.tfvars.example"
+)
+
+// terraformTypes maps the inferred Go field types to Terraform types.
+var terraformTypes = map[string]string{
+	"int":               "number",
+	"float64":           "number",
+	"bool":              "bool",
+	"[]string":          "list(string)",
+	"map[string]string": "map(string)",
+}
+
+// hclStringEscaper escapes the sequences that HCL would take as
+// interpolations or directives in quoted strings.
+var hclStringEscaper = strings.NewReplacer("${", "$${", "%{", "%%{")
+
+// terraformVariables returns a Terraform file declaring a variable for
+// every given variable, named after it in lower case, with its type,
+// description, taken from its comment, and default value, if any. Optional
+// variables default to null, and sensitive ones are marked as such, their
+// defaults being left out.
+func (g *generator) terraformVariables(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated by goprojconfig. Variables holding the env vars expected\n")
+	sb.WriteString(fmt.Sprintf("# by the '%s' package.\n", g.packageName))
+	for _, v := range vars {
+		fieldType := fieldType(v)
+		var attributes [][2]string
+		if d := description(v.comment); d != "" {
+			attributes = append(attributes, [2]string{"description", hclString(strings.ReplaceAll(d, "\n", " "))})
+		}
+		attributes = append(attributes, [2]string{"type", terraformType(fieldType)})
+		switch {
+		case g.hasDefault(v) && !isSensitive(v):
+			attributes = append(attributes, [2]string{"default", hclValue(fieldType, v.value)})
+		case g.hasDefault(v) || !isRequired(v):
+			attributes = append(attributes, [2]string{"default", "null"})
+		}
+		if isSensitive(v) {
+			attributes = append(attributes, [2]string{"sensitive", "true"})
+		}
+		sb.WriteString(fmt.Sprintf("\nvariable %q {\n", strings.ToLower(v.key)))
+		writeHCLAttributes(&sb, attributes)
+		sb.WriteString("}\n")
+	}
+	return sb.String(), nil
+}
+
+// terraformTfvars returns an example Terraform variable definitions
+// file, assigning the env file values to the variables declared by
+// terraformVariables. The values of sensitive variables are left empty.
+func (g *generator) terraformTfvars(vars []envVar) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated by goprojconfig. Copy it to terraform.tfvars and set the values\n")
+	sb.WriteString(fmt.Sprintf("# of the env vars expected by the '%s' package.\n", g.packageName))
+	for _, v := range vars {
+		sb.WriteString("\n")
+		writeComment(&sb, "", description(v.comment))
+		value := hclValue(fieldType(v), v.value)
+		if isSensitive(v) {
+			value = `""`
+		}
+		sb.WriteString(fmt.Sprintf("%s = %s\n", strings.ToLower(v.key), value))
+	}
+	return sb.String(), nil
+}
+
+// writeHCLAttributes writes the given name and value pairs as the
+// attributes of an HCL block, with their equals signs aligned, as
+// 'terraform fmt' does.
+func writeHCLAttributes(sb *strings.Builder, attributes [][2]string) {
+	width := 0
+	for _, a := range attributes {
+		width = max(width, len(a[0]))
+	}
+	for _, a := range attributes {
+		sb.WriteString(fmt.Sprintf("  %-*s = %s\n", width, a[0], a[1]))
+	}
+}
+
+// terraformType returns the Terraform type of the given
+// inferred Go type, which is 'string' by default.
+func terraformType(fieldType string) string {
+	if t, ok := terraformTypes[fieldType]; ok {
+		return t
+	}
+	return "string"
+}
+
+// hclValue returns the given value as an HCL expression of the Terraform
+// type of the given inferred Go type. Empty values of other types than
+// strings, as well as numbers that can't be parsed, are null.
+func hclValue(fieldType, value string) string {
+	switch terraformType(fieldType) {
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "null"
+		}
+		return value
+	case "bool":
+		if value == "" {
+			return "null"
+		}
+		return strconv.FormatBool(strings.EqualFold(value, "true"))
+	case "list(string)":
+		if value == "" {
+			return "null"
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, hclString(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case "map(string)":
+		if value == "" {
+			return "null"
+		}
+		var items []string
+		for _, pair := range strings.Split(value, ",") {
+			k, v, _ := strings.Cut(pair, ":")
+			items = append(items, hclString(k)+" = "+hclString(v))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	}
+	return hclString(value)
+}
+
+// hclString returns the given value as an HCL quoted string.
+func hclString(value string) string {
+	return hclStringEscaper.Replace(strconv.Quote(value))
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_terraformVariables(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+		{key: "DB_PASSWORD", value: "secret"},
+		{key: "PORT", value: "8080"},
+		{key: "RATIO", value: "0.5", comment: "optional"},
+		{key: "HOSTS", value: "a,b", inferredType: "[]string"},
+		{key: "LABELS", value: "k1:v1,k2:v2", inferredType: "map[string]string"},
+		{key: "TEMPLATE", value: "${name}"},
+	}
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedOutput string
+	}{
+		{
+			name: "happy path",
+			expectedOutput: "# Generated by goprojconfig. Variables holding the env vars expected\n" +
+				"# by the 'config' package.\n" +
+				"\nvariable \"db_host\" {\n  description = \"database host\"\n  type        = string\n}\n" +
+				"\nvariable \"db_password\" {\n  type      = string\n  sensitive = true\n}\n" +
+				"\nvariable \"port\" {\n  type = number\n}\n" +
+				"\nvariable \"ratio\" {\n  type    = number\n  default = null\n}\n" +
+				"\nvariable \"hosts\" {\n  type = list(string)\n}\n" +
+				"\nvariable \"labels\" {\n  type = map(string)\n}\n" +
+				"\nvariable \"template\" {\n  type = string\n}\n",
+		},
+		{
+			name: "defaults from values",
+			opts: []Option{WithDefaultsFromValues()},
+			expectedOutput: "# Generated by goprojconfig. Variables holding the env vars expected\n" +
+				"# by the 'config' package.\n" +
+				"\nvariable \"db_host\" {\n  description = \"database host\"\n  type        = string\n  default     = \"localhost\"\n}\n" +
+				"\nvariable \"db_password\" {\n  type      = string\n  default   = null\n  sensitive = true\n}\n" +
+				"\nvariable \"port\" {\n  type    = number\n  default = 8080\n}\n" +
+				"\nvariable \"ratio\" {\n  type    = number\n  default = 0.5\n}\n" +
+				"\nvariable \"hosts\" {\n  type    = list(string)\n  default = [\"a\", \"b\"]\n}\n" +
+				"\nvariable \"labels\" {\n  type    = map(string)\n  default = { \"k1\" = \"v1\", \"k2\" = \"v2\" }\n}\n" +
+				"\nvariable \"template\" {\n  type    = string\n  default = \"$${name}\"\n}\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", tc.opts...).(*generator)
+			output, err := g.terraformVariables(vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+func Test_terraformTfvars(t *testing.T) {
+	g := NewGenerator("config").(*generator)
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost", comment: "database host\nrequired"},
+		{key: "DB_PASSWORD", value: "secret"},
+		{key: "DEBUG", value: "TRUE"},
+		{key: "MAX_CONNECTIONS", comment: "type: int"},
+		{key: "EMPTY"},
+	}
+	expectedOutput := "# Generated by goprojconfig. Copy it to terraform.tfvars and set the values\n" +
+		"# of the env vars expected by the 'config' package.\n" +
+		"\n# database host\ndb_host = \"localhost\"\n" +
+		"\ndb_password = \"\"\n" +
+		"\ndebug = true\n" +
+		"\nmax_connections = null\n" +
+		"\nempty = \"\"\n"
+	output, err := g.terraformTfvars(vars)
+	require.NoError(t, err)
+	require.Equal(t, expectedOutput, output)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// testConfigPackageName returns the name of the package providing
+// configurations for tests, e.g. 'appcfgtest' for 'appcfg'.
+func (g *generator) testConfigPackageName() string {
+	return g.packageName + "test"
+}
+
+// testConfig returns the 'Config' returned by the 'NewTestConfig' function
+// of the test config package, as a Go expression, along with the packages
+// it imports and the helpers it calls. Fields get their env file values,
+// except sensitive ones, so that secrets don't end up in tests, and the
+// required ones with empty values, which get sample values instead.
+func (g *generator) testConfig(vars []envVar) (string, []string, []string) {
+	qualifier := g.packageName + "."
+	var sb strings.Builder
+	sb.WriteString("&" + qualifier + g.structName + "{\n")
+	for _, v := range vars {
+		value := v.value
+		if value == "" && !isRequired(v) {
+			continue
+		}
+		if value == "" || isSensitive(v) {
+			value = sampleValue(v)
+		}
+		if fieldValue := fieldLiteral(v, g.fieldName(v.key), value, qualifier); fieldValue != "" {
+			fmt.Fprintf(&sb, "%s: %s,\n", g.fieldName(v.key), fieldValue)
+		}
+	}
+	sb.WriteString("}")
+	imports, helpers := realTestImports(sb.String())
+	return sb.String(), imports, helpers
+}
+
+// generateTestConfigPackage generates the test config package next to
+// the config package, along with its unit tests unless they're disabled,
+// filling the 'Config' it returns from the given variables.
+func (g *generator) generateTestConfigPackage(vars []envVar) ([]string, error) {
+	if g.modImportPath == "" {
+		return nil, errors.New("the test config package can only be generated in a Go module")
+	}
+	pkgName := g.testConfigPackageName()
+	pkgDir := g.outputPath(pkgName)
+	if err := g.fs.Mkdir(pkgDir); err != nil && !os.IsExist(err) {
+		return nil, errors.Wrapf(err, "creating dir %s", pkgDir)
+	}
+	templateValues := g.templateValues()
+	templateValues[testConfigPackagePlaceHolder] = pkgName
+	templateValues[testConfigPlaceHolder], templateValues[testImportsPlaceHolder], templateValues[testHelpersPlaceHolder] = g.testConfig(vars)
+	files := []optionalFile{{pkgName + ".go", testConfigFileTemplateName, testConfigFileTemplate}}
+	if !g.noTests {
+		files = append(files, optionalFile{pkgName + "_test.go", testConfigUnitTestFileTemplateName, testConfigUnitTestFileTemplate})
+	}
+	var generatedFiles []string
+	for _, f := range files {
+		filePath := filepath.Join(pkgDir, f.fileName)
+		file, err := g.fs.Create(filePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating file %s", filePath)
+		}
+		g.logger.Debug("executing template", "template", f.templateName, "file", filePath)
+		err = g.writeFileFromTemplate(f.templateName, f.templateText, templateValues, file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		g.logger.Debug("formatting file", "file", filePath)
+		if err := g.formatGoFile(filePath); err != nil {
+			return nil, err
+		}
+		generatedFiles = append(generatedFiles, filePath)
+	}
+	return generatedFiles, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	testConfigFileTemplateName         = "testConfigFile"
+	testConfigUnitTestFileTemplateName = "testConfigUnitTestFile"
+	testConfigFileTemplate             = `{{ .Header }}// Package {{ .TestConfigPackage }} provides configurations
+// for the tests of the packages using {{ .ConfigReaderPkgName }}.
+package {{ .TestConfigPackage }}
+
+import (
+{{- range .TestImports }}
+	"{{ . }}"
+{{- end }}
+
+	"{{ .ImportPath }}"
+)
+
+// NewTestConfig returns a configuration prefilled with sample values,
+// to which the given overrides are applied, e.g.:
+//
+//	cfg := {{ .TestConfigPackage }}.NewTestConfig(func(c *{{ .ConfigReaderPkgName }}.{{ .StructName }}) {
+//		c.SomeField = "some value"
+//	})
+func NewTestConfig(overrides ...func(*{{ .ConfigReaderPkgName }}.{{ .StructName }})) *{{ .ConfigReaderPkgName }}.{{ .StructName }} {
+	cfg := {{ .TestConfig }}
+	for _, override := range overrides {
+		override(cfg)
+	}
+	return cfg
+}
+{{- range .TestHelpers }}
+
+{{ . }}
+{{- end }}
+`
+
+	testConfigUnitTestFileTemplate = `{{ .Header }}package {{ .TestConfigPackage }}
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"{{ .ImportPath }}"
+)
+
+func TestNewTestConfig(t *testing.T) {
+	cfg := NewTestConfig()
+	require.NotNil(t, cfg)
+	require.NotSame(t, cfg, NewTestConfig())
+	require.Equal(t, cfg, NewTestConfig())
+	var calls []int
+	overridden := NewTestConfig(
+		func(c *{{ .ConfigReaderPkgName }}.{{ .StructName }}) {
+			calls = append(calls, 1)
+		},
+		func(c *{{ .ConfigReaderPkgName }}.{{ .StructName }}) {
+			calls = append(calls, 2)
+			*c = {{ .ConfigReaderPkgName }}.{{ .StructName }}{}
+		},
+	)
+	require.Equal(t, []int{1, 2}, calls)
+	require.Equal(t, &{{ .ConfigReaderPkgName }}.{{ .StructName }}{}, overridden)
+}
+`
+)
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_testConfig(t *testing.T) {
+	g := NewGenerator("appcfg").(*generator)
+	vars := []envVar{
+		{key: "PORT", value: "8080"},
+		{key: "DB_PASSWORD", value: "supersecret"},
+		{key: "LOG_LEVEL", comment: "required\nenum: debug,info"},
+		{key: "START", value: "2024-03-04", comment: "layout: 2006-01-02"},
+		{key: "BASE_URL", value: "https://example.com"},
+		{key: "API_TOKEN", comment: "optional"},
+	}
+	expectedConfig := "&appcfg.Config{\n" +
+		"Port: 8080,\n" +
+		"DbPassword: \"value\",\n" +
+		"LogLevel: \"debug\",\n" +
+		"Start: appcfg.StartTime{Time: mustParseTime(\"2006-01-02\", \"2024-03-04\")},\n" +
+		"BaseUrl: mustParseURL(\"https://example.com\"),\n" +
+		"}"
+	config, imports, helpers := g.testConfig(vars)
+	require.Equal(t, expectedConfig, config)
+	require.Equal(t, []string{"time", "net/url"}, imports)
+	require.Len(t, helpers, 2)
+}
+
+func TestGenerateTestConfigPackage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         map[string][]byte
+		expectedFiles []string
+		expectedError error
+	}{
+		{
+			name: "happy path",
+			files: map[string][]byte{
+				"/app/go.mod": []byte("module example.com/app\n"),
+				".env":        []byte("PORT=8080\n"),
+			},
+			expectedFiles: []string{"appcfg/config.go", "appcfg/config_test.go", "appcfgtest/appcfgtest.go", "appcfgtest/appcfgtest_test.go"},
+		},
+		{
+			name:          "outside of a module",
+			files:         map[string][]byte{".env": []byte("PORT=8080\n")},
+			expectedError: errors.New("the test config package can only be generated in a Go module"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getwd := func() (string, error) {
+				return "/app", nil
+			}
+			fsys := NewMemFileSystem(tc.files)
+			g := NewGenerator("appcfg", WithFileSystem(fsys), WithTestConfigPackage(), withGetwd(getwd))
+			output, err := g.GenerateConfigPackageFromEnvFile(".env")
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf(`expected no error, got "%v"`, err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf(`expected error "%v", got nil`, tc.expectedError)
+				}
+				require.Equal(t, tc.expectedFiles, output)
+				testConfigFile := string(fsys.Files()["appcfgtest/appcfgtest.go"])
+				require.Contains(t, testConfigFile, "\"example.com/app/appcfg\"")
+				require.Contains(t, testConfigFile, "cfg := &appcfg.Config{\n\t\tPort: 8080,\n\t}")
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TestStyle identifies how the generated 'config_test.go' tests
+// the generated package.
+type TestStyle string
+
+const (
+	// MockTestStyle tests the generated functions against mocked
+	// 'godotenv' and 'envconfig' functions. It's the default.
+	MockTestStyle TestStyle = "mock"
+	// RealTestStyle tests the generated functions against a temporary
+	// env file and env vars set with 't.Setenv', asserting the values
+	// of the fields that are actually read.
+	RealTestStyle TestStyle = "real"
+)
+
+// sampleValues are the values given in the tests of the real test style
+// to the required variables whose env file values are empty.
+var sampleValues = map[string]string{
+	"int":               "1",
+	"float64":           "1.5",
+	"bool":              "true",
+	"[]string":          "a,b",
+	"map[string]string": "a:b",
+	"time.Time":         "2024-01-02T15:04:05Z",
+	"*url.URL":          "http://localhost",
+	"net.IP":            "127.0.0.1",
+}
+
+// sampleTime is the time given in the tests of the real test style to
+// the required variables read with a non-default layout whose env file
+// values are empty.
+var sampleTime = time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// realTestValues returns the env file read by the tests of the real test
+// style, the env vars it defines and the 'Config' expected to be read
+// from them, as Go expressions, naming fields with the given field namer
+// and the struct with the given struct name.
+// Optional variables with empty values are left out, so that their fields
+// keep their zero values, while required ones are given sample values,
+// e.g. the first value of enums or the min of bounded numbers.
+// The values of an immutable 'Config' are set into its 'configValues', and
+// the env file is written in the given dialect.
+func realTestValues(vars []envVar, fieldNamer func(envKey string) string, structName string, immutable bool, dialect Dialect) (envFile, envVars, config string) {
+	var envFileSb, envVarsSb, configSb strings.Builder
+	envVarsSb.WriteString("map[string]string{\n")
+	configSb.WriteString("&" + structName + "{\n")
+	if immutable {
+		configSb.WriteString("values: " + configValuesStructName + "{\n")
+	}
+	for _, v := range vars {
+		value := v.value
+		if value == "" {
+			if !isRequired(v) {
+				continue
+			}
+			value = sampleValue(v)
+		}
+		fmt.Fprintf(&envFileSb, "%s=%s\n", v.key, dialect.envFileValue(value))
+		fmt.Fprintf(&envVarsSb, "%q: %q,\n", v.key, value)
+		if fieldValue := fieldLiteral(v, fieldNamer(v.key), value, ""); fieldValue != "" {
+			fmt.Fprintf(&configSb, "%s: %s,\n", fieldNamer(v.key), fieldValue)
+		}
+	}
+	envVarsSb.WriteString("}")
+	if immutable {
+		configSb.WriteString("},\n")
+	}
+	configSb.WriteString("}")
+	return strconv.Quote(envFileSb.String()), envVarsSb.String(), configSb.String()
+}
+
+// sampleValue returns the sample value given to the given variable when
+// its env file value is empty or can't be used, e.g. the first value of
+// enums or the min of bounded numbers.
+func sampleValue(v envVar) string {
+	fieldType := fieldType(v)
+	value := "value"
+	if sampleValue, ok := sampleValues[fieldType]; ok {
+		value = sampleValue
+	}
+	if layout, ok := timeLayout(v); ok && fieldType == "time.Time" {
+		value = sampleTime.Format(layout)
+	}
+	switch c := variableConstraints(v); {
+	case c.min != "":
+		value = c.min
+	case c.max != "":
+		value = c.max
+	case c.pattern != "":
+		value = samplePatternValue(c.pattern)
+	}
+	if values, ok := enumValues(v); ok && fieldType == "string" && len(values) > 0 {
+		value = values[0]
+	}
+	return value
+}
+
+// fieldLiteral returns the Go expression of the given value of the field
+// with the given name generated for the given variable, qualifying the
+// types declared by the config package with the given qualifier, e.g.
+// 'config.'. It's empty when the type of the field can't be inferred.
+func fieldLiteral(v envVar, fieldName, value, qualifier string) string {
+	fieldType := fieldType(v)
+	if layout, ok := timeLayout(v); ok && fieldType == "time.Time" {
+		return fmt.Sprintf("%s%s{Time: mustParseTime(%q, %q)}", qualifier, layoutTypeName(fieldName), layout, value)
+	}
+	if fieldType == "" {
+		return ""
+	}
+	return goLiteral(fieldType, value)
+}
+
+// goLiteral returns the Go literal of the given type holding the given
+// value, as parsed by 'envconfig'.
+func goLiteral(fieldType, value string) string {
+	switch fieldType {
+	case "int":
+		n, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			n, _ = strconv.ParseInt(value, 10, 64)
+		}
+		return strconv.FormatInt(n, 10)
+	case "float64":
+		f, _ := strconv.ParseFloat(value, 64)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			b = strings.EqualFold(value, "true")
+		}
+		return strconv.FormatBool(b)
+	case "[]string":
+		elems := strings.Split(value, ",")
+		for i, elem := range elems {
+			elems[i] = strconv.Quote(elem)
+		}
+		return "[]string{" + strings.Join(elems, ", ") + "}"
+	case "map[string]string":
+		m := mapValue(value)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = fmt.Sprintf("%q: %q", k, m[k])
+		}
+		return "map[string]string{" + strings.Join(pairs, ", ") + "}"
+	case "time.Time":
+		return fmt.Sprintf("mustParseTime(time.RFC3339, %q)", value)
+	case "*url.URL":
+		return fmt.Sprintf("mustParseURL(%q)", value)
+	case "net.IP":
+		return fmt.Sprintf("net.ParseIP(%q)", value)
+	}
+	return strconv.Quote(value)
+}
+
+// realTestDependency is a function called by the expected 'Config' of
+// the tests of the real test style, along with the package it needs
+// and the helper declaring it, if it's not a standard one.
+type realTestDependency struct {
+	call       string
+	importPath string
+	helper     string
+}
+
+// realTestDependencies are the functions that may be called by the
+// expected 'Config' of the tests of the real test style.
+var realTestDependencies = []realTestDependency{
+	{
+		call:       "mustParseTime(",
+		importPath: "time",
+		helper: `// mustParseTime parses the given value with the given layout.
+func mustParseTime(layout, value string) time.Time {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}`,
+	},
+	{
+		call:       "mustParseURL(",
+		importPath: "net/url",
+		helper: `// mustParseURL parses the given URL.
+func mustParseURL(value string) *url.URL {
+	u, err := url.Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}`,
+	},
+	{
+		call:       "net.ParseIP(",
+		importPath: "net",
+	},
+}
+
+// realTestImports returns the packages imported by the given expected
+// 'Config' of the tests of the real test style, along with the helpers
+// it calls.
+func realTestImports(config string) ([]string, []string) {
+	var imports, helpers []string
+	for _, d := range realTestDependencies {
+		if !strings.Contains(config, d.call) {
+			continue
+		}
+		imports = append(imports, d.importPath)
+		if d.helper != "" {
+			helpers = append(helpers, d.helper)
+		}
+	}
+	return imports, helpers
+}
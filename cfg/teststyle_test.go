@@ -0,0 +1,110 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_realTestValues(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost"},
+		{key: "DB_PORT", value: "5432"},
+		{key: "FILE_MODE", value: "0755"},
+		{key: "RATIO", value: ".5"},
+		{key: "DEBUG", value: "TRUE"},
+		{key: "GREETING", value: `say "hi"`},
+		{key: "OPTIONAL", value: ""},
+		{key: "REQUIRED", value: "", comment: "required"},
+		{key: "REQUIRED_INT", value: "", comment: "type: int\nrequired"},
+		{key: "HOSTS", value: "a,b", comment: "type: []string"},
+		{key: "LABELS", value: "b:2,a:1", inferredType: "map[string]string"},
+	}
+	envFile, envVars, config := realTestValues(vars, CamelCaseFieldNamer, "Config", false, GodotenvDialect)
+	require.Equal(t, `"DB_HOST=localhost\nDB_PORT=5432\nFILE_MODE=0755\nRATIO=.5\nDEBUG=TRUE\nGREETING=\"say \\\"hi\\\"\"\nREQUIRED=value\nREQUIRED_INT=1\nHOSTS=a,b\nLABELS=b:2,a:1\n"`, envFile)
+	require.Equal(t, `map[string]string{
+"DB_HOST": "localhost",
+"DB_PORT": "5432",
+"FILE_MODE": "0755",
+"RATIO": ".5",
+"DEBUG": "TRUE",
+"GREETING": "say \"hi\"",
+"REQUIRED": "value",
+"REQUIRED_INT": "1",
+"HOSTS": "a,b",
+"LABELS": "b:2,a:1",
+}`, envVars)
+	require.Equal(t, `&Config{
+DbHost: "localhost",
+DbPort: 5432,
+FileMode: 493,
+Ratio: 0.5,
+Debug: true,
+Greeting: "say \"hi\"",
+RequiredInt: 1,
+Hosts: []string{"a", "b"},
+Labels: map[string]string{"a": "1", "b": "2"},
+}`, config)
+}
+
+func Test_realTestValues_times(t *testing.T) {
+	vars := []envVar{
+		{key: "STARTED_AT", value: "2024-03-01T10:00:00Z"},
+		{key: "START_DATE", value: "2024-03-01", comment: "layout: 2006-01-02"},
+		{key: "OPEN_TIME", value: "", comment: "layout: 15:04\nrequired"},
+	}
+	envFile, _, config := realTestValues(vars, CamelCaseFieldNamer, "Config", false, GodotenvDialect)
+	require.Equal(t, `"STARTED_AT=2024-03-01T10:00:00Z\nSTART_DATE=2024-03-01\nOPEN_TIME=15:04\n"`, envFile)
+	require.Equal(t, `&Config{
+StartedAt: mustParseTime(time.RFC3339, "2024-03-01T10:00:00Z"),
+StartDate: StartDateTime{Time: mustParseTime("2006-01-02", "2024-03-01")},
+OpenTime: OpenTimeValue{Time: mustParseTime("15:04", "15:04")},
+}`, config)
+}
+
+func Test_realTestValues_enums(t *testing.T) {
+	vars := []envVar{
+		{key: "LOG_LEVEL", value: "info", comment: "enum: debug,info"},
+		{key: "MODE", value: "", comment: "enum: fast,safe\nrequired"},
+	}
+	envFile, _, config := realTestValues(vars, CamelCaseFieldNamer, "Config", false, GodotenvDialect)
+	require.Equal(t, `"LOG_LEVEL=info\nMODE=fast\n"`, envFile)
+	require.Equal(t, `&Config{
+LogLevel: "info",
+Mode: "fast",
+}`, config)
+}
+
+func Test_realTestImports(t *testing.T) {
+	vars := []envVar{
+		{key: "API_URL", value: "https://example.com"},
+		{key: "BIND_IP", value: "10.0.0.1"},
+	}
+	_, _, config := realTestValues(vars, CamelCaseFieldNamer, "Config", false, GodotenvDialect)
+	require.Equal(t, `&Config{
+ApiUrl: mustParseURL("https://example.com"),
+BindIp: net.ParseIP("10.0.0.1"),
+}`, config)
+	imports, helpers := realTestImports(config)
+	require.Equal(t, []string{"net/url", "net"}, imports)
+	require.Len(t, helpers, 1)
+	require.Contains(t, helpers[0], "func mustParseURL(value string) *url.URL {")
+}
+
+func Test_realTestValues_immutable(t *testing.T) {
+	vars := []envVar{
+		{key: "DB_HOST", value: "localhost"},
+		{key: "DB_PORT", value: "5432"},
+	}
+	_, _, config := realTestValues(vars, CamelCaseFieldNamer, "Config", true, GodotenvDialect)
+	require.Equal(t, `&Config{
+values: configValues{
+DbHost: "localhost",
+DbPort: 5432,
+},
+}`, config)
+}
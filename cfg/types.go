@@ -0,0 +1,155 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultFieldType is the type used for fields whose type
+// could not be inferred from the env file value.
+const defaultFieldType = "interface{}"
+
+// optionalFieldType is the type used for optional fields whose type
+// could not be inferred, so that unset variables can be told apart.
+const optionalFieldType = "*string"
+
+// annotatedTypes are the types that can be given to variables with a
+// '# type: <type>' annotation, for the ones whose type can't be inferred
+// from their values, e.g. because they're empty.
+var annotatedTypes = map[string]bool{
+	"string":            true,
+	"int":               true,
+	"float64":           true,
+	"bool":              true,
+	"[]string":          true,
+	"map[string]string": true,
+	"time.Time":         true,
+	"*url.URL":          true,
+	"net.IP":            true,
+}
+
+// mapKeyRegexp matches the keys of the 'key:value' pairs of
+// map values, e.g. 'env' in 'env:prod,team:core'.
+var mapKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// isRequired reports whether the given variable is required. Variables
+// with empty values are optional and the others are required, unless
+// annotated otherwise with '# required' or '# optional' comments.
+func isRequired(v envVar) bool {
+	if hasAnnotation(v.comment, "required") {
+		return true
+	}
+	if hasAnnotation(v.comment, "optional") {
+		return false
+	}
+	return v.value != ""
+}
+
+// fieldType returns the Go type of the field generated for the given
+// variable: the one given by its '# type' annotation, if any, or else
+// the one inferred from its value. Variables annotated with a '# layout'
+// are times, and the ones annotated with an '# enum' or a '# pattern'
+// are strings.
+func fieldType(v envVar) string {
+	if t, ok := annotationValue(v.comment, "type"); ok {
+		return t
+	}
+	if v.inferredType != "" {
+		return v.inferredType
+	}
+	if _, ok := timeLayout(v); ok {
+		return "time.Time"
+	}
+	if _, ok := enumValues(v); ok {
+		return "string"
+	}
+	if _, ok := annotationValue(v.comment, "pattern"); ok {
+		return "string"
+	}
+	return inferType(v.value)
+}
+
+// inferType infers the Go type of a field from the given value.
+// It returns an empty string when the type can't be inferred.
+func inferType(value string) string {
+	if value == "" {
+		return ""
+	}
+	if _, err := strconv.Atoi(value); err == nil {
+		return "int"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil && strings.ContainsAny(value, "0123456789") {
+		return "float64"
+	}
+	if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return "bool"
+	}
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return "time.Time"
+	}
+	if u, err := url.Parse(value); err == nil && u.IsAbs() && u.Host != "" {
+		return "*url.URL"
+	}
+	if net.ParseIP(value) != nil {
+		return "net.IP"
+	}
+	return "string"
+}
+
+// inferCollectionTypes infers the types of the given variables whose
+// values are comma delimited lists, as read by envconfig: '[]string' for
+// values like 'a.com,b.com' and 'map[string]string' for values like
+// 'k1:v1,k2:v2'.
+func inferCollectionTypes(vars []envVar) {
+	for i, v := range vars {
+		vars[i].inferredType = inferCollectionType(v.value)
+	}
+}
+
+// inferCollectionType infers the collection type of the given value.
+// It returns an empty string when the value is not a comma delimited list.
+func inferCollectionType(value string) string {
+	if !strings.Contains(value, ",") {
+		return ""
+	}
+	for _, pair := range strings.Split(value, ",") {
+		key, value, ok := strings.Cut(pair, ":")
+		if !ok || !mapKeyRegexp.MatchString(key) || strings.HasPrefix(value, "/") {
+			return "[]string"
+		}
+	}
+	return "map[string]string"
+}
+
+// typePackages are the import paths of the packages
+// of the types that fields may have, by package name.
+var typePackages = map[string]string{
+	"time": "time",
+	"url":  "net/url",
+	"net":  "net",
+}
+
+// structImports returns the packages imported by the
+// fields generated for the given variables, sorted.
+func structImports(vars []envVar) []string {
+	imported := make(map[string]bool)
+	var imports []string
+	for _, v := range vars {
+		pkg, _, ok := strings.Cut(strings.TrimLeft(fieldType(v), "*[]"), ".")
+		if importPath, known := typePackages[pkg]; ok && known && !imported[importPath] {
+			imported[importPath] = true
+			imports = append(imports, importPath)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	usageFileName                 = "usage.go"
+	usageUnitTestFileName         = "usage_test.go"
+	usageFileTemplateName         = "usageFile"
+	usageUnitTestFileTemplateName = "usageUnitTestFile"
+	usageFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// usedEnvVars holds the names of the env vars read into {{ .StructName }}
+// whose getters were called since usage tracking last started.
+var usedEnvVars sync.Map
+
+// For ease of unit testing.
+var timeAfter = time.After
+
+// markUsed records that the given env var was read through its getter.
+// Loading first keeps getters called in hot paths from contending.
+func markUsed(envVar string) {
+	if _, ok := usedEnvVars.Load(envVar); !ok {
+		usedEnvVars.Store(envVar, true)
+	}
+}
+
+// ReportUnused starts tracking which env vars read into {{ .StructName }}
+// are read through its getters, waits for the given sample period and logs
+// the ones whose getters weren't called during it with the given logger, if
+// not nil, returning their names. Nothing is reported when ctx is done
+// before the period ends. It's meant to be run in its own goroutine, e.g.
+// 'go {{ .ConfigReaderPkgName }}.ReportUnused(ctx, logger, 24*time.Hour)', to find
+// dead configuration that can be pruned.
+func ReportUnused(ctx context.Context, logger *slog.Logger, period time.Duration) []string {
+	usedEnvVars.Range(func(key, _ any) bool {
+		usedEnvVars.Delete(key)
+		return true
+	})
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-timeAfter(period):
+	}
+	var unused []string
+	configType := reflect.TypeOf(configValues{})
+	for i := 0; i < configType.NumField(); i++ {
+		key := configType.Field(i).Tag.Get("envconfig")
+		if key == "" {
+			continue
+		}
+		envVar := {{ if .EnvPrefix }}envPrefix + "_" + {{ end }}key
+		if _, ok := usedEnvVars.Load(envVar); !ok {
+			unused = append(unused, envVar)
+		}
+	}
+	if logger != nil {
+		for _, envVar := range unused {
+			logger.WarnContext(ctx, "unused env var", slog.String("env_var", envVar), slog.Duration("period", period))
+		}
+	}
+	return unused
+}
+`
+
+	usageUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportUnused(t *testing.T) {
+	var envVars []string
+	configType := reflect.TypeOf(configValues{})
+	for i := 0; i < configType.NumField(); i++ {
+		envVars = append(envVars, {{ if .EnvPrefix }}envPrefix+"_"+{{ end }}configType.Field(i).Tag.Get("envconfig"))
+	}
+	testCases := []struct {
+		name           string
+		used           []string
+		cancel         bool
+		expectedOutput []string
+	}{
+		{
+			name:           "happy path",
+			used:           envVars[:len(envVars)/2],
+			expectedOutput: envVars[len(envVars)/2:],
+		},
+		{
+			name:           "every env var used",
+			used:           envVars,
+			expectedOutput: nil,
+		},
+		{
+			name:           "context done",
+			cancel:         true,
+			expectedOutput: nil,
+		},
+	}
+	t.Cleanup(func() {
+		timeAfter = time.After
+	})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			elapsed := make(chan time.Time)
+			timeAfter = func(d time.Duration) <-chan time.Time {
+				for _, envVar := range tc.used {
+					markUsed(envVar)
+				}
+				if tc.cancel {
+					cancel()
+				} else {
+					close(elapsed)
+				}
+				return elapsed
+			}
+			var log bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&log, nil))
+			unused := ReportUnused(ctx, logger, time.Hour)
+			if len(tc.expectedOutput) == 0 {
+				require.Empty(t, unused)
+				require.Empty(t, log.String())
+				return
+			}
+			require.Equal(t, tc.expectedOutput, unused)
+			for _, envVar := range tc.expectedOutput {
+				require.Contains(t, log.String(), "msg=\"unused env var\" env_var="+envVar+" period=1h0m0s")
+			}
+		})
+	}
+}
+`
+)
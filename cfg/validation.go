@@ -0,0 +1,368 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// boundsRegexp matches the '# min' and '# max' annotations, which
+// may share a line, e.g. '# min: 1 max: 65535'.
+var boundsRegexp = regexp.MustCompile(`(?i)^\s*(min|max)\s*:\s*(\S+)(?:\s+(min|max)\s*:\s*(\S+))?\s*$`)
+
+// constraints holds the constraints annotated for a variable.
+type constraints struct {
+	// min and max are the bounds of numeric values, if any.
+	min, max string
+	// pattern is the regular expression string values must match, if any.
+	pattern string
+}
+
+// isEmpty reports whether there are no constraints.
+func (c constraints) isEmpty() bool {
+	return c.min == "" && c.max == "" && c.pattern == ""
+}
+
+// variableConstraints returns the constraints annotated for the given
+// variable with '# min', '# max' and '# pattern' comments.
+func variableConstraints(v envVar) constraints {
+	var c constraints
+	for _, line := range strings.Split(v.comment, "\n") {
+		m := boundsRegexp.FindStringSubmatch(line)
+		for i := 1; m != nil && i < len(m); i += 2 {
+			switch strings.ToLower(m[i]) {
+			case "min":
+				c.min = m[i+1]
+			case "max":
+				c.max = m[i+1]
+			}
+		}
+	}
+	c.pattern, _ = annotationValue(v.comment, "pattern")
+	return c
+}
+
+// hasConstraints reports whether any of the given variables has constraints.
+func hasConstraints(vars []envVar) bool {
+	for _, v := range vars {
+		if !variableConstraints(v).isEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateConstraints reports, with the given addProblem function, bounds
+// given to variables that aren't numbers or that aren't valid, patterns
+// given to variables that aren't strings or that don't compile, as well
+// as variable values that don't satisfy their constraints.
+func validateConstraints(v envVar, c constraints, addProblem func(line int, format string, args ...interface{})) {
+	t := fieldType(v)
+	if c.min != "" || c.max != "" {
+		if t != "int" && t != "float64" {
+			addProblem(v.line, "bounds given for variable %s of type %s", v.key, typeDescription(t))
+			return
+		}
+		valid := true
+		for _, bound := range []string{c.min, c.max} {
+			if bound != "" && checkValue(t, bound) != nil {
+				addProblem(v.line, "invalid bound %q for variable %s of type %s", bound, v.key, t)
+				valid = false
+			}
+		}
+		if !valid {
+			return
+		}
+		if c.min != "" && c.max != "" && parseBound(c.min) > parseBound(c.max) {
+			addProblem(v.line, "min %s greater than max %s for variable %s", c.min, c.max, v.key)
+			return
+		}
+		if v.value == "" || checkValue(t, v.value) != nil {
+			return
+		}
+		if value := parseBound(v.value); c.min != "" && value < parseBound(c.min) {
+			addProblem(v.line, "value %s for variable %s is less than min %s", v.value, v.key, c.min)
+		} else if c.max != "" && value > parseBound(c.max) {
+			addProblem(v.line, "value %s for variable %s is greater than max %s", v.value, v.key, c.max)
+		}
+	}
+	if c.pattern != "" {
+		if t != "string" {
+			addProblem(v.line, "pattern given for variable %s of type %s", v.key, typeDescription(t))
+			return
+		}
+		if strings.Contains(c.pattern, "`") {
+			addProblem(v.line, "pattern for variable %s contains a backtick", v.key)
+			return
+		}
+		re, err := regexp.Compile(c.pattern)
+		if err != nil {
+			addProblem(v.line, "invalid pattern %q for variable %s: %v", c.pattern, v.key, err)
+			return
+		}
+		if v.value != "" && !re.MatchString(v.value) {
+			addProblem(v.line, "value %q for variable %s doesn't match pattern %s", v.value, v.key, c.pattern)
+		}
+	}
+}
+
+// typeDescription returns the given type, or 'unknown' if it's empty.
+func typeDescription(fieldType string) string {
+	if fieldType == "" {
+		return "unknown"
+	}
+	return fieldType
+}
+
+// parseBound parses the given numeric bound, already checked to be valid.
+func parseBound(bound string) float64 {
+	if n, err := strconv.ParseInt(bound, 0, 64); err == nil {
+		return float64(n)
+	}
+	f, _ := strconv.ParseFloat(bound, 64)
+	return f
+}
+
+// formatBound formats the given numeric bound in decimal notation,
+// e.g. '16' for '0x10'.
+func formatBound(bound string) string {
+	return strconv.FormatFloat(parseBound(bound), 'g', -1, 64)
+}
+
+// isOptionalField reports whether the field generated for the given
+// variable may be left unset, in which case its constraints don't apply.
+func (g *generator) isOptionalField(v envVar) bool {
+	return !g.hasDefault(v) && !isRequired(v)
+}
+
+// validateTag returns the 'validate' tag of the field of the given type
+// generated for the given variable, if it has constraints or its path
+// is checked, e.g. 'min=1,max=65535'. Commas and pipes in patterns are
+// escaped as the validator expects.
+func (g *generator) validateTag(v envVar, fieldType string) string {
+	c := variableConstraints(v)
+	check, isPath := g.fieldPathCheck(v, fieldType)
+	if c.isEmpty() && !isPath {
+		return ""
+	}
+	var rules []string
+	if g.isOptionalField(v) {
+		rules = append(rules, "omitempty")
+	}
+	if c.min != "" {
+		rules = append(rules, "min="+c.min)
+	}
+	if c.max != "" {
+		rules = append(rules, "max="+c.max)
+	}
+	if c.pattern != "" {
+		rules = append(rules, "pattern="+strings.NewReplacer(",", "0x2C", "|", "0x7C").Replace(c.pattern))
+	}
+	if isPath {
+		rules = append(rules, check.tag)
+	}
+	return fmt.Sprintf(" validate:%q", strings.Join(rules, ","))
+}
+
+// patternVarName returns the name of the variable holding the compiled
+// pattern of the field with the given name, e.g. 'userNamePattern'.
+func patternVarName(fieldName string) string {
+	runes := []rune(fieldName)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes) + "Pattern"
+}
+
+// hasValidation reports whether the values of any of the given
+// variables are validated, i.e. have constraints or are checked paths.
+func (g *generator) hasValidation(vars []envVar) bool {
+	return hasConstraints(vars) || g.hasPathChecks(vars)
+}
+
+// validateMethod generates the 'Validate' method of 'Config', which
+// checks the values of the fields generated for the given variables
+// against their constraints and checks their paths, if any of them is
+// validated. With the validator tags, it validates them with the
+// validator package.
+func (g *generator) validateMethod(vars []envVar) string {
+	if !g.hasValidation(vars) {
+		return ""
+	}
+	values := "c"
+	if g.immutable {
+		values = "c.values"
+	}
+	doc := "// Validate checks the values of the configuration against the\n// constraints annotated in the env file"
+	if g.hasPathChecks(vars) {
+		doc += " and checks that its paths exist"
+	}
+	var sb strings.Builder
+	if g.validatorTags {
+		sb.WriteString("\n// validate validates values against the constraints in their 'validate'\n")
+		sb.WriteString("// tags, with custom validations, e.g. 'pattern', matching regular expressions.\n")
+		sb.WriteString("var validate = newValidator()\n")
+		sb.WriteString("\n// newValidator returns a validator with the custom validations registered.\n")
+		sb.WriteString("func newValidator() *validator.Validate {\n")
+		sb.WriteString("\tv := validator.New()\n")
+		sb.WriteString("\tif err := v.RegisterValidation(\"pattern\", func(fl validator.FieldLevel) bool {\n")
+		sb.WriteString("\t\treturn regexp.MustCompile(fl.Param()).MatchString(fl.Field().String())\n")
+		sb.WriteString("\t}); err != nil {\n\t\tpanic(err)\n\t}\n")
+		if g.hasPathChecks(vars) {
+			sb.WriteString("\tfor tag, check := range map[string]func(path string) error{\n")
+			for _, check := range pathChecks {
+				fmt.Fprintf(&sb, "\t\t%q: func(path string) error { return %s(path) },\n", check.tag, check.function)
+			}
+			sb.WriteString("\t} {\n")
+			sb.WriteString("\t\tif err := v.RegisterValidation(tag, pathValidation(check)); err != nil {\n\t\t\tpanic(err)\n\t\t}\n\t}\n")
+		}
+		sb.WriteString("\treturn v\n}\n")
+		if g.hasPathChecks(vars) {
+			sb.WriteString("\n// pathValidation returns a validation of paths with the given check,\n")
+			sb.WriteString("// leaving empty paths unchecked.\n")
+			sb.WriteString("func pathValidation(check func(path string) error) validator.Func {\n")
+			sb.WriteString("\treturn func(fl validator.FieldLevel) bool {\n")
+			sb.WriteString("\t\tpath := fl.Field().String()\n")
+			sb.WriteString("\t\treturn path == \"\" || check(path) == nil\n\t}\n}\n")
+		}
+		sb.WriteString("\n" + doc + ".\n")
+		sb.WriteString("func (c *" + g.structName + ") Validate() error {\n")
+		fmt.Fprintf(&sb, "\treturn validate.Struct(%s)\n}\n", values)
+		return sb.String()
+	}
+	var checks strings.Builder
+	for _, v := range vars {
+		c := variableConstraints(v)
+		check, isPath := g.variablePathCheck(v)
+		if c.isEmpty() && !isPath {
+			continue
+		}
+		goFieldName := g.fieldName(v.key)
+		field := values + "." + goFieldName
+		fieldType, _ := g.structField(v)
+		unset := ""
+		if g.isOptionalField(v) {
+			unset = field + " != 0 && "
+			if c.pattern != "" {
+				unset = field + " != \"\" && "
+			}
+		}
+		if c.min != "" {
+			fmt.Fprintf(&checks, "\tif %s%s < %s {\n", unset, field, c.min)
+			fmt.Fprintf(&checks, "\t\terrs = append(errs, fmt.Errorf(\"%s: %%v is less than %s\", %s))\n\t}\n", v.key, c.min, field)
+		}
+		if c.max != "" {
+			fmt.Fprintf(&checks, "\tif %s%s > %s {\n", unset, field, c.max)
+			fmt.Fprintf(&checks, "\t\terrs = append(errs, fmt.Errorf(\"%s: %%v is greater than %s\", %s))\n\t}\n", v.key, c.max, field)
+		}
+		if c.pattern != "" {
+			varName := patternVarName(goFieldName)
+			fmt.Fprintf(&sb, "\n// %s is the pattern of %s values.\n", varName, v.key)
+			fmt.Fprintf(&sb, "var %s = regexp.MustCompile(`%s`)\n", varName, c.pattern)
+			value := field
+			if fieldType != "string" {
+				value = "string(" + field + ")"
+			}
+			fmt.Fprintf(&checks, "\tif %s!%s.MatchString(%s) {\n", unset, varName, value)
+			fmt.Fprintf(&checks, "\t\terrs = append(errs, stderrors.New(%q))\n\t}\n", fmt.Sprintf("%s: value doesn't match %s", v.key, c.pattern))
+		}
+		if isPath {
+			checks.WriteString(pathCheckStatement(v.key, field, fieldType, check))
+		}
+	}
+	if g.hasPathChecks(vars) {
+		doc += ",\n// returning all the violations.\n"
+	} else {
+		doc += ", returning all the violations.\n"
+	}
+	sb.WriteString("\n" + doc)
+	sb.WriteString("func (c *" + g.structName + ") Validate() error {\n")
+	sb.WriteString("\tvar errs []error\n")
+	sb.WriteString(checks.String())
+	sb.WriteString("\treturn stderrors.Join(errs...)\n}\n")
+	return sb.String()
+}
+
+// pathCheckStatement returns the statement checking the path held by the
+// given field of the given type, with the given check, appending the error
+// to 'errs'. Empty paths are left unchecked, as they're paths not set.
+func pathCheckStatement(key, field, fieldType string, check pathCheck) string {
+	value, set := field, field+" != \"\""
+	if strings.HasPrefix(fieldType, "*") {
+		value, set = "*"+field, field+" != nil && *"+field+" != \"\""
+	}
+	if strings.TrimPrefix(fieldType, "*") == secretTypeName {
+		value = "string(" + value + ")"
+	}
+	return indent(fmt.Sprintf("if %s {\n\tif err := %s(%s); err != nil {\n\t\terrs = append(errs, fmt.Errorf(\"%s: %%w\", err))\n\t}\n}\n", set, check.function, value, key))
+}
+
+// indent indents every line of the given code with a tab.
+func indent(code string) string {
+	return strings.ReplaceAll("\t"+strings.TrimSuffix(code, "\n"), "\n", "\n\t") + "\n"
+}
+
+// validationImports returns the packages imported by the 'Validate'
+// method generated for the given variables.
+func (g *generator) validationImports(vars []envVar) []string {
+	if !g.hasValidation(vars) {
+		return nil
+	}
+	if g.validatorTags {
+		return []string{"regexp"}
+	}
+	for _, v := range vars {
+		if variableConstraints(v).pattern != "" {
+			return []string{"regexp"}
+		}
+	}
+	return nil
+}
+
+// samplePatternValue returns a short value matching the given pattern,
+// or 'value' if none can be found, for required variables whose env
+// file values are empty.
+func samplePatternValue(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "value"
+	}
+	var sb strings.Builder
+	writeSample(&sb, re.Simplify())
+	if sample := sb.String(); regexp.MustCompile(pattern).MatchString(sample) {
+		return sample
+	}
+	return "value"
+}
+
+// writeSample writes the shortest string matched by the given
+// regular expression, taking the first alternative of each choice.
+func writeSample(sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			sb.WriteRune(re.Rune[0])
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte('a')
+	case syntax.OpCapture, syntax.OpPlus:
+		writeSample(sb, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			writeSample(sb, re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeSample(sb, sub)
+		}
+	case syntax.OpAlternate:
+		writeSample(sb, re.Sub[0])
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_variableConstraints(t *testing.T) {
+	testCases := []struct {
+		name           string
+		comment        string
+		expectedOutput constraints
+	}{
+		{
+			name:           "bounds in one line",
+			comment:        "http port\nmin: 1 max: 65535",
+			expectedOutput: constraints{min: "1", max: "65535"},
+		},
+		{
+			name:           "bounds in separate lines",
+			comment:        "Max: 10\nmin: -1",
+			expectedOutput: constraints{min: "-1", max: "10"},
+		},
+		{
+			name:           "pattern",
+			comment:        "pattern: ^[a-z]+:[0-9]+$",
+			expectedOutput: constraints{pattern: "^[a-z]+:[0-9]+$"},
+		},
+		{
+			name:    "no constraints",
+			comment: "the minimum: one",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, variableConstraints(envVar{key: "VAR", comment: tc.comment}))
+		})
+	}
+}
+
+func Test_validateTag(t *testing.T) {
+	testCases := []struct {
+		name           string
+		v              envVar
+		expectedOutput string
+	}{
+		{
+			name:           "bounds",
+			v:              envVar{key: "PORT", value: "8080", comment: "min: 1 max: 65535"},
+			expectedOutput: ` validate:"min=1,max=65535"`,
+		},
+		{
+			name:           "optional",
+			v:              envVar{key: "RETRIES", comment: "type: int\nmax: 10"},
+			expectedOutput: ` validate:"omitempty,max=10"`,
+		},
+		{
+			name:           "pattern with commas and pipes",
+			v:              envVar{key: "NAME", value: "ab", comment: `pattern: ^(a|b){2,3}\d*$`},
+			expectedOutput: ` validate:"pattern=^(a0x7Cb){20x2C3}\\d*$"`,
+		},
+		{
+			name: "no constraints",
+			v:    envVar{key: "HOST", value: "localhost"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("config", WithValidatorTags()).(*generator)
+			require.Equal(t, tc.expectedOutput, g.validateTag(tc.v, fieldType(tc.v)))
+		})
+	}
+}
+
+func Test_samplePatternValue(t *testing.T) {
+	testCases := []struct {
+		pattern        string
+		expectedOutput string
+	}{
+		{pattern: `^[a-z]+(-[a-z]+)*$`, expectedOutput: "a"},
+		{pattern: `^v[0-9]+\.[0-9]+$`, expectedOutput: "v0.0"},
+		{pattern: `^(prod|staging)$`, expectedOutput: "prod"},
+		{pattern: `^\w{3}$`, expectedOutput: "000"},
+		{pattern: `^[a-z]+$|^$`, expectedOutput: "a"},
+		{pattern: `\bfoo\b`, expectedOutput: "foo"},
+		{pattern: `^a$^b$`, expectedOutput: "value"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, samplePatternValue(tc.pattern))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+// Variable is a variable defined in an env file.
+type Variable struct {
+	// Name is the variable name, as defined in the env file.
+	Name string
+	// Value is the variable value, with quotes removed and
+	// variable references resolved.
+	Value string
+	// Comment holds the comment lines right above the variable
+	// definition, followed by its inline comment, if any.
+	Comment string
+	// Type is the Go type inferred for the variable, e.g. 'int',
+	// or empty if it can't be inferred.
+	Type string
+	// Line is the line number where the variable is defined.
+	Line int
+}
+
+// ParseEnvFile parses the given env file the way the generator does,
+// returning the variables defined in it, so that other tools can build on
+// the parser without generating code. Options like WithDialect, WithEnvrc,
+// WithPrefix and WithInferCollections are honored, and the env file is read
+// from the file system set with WithFileSystem, if any.
+func ParseEnvFile(envFilePath string, opts ...Option) ([]Variable, error) {
+	g := NewGenerator("config", opts...).(*generator)
+	vars, err := g.readEnvFiles([]string{envFilePath}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return variables(vars), nil
+}
+
+// variables returns the given variables as they're returned by
+// ParseEnvFile and passed to plugins.
+func variables(vars []envVar) []Variable {
+	variables := make([]Variable, len(vars))
+	for i, v := range vars {
+		variables[i] = Variable{
+			Name:    v.key,
+			Value:   v.value,
+			Comment: v.comment,
+			Type:    fieldType(v),
+			Line:    v.line,
+		}
+	}
+	return variables
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_variables(t *testing.T) {
+	vars := []envVar{
+		{key: "PORT", value: "8080", line: 2, comment: "http port"},
+		{key: "HOSTS", value: "a,b", line: 3, inferredType: "[]string"},
+		{key: "EMPTY", line: 4},
+	}
+	require.Equal(t, []Variable{
+		{Name: "PORT", Value: "8080", Comment: "http port", Type: "int", Line: 2},
+		{Name: "HOSTS", Value: "a,b", Type: "[]string", Line: 3},
+		{Name: "EMPTY", Line: 4},
+	}, variables(vars))
+}
+
+func TestParseEnvFile(t *testing.T) {
+	testCases := []struct {
+		name           string
+		envFile        string
+		opts           []Option
+		expectedOutput []Variable
+		expectedError  error
+	}{
+		{
+			name:    "happy path",
+			envFile: "# http port\nPORT=8080\n\nDB_URL=postgres://${DB_HOST}/app\nTIMEOUT=5s # request timeout\n",
+			expectedOutput: []Variable{
+				{Name: "PORT", Value: "8080", Comment: "http port", Type: "int", Line: 2},
+				{Name: "DB_URL", Value: "postgres:///app", Type: "string", Line: 4},
+				{Name: "TIMEOUT", Value: "5s", Comment: "request timeout", Type: "string", Line: 5},
+			},
+		},
+		{
+			name:    "with options",
+			envFile: "APP_HOSTS=\"a.com,b.com\"\nOTHER=1\n",
+			opts:    []Option{WithPrefix("APP_"), WithInferCollections(), WithDialect(DockerDialect)},
+			expectedOutput: []Variable{
+				{Name: "APP_HOSTS", Value: "\"a.com,b.com\"", Type: "[]string", Line: 1},
+			},
+		},
+		{
+			name:          "invalid env file",
+			envFile:       "PORT=8080\nPORT=9090\n",
+			expectedError: errors.New("generating struct from env file .env: line 2: duplicate variable PORT, first defined at line 1"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFileSystem(map[string][]byte{".env": []byte(tc.envFile)})
+			output, err := ParseEnvFile(".env", append(tc.opts, WithFileSystem(fsys))...)
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+				require.Equal(t, tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestParseEnvFileNotFound(t *testing.T) {
+	_, err := ParseEnvFile(".env", WithFileSystem(NewMemFileSystem(nil)))
+	require.ErrorIs(t, err, ErrEnvFileNotFound)
+}
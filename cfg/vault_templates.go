@@ -0,0 +1,366 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+const (
+	vaultFileName                 = "vault.go"
+	vaultUnitTestFileName         = "vault_test.go"
+	vaultFileTemplateName         = "vaultFile"
+	vaultUnitTestFileTemplateName = "vaultUnitTestFile"
+	vaultFileTemplate             = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Env vars used to connect to Vault. VAULT_TOKEN is used to authenticate,
+// unless VAULT_K8S_ROLE is set, in which case the Kubernetes auth method,
+// mounted at VAULT_K8S_MOUNT ('kubernetes' by default), is used with the
+// pod's service account token.
+const (
+	vaultAddrEnvVar     = "VAULT_ADDR"
+	vaultTokenEnvVar    = "VAULT_TOKEN"
+	vaultK8sRoleEnvVar  = "VAULT_K8S_ROLE"
+	vaultK8sMountEnvVar = "VAULT_K8S_MOUNT"
+	vaultK8sTokenPath   = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// For ease of unit testing.
+var (
+	osReadFile      = os.ReadFile
+	vaultHTTPClient = http.DefaultClient
+)
+
+func init() {
+	secretLoaders = append(secretLoaders, loadVaultSecrets)
+}
+
+// loadVaultSecrets sets the env vars of the {{ .StructName }} fields tagged with
+// 'vault:"secret/path#key"' to the values of the referenced secrets.
+// When VAULT_ADDR is not set or a secret is not found, env vars are
+// left untouched, so that their values are used instead.
+func loadVaultSecrets(ctx context.Context) error {
+	return loadVaultSecretsForType(ctx, reflect.TypeOf({{ if .Immutable }}configValues{{ else }}{{ .StructName }}{{ end }}{}))
+}
+
+// loadVaultSecretsForType sets the env vars of the fields of the given
+// struct type that are tagged with 'vault:"secret/path#key"'.
+func loadVaultSecretsForType(ctx context.Context, t reflect.Type) error {
+	addr, ok := osLookupEnv(vaultAddrEnvVar)
+	if !ok || addr == "" {
+		return nil
+	}
+	var client *vaultClient
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		ref, ok := field.Tag.Lookup("vault")
+		if !ok {
+			continue
+		}
+		path, key, ok := strings.Cut(ref, "#")
+		if !ok {
+			return errors.Errorf("invalid vault reference %s for field %s, expected secret/path#key", ref, field.Name)
+		}
+		if client == nil {
+			c, err := newVaultClient(ctx, strings.TrimSuffix(addr, "/"))
+			if err != nil {
+				return errors.Wrap(err, "authenticating to Vault")
+			}
+			client = c
+		}
+		secret, found, err := client.read(ctx, path, key)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s from Vault", ref)
+		}
+		if !found {
+			continue
+		}
+		envVar := {{ if .EnvPrefix }}envPrefix + "_" + {{ end }}field.Tag.Get("envconfig")
+		if err := osSetenv(envVar, secret); err != nil {
+			return errors.Wrapf(err, "setting %s", envVar)
+		}
+	}
+	return nil
+}
+
+// vaultClient reads secrets through Vault's HTTP API.
+type vaultClient struct {
+	addr  string
+	token string
+}
+
+// newVaultClient returns a client authenticated either with VAULT_TOKEN
+// or through the Kubernetes auth method.
+func newVaultClient(ctx context.Context, addr string) (*vaultClient, error) {
+	role, _ := osLookupEnv(vaultK8sRoleEnvVar)
+	if role == "" {
+		token, _ := osLookupEnv(vaultTokenEnvVar)
+		if token == "" {
+			return nil, errors.Errorf("either %s or %s must be set", vaultTokenEnvVar, vaultK8sRoleEnvVar)
+		}
+		return &vaultClient{addr: addr, token: token}, nil
+	}
+	mount, _ := osLookupEnv(vaultK8sMountEnvVar)
+	if mount == "" {
+		mount = "kubernetes"
+	}
+	jwt, err := osReadFile(vaultK8sTokenPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading service account token")
+	}
+	body, err := json.Marshal(map[string]string{"role": role, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding login request")
+	}
+	var login struct {
+		Auth struct {
+			ClientToken string ` + "`json:\"client_token\"`" + `
+		} ` + "`json:\"auth\"`" + `
+	}
+	url := fmt.Sprintf("%s/v1/auth/%s/login", addr, mount)
+	found, err := vaultRequest(ctx, http.MethodPost, url, "", body, &login)
+	if err != nil {
+		return nil, errors.Wrap(err, "logging in with Kubernetes auth")
+	}
+	if !found {
+		return nil, errors.Errorf("Kubernetes auth method not mounted at %s", mount)
+	}
+	return &vaultClient{addr: addr, token: login.Auth.ClientToken}, nil
+}
+
+// read returns the value of the given key of the secret stored at the given
+// path, reporting whether it was found. Both KV version 1 and 2 secrets
+// engines are supported.
+func (c *vaultClient) read(ctx context.Context, path, key string) (string, bool, error) {
+	var secret struct {
+		Data map[string]interface{} ` + "`json:\"data\"`" + `
+	}
+	url := fmt.Sprintf("%s/v1/%s", c.addr, strings.TrimPrefix(path, "/"))
+	found, err := vaultRequest(ctx, http.MethodGet, url, c.token, nil, &secret)
+	if err != nil || !found {
+		return "", false, err
+	}
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok && data["metadata"] != nil {
+		data = nested
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", false, nil
+	}
+	if s, ok := value.(string); ok {
+		return s, true, nil
+	}
+	return fmt.Sprint(value), true, nil
+}
+
+// vaultRequest sends a request to Vault and decodes its response into out,
+// reporting whether the requested resource was found.
+func vaultRequest(ctx context.Context, method, url, token string, body []byte, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "creating request")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := vaultHTTPClient.Do(req)
+	if err != nil {
+		return false, errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, errors.Errorf("unexpected status %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, errors.Wrap(err, "decoding response")
+	}
+	return true, nil
+}
+`
+
+	vaultUnitTestFileTemplate = `{{ .Header }}package {{ .ConfigReaderPkgName }}
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadVaultSecretsForType(t *testing.T) {
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var login map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&login); err != nil || !reflect.DeepEqual(login, map[string]string{"role": "app", "jwt": "jwt"}) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(` + "`" + `{"auth": {"client_token": "k8s-token"}}` + "`" + `))
+			return
+		case "/v1/secret/data/db":
+			if token := r.Header.Get("X-Vault-Token"); token != "token" && token != "k8s-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(` + "`" + `{"data": {"data": {"password": "s3cr3t", "port": 5432}, "metadata": {"version": 1}}}` + "`" + `))
+			return
+		case "/v1/kv/api":
+			w.Write([]byte(` + "`" + `{"data": {"key": "k3y"}}` + "`" + `))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer vaultServer.Close()
+	configType := func(refs ...string) reflect.Type {
+		var fields []reflect.StructField
+		for i, ref := range refs {
+			fields = append(fields, reflect.StructField{
+				Name: "Field" + string(rune('A'+i)),
+				Type: reflect.TypeOf(""),
+				Tag:  reflect.StructTag("envconfig:\"FIELD_" + string(rune('A'+i)) + "\" vault:\"" + ref + "\""),
+			})
+		}
+		fields = append(fields, reflect.StructField{Name: "Untagged", Type: reflect.TypeOf("")})
+		return reflect.StructOf(fields)
+	}
+	testCases := []struct {
+		name            string
+		env             map[string]string
+		refs            []string
+		mockedOsSetenv  func(key, value string) error
+		expectedEnviron map[string]string
+		expectedError   error
+	}{
+		{
+			name: "token auth",
+			env:  map[string]string{"VAULT_ADDR": vaultServer.URL + "/", "VAULT_TOKEN": "token"},
+			refs: []string{"secret/data/db#password", "secret/data/db#port", "kv/api#key"},
+			expectedEnviron: map[string]string{
+				"FIELD_A": "s3cr3t",
+				"FIELD_B": "5432",
+				"FIELD_C": "k3y",
+			},
+		},
+		{
+			name:            "kubernetes auth",
+			env:             map[string]string{"VAULT_ADDR": vaultServer.URL, "VAULT_K8S_ROLE": "app"},
+			refs:            []string{"secret/data/db#password"},
+			expectedEnviron: map[string]string{"FIELD_A": "s3cr3t"},
+		},
+		{
+			name:            "vault not configured",
+			refs:            []string{"secret/data/db#password"},
+			expectedEnviron: map[string]string{},
+		},
+		{
+			name:            "secret not found",
+			env:             map[string]string{"VAULT_ADDR": vaultServer.URL, "VAULT_TOKEN": "token"},
+			refs:            []string{"secret/data/unknown#password", "secret/data/db#unknown"},
+			expectedEnviron: map[string]string{},
+		},
+		{
+			name:          "invalid reference",
+			env:           map[string]string{"VAULT_ADDR": vaultServer.URL, "VAULT_TOKEN": "token"},
+			refs:          []string{"secret/data/db"},
+			expectedError: errors.New("invalid vault reference secret/data/db for field FieldA, expected secret/path#key"),
+		},
+		{
+			name:          "missing credentials",
+			env:           map[string]string{"VAULT_ADDR": vaultServer.URL},
+			refs:          []string{"secret/data/db#password"},
+			expectedError: errors.New("authenticating to Vault: either VAULT_TOKEN or VAULT_K8S_ROLE must be set"),
+		},
+		{
+			name:          "error logging in with kubernetes auth",
+			env:           map[string]string{"VAULT_ADDR": vaultServer.URL, "VAULT_K8S_ROLE": "unknown"},
+			refs:          []string{"secret/data/db#password"},
+			expectedError: errors.New("authenticating to Vault: logging in with Kubernetes auth: unexpected status 400 Bad Request"),
+		},
+		{
+			name:          "kubernetes auth method not mounted",
+			env:           map[string]string{"VAULT_ADDR": vaultServer.URL, "VAULT_K8S_ROLE": "app", "VAULT_K8S_MOUNT": "k8s"},
+			refs:          []string{"secret/data/db#password"},
+			expectedError: errors.New("authenticating to Vault: Kubernetes auth method not mounted at k8s"),
+		},
+		{
+			name:          "error reading secret",
+			env:           map[string]string{"VAULT_ADDR": vaultServer.URL, "VAULT_TOKEN": "invalid"},
+			refs:          []string{"secret/data/db#password"},
+			expectedError: errors.New("reading secret/data/db#password from Vault: unexpected status 403 Forbidden"),
+		},
+		{
+			name: "error setting env var",
+			env:  map[string]string{"VAULT_ADDR": vaultServer.URL, "VAULT_TOKEN": "token"},
+			refs: []string{"secret/data/db#password"},
+			mockedOsSetenv: func(key, value string) error {
+				return errors.New("random error")
+			},
+			expectedError: errors.New("setting {{ with .EnvPrefix }}{{ . }}_{{ end }}FIELD_A: random error"),
+		},
+	}
+	lookupEnv, readFile, setenv := osLookupEnv, osReadFile, osSetenv
+	t.Cleanup(func() {
+		osLookupEnv, osReadFile, osSetenv = lookupEnv, readFile, setenv
+	})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			osLookupEnv = func(key string) (string, bool) {
+				value, ok := tc.env[key]
+				return value, ok
+			}
+			osReadFile = func(name string) ([]byte, error) {
+				return []byte("jwt\n"), nil
+			}
+			environ := make(map[string]string)
+			osSetenv = func(key, value string) error {
+				if tc.mockedOsSetenv != nil {
+					return tc.mockedOsSetenv(key, value)
+				}
+				environ[key] = value
+				return nil
+			}
+			err := loadVaultSecretsForType(context.Background(), configType(tc.refs...))
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error, got nil")
+				}
+				expectedEnviron := tc.expectedEnviron
+{{- if .EnvPrefix }}
+				expectedEnviron = make(map[string]string)
+				for key, value := range tc.expectedEnviron {
+					expectedEnviron[envPrefix+"_"+key] = value
+				}
+{{- end }}
+				require.Equal(t, expectedEnviron, environ)
+			}
+		})
+	}
+}
+`
+)
@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// verifyPackages type-checks the Go packages the given generated files
+// belong to, unit tests included, returning an error listing where and
+// why they don't compile, if they don't. Imports that can't be resolved,
+// like those of modules that weren't downloaded yet, are left out, since
+// 'go mod tidy' fixes them.
+func (g *generator) verifyPackages(generatedFiles []string) error {
+	var dirs []string
+	filesByDir := make(map[string][]string)
+	for _, f := range generatedFiles {
+		if path.Ext(f) != ".go" {
+			continue
+		}
+		dir := path.Dir(f)
+		if _, ok := filesByDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		filesByDir[dir] = append(filesByDir[dir], f)
+	}
+	var problems []string
+	for _, dir := range dirs {
+		g.logger.Debug("verifying package", "dir", dir)
+		dirProblems, err := g.verifyPackage(filesByDir[dir])
+		if err != nil {
+			return err
+		}
+		problems = append(problems, dirProblems...)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("generated code doesn't compile:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// verifyPackage type-checks the package made of the given Go files,
+// returning its compilation errors.
+func (g *generator) verifyPackage(filePaths []string) ([]string, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, filePath := range filePaths {
+		src, err := g.fs.ReadFile(filePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading go file %s", filePath)
+		}
+		file, err := parser.ParseFile(fset, filePath, src, 0)
+		if err != nil {
+			return []string{err.Error()}, nil
+		}
+		files = append(files, file)
+	}
+	var problems []string
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			if typeErr, ok := err.(types.Error); ok && strings.HasPrefix(typeErr.Msg, "could not import") {
+				return
+			}
+			problems = append(problems, err.Error())
+		},
+	}
+	conf.Check(files[0].Name.Name, fset, files, nil)
+	return problems, nil
+}
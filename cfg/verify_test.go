@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_verifyPackages(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         map[string][]byte
+		expectedError error
+	}{
+		{
+			name: "happy path",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(`package appcfg
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	Timeout time.Duration
+}
+
+func Read() (*Config, error) {
+	var config Config
+	return &config, envconfig.Process("", &config)
+}
+`),
+				"appcfg/config_test.go": []byte(`package appcfg
+
+import "testing"
+
+func TestRead(t *testing.T) {
+	_, _ = Read()
+}
+`),
+				"docker-compose.env.yaml": []byte("environment:\n"),
+			},
+		},
+		{
+			name: "compilation errors",
+			files: map[string][]byte{
+				"appcfg/config.go": []byte(`package appcfg
+
+type Config struct {
+	Port int
+}
+`),
+				"appcfg/config_test.go": []byte(`package appcfg
+
+func sample() Config {
+	return Config{Port: "8080", Host: "localhost"}
+}
+`),
+				"docker-compose.env.yaml": []byte("environment:\n"),
+			},
+			expectedError: errors.New(`generated code doesn't compile:
+appcfg/config_test.go:4:22: cannot use "8080" (untyped string constant) as int value in struct literal
+appcfg/config_test.go:4:30: unknown field Host in struct literal of type Config`),
+		},
+		{
+			name: "syntax error",
+			files: map[string][]byte{
+				"appcfg/config.go":        []byte("package appcfg\n\ntype Config struct {\n"),
+				"appcfg/config_test.go":   []byte("package appcfg\n"),
+				"docker-compose.env.yaml": []byte("environment:\n"),
+			},
+			expectedError: errors.New(`generated code doesn't compile:
+appcfg/config.go:3:22: expected '}', found 'EOF'`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGenerator("appcfg", WithFileSystem(NewMemFileSystem(tc.files))).(*generator)
+			err := g.verifyPackages([]string{"appcfg/config.go", "appcfg/config_test.go", "docker-compose.env.yaml"})
+			if err != nil {
+				if tc.expectedError == nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				if tc.expectedError != nil {
+					t.Fatalf("expected error to be %v, got nil", tc.expectedError)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateWithVerify(t *testing.T) {
+	fsys := NewMemFileSystem(map[string][]byte{".env": []byte("# the timeout.\nTIMEOUT=5s\nBASE_URL=http://localhost\n")})
+	g := NewGenerator("appcfg", WithFileSystem(fsys), WithVerify(), WithConfigProvider(), WithClone())
+	output, err := g.GenerateConfigPackageFromEnvFile(".env")
+	require.NoError(t, err)
+	require.Contains(t, output, "appcfg/provider.go")
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// addCommand adds env vars to the 'Config' struct of an existing
+// generated package, and to its env file, without regenerating it.
+type addCommand struct {
+	ConfigPackageName string `short:"p" long:"packageName" description:"package name" required:"true"`
+	EnvFile           string `short:"e" long:"envFile" description:"env file to add the env vars to" default:".env"`
+	Naming            string `long:"naming" description:"field naming strategy" choice:"camel" choice:"pascal" choice:"golint" default:"camel"`
+	StructName        string `long:"structName" description:"name of the generated struct" default:"Config"`
+	Args              struct {
+		Vars []string `positional-arg-name:"VAR_NAME[:type]" required:"1"`
+	} `positional-args:"yes"`
+}
+
+// Execute adds each env var, e.g. 'TIMEOUT:time.Duration', as a field
+// of the given type, or string if none is given.
+func (c *addCommand) Execute(args []string) error {
+	configFilePath := filepath.Join(c.ConfigPackageName, "config.go")
+	for _, v := range c.Args.Vars {
+		key, fieldType, _ := strings.Cut(v, ":")
+		err := cfg.AddEnvVar(configFilePath, c.EnvFile, key, fieldType, append(loggerOptions(),
+			cfg.WithFieldNamer(namingStrategies[c.Naming]),
+			cfg.WithStructName(c.StructName),
+		)...)
+		if err != nil {
+			return err
+		}
+		printInfo("added:", key, "to", configFilePath, "and", c.EnvFile)
+	}
+	return nil
+}
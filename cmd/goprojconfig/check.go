@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// checkCommand validates env vars against the 'Config' struct of an
+// existing generated package, e.g. as a pre-deploy gate.
+type checkCommand struct {
+	ConfigPackageName string   `short:"p" long:"packageName" description:"package name" required:"true"`
+	StructName        string   `long:"structName" description:"name of the generated struct" default:"Config"`
+	EnvFiles          []string `short:"e" long:"envFile" description:"env file, can be repeated to merge several files (later ones take precedence)"`
+}
+
+// Execute validates the env vars defined in the env files, or the process
+// environment if none is given, reporting every problem found.
+func (c *checkCommand) Execute(args []string) error {
+	env, err := checkedEnv(c.EnvFiles)
+	if err != nil {
+		return err
+	}
+	configFilePath := filepath.Join(c.ConfigPackageName, "config.go")
+	problems, err := cfg.CheckEnv(configFilePath, env, cfg.WithStructName(c.StructName))
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Println("invalid:", problem)
+		}
+		return errors.Errorf("%d problem(s) found checking env vars against %s", len(problems), configFilePath)
+	}
+	printInfo("ok: env vars are valid for", configFilePath)
+	return nil
+}
+
+// checkedEnv returns the env vars defined in the given env files, with later
+// ones taking precedence, or the process environment if none is given.
+func checkedEnv(envFilePaths []string) (map[string]string, error) {
+	env := make(map[string]string)
+	if len(envFilePaths) == 0 {
+		for _, envVar := range os.Environ() {
+			key, value, _ := strings.Cut(envVar, "=")
+			env[key] = value
+		}
+		return env, nil
+	}
+	for _, envFilePath := range envFilePaths {
+		fileEnv, err := godotenv.Read(envFilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading env file %s", envFilePath)
+		}
+		for key, value := range fileEnv {
+			env[key] = value
+		}
+	}
+	return env, nil
+}
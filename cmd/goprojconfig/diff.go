@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// diffCommand shows how regenerating a config package from
+// env files would change its 'Config' struct.
+type diffCommand struct {
+	ConfigPackageName  string   `short:"p" long:"packageName" description:"package name" required:"true"`
+	StructName         string   `long:"structName" description:"name of the generated struct" default:"Config"`
+	EnvFiles           []string `short:"e" long:"envFile" description:"env file, '-' for the standard input, can be repeated to merge several files (later ones take precedence)" required:"true"`
+	Naming             string   `long:"naming" description:"field naming strategy" choice:"camel" choice:"pascal" choice:"golint" default:"camel"`
+	Prefix             string   `long:"prefix" description:"only read the env vars with the given prefix, e.g. 'APP_', leaving it out of field names"`
+	Include            []string `long:"include" description:"only read the env vars matching the given glob pattern, e.g. 'DB_*', can be repeated"`
+	Exclude            []string `long:"exclude" description:"leave out the env vars matching the given glob pattern, e.g. '*_DEPRECATED', can be repeated"`
+	InferCollections   bool     `long:"infer-collections" description:"infer []string and map[string]string fields from comma delimited values, e.g. 'a,b' and 'k1:v1,k2:v2'"`
+	MaskSecrets        bool     `long:"mask-secrets" description:"generate a Secret type, masked when printed, for sensitive fields, e.g. DB_PASSWORD"`
+	ValidatorTags      bool     `long:"validator-tags" description:"validate '# min', '# max' and '# pattern' constraints with go-playground/validator tags instead of hand-rolled checks"`
+	CheckPaths         bool     `long:"check-paths" description:"check that _FILE, _DIR and _PATH env vars hold existing paths when reading configuration"`
+	DefaultsFromValues bool     `long:"defaults-from-values" description:"use env file values as field defaults instead of requiring them"`
+	MaxLineLength      int      `long:"max-line-length" description:"maximum length, in bytes, of the lines of env files, e.g. of base64 encoded certificates" default:"1048576"`
+	Secrets            []string `long:"secrets" description:"resolve secrets from the given secrets manager, can be repeated" choice:"aws" choice:"gcp" choice:"azure" choice:"vault" choice:"doppler" choice:"1password"`
+}
+
+// Execute prints the fields that would be removed, changed or added.
+func (c *diffCommand) Execute(args []string) error {
+	genOpts := append(loggerOptions(), cfg.WithFieldNamer(namingStrategies[c.Naming]), cfg.WithStructName(c.StructName))
+	if c.Prefix != "" {
+		genOpts = append(genOpts, cfg.WithPrefix(c.Prefix))
+	}
+	if len(c.Include) > 0 {
+		genOpts = append(genOpts, cfg.WithInclude(c.Include...))
+	}
+	if len(c.Exclude) > 0 {
+		genOpts = append(genOpts, cfg.WithExclude(c.Exclude...))
+	}
+	if c.InferCollections {
+		genOpts = append(genOpts, cfg.WithInferCollections())
+	}
+	if c.MaskSecrets {
+		genOpts = append(genOpts, cfg.WithMaskedSecrets())
+	}
+	if c.ValidatorTags {
+		genOpts = append(genOpts, cfg.WithValidatorTags())
+	}
+	if c.CheckPaths {
+		genOpts = append(genOpts, cfg.WithPathChecks())
+	}
+	if c.DefaultsFromValues {
+		genOpts = append(genOpts, cfg.WithDefaultsFromValues())
+	}
+	for _, backend := range c.Secrets {
+		genOpts = append(genOpts, cfg.WithSecretsBackends(cfg.SecretsBackend(backend)))
+	}
+	if c.MaxLineLength != cfg.DefaultMaxLineLength {
+		genOpts = append(genOpts, cfg.WithMaxLineLength(c.MaxLineLength))
+	}
+	configFilePath := filepath.Join(c.ConfigPackageName, "config.go")
+	lines, err := cfg.DiffConfig(configFilePath, c.EnvFiles, genOpts...)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		printInfo("no changes:", configFilePath, "is up to date")
+		return nil
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return nil
+}
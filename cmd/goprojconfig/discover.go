@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// discoverCommand looks for env files across the modules of a repository
+// and generates a config package next to each of them.
+type discoverCommand struct {
+	ConfigPackageName string `short:"p" long:"packageName" description:"name of the generated packages" default:"config"`
+	Root              string `long:"root" description:"dir of the repository to walk" default:"."`
+	Yes               bool   `short:"y" long:"yes" description:"generate the packages without asking for confirmation"`
+}
+
+// Execute prints the generation plan and, once confirmed,
+// generates the packages it lists.
+func (c *discoverCommand) Execute(args []string) error {
+	plans, err := cfg.DiscoverGenerationPlans(os.DirFS(c.Root), c.ConfigPackageName)
+	if err != nil {
+		return err
+	}
+	if len(plans) == 0 {
+		printInfo("no env files found in Go modules under", c.Root)
+		return nil
+	}
+	for _, plan := range plans {
+		fmt.Printf("%s -> %s (%s)\n", filepath.Join(c.Root, plan.Dir, plan.EnvFile), filepath.Join(c.Root, plan.PackageDir), plan.ImportPath)
+	}
+	if !c.Yes {
+		w := &wizard{in: bufio.NewScanner(os.Stdin), out: os.Stdout}
+		answer, err := w.ask(fmt.Sprintf("generate %d package(s) (y, n)", len(plans)), "n", []string{"y", "n"})
+		if err != nil {
+			return err
+		}
+		if answer != "y" {
+			return nil
+		}
+	}
+	for _, plan := range plans {
+		generatedFiles, err := generatePlan(filepath.Join(c.Root, plan.Dir), c.ConfigPackageName, plan.EnvFile)
+		if err != nil {
+			return errors.Wrapf(err, "generating %s", filepath.Join(c.Root, plan.PackageDir))
+		}
+		for _, f := range generatedFiles {
+			printInfo("created:", f)
+		}
+	}
+	return nil
+}
+
+// generatePlan generates the given package from the given env file
+// in the given dir.
+func generatePlan(dir, packageName, envFile string) ([]string, error) {
+	genOpts := append(loggerOptions(), cfg.WithOutputDir(dir))
+	return cfg.NewGenerator(packageName, genOpts...).GenerateConfigPackageFromEnvFiles(filepath.Join(dir, envFile))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// docsCommand documents the env vars defined in env files.
+type docsCommand struct {
+	EnvFiles           []string `short:"e" long:"envFile" description:"env file, '-' for the standard input, can be repeated to merge several files (later ones take precedence)" required:"true"`
+	Prefix             string   `long:"prefix" description:"only read the env vars with the given prefix, e.g. 'APP_', leaving it out of field names"`
+	Include            []string `long:"include" description:"only read the env vars matching the given glob pattern, e.g. 'DB_*', can be repeated"`
+	Exclude            []string `long:"exclude" description:"leave out the env vars matching the given glob pattern, e.g. '*_DEPRECATED', can be repeated"`
+	InferCollections   bool     `long:"infer-collections" description:"infer []string and map[string]string fields from comma delimited values, e.g. 'a,b' and 'k1:v1,k2:v2'"`
+	DefaultsFromValues bool     `long:"defaults-from-values" description:"document env file values as defaults, as with the generate command"`
+	Format             string   `long:"docs-format" description:"format of the documentation: a Markdown table, or a JSON catalog for tools, e.g. developer portals" choice:"markdown" choice:"json" default:"markdown"`
+	Output             string   `short:"o" long:"output" description:"file to write the documentation to, instead of the standard output"`
+}
+
+// Execute prints the documentation, or writes it to the output file.
+func (c *docsCommand) Execute(args []string) error {
+	genOpts := append(loggerOptions(), cfg.WithDocsFormat(cfg.DocsFormat(c.Format)))
+	if c.Prefix != "" {
+		genOpts = append(genOpts, cfg.WithPrefix(c.Prefix))
+	}
+	if len(c.Include) > 0 {
+		genOpts = append(genOpts, cfg.WithInclude(c.Include...))
+	}
+	if len(c.Exclude) > 0 {
+		genOpts = append(genOpts, cfg.WithExclude(c.Exclude...))
+	}
+	if c.InferCollections {
+		genOpts = append(genOpts, cfg.WithInferCollections())
+	}
+	if c.DefaultsFromValues {
+		genOpts = append(genOpts, cfg.WithDefaultsFromValues())
+	}
+	docs, err := cfg.Docs(c.EnvFiles, genOpts...)
+	if err != nil {
+		return err
+	}
+	if c.Output == "" {
+		fmt.Print(docs)
+		return nil
+	}
+	if err := os.WriteFile(c.Output, []byte(docs), 0644); err != nil {
+		return errors.Wrapf(err, "writing file %s", c.Output)
+	}
+	printInfo("created:", c.Output)
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// encryptCommand encrypts an env file with age.
+type encryptCommand struct {
+	EnvFile    string   `short:"e" long:"envFile" description:"env file to encrypt" default:".env"`
+	Output     string   `short:"o" long:"output" description:"encrypted env file to write, the env file with the .age extension by default"`
+	Recipients []string `short:"r" long:"recipient" description:"age public key, e.g. 'age1...', to encrypt the env file for, can be repeated" required:"true"`
+}
+
+// Execute encrypts the env file.
+func (c *encryptCommand) Execute(args []string) error {
+	output := c.Output
+	if output == "" {
+		output = c.EnvFile + cfg.AgeEnvFileExtension
+	}
+	if err := cfg.EncryptEnvFile(c.EnvFile, output, c.Recipients); err != nil {
+		return err
+	}
+	printInfo("created:", output)
+	return nil
+}
+
+// decryptCommand decrypts an env file encrypted with age.
+type decryptCommand struct {
+	EnvFile  string `short:"e" long:"envFile" description:"encrypted env file to decrypt" default:".env.age"`
+	Output   string `short:"o" long:"output" description:"env file to write, the encrypted env file without the .age extension by default"`
+	Identity string `short:"i" long:"identity" description:"age identity file, e.g. one generated by 'age-keygen'" required:"true"`
+}
+
+// Execute decrypts the env file.
+func (c *decryptCommand) Execute(args []string) error {
+	output := c.Output
+	if output == "" {
+		output = strings.TrimSuffix(c.EnvFile, cfg.AgeEnvFileExtension)
+	}
+	if output == c.EnvFile {
+		output += ".decrypted"
+	}
+	if err := cfg.DecryptEnvFile(c.EnvFile, output, c.Identity); err != nil {
+		return err
+	}
+	printInfo("created:", output)
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import "github.com/tiagomelo/go-project-config/cfg"
+
+// envFileCommand generates a sample env file from the 'Config'
+// struct of an existing package.
+type envFileCommand struct {
+	From       string `long:"from" description:"Go file declaring the Config struct, e.g. appcfg/config.go" required:"true"`
+	Output     string `short:"o" long:"output" description:"env file to generate" default:".env.example"`
+	StructName string `long:"structName" description:"name of the struct" default:"Config"`
+}
+
+// Execute generates the env file.
+func (c *envFileCommand) Execute(args []string) error {
+	if err := cfg.GenerateEnvFileFromConfig(c.From, c.Output, cfg.WithStructName(c.StructName)); err != nil {
+		return err
+	}
+	printInfo("created:", c.Output)
+	return nil
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// generateCommand generates a config package from env files, or a sample
+// one along with a sample '.env' file if none is given.
+type generateCommand struct {
+	ConfigPackageName  string   `short:"p" long:"packageName" description:"package name" required:"true"`
+	StructName         string   `long:"structName" description:"name of the generated struct" default:"Config"`
+	ReaderName         string   `long:"readerName" description:"name of the generated function reading configuration, also replacing 'Read' in the names of the other reading functions, e.g. 'LoadFromEnvFile'" default:"Read"`
+	OutputDir          string   `short:"o" long:"output-dir" description:"dir the package dir is created in, instead of the working dir, e.g. 'internal' or an absolute dir"`
+	EnvFiles           []string `short:"e" long:"envFile" description:"env file, '-' for the standard input, can be repeated to merge several files (later ones take precedence)"`
+	Prefix             string   `long:"prefix" description:"only read the env vars with the given prefix, e.g. 'APP_', leaving it out of field names"`
+	Include            []string `long:"include" description:"only read the env vars matching the given glob pattern, e.g. 'DB_*', can be repeated"`
+	Exclude            []string `long:"exclude" description:"leave out the env vars matching the given glob pattern, e.g. '*_DEPRECATED', can be repeated"`
+	Naming             string   `long:"naming" description:"field naming strategy" choice:"camel" choice:"pascal" choice:"golint" default:"camel"`
+	Sort               string   `long:"sort" description:"emit struct fields in env file order or sorted by name" choice:"source" choice:"fields" default:"source"`
+	Immutable          bool     `long:"immutable" description:"generate unexported struct fields with exported getters, so that configuration can't be changed once read"`
+	TrackUsage         bool     `long:"track-usage" description:"make getters record which env vars are read and generate ReportUnused, logging the ones never read during a sample period; requires --immutable"`
+	MaskSecrets        bool     `long:"mask-secrets" description:"generate a Secret type, masked when printed, for sensitive fields, e.g. DB_PASSWORD"`
+	ValidatorTags      bool     `long:"validator-tags" description:"validate '# min', '# max' and '# pattern' constraints with go-playground/validator tags instead of hand-rolled checks"`
+	CheckPaths         bool     `long:"check-paths" description:"check that _FILE, _DIR and _PATH env vars hold existing paths when reading configuration"`
+	InferCollections   bool     `long:"infer-collections" description:"infer []string and map[string]string fields from comma delimited values, e.g. 'a,b' and 'k1:v1,k2:v2'"`
+	K8sManifest        bool     `long:"k8s-manifest" description:"read the env vars from the data keys of the Kubernetes ConfigMaps and Secrets defined in the manifest given with -e"`
+	Envrc              bool     `long:"envrc" description:"parse the env files as direnv .envrc files, taking variables from their export statements (.envrc files always are)"`
+	Dialect            string   `long:"dialect" description:"rules the env files are interpreted with, when generating and by the generated readers: godotenv's, or Docker's, which take values verbatim" choice:"godotenv" choice:"docker" default:"godotenv"`
+	DefaultsFromValues bool     `long:"defaults-from-values" description:"use env file values as field defaults instead of requiring them"`
+	Profiles           bool     `long:"profiles" description:"generate ReadForEnv and make Read honor APP_ENV"`
+	Watch              bool     `long:"watch" description:"regenerate whenever the env files change"`
+	HotReload          bool     `long:"hot-reload" description:"generate Watch, which reloads configuration whenever the env file changes"`
+	SIGHUPReload       bool     `long:"sighup-reload" description:"generate ReloadOnSIGHUP, which reloads configuration on SIGHUP"`
+	WithContext        bool     `long:"with-context" description:"make the functions reading configuration take a context, honored by secrets managers and remote sources"`
+	OTel               bool     `long:"otel" description:"record OpenTelemetry spans of configuration reads, implies --with-context"`
+	Metrics            bool     `long:"metrics" description:"generate RegisterMetrics, which registers Prometheus metrics of configuration reloads, requires --hot-reload or --sighup-reload"`
+	Singleton          bool     `long:"singleton" description:"generate Get and Set, a thread-safe configuration singleton"`
+	Clone              bool     `long:"clone" description:"generate Clone and Equal, which deep copy and deeply compare configurations"`
+	DebugHandler       bool     `long:"debug-handler" description:"generate Handler, an HTTP handler serving the configuration as JSON with sensitive values redacted"`
+	Embed              bool     `long:"embed" description:"embed the env file values, except sensitive ones, in the package with go:embed and generate ReadEmbedded, which falls back to them"`
+	Checksum           bool     `long:"checksum" description:"pin the SHA-256 of the config schema and verify when reading configuration that all its env vars are set, generating SetStrictSchema along with --prefix to reject unexpected ones"`
+	MaxLineLength      int      `long:"max-line-length" description:"maximum length, in bytes, of the lines of env files, e.g. of base64 encoded certificates" default:"1048576"`
+	Benchmarks         bool     `long:"benchmarks" description:"generate config_bench_test.go, benchmarking reading configuration and reloading it"`
+	Strict             bool     `long:"strict" description:"fail to read configuration when env vars with the prefix that don't map to any field are set, e.g. misspelled ones; requires --prefix"`
+	Age                bool     `long:"age" description:"generate ReadFromAgeEnvFile, which reads configuration from an env file encrypted with 'goprojconfig encrypt', given an age identity file"`
+	SecretFiles        bool     `long:"secret-files" description:"read sensitive env vars, e.g. DB_PASSWORD, from the files set by their _FILE variants, e.g. DB_PASSWORD_FILE, as with mounted Kubernetes secrets"`
+	Overrides          bool     `long:"overrides" description:"generate ReadWithOverrides, which reads configuration with the given overrides, e.g. from command-line flags, taking precedence over env vars, the .env file and defaults"`
+	SchemaVersion      int      `long:"schema-version" description:"generate a SchemaVersion constant set to the given version, and Migrate, which migrates env vars of previous schema versions with registered rename and transform hooks"`
+	Flags              string   `long:"flags" description:"generate BindFlags, which registers a flag per field with the given flags library, overriding its env var" choice:"cobra"`
+	Features           bool     `long:"features" description:"generate Features, feature flags read from the bool FEATURE_* env vars"`
+	TestConfig         bool     `long:"test-config" description:"also generate a <packageName>test package with NewTestConfig, returning a Config prefilled with sample values for tests"`
+	Provider           bool     `long:"provider" description:"generate ConfigProvider, an interface with a getter per field, along with ConfigProviderMock"`
+	Verify             bool     `long:"verify" description:"type-check the generated packages, failing with their compilation errors if they don't compile"`
+	Secrets            []string `long:"secrets" description:"resolve secrets from the given secrets manager, can be repeated" choice:"aws" choice:"gcp" choice:"azure" choice:"vault" choice:"doppler" choice:"1password"`
+	Remote             []string `long:"remote" description:"generate a reader for the given remote key/value store, HTTP endpoint or custom sources registered in the package, can be repeated" choice:"consul" choice:"etcd" choice:"http" choice:"plugin"`
+	DockerCompose      bool     `long:"docker-compose" description:"also generate docker-compose.env.yaml, listing all env vars"`
+	GHA                bool     `long:"gha" description:"also generate gha.env.yaml, a GitHub Actions env section taking the env vars from secrets or variables"`
+	Helm               bool     `long:"helm" description:"also generate a Helm values.yaml fragment and an _env.tpl helper under the helm dir"`
+	Terraform          bool     `long:"terraform" description:"also generate a Terraform variables.tf and terraform.tfvars.example under the terraform dir, declaring a variable per env var"`
+	Systemd            bool     `long:"systemd" description:"also generate systemd.env, for the EnvironmentFile directive of systemd units"`
+	JSONSchema         bool     `long:"json-schema" description:"also generate config.schema.json alongside the package, describing every env var"`
+	CUE                bool     `long:"cue" description:"also generate config.cue, a CUE definition of the env vars, and ValidateWithCUE"`
+	Readme             bool     `long:"readme" description:"also generate README.md in the package dir, documenting the package and its env vars"`
+	HeaderFile         string   `long:"header-file" description:"file holding a banner, e.g. a copyright and license notice, written at the top of the generated Go files"`
+	Changelog          bool     `long:"changelog" description:"append the env vars added, removed or retyped since the last generation to CONFIG_CHANGELOG.md in the package dir"`
+	Gitignore          bool     `long:"gitignore" description:"also create or append to .gitignore to exclude the .env file"`
+	NoGoGenerate       bool     `long:"no-go-generate" description:"don't write a go:generate directive into the generated config.go"`
+	NoTests            bool     `long:"no-tests" description:"don't generate config_test.go nor the unit tests of the optional files"`
+	CheckStale         bool     `long:"check-stale" description:"instead of generating, report whether the env files changed since the package was generated"`
+	TestStyle          string   `long:"test-style" description:"how config_test.go tests the package: with mocks, or reading a temp env file and env vars for real" choice:"mock" choice:"real" default:"mock"`
+}
+
+// Execute generates the config package, then keeps regenerating it
+// whenever the env files change if '--watch' is given.
+func (c *generateCommand) Execute(args []string) error {
+	if err := leavePackageDir(c); err != nil {
+		return err
+	}
+	if c.CheckStale {
+		return checkStale(filepath.Join(c.OutputDir, c.ConfigPackageName))
+	}
+	if c.Watch {
+		for _, envFile := range c.EnvFiles {
+			if envFile == cfg.StdinEnvFile {
+				return errors.New("watch mode can't watch the standard input")
+			}
+		}
+	}
+	generatedFiles, err := run(c)
+	if err != nil {
+		return err
+	}
+	for _, f := range generatedFiles {
+		printInfo("created:", f)
+	}
+	if !c.Watch {
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	printInfo("watching for changes, press Ctrl+C to stop")
+	return watch(ctx, c)
+}
+
+func run(opts *generateCommand) ([]string, error) {
+	genOpts := append(loggerOptions(),
+		cfg.WithFieldNamer(namingStrategies[opts.Naming]),
+		cfg.WithGeneratorVersion(currentVersion()),
+		cfg.WithSortOrder(cfg.SortOrder(opts.Sort)),
+		cfg.WithStructName(opts.StructName),
+		cfg.WithReaderName(opts.ReaderName),
+	)
+	if opts.OutputDir != "" {
+		genOpts = append(genOpts, cfg.WithOutputDir(opts.OutputDir))
+	}
+	if opts.Prefix != "" {
+		genOpts = append(genOpts, cfg.WithPrefix(opts.Prefix))
+	}
+	if len(opts.Include) > 0 {
+		genOpts = append(genOpts, cfg.WithInclude(opts.Include...))
+	}
+	if len(opts.Exclude) > 0 {
+		genOpts = append(genOpts, cfg.WithExclude(opts.Exclude...))
+	}
+	if opts.Immutable {
+		genOpts = append(genOpts, cfg.WithImmutable())
+	}
+	if opts.TrackUsage {
+		genOpts = append(genOpts, cfg.WithUsageTracking())
+	}
+	if opts.MaskSecrets {
+		genOpts = append(genOpts, cfg.WithMaskedSecrets())
+	}
+	if opts.ValidatorTags {
+		genOpts = append(genOpts, cfg.WithValidatorTags())
+	}
+	if opts.CheckPaths {
+		genOpts = append(genOpts, cfg.WithPathChecks())
+	}
+	if opts.InferCollections {
+		genOpts = append(genOpts, cfg.WithInferCollections())
+	}
+	if opts.Envrc {
+		genOpts = append(genOpts, cfg.WithEnvrc())
+	}
+	if opts.DefaultsFromValues {
+		genOpts = append(genOpts, cfg.WithDefaultsFromValues())
+	}
+	if opts.Profiles {
+		genOpts = append(genOpts, cfg.WithProfiles())
+	}
+	if opts.HotReload {
+		genOpts = append(genOpts, cfg.WithHotReload())
+	}
+	if opts.SIGHUPReload {
+		genOpts = append(genOpts, cfg.WithSIGHUPReload())
+	}
+	if opts.WithContext {
+		genOpts = append(genOpts, cfg.WithContext())
+	}
+	if opts.OTel {
+		genOpts = append(genOpts, cfg.WithTracing())
+	}
+	if opts.Metrics {
+		genOpts = append(genOpts, cfg.WithMetrics())
+	}
+	if opts.Singleton {
+		genOpts = append(genOpts, cfg.WithSingleton())
+	}
+	if opts.Clone {
+		genOpts = append(genOpts, cfg.WithClone())
+	}
+	if opts.DebugHandler {
+		genOpts = append(genOpts, cfg.WithDebugHandler())
+	}
+	if opts.Embed {
+		genOpts = append(genOpts, cfg.WithEmbeddedEnvFile())
+	}
+	if opts.Checksum {
+		genOpts = append(genOpts, cfg.WithSchemaChecksum())
+	}
+	if opts.MaxLineLength != cfg.DefaultMaxLineLength {
+		genOpts = append(genOpts, cfg.WithMaxLineLength(opts.MaxLineLength))
+	}
+	if opts.Benchmarks {
+		genOpts = append(genOpts, cfg.WithBenchmarks())
+	}
+	if opts.Strict {
+		genOpts = append(genOpts, cfg.WithStrict())
+	}
+	if opts.Age {
+		genOpts = append(genOpts, cfg.WithAgeEnvFile())
+	}
+	if opts.SecretFiles {
+		genOpts = append(genOpts, cfg.WithSecretFiles())
+	}
+	if opts.Overrides {
+		genOpts = append(genOpts, cfg.WithOverrides())
+	}
+	if opts.SchemaVersion != 0 {
+		genOpts = append(genOpts, cfg.WithSchemaVersion(opts.SchemaVersion))
+	}
+	if opts.Flags != "" {
+		genOpts = append(genOpts, cfg.WithFlags(cfg.FlagsLibrary(opts.Flags)))
+	}
+	if opts.Features {
+		genOpts = append(genOpts, cfg.WithFeatures())
+	}
+	if opts.Provider {
+		genOpts = append(genOpts, cfg.WithConfigProvider())
+	}
+	if opts.TestConfig {
+		genOpts = append(genOpts, cfg.WithTestConfigPackage())
+	}
+	if opts.Verify {
+		genOpts = append(genOpts, cfg.WithVerify())
+	}
+	for _, backend := range opts.Secrets {
+		genOpts = append(genOpts, cfg.WithSecretsBackends(cfg.SecretsBackend(backend)))
+	}
+	if opts.DockerCompose {
+		genOpts = append(genOpts, cfg.WithDockerCompose())
+	}
+	if opts.GHA {
+		genOpts = append(genOpts, cfg.WithGHA())
+	}
+	if opts.Helm {
+		genOpts = append(genOpts, cfg.WithHelm())
+	}
+	if opts.Terraform {
+		genOpts = append(genOpts, cfg.WithTerraform())
+	}
+	if opts.Systemd {
+		genOpts = append(genOpts, cfg.WithSystemd())
+	}
+	if opts.JSONSchema {
+		genOpts = append(genOpts, cfg.WithJSONSchema())
+	}
+	if opts.CUE {
+		genOpts = append(genOpts, cfg.WithCUE())
+	}
+	if opts.Readme {
+		genOpts = append(genOpts, cfg.WithReadme())
+	}
+	if opts.Gitignore {
+		genOpts = append(genOpts, cfg.WithGitignore())
+	}
+	if opts.Changelog {
+		genOpts = append(genOpts, cfg.WithChangelog())
+	}
+	for _, source := range opts.Remote {
+		genOpts = append(genOpts, cfg.WithRemoteSources(cfg.RemoteSource(source)))
+	}
+	if opts.HeaderFile != "" {
+		header, err := os.ReadFile(opts.HeaderFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading header file %s", opts.HeaderFile)
+		}
+		genOpts = append(genOpts, cfg.WithHeader(string(header)))
+	}
+	if opts.NoGoGenerate {
+		genOpts = append(genOpts, cfg.WithoutGoGenerate())
+	} else {
+		flags := goGenerateFlags(generateArgs(os.Args[1:]))
+		if opts.HeaderFile != "" {
+			flags = append(flags, headerFileFlag(filepath.Join(opts.OutputDir, opts.ConfigPackageName), opts.HeaderFile)...)
+		}
+		genOpts = append(genOpts, cfg.WithGoGenerateFlags(flags...))
+	}
+	if opts.NoTests {
+		genOpts = append(genOpts, cfg.WithoutTests())
+	}
+	genOpts = append(genOpts, cfg.WithTestStyle(cfg.TestStyle(opts.TestStyle)))
+	genOpts = append(genOpts, cfg.WithDialect(cfg.Dialect(opts.Dialect)))
+	generator := cfg.NewGenerator(opts.ConfigPackageName, genOpts...)
+	if opts.K8sManifest {
+		if len(opts.EnvFiles) != 1 {
+			return nil, errors.New("--k8s-manifest requires a single manifest given with -e")
+		}
+		return generator.GenerateConfigPackageFromK8sManifest(opts.EnvFiles[0])
+	}
+	if len(opts.EnvFiles) > 0 {
+		return generator.GenerateConfigPackageFromEnvFiles(opts.EnvFiles...)
+	}
+	return generator.GenerateConfigPackage()
+}
+
+// checkStale reports the env files that changed since the package
+// in the given dir was generated from them, failing if any did.
+func checkStale(packageDir string) error {
+	configFilePath := filepath.Join(packageDir, "config.go")
+	stale, err := cfg.StaleSources(configFilePath)
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		for _, envFilePath := range stale {
+			fmt.Println("stale:", envFilePath)
+		}
+		return errors.Errorf("%d env file(s) changed since %s was generated", len(stale), configFilePath)
+	}
+	printInfo("ok:", configFilePath, "is up to date")
+	return nil
+}
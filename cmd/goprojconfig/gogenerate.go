@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// leavePackageDir changes the working dir to the parent of the package
+// dir when goprojconfig is run from the latter, as 'go generate' does,
+// so that the package is not generated inside itself. Relative env file
+// and header file paths, which are then relative to the package dir, are
+// adjusted, except for the standard input.
+func leavePackageDir(opts *generateCommand) error {
+	if os.Getenv("GOFILE") == "" || os.Getenv("GOPACKAGE") != opts.ConfigPackageName {
+		return nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return errors.Wrap(err, "getting working dir")
+	}
+	if filepath.Base(wd) != opts.ConfigPackageName {
+		return nil
+	}
+	for i, envFilePath := range opts.EnvFiles {
+		if envFilePath != cfg.StdinEnvFile && !filepath.IsAbs(envFilePath) {
+			opts.EnvFiles[i] = filepath.Join(opts.ConfigPackageName, envFilePath)
+		}
+	}
+	if opts.HeaderFile != "" && !filepath.IsAbs(opts.HeaderFile) {
+		opts.HeaderFile = filepath.Join(opts.ConfigPackageName, opts.HeaderFile)
+	}
+	if err := os.Chdir(filepath.Dir(wd)); err != nil {
+		return errors.Wrap(err, "changing working dir")
+	}
+	return nil
+}
+
+// goGenerateFlags returns the given 'generate' command arguments that are
+// to be kept in the 'go:generate' directive of the generated package,
+// leaving out the package name and the env files, which the generator
+// writes itself, the output dir, which is the dir the package dir is left
+// for, the header file, written by headerFileFlag, and the ones that only
+// make sense interactively.
+func goGenerateFlags(args []string) []string {
+	var flags []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-p" || arg == "--packageName" || arg == "-e" || arg == "--envFile" || arg == "-o" || arg == "--output-dir" || arg == "--header-file":
+			i++
+		case strings.HasPrefix(arg, "--packageName=") || strings.HasPrefix(arg, "--envFile=") || strings.HasPrefix(arg, "--output-dir=") || strings.HasPrefix(arg, "--header-file="):
+		case !strings.HasPrefix(arg, "--") && (strings.HasPrefix(arg, "-p") || strings.HasPrefix(arg, "-e") || strings.HasPrefix(arg, "-o")):
+		case arg == "--watch" || arg == "-v" || arg == "--verbose" || arg == "-q" || arg == "--quiet":
+		default:
+			flags = append(flags, arg)
+		}
+	}
+	return flags
+}
+
+// headerFileFlag returns the '--header-file' flag of the 'go:generate'
+// directive of the package generated in the given dir, with the given
+// header file path made relative to the package dir if it's relative,
+// as 'go generate' runs the directive from there.
+func headerFileFlag(packageDir, headerFilePath string) []string {
+	if !filepath.IsAbs(headerFilePath) {
+		absPackageDir, err := filepath.Abs(packageDir)
+		if err == nil {
+			absHeaderFilePath, err := filepath.Abs(headerFilePath)
+			if err == nil {
+				if rel, err := filepath.Rel(absPackageDir, absHeaderFilePath); err == nil {
+					headerFilePath = rel
+				}
+			}
+		}
+	}
+	return []string{"--header-file", filepath.ToSlash(headerFilePath)}
+}
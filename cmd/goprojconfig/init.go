@@ -0,0 +1,222 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// envVarNameRegexp matches valid env var names.
+var envVarNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// initCommand interactively prompts for everything needed to generate
+// a config package, for users who don't have an env file yet.
+type initCommand struct{}
+
+// Execute runs the wizard, writes the env file built from the answers
+// and generates the config package from it.
+func (c *initCommand) Execute(args []string) error {
+	w := &wizard{in: bufio.NewScanner(os.Stdin), out: os.Stdout}
+	answers, err := w.run()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(answers.envFile); err == nil {
+		return errors.Wrap(cfg.ErrFileExists, answers.envFile)
+	}
+	if err := os.WriteFile(answers.envFile, []byte(answers.envFileContent()), 0644); err != nil {
+		return errors.Wrapf(err, "writing env file %s", answers.envFile)
+	}
+	printInfo("created:", answers.envFile)
+	generatedFiles, err := cfg.NewGenerator(answers.packageName, answers.generatorOptions()...).
+		GenerateConfigPackageFromEnvFiles(answers.envFile)
+	if err != nil {
+		return err
+	}
+	for _, f := range generatedFiles {
+		printInfo("created:", f)
+	}
+	return nil
+}
+
+// wizardVar holds the answers about a variable.
+type wizardVar struct {
+	name         string
+	fieldType    string
+	required     bool
+	defaultValue string
+}
+
+// wizardAnswers holds the answers given to the wizard.
+type wizardAnswers struct {
+	packageName   string
+	envFile       string
+	remoteSource  string
+	secretBackend string
+	vars          []wizardVar
+}
+
+// generatorOptions returns the generator options matching the answers.
+// Env file values are the variables defaults, and the go:generate
+// directive carries the flags needed to regenerate the package.
+func (a *wizardAnswers) generatorOptions() []cfg.Option {
+	opts := append(loggerOptions(), cfg.WithDefaultsFromValues())
+	flags := []string{"--defaults-from-values"}
+	if a.remoteSource != "" {
+		opts = append(opts, cfg.WithRemoteSources(cfg.RemoteSource(a.remoteSource)))
+		flags = append(flags, "--remote", a.remoteSource)
+	}
+	if a.secretBackend != "" {
+		opts = append(opts, cfg.WithSecretsBackends(cfg.SecretsBackend(a.secretBackend)))
+		flags = append(flags, "--secrets", a.secretBackend)
+	}
+	return append(opts, cfg.WithGoGenerateFlags(flags...))
+}
+
+// envFileContent returns the env file defining the variables, annotated
+// with their types and with whether they're required.
+func (a *wizardAnswers) envFileContent() string {
+	var sb strings.Builder
+	for _, v := range a.vars {
+		fmt.Fprintf(&sb, "# type: %s\n", v.fieldType)
+		if v.required {
+			sb.WriteString("# required\n")
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", v.name, quoteEnvValue(v.defaultValue))
+	}
+	return sb.String()
+}
+
+// quoteEnvValue single quotes the given value when it has characters
+// that would otherwise be taken as comments, quotes or references,
+// or double quotes it if it has single quotes itself.
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t#\"'$\\") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`"`, `\"`, "$", `\$`).Replace(value) + `"`
+}
+
+// wizard prompts for answers, reading them line by line.
+type wizard struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// run prompts for all the answers.
+func (w *wizard) run() (*wizardAnswers, error) {
+	var answers wizardAnswers
+	var err error
+	for {
+		if answers.packageName, err = w.ask("package name", "config", nil); err != nil {
+			return nil, err
+		}
+		if err = cfg.ValidatePackageName(answers.packageName); err == nil {
+			break
+		}
+		fmt.Fprintln(w.out, err)
+	}
+	if answers.envFile, err = w.ask("env file", ".env", nil); err != nil {
+		return nil, err
+	}
+	source, err := w.ask("source format (env, consul, etcd, http)", "env", []string{"env", "consul", "etcd", "http"})
+	if err != nil {
+		return nil, err
+	}
+	if source != "env" {
+		answers.remoteSource = source
+	}
+	backend, err := w.ask("secrets backend (none, aws, gcp, azure, vault, doppler, 1password)", "none", []string{"none", "aws", "gcp", "azure", "vault", "doppler", "1password"})
+	if err != nil {
+		return nil, err
+	}
+	if backend != "none" {
+		answers.secretBackend = backend
+	}
+	fmt.Fprintln(w.out, "add variables, leave the name empty to finish")
+	for {
+		v, err := w.askVar()
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			break
+		}
+		answers.vars = append(answers.vars, *v)
+	}
+	if len(answers.vars) == 0 {
+		return nil, errors.New("no variables given")
+	}
+	return &answers, nil
+}
+
+// askVar prompts for a variable, returning nil when no name is given.
+func (w *wizard) askVar() (*wizardVar, error) {
+	name, err := w.ask("name", "", nil)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	if !envVarNameRegexp.MatchString(name) {
+		fmt.Fprintf(w.out, "invalid name %s\n", name)
+		return w.askVar()
+	}
+	v := &wizardVar{name: name}
+	if v.fieldType, err = w.ask("  type (string, int, float64, bool, []string)", "string", []string{"string", "int", "float64", "bool", "[]string"}); err != nil {
+		return nil, err
+	}
+	if v.defaultValue, err = w.ask("  default value, if any", "", nil); err != nil {
+		return nil, err
+	}
+	if v.defaultValue == "" {
+		required, err := w.ask("  required (y, n)", "y", []string{"y", "n"})
+		if err != nil {
+			return nil, err
+		}
+		v.required = required == "y"
+	}
+	return v, nil
+}
+
+// ask prompts for an answer, returning the given default answer when
+// none is given. Answers not in the given choices, if any, are refused.
+func (w *wizard) ask(question, defaultAnswer string, choices []string) (string, error) {
+	for {
+		if defaultAnswer != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", question, defaultAnswer)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", question)
+		}
+		if !w.in.Scan() {
+			if err := w.in.Err(); err != nil {
+				return "", errors.Wrap(err, "reading answer")
+			}
+			return "", errors.New("no answer given")
+		}
+		answer := strings.TrimSpace(w.in.Text())
+		if answer == "" {
+			answer = defaultAnswer
+		}
+		if len(choices) == 0 {
+			return answer, nil
+		}
+		for _, choice := range choices {
+			if answer == choice {
+				return answer, nil
+			}
+		}
+		fmt.Fprintf(w.out, "invalid answer %s\n", answer)
+	}
+}
@@ -6,47 +6,257 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
+	"github.com/pkg/errors"
 	"github.com/tiagomelo/go-project-config/cfg"
 )
 
-type options struct {
-	ConfigPackageName string `short:"p" long:"packageName" description:"package name" required:"true"`
-	EnvFile           string `short:"e" long:"envFile" description:"env file" default:""`
+// namingStrategies maps the values accepted by the '--naming'
+// option to the correspondent field namers.
+var namingStrategies = map[string]func(envKey string) string{
+	"camel":  cfg.CamelCaseFieldNamer,
+	"pascal": cfg.PascalCaseFieldNamer,
+	"golint": cfg.GolintFieldNamer,
 }
 
-func run(opts *options) ([]string, error) {
-	generator := cfg.NewGenerator(opts.ConfigPackageName)
-	if opts.EnvFile != "" {
-		return generator.GenerateConfigPackageFromEnvFile(opts.EnvFile)
+// globalOptions are the options accepted by every command.
+type globalOptions struct {
+	Verbose bool `short:"v" long:"verbose" description:"log each generation step to the standard error"`
+	Quiet   bool `short:"q" long:"quiet" description:"only print errors and command results, e.g. no created files"`
+}
+
+// globalOpts holds the parsed global options.
+var globalOpts globalOptions
+
+// loggerOptions returns the generator options that make generators log
+// their progress to the standard error when '--verbose' is given.
+func loggerOptions() []cfg.Option {
+	if !globalOpts.Verbose {
+		return nil
+	}
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return []cfg.Option{cfg.WithLogger(slog.New(handler))}
+}
+
+// printInfo prints the given informational message,
+// unless '--quiet' is given.
+func printInfo(a ...interface{}) {
+	if !globalOpts.Quiet {
+		fmt.Println(a...)
+	}
+}
+
+// command describes a subcommand.
+type command struct {
+	name             string
+	shortDescription string
+	longDescription  string
+	data             interface{}
+}
+
+// commands are the subcommands, with 'generate' being the
+// default one when only flags are given.
+var commands = []command{
+	{
+		name:             "generate",
+		shortDescription: "generate a config package from env files",
+		longDescription: "Generates a config package from the env files given with -e, or a sample one along with " +
+			"a sample .env file if none is given. It's the default command when only flags are given.",
+		data: &generateCommand{},
+	},
+	{
+		name:             "add",
+		shortDescription: "add env vars to an existing config package",
+		longDescription: "Adds each VAR_NAME[:type] argument as an optional field of the given type, or string if " +
+			"none is given, to the Config struct of the package given with -p, and to the env file given with -e, " +
+			"so that adding a setting doesn't require regenerating the package.",
+		data: &addCommand{},
+	},
+	{
+		name:             "check",
+		shortDescription: "validate env vars against the generated Config",
+		longDescription: "Validates the env vars defined in the env files given with -e, or the process environment " +
+			"if none is given, against the Config struct of the package given with -p, reporting required variables " +
+			"that are not set and values that can't be parsed into the declared types.",
+		data: &checkCommand{},
+	},
+	{
+		name:             "diff",
+		shortDescription: "show how regenerating would change the Config",
+		longDescription: "Compares the Config struct of the package given with -p with the one that would be " +
+			"generated from the env files given with -e, listing the fields that would be added, removed or changed.",
+		data: &diffCommand{},
+	},
+	{
+		name:             "discover",
+		shortDescription: "generate config packages for the env files of a repository",
+		longDescription: "Walks the repository for env files and go.mod files, prints a plan of the config packages " +
+			"to generate next to the env files that belong to Go modules, with their import paths, and generates " +
+			"them once confirmed.",
+		data: &discoverCommand{},
+	},
+	{
+		name:             "docs",
+		shortDescription: "document the env vars",
+		longDescription: "Generates a Markdown table documenting the env vars defined in the env files given " +
+			"with -e, with their types, whether they're required, their defaults and their descriptions, " +
+			"or a JSON catalog of them with --docs-format json.",
+		data: &docsCommand{},
+	},
+	{
+		name:             "decrypt",
+		shortDescription: "decrypt an env file encrypted with age",
+		longDescription: "Decrypts the env file given with -e, encrypted with the encrypt command, with the identities " +
+			"of the age identity file given with -i, writing it without the .age extension, readable by its owner only.",
+		data: &decryptCommand{},
+	},
+	{
+		name:             "encrypt",
+		shortDescription: "encrypt an env file with age",
+		longDescription: "Encrypts the env file given with -e with age for the public keys given with -r, writing it " +
+			"with the .age extension, so that it can be committed and read by the generated package with --age.",
+		data: &encryptCommand{},
+	},
+	{
+		name:             "envfile",
+		shortDescription: "generate a sample env file from an existing Config",
+		longDescription: "Generates a sample env file listing every env var read by the Config struct declared in " +
+			"the Go file given with --from, with their types and defaults, for projects that have a Config struct " +
+			"but no env file.",
+		data: &envFileCommand{},
+	},
+	{
+		name:             "init",
+		shortDescription: "interactively generate a config package",
+		longDescription: "Prompts for the package name, the env file, the source format, the secrets backend and " +
+			"the variables, with their types, whether they're required and their default values, then writes the " +
+			"env file and generates the config package from it.",
+		data: &initCommand{},
+	},
+	{
+		name:             "remove",
+		shortDescription: "remove env vars from an existing config package",
+		longDescription: "Removes each VAR_NAME argument from the Config struct of the package given with -p, along " +
+			"with the validations of its field, and from the env files given with -e. With --usages, the uses of " +
+			"the fields in the module, which have to be removed too, are listed first.",
+		data: &removeCommand{},
+	},
+	{
+		name:             "rename",
+		shortDescription: "rename an env var of an existing config package",
+		longDescription: "Renames the OLD env var to NEW in the Config struct of the package given with -p, renaming " +
+			"its field after it, and in the env files given with -e. With --usages, the uses of the field in the " +
+			"module, which have to be renamed too, are listed first.",
+		data: &renameCommand{},
+	},
+	{
+		name:             "verify",
+		shortDescription: "check that the Config and the env files define the same env vars",
+		longDescription: "Checks that every field of the Config struct of the package given with -p has an entry in " +
+			"each of the env files given with -e (.env and .env.example by default), and vice versa, printing a " +
+			"table of the env vars missing from any of them.",
+		data: &verifyCommand{},
+	},
+	{
+		name:             "version",
+		shortDescription: "print the version",
+		longDescription:  "Prints the version of goprojconfig.",
+		data:             &versionCommand{},
+	},
+}
+
+// Exit codes, so that wrappers can tell failure causes
+// apart instead of matching error messages.
+const (
+	exitError              = 1
+	exitUsage              = 2
+	exitInvalidPackageName = 3
+	exitEnvFileNotFound    = 4
+	exitEnvParse           = 5
+	exitFileExists         = 6
+)
+
+// exitCode returns the exit code for the given error,
+// which has already been printed by the parser.
+func exitCode(err error) int {
+	var flagsErr *flags.Error
+	switch {
+	case errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp:
+		return 0
+	case errors.As(err, &flagsErr):
+		return exitUsage
+	case errors.Is(err, cfg.ErrInvalidPackageName):
+		return exitInvalidPackageName
+	case errors.Is(err, cfg.ErrEnvFileNotFound):
+		return exitEnvFileNotFound
+	case errors.Is(err, cfg.ErrEnvParse):
+		return exitEnvParse
+	case errors.Is(err, cfg.ErrFileExists):
+		return exitFileExists
 	}
-	return generator.GenerateConfigPackage()
+	return exitError
+}
+
+// isGlobalOption reports whether the given command line argument
+// is made of global options, e.g. '-q', '--verbose' or '-vq'.
+func isGlobalOption(arg string) bool {
+	parser := flags.NewParser(&globalOptions{}, flags.None)
+	if longName, ok := strings.CutPrefix(arg, "--"); ok {
+		return parser.FindOptionByLongName(longName) != nil
+	}
+	shortNames, ok := strings.CutPrefix(arg, "-")
+	if !ok || shortNames == "" {
+		return false
+	}
+	for _, shortName := range shortNames {
+		if parser.FindOptionByShortName(shortName) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// globalOptionsCount returns the number of global options
+// the given command line arguments start with.
+func globalOptionsCount(args []string) int {
+	n := 0
+	for n < len(args) && isGlobalOption(args[n]) {
+		n++
+	}
+	return n
+}
+
+// commandArgs returns the given command line arguments, with the 'generate'
+// command inserted after the global options when they aren't followed by
+// a command, so that 'goprojconfig -p <package> -e <env file>' keeps working.
+func commandArgs(args []string) []string {
+	n := globalOptionsCount(args)
+	if len(args) == 0 || n < len(args) && (!strings.HasPrefix(args[n], "-") || args[n] == "-h" || args[n] == "--help") {
+		return args
+	}
+	return append(append(args[:n:n], "generate"), args[n:]...)
+}
+
+// generateArgs returns the arguments of the 'generate' command
+// in the given command line arguments.
+func generateArgs(args []string) []string {
+	args = commandArgs(args)
+	return args[globalOptionsCount(args)+1:]
 }
 
 func main() {
-	var opts options
-	parser := flags.NewParser(&opts, flags.Default)
-	if _, err := parser.Parse(); err != nil {
-		switch flagsErr := err.(type) {
-		case flags.ErrorType:
-			if flagsErr == flags.ErrHelp {
-				fmt.Println(err)
-				os.Exit(0)
-			}
+	parser := flags.NewParser(&globalOpts, flags.Default)
+	for _, c := range commands {
+		if _, err := parser.AddCommand(c.name, c.shortDescription, c.longDescription, c.data); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
-		default:
-			os.Exit(1)
 		}
 	}
-	generatedFiles, err := run(&opts)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-	for _, f := range generatedFiles {
-		fmt.Println("created:", f)
+	if _, err := parser.ParseArgs(commandArgs(os.Args[1:])); err != nil {
+		os.Exit(exitCode(err))
 	}
 }
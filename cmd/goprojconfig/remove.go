@@ -0,0 +1,56 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// removeCommand removes env vars read by the 'Config' struct of an
+// existing generated package, along with their fields, and from its
+// env files.
+type removeCommand struct {
+	ConfigPackageName string   `short:"p" long:"packageName" description:"package name" required:"true"`
+	EnvFiles          []string `short:"e" long:"envFile" description:"env file to remove the env vars from, can be given multiple times" default:".env"`
+	StructName        string   `long:"structName" description:"name of the generated struct" default:"Config"`
+	Usages            bool     `long:"usages" description:"list the uses of the fields in the module, which have to be removed too"`
+	Args              struct {
+		Vars []string `positional-arg-name:"VAR_NAME" required:"1"`
+	} `positional-args:"yes"`
+}
+
+// Execute removes each env var, listing the uses of its field
+// first when '--usages' is given, then the rewritten files, and
+// then the references to it left in the env files, which expand
+// to empty strings.
+func (c *removeCommand) Execute(args []string) error {
+	configFilePath := filepath.Join(c.ConfigPackageName, "config.go")
+	for _, key := range c.Args.Vars {
+		if c.Usages {
+			if err := printUsages(configFilePath, key, c.StructName); err != nil {
+				return err
+			}
+		}
+		envFiles, err := cfg.RemoveEnvVar(configFilePath, c.EnvFiles, key, append(loggerOptions(),
+			cfg.WithStructName(c.StructName),
+		)...)
+		if err != nil {
+			return err
+		}
+		printInfo("removed:", key, "from", strings.Join(append([]string{configFilePath}, envFiles...), ", "))
+		refs, err := cfg.EnvVarReferences(configFilePath, c.EnvFiles, key, cfg.WithStructName(c.StructName))
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			fmt.Println("warning:", ref, "references the removed", key)
+		}
+	}
+	return nil
+}
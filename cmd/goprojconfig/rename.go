@@ -0,0 +1,60 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// renameCommand renames an env var read by the 'Config' struct of an
+// existing generated package, along with its field, and in its env files.
+type renameCommand struct {
+	ConfigPackageName string   `short:"p" long:"packageName" description:"package name" required:"true"`
+	EnvFiles          []string `short:"e" long:"envFile" description:"env file to rename the env var in, can be given multiple times" default:".env"`
+	Naming            string   `long:"naming" description:"field naming strategy" choice:"camel" choice:"pascal" choice:"golint" default:"camel"`
+	StructName        string   `long:"structName" description:"name of the generated struct" default:"Config"`
+	Usages            bool     `long:"usages" description:"list the uses of the field in the module, which have to be renamed too"`
+	Args              struct {
+		OldName string `positional-arg-name:"OLD" required:"yes"`
+		NewName string `positional-arg-name:"NEW" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+// Execute renames the env var, listing the uses of its field
+// first when '--usages' is given, and then the rewritten files.
+func (c *renameCommand) Execute(args []string) error {
+	configFilePath := filepath.Join(c.ConfigPackageName, "config.go")
+	if c.Usages {
+		if err := printUsages(configFilePath, c.Args.OldName, c.StructName); err != nil {
+			return err
+		}
+	}
+	envFiles, err := cfg.RenameEnvVar(configFilePath, c.EnvFiles, c.Args.OldName, c.Args.NewName, append(loggerOptions(),
+		cfg.WithFieldNamer(namingStrategies[c.Naming]),
+		cfg.WithStructName(c.StructName),
+	)...)
+	if err != nil {
+		return err
+	}
+	printInfo("renamed:", c.Args.OldName, "to", c.Args.NewName, "in", strings.Join(append([]string{configFilePath}, envFiles...), ", "))
+	return nil
+}
+
+// printUsages prints the uses of the field reading the given env var
+// of the given struct declared in the given Go file, one per line.
+func printUsages(configFilePath, key, structName string) error {
+	usages, err := cfg.FieldUsages(configFilePath, key, cfg.WithStructName(structName))
+	if err != nil {
+		return err
+	}
+	for _, usage := range usages {
+		fmt.Println("usage:", usage)
+	}
+	return nil
+}
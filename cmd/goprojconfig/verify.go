@@ -0,0 +1,59 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-project-config/cfg"
+)
+
+// verifyCommand checks that the 'Config' struct of an existing generated
+// package and the tracked env files define the same env vars.
+type verifyCommand struct {
+	ConfigPackageName string   `short:"p" long:"packageName" description:"package name" required:"true"`
+	StructName        string   `long:"structName" description:"name of the generated struct" default:"Config"`
+	EnvFiles          []string `short:"e" long:"envFile" description:"tracked env file, can be repeated" default:".env" default:".env.example"`
+}
+
+// Execute prints a table of the env vars missing from the struct or
+// from any of the env files, failing if there's any.
+func (c *verifyCommand) Execute(args []string) error {
+	configFilePath := filepath.Join(c.ConfigPackageName, "config.go")
+	drifts, err := cfg.FindDrift(configFilePath, c.EnvFiles, append(loggerOptions(), cfg.WithStructName(c.StructName))...)
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		printInfo("ok:", configFilePath, "and", strings.Join(c.EnvFiles, ", "), "define the same env vars")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ENV VAR\t%s\t%s\n", configFilePath, strings.Join(c.EnvFiles, "\t"))
+	for _, d := range drifts {
+		fmt.Fprintf(w, "%s\t%s", d.Key, presence(d.InConfig))
+		for _, inEnvFile := range d.InEnvFiles {
+			fmt.Fprintf(w, "\t%s", presence(inEnvFile))
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "printing drift table")
+	}
+	return errors.Errorf("%d env var(s) drifted between %s and the env files", len(drifts), configFilePath)
+}
+
+// presence returns the drift table cell telling whether an env var is there.
+func presence(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return "missing"
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// version is the version of goprojconfig, which can be set at build
+// time with '-ldflags "-X main.version=<version>"'. It defaults to the
+// module version when installed with 'go install'.
+var version string
+
+// versionCommand prints the version of goprojconfig.
+type versionCommand struct{}
+
+// Execute prints the version.
+func (c *versionCommand) Execute(args []string) error {
+	fmt.Println("goprojconfig", currentVersion())
+	return nil
+}
+
+// currentVersion returns the version of goprojconfig.
+func currentVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
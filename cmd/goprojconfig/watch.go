@@ -0,0 +1,71 @@
+// Copyright (c) 2024 Tiago Melo. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// watchDebounce is how long to wait for further changes before
+// regenerating, since editors often save files in several steps.
+const watchDebounce = 100 * time.Millisecond
+
+// watch re-runs generation whenever one of the env files changes,
+// until the given context is done.
+func watch(ctx context.Context, opts *generateCommand) error {
+	if len(opts.EnvFiles) == 0 {
+		return errors.New("watch mode requires at least one env file")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "creating watcher")
+	}
+	defer watcher.Close()
+	// Directories are watched instead of the files themselves, since
+	// editors usually replace a file when saving it.
+	envFiles := make(map[string]bool)
+	for _, envFile := range opts.EnvFiles {
+		envFiles[filepath.Clean(envFile)] = true
+		dir := filepath.Dir(envFile)
+		if err := watcher.Add(dir); err != nil {
+			return errors.Wrapf(err, "watching dir %s", dir)
+		}
+	}
+	var regenerate <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if envFiles[filepath.Clean(event.Name)] && event.Has(fsnotify.Write|fsnotify.Create) {
+				regenerate = time.After(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return errors.Wrap(err, "watching env files")
+		case <-regenerate:
+			regenerate = nil
+			generatedFiles, err := run(opts)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			for _, f := range generatedFiles {
+				printInfo("regenerated:", f)
+			}
+		}
+	}
+}